@@ -0,0 +1,52 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+// BackupInteractor provide backup data
+type BackupInteractor struct {
+	repository repository.Bigtable
+}
+
+// NewBackupInteractor returns initialized BackupInteractor
+func NewBackupInteractor(r repository.Bigtable) *BackupInteractor {
+	return &BackupInteractor{
+		repository: r,
+	}
+}
+
+// TemporaryTable returns the name of the table restored from the backup of the table at the time
+func TemporaryTable(table string, t time.Time) string {
+	return fmt.Sprintf("%s-restore-%d", table, t.Unix())
+}
+
+// GetRows returns rows in the backup via a temporary restored table, and deletes the table after reading
+func (t *BackupInteractor) GetRows(ctx context.Context, table, cluster, backup string, rs bigtable.RowSet, opts ...bigtable.ReadOption) (rows []*domain.Row, err error) {
+	tmp := TemporaryTable(table, time.Now())
+	if err := t.repository.RestoreTable(ctx, tmp, cluster, backup); err != nil {
+		// the restore may have created the table before canceled
+		if ctx.Err() != nil {
+			t.repository.DeleteTable(context.Background(), tmp)
+		}
+		return nil, err
+	}
+	// deleted without the context of the read, canceled by an interrupt
+	defer func() {
+		if derr := t.repository.DeleteTable(context.Background(), tmp); derr != nil && err == nil {
+			err = fmt.Errorf("failed to delete the temporary table %q: %v", tmp, derr)
+		}
+	}()
+
+	tbl, err := t.repository.GetRows(ctx, tmp, rs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return tbl.Rows, nil
+}
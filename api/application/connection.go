@@ -0,0 +1,27 @@
+package application
+
+import (
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+// ConnectionInteractor provide connection management
+type ConnectionInteractor struct {
+	repository repository.Bigtable
+}
+
+// NewConnectionInteractor returns initialized ConnectionInteractor
+func NewConnectionInteractor(r repository.Bigtable) *ConnectionInteractor {
+	return &ConnectionInteractor{
+		repository: r,
+	}
+}
+
+// ResetCircuit closes the circuit breaker, returns false if the repository doesn't have it
+func (t *ConnectionInteractor) ResetCircuit() bool {
+	cb, ok := t.repository.(repository.CircuitBreaker)
+	if !ok {
+		return false
+	}
+	cb.Reset()
+	return true
+}
@@ -0,0 +1,99 @@
+package application
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+// IndexInteractor provide the local index of the row keys
+type IndexInteractor struct {
+	repository repository.Bigtable
+	index      repository.KeyIndex
+}
+
+// NewIndexInteractor returns initialized IndexInteractor
+func NewIndexInteractor(r repository.Bigtable, i repository.KeyIndex) *IndexInteractor {
+	return &IndexInteractor{
+		repository: r,
+		index:      i,
+	}
+}
+
+// Build stores all keys of the table in the index, returns a number of the keys
+func (t *IndexInteractor) Build(ctx context.Context, table string) (int, error) {
+	keys, err := t.repository.Keys(ctx, table, bigtable.InfiniteRange(""))
+	if err != nil {
+		return 0, err
+	}
+	if err := t.index.Save(table, keys); err != nil {
+		return 0, err
+	}
+	return len(keys), nil
+}
+
+// BloomFilter returns the bloom filter of all keys of the table with the false positive rate, and a number of the keys
+func (t *IndexInteractor) BloomFilter(ctx context.Context, table string, p float64) (*domain.BloomFilter, int, error) {
+	keys, err := t.repository.Keys(ctx, table, bigtable.InfiniteRange(""))
+	if err != nil {
+		return nil, 0, err
+	}
+	f := domain.NewBloomFilter(len(keys), p)
+	for _, k := range keys {
+		f.Add(k)
+	}
+	return f, len(keys), nil
+}
+
+// Search returns the indexed keys matching the pattern grouped by the table,
+// searches all indexed tables when the tables are empty. 0 limit returns all keys
+func (t *IndexInteractor) Search(pattern string, tables []string, limit int) (map[string][]string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		if tables, err = t.index.Tables(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(tables)
+
+	prefix := literalPrefix(pattern)
+	found := map[string][]string{}
+	cnt := 0
+	for _, table := range tables {
+		err := t.index.Scan(table, prefix, func(key string) bool {
+			if re.MatchString(key) {
+				found[table] = append(found[table], key)
+				cnt++
+			}
+			return limit == 0 || cnt < limit
+		})
+		if err != nil {
+			return nil, err
+		}
+		if limit > 0 && cnt >= limit {
+			break
+		}
+	}
+	return found, nil
+}
+
+// literalPrefix returns the prefix of all keys matching the anchored pattern to skip the other keys
+func literalPrefix(pattern string) string {
+	if !strings.HasPrefix(pattern, "^") || strings.Contains(pattern, "|") {
+		return ""
+	}
+	re, err := regexp.Compile(pattern[1:])
+	if err != nil {
+		return ""
+	}
+	prefix, _ := re.LiteralPrefix()
+	return prefix
+}
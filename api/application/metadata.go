@@ -0,0 +1,68 @@
+package application
+
+import (
+	"context"
+
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+// MetadataInteractor provide the metadata of the instance through the cache shared by the sessions
+type MetadataInteractor struct {
+	repository repository.Bigtable
+	cache      repository.MetadataCache
+}
+
+// NewMetadataInteractor returns initialized MetadataInteractor, the nil cache always reads the repository
+func NewMetadataInteractor(r repository.Bigtable, c repository.MetadataCache) *MetadataInteractor {
+	return &MetadataInteractor{
+		repository: r,
+		cache:      c,
+	}
+}
+
+// Tables returns the tables, the cached tables are returned until they expire
+func (t *MetadataInteractor) Tables(ctx context.Context) ([]string, error) {
+	if t.cache != nil {
+		if tables, ok := t.cache.Tables(); ok {
+			return tables, nil
+		}
+	}
+	tables, err := t.repository.Tables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.cache != nil {
+		// the cache is best effort
+		t.cache.SaveTables(tables)
+	}
+	return tables, nil
+}
+
+// Families returns the column families of the table, the cached families are returned until they expire
+func (t *MetadataInteractor) Families(ctx context.Context, table string) ([]string, error) {
+	if t.cache != nil {
+		if families, ok := t.cache.Families(table); ok {
+			return families, nil
+		}
+	}
+	info, err := t.repository.TableInfo(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	families := make([]string, 0, len(info.Families))
+	for _, f := range info.Families {
+		families = append(families, f.Name)
+	}
+	if t.cache != nil {
+		t.cache.SaveFamilies(table, families)
+	}
+	return families, nil
+}
+
+// Clear drops the cached metadata after the tables are changed
+func (t *MetadataInteractor) Clear() error {
+	if t.cache == nil {
+		return nil
+	}
+	return t.cache.Clear()
+}
@@ -2,6 +2,10 @@ package application
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/bigtable"
 	"github.com/takashabe/btcli/api/domain"
@@ -30,15 +34,162 @@ func (t *RowsInteractor) GetRow(ctx context.Context, table, key string, opts ...
 }
 
 // GetRows returns rows
-func (t *RowsInteractor) GetRows(ctx context.Context, table string, rr bigtable.RowRange, opts ...bigtable.ReadOption) ([]*domain.Row, error) {
-	tbl, err := t.repository.GetRows(ctx, table, rr, opts...)
+func (t *RowsInteractor) GetRows(ctx context.Context, table string, rs bigtable.RowSet, opts ...bigtable.ReadOption) ([]*domain.Row, error) {
+	tbl, err := t.repository.GetRows(ctx, table, rs, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return tbl.Rows, nil
 }
 
-// GetRowCount returns number of the table
-func (t *RowsInteractor) GetRowCount(ctx context.Context, table string) (int, error) {
-	return t.repository.Count(ctx, table)
+// FanoutRows reads each row of the keys by a request in parallel and returns the reads in the order of the keys
+func (t *RowsInteractor) FanoutRows(ctx context.Context, table string, keys []string, parallel int, opts ...bigtable.ReadOption) []*domain.KeyRead {
+	reads := make([]*domain.KeyRead, len(keys))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, k := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, k string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			start := time.Now()
+			row, err := t.GetRow(ctx, table, k, opts...)
+			reads[i] = &domain.KeyRead{Key: k, Row: row, Latency: time.Since(start), Err: err}
+		}(i, k)
+	}
+	wg.Wait()
+	return reads
+}
+
+// ScanRows calls f with each row as it is read until f returns false
+func (t *RowsInteractor) ScanRows(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+	return t.repository.ScanRows(ctx, table, rs, f, opts...)
+}
+
+// GetRowCount returns number of the rows in the row set
+func (t *RowsInteractor) GetRowCount(ctx context.Context, table string, rs bigtable.RowSet) (int, error) {
+	return t.repository.Count(ctx, table, rs)
+}
+
+// ExistingKeys returns the keys existing in the table, the keys are read in the batches of batchSize keys
+func (t *RowsInteractor) ExistingKeys(ctx context.Context, table string, keys []string, batchSize int) (map[string]bool, error) {
+	existing := map[string]bool{}
+	for i := 0; i < len(keys); i += batchSize {
+		end := i + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		found, err := t.repository.Keys(ctx, table, bigtable.RowList(keys[i:end]))
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range found {
+			existing[k] = true
+		}
+	}
+	return existing, nil
+}
+
+// GetRangeStats returns statistics of the cells in the row set
+func (t *RowsInteractor) GetRangeStats(ctx context.Context, table string, rs bigtable.RowSet, opts ...bigtable.ReadOption) (*domain.RangeStats, error) {
+	return t.repository.Stats(ctx, table, rs, opts...)
+}
+
+// FamilyUsage returns the usages of the column families in the rows read by the options,
+// in descending order of the bytes
+func (t *RowsInteractor) FamilyUsage(ctx context.Context, table string, opts ...bigtable.ReadOption) ([]*domain.FamilyUsage, error) {
+	usages := map[string]*domain.FamilyUsage{}
+	err := t.repository.ScanRows(ctx, table, bigtable.InfiniteRange(""), func(r *domain.Row) bool {
+		for _, c := range r.Columns {
+			u, ok := usages[c.Family]
+			if !ok {
+				u = &domain.FamilyUsage{Family: c.Family}
+				usages[c.Family] = u
+			}
+			u.Cells++
+			// the qualifier is prefixed by "<family>:"
+			u.Bytes += int64(len(r.Key) + len(c.Qualifier) - len(c.Family) - 1 + len(c.Value))
+		}
+		return true
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*domain.FamilyUsage, 0, len(usages))
+	for _, u := range usages {
+		list = append(list, u)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Bytes != list[j].Bytes {
+			return list[i].Bytes > list[j].Bytes
+		}
+		return list[i].Family < list[j].Family
+	})
+	return list, nil
+}
+
+// SampleKeys returns the sampled boundary keys of the table
+func (t *RowsInteractor) SampleKeys(ctx context.Context, table string) ([]*domain.KeySample, error) {
+	return t.repository.SampleKeys(ctx, table)
+}
+
+// WriteRows writes the cells of the rows
+func (t *RowsInteractor) WriteRows(ctx context.Context, table string, rows []*domain.Row) error {
+	return t.repository.WriteRows(ctx, table, rows)
+}
+
+// DeleteRows deletes the rows of the keys
+func (t *RowsInteractor) DeleteRows(ctx context.Context, table string, keys []string) error {
+	return t.repository.DeleteRows(ctx, table, keys)
+}
+
+// SingleClusterProfiles returns the app profiles routing to a single cluster
+func (t *RowsInteractor) SingleClusterProfiles(ctx context.Context) ([]*domain.AppProfile, error) {
+	profiles, err := t.repository.AppProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	single := []*domain.AppProfile{}
+	for _, p := range profiles {
+		if p.Cluster != "" {
+			single = append(single, p)
+		}
+	}
+	return single, nil
+}
+
+// PriorityProfile returns the app profile of the request priority
+func (t *RowsInteractor) PriorityProfile(ctx context.Context, priority string) (string, error) {
+	profiles, err := t.repository.AppProfiles(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range profiles {
+		if p.Priority == priority {
+			return p.ID, nil
+		}
+	}
+	return "", fmt.Errorf("no app profile has the %s priority, create an app profile with the standard isolation of the priority", priority)
+}
+
+// GetRowReplicas returns the row read with each app profile in the order of the profiles
+func (t *RowsInteractor) GetRowReplicas(ctx context.Context, table, key string, profiles []string, opts ...bigtable.ReadOption) ([]*domain.Row, error) {
+	rows := make([]*domain.Row, 0, len(profiles))
+	for _, p := range profiles {
+		row, err := t.GetRow(repository.WithAppProfile(ctx, p), table, key, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("app profile %s: %v", p, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// GetRowHistory returns changes of the row since the time recorded in the change stream
+func (t *RowsInteractor) GetRowHistory(ctx context.Context, table, key string, since time.Time) ([]*domain.RowChange, error) {
+	return t.repository.RowHistory(ctx, table, key, since)
 }
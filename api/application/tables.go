@@ -2,7 +2,10 @@ package application
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/takashabe/btcli/api/domain"
 	"github.com/takashabe/btcli/api/domain/repository"
 )
 
@@ -22,3 +25,88 @@ func NewTableInteractor(r repository.Bigtable) *TableInteractor {
 func (t *TableInteractor) GetTables(ctx context.Context) ([]string, error) {
 	return t.repository.Tables(ctx)
 }
+
+// GetTableInfo returns settings of the table
+func (t *TableInteractor) GetTableInfo(ctx context.Context, table string) (*domain.TableInfo, error) {
+	return t.repository.TableInfo(ctx, table)
+}
+
+// SetBackupPolicy updates the automated backup policy, nil policy disables the automated backup
+func (t *TableInteractor) SetBackupPolicy(ctx context.Context, table string, policy *domain.BackupPolicy) error {
+	return t.repository.SetBackupPolicy(ctx, table, policy)
+}
+
+// SetDeletionProtection enables or disables the deletion protection of the table
+func (t *TableInteractor) SetDeletionProtection(ctx context.Context, table string, protected bool) error {
+	return t.repository.SetDeletionProtection(ctx, table, protected)
+}
+
+// SetChangeStream enables the change stream of the table with the retention, zero retention disables the change stream
+func (t *TableInteractor) SetChangeStream(ctx context.Context, table string, retention time.Duration) error {
+	return t.repository.SetChangeStream(ctx, table, retention)
+}
+
+// DeleteTable deletes the table, fails when the deletion protection of the table is enabled
+func (t *TableInteractor) DeleteTable(ctx context.Context, table string) error {
+	info, err := t.repository.TableInfo(ctx, table)
+	if err != nil {
+		return err
+	}
+	if info.DeletionProtection {
+		return fmt.Errorf("table %s is protected from deletion, disable it by \"setprotection %s enabled=false\"", table, table)
+	}
+	return t.repository.DeleteTable(ctx, table)
+}
+
+// CreateTable creates the table with the families and the initial split points
+func (t *TableInteractor) CreateTable(ctx context.Context, table string, families []*domain.Family, splits []string) error {
+	return t.repository.CreateTable(ctx, table, families, splits)
+}
+
+// CreateFamily adds the column family with the GC policy to the table
+func (t *TableInteractor) CreateFamily(ctx context.Context, table string, family *domain.Family) error {
+	return t.repository.CreateFamily(ctx, table, family)
+}
+
+// CloneTable creates the dst table with the same families and GC policies as the src table,
+// and copies the rows unless schemaOnly. returns a number of the copied rows
+func (t *TableInteractor) CloneTable(ctx context.Context, src, dst string, schemaOnly bool) (int, error) {
+	if err := t.repository.CreateTableLike(ctx, src, dst); err != nil {
+		return 0, err
+	}
+	if schemaOnly {
+		return 0, nil
+	}
+	return t.repository.CopyRows(ctx, src, dst)
+}
+
+// SuggestSplits returns the split points dividing the table into n tablets of the similar bytes,
+// chosen from the sampled boundary keys. fewer points are returned when the table has fewer samples
+func (t *TableInteractor) SuggestSplits(ctx context.Context, table string, n int) ([]string, error) {
+	samples, err := t.repository.SampleKeys(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) == 0 {
+		return []string{}, nil
+	}
+	// the last sample is the end of the table
+	total := samples[len(samples)-1].Offset
+
+	splits := []string{}
+	j := 0
+	for i := 1; i < n; i++ {
+		target := total * int64(i) / int64(n)
+		for j < len(samples) && samples[j].Offset < target {
+			j++
+		}
+		if j >= len(samples) || samples[j].Key == "" {
+			break
+		}
+		// the same sample is nearest to the several targets of a small table
+		if len(splits) == 0 || splits[len(splits)-1] != samples[j].Key {
+			splits = append(splits, samples[j].Key)
+		}
+	}
+	return splits, nil
+}
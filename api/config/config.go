@@ -12,13 +12,26 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
 )
 
-var config = &Config{}
+// defaultPageSize is a number of rows printed at once by the read command
+const defaultPageSize = 100
+
+// defaultReadLimit is a number of rows read at most by the unpaginated read command without count
+const defaultReadLimit = 1000
+
+// defaultMaxValueBytes is a number of bytes of a value printed at most by the text output
+const defaultMaxValueBytes = 1024
+
+// defaultCompletionCacheTTL is a time the tables of the completion are shared by the sessions
+const defaultCompletionCacheTTL = time.Minute
+
+var config = &Config{PageSize: defaultPageSize, ReadLimit: defaultReadLimit, MaxValueBytes: defaultMaxValueBytes, CompletionCacheTTL: defaultCompletionCacheTTL}
 
 // Config represents a configuration.
 type Config struct {
@@ -26,23 +39,260 @@ type Config struct {
 	Instance    string
 	Creds       string
 	TokenSource oauth2.TokenSource
+	Secrets     SecretStore
+	// PlaintextSecrets stores the secrets in the plaintext file ~/.btcli/secrets when the keyring is unavailable
+	PlaintextSecrets bool
+
+	// IdleTimeout locks write commands after the session is idle for the duration
+	IdleTimeout time.Duration
+	// IdleTimeouts are the idle timeouts of the instances by idle_timeout.<instance>,
+	// the timeout of the connected instance wins over idle_timeout unless -idle-timeout is given
+	IdleTimeouts map[string]time.Duration
+
+	// RequestTimeout gives up each point request to bigtable after the duration, 0 waits for the response
+	RequestTimeout time.Duration
+
+	// AppProfile is the default app profile of the requests, empty uses the default of the instance
+	AppProfile string
+
+	// PageSize is a number of rows printed at once by the read command, 0 prints all rows
+	PageSize int
+
+	// ReadLimit is a number of rows read at most by the unpaginated read command without count, 0 reads all rows
+	ReadLimit int
+	// MaxValueBytes is a number of bytes of a value printed at most by the text output, 0 prints all bytes
+	MaxValueBytes int
+
+	// NumberFormat is a format of the counts, empty prints the raw numbers
+	NumberFormat string
+
+	// Format is an output format of the rows, empty prints the text
+	Format string
+	// Template is a text/template of the rows printed by the template format
+	Template string
+
+	// Transforms are the named pipelines transforming the values of the columns before printing
+	Transforms []*Transform
+
+	// Script is a file of the commands executed instead of the interactive shell
+	Script string
+	// Execute is a command executed instead of the interactive shell, by the daemon of the instance if running
+	Execute string
+	// AuditLog is a file logging the steps of the scripts, empty uses ~/.btcli/audit.log and "off" disables
+	AuditLog string
+
+	// ProtoDescriptors is a FileDescriptorSet file of the messages decoded by decode=proto:<message>
+	ProtoDescriptors string
+
+	// NoColor disables the colors of the text output, the colors are also disabled unless the output is a terminal
+	NoColor bool
+
+	// AutoDecode is the types guessed by the values without the decodes, empty guesses all types
+	AutoDecode string
+
+	// Quiet prints the rows in the plain format, same as Format "plain"
+	Quiet bool
+
+	// Locale is the language of the messages, empty is given by LC_ALL, LC_MESSAGES or LANG
+	Locale string
+
+	// TimestampFormat is the format of the timestamps of the cells, empty prints the default layout
+	TimestampFormat string
+	// UTC prints the timestamps in UTC instead of the local time
+	UTC bool
+	// EnableExperimental enables the experimental commands, see interfaces.Command
+	EnableExperimental bool
+	// Verbose prints the byte size, the labels and the raw value of each cell
+	Verbose bool
+
+	// Decoders are the decodes of the columns by "family:qualifier", used unless given by the options
+	Decoders map[string]string
+
+	// CompletionCacheTTL is a time the tables of the completion are cached in the file shared by the sessions, 0 disables
+	CompletionCacheTTL time.Duration
 }
 
+// Transform is a pipeline of the stages applied to the values of the columns matching the regex,
+// e.g. "gunzip | jsonpath:$.user.id"
+type Transform struct {
+	Name     string
+	Columns  string
+	Pipeline string
+}
+
+// NumberFormats are the available formats of the counts
+var NumberFormats = []string{"raw", "comma", "period", "space", "locale"}
+
+// Formats are the available output formats of the rows
+var Formats = []string{"text", "json", "ndjson", "csv", "tsv", "yaml", "table", "wide", "plain", "template"}
+
+// AutoDecodes are the available types guessed by the values of 8 bytes
+var AutoDecodes = []string{"off", "int", "float", "all"}
+
+// Locales are the available languages of the messages
+var Locales = []string{"en", "ja"}
+
+// TimestampFormats are the available formats of the timestamps
+var TimestampFormats = []string{"default", "rfc3339", "unix-micros", "relative"}
+
+// DecodeTypes are the decodes of the values, proto:<message> and avro:<schema.json> are given with the arguments
+var DecodeTypes = []string{"string", "int", "float", "hex", "base64", "json", "proto:<message>", "avro:<schema.json>"}
+
+// decodeAliases are the names of the decodes in the Go types
+var decodeAliases = map[string]string{"int64": "int", "float64": "float"}
+
+// gcloudTokenKey is the key of the cached gcloud token in the secret store
+const gcloudTokenKey = "gcloud-token"
+
 // RegisterFlags registers a set of standard flags for this config.
 func (c *Config) registerFlags() {
 	flag.StringVar(&c.Project, "project", c.Project, "project ID, if unset uses gcloud configured project")
 	flag.StringVar(&c.Instance, "instance", c.Instance, "Cloud Bigtable instance")
 	flag.StringVar(&c.Creds, "creds", c.Creds, "if set, use application credentials in this file")
+	flag.StringVar(&c.AppProfile, "app-profile", c.AppProfile, "app profile of the requests, if unset uses the default app profile")
+	flag.DurationVar(&c.RequestTimeout, "request-timeout", c.RequestTimeout, "if set, give up each point request to bigtable after the duration, the scans aren't timed out, e.g. 30s")
+	flag.DurationVar(&c.IdleTimeout, "idle-timeout", c.IdleTimeout, "if set, require unlock before write commands after being idle for the duration")
+	flag.IntVar(&c.PageSize, "page-size", c.PageSize, "number of rows printed at once by read in the terminal of the interactive shell, 0 prints all rows")
+	flag.IntVar(&c.ReadLimit, "read-limit", c.ReadLimit, "number of rows read at most by unpaginated read without count, 0 reads all rows")
+	flag.IntVar(&c.MaxValueBytes, "max-value-bytes", c.MaxValueBytes, "number of bytes of a value printed at most by the text and table formats, 0 prints all bytes")
+	flag.StringVar(&c.NumberFormat, "number-format", c.NumberFormat, "thousands separator of the counts: "+strings.Join(NumberFormats, ", ")+", if unset prints the raw numbers")
+	flag.StringVar(&c.Format, "format", c.Format, "output format of the rows: "+strings.Join(Formats, ", ")+", if unset prints the text")
+	flag.StringVar(&c.Template, "template", c.Template, "text/template of each row printed by -format template, e.g. '{{.Key}}\\t{{index .Cells \"d:name\"}}'")
+	flag.StringVar(&c.Script, "f", c.Script, "if set, execute the commands in this file instead of the interactive shell")
+	flag.StringVar(&c.Execute, "e", c.Execute, "if set, execute the command instead of the interactive shell, via \"btcli daemon\" of the instance if running")
+	flag.StringVar(&c.AuditLog, "audit-log", c.AuditLog, "file logging the steps of the scripts, off disables, if unset uses ~/.btcli/audit.log")
+	flag.DurationVar(&c.CompletionCacheTTL, "completion-cache-ttl", c.CompletionCacheTTL, "time the tables of the completion are shared by the sessions via ~/.btcli/cache, 0 disables")
+	flag.BoolVar(&c.NoColor, "no-color", c.NoColor, "disable the colors of the rows, also disabled by NO_COLOR or unless the output is a terminal")
+	flag.StringVar(&c.AutoDecode, "autodecode", c.AutoDecode, "types guessed by the values without decode: "+strings.Join(AutoDecodes, ", ")+", if unset guesses all types")
+	flag.BoolVar(&c.Quiet, "quiet", c.Quiet, "print the rows without the separators and the timestamps, same as -format plain")
+	flag.StringVar(&c.Locale, "locale", c.Locale, "language of the messages: "+strings.Join(Locales, ", ")+", if unset uses LC_ALL, LC_MESSAGES or LANG")
+	flag.StringVar(&c.TimestampFormat, "timestamp-format", c.TimestampFormat, "format of the timestamps: "+strings.Join(TimestampFormats, ", ")+", if unset prints the default")
+	flag.BoolVar(&c.UTC, "utc", c.UTC, "print the timestamps in UTC instead of the local time")
+	flag.BoolVar(&c.EnableExperimental, "enable-experimental", c.EnableExperimental, "enable the experimental commands, their behavior may change in the later versions")
+	flag.BoolVar(&c.Verbose, "verbose", c.Verbose, "print the byte size, the labels and the raw value of each cell with the decoded value")
+	flag.StringVar(&c.ProtoDescriptors, "proto-descriptors", c.ProtoDescriptors, "FileDescriptorSet file of the messages decoded by decode=proto:<message>, e.g. protoc --include_imports --descriptor_set_out")
+}
+
+// Validate checks the values given by the file and the flags
+func (c *Config) Validate() error {
+	if c.NumberFormat != "" && !contains(NumberFormats, c.NumberFormat) {
+		return fmt.Errorf("unknown number format %q, must be one of %s", c.NumberFormat, strings.Join(NumberFormats, ", "))
+	}
+	if c.Format != "" && !contains(Formats, c.Format) {
+		return fmt.Errorf("unknown format %q, must be one of %s", c.Format, strings.Join(Formats, ", "))
+	}
+	if c.Quiet {
+		if c.Format != "" && c.Format != "plain" {
+			return fmt.Errorf("-quiet may not be mixed with the format %q", c.Format)
+		}
+		c.Format = "plain"
+	}
+	if c.Format == "template" && c.Template == "" {
+		return fmt.Errorf("-format template requires -template")
+	}
+	if c.Script != "" && c.Execute != "" {
+		return fmt.Errorf("-e may not be mixed with -f")
+	}
+	if c.AutoDecode != "" && !contains(AutoDecodes, c.AutoDecode) {
+		return fmt.Errorf("unknown autodecode %q, must be one of %s", c.AutoDecode, strings.Join(AutoDecodes, ", "))
+	}
+	if c.Locale != "" && !contains(Locales, c.Locale) {
+		return fmt.Errorf("unknown locale %q, must be one of %s", c.Locale, strings.Join(Locales, ", "))
+	}
+	if c.TimestampFormat != "" && !contains(TimestampFormats, c.TimestampFormat) {
+		return fmt.Errorf("unknown timestamp format %q, must be one of %s", c.TimestampFormat, strings.Join(TimestampFormats, ", "))
+	}
+	if d, ok := c.IdleTimeouts[c.Instance]; ok && !flagPassed("idle-timeout") {
+		c.IdleTimeout = d
+	}
+	for _, t := range c.Transforms {
+		if t.Columns == "" || t.Pipeline == "" {
+			return fmt.Errorf("transform %q requires transform.%s and transform.%s.columns", t.Name, t.Name, t.Name)
+		}
+	}
+	return nil
+}
+
+// ParseDecoders parses the decodes of the columns separated by the commas,
+// e.g. "d:count=int64, d:score=float64, d:payload=proto:my.Msg", an empty decode removes the column
+func ParseDecoders(s string) (map[string]string, error) {
+	decoders := map[string]string{}
+	for _, d := range strings.Split(s, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		i := strings.Index(d, "=")
+		if i < 0 || !strings.Contains(d[:i], ":") {
+			return nil, fmt.Errorf("invalid decoder %q, must be <family:qualifier>=<type>", d)
+		}
+		column, decode := d[:i], d[i+1:]
+		if alias, ok := decodeAliases[decode]; ok {
+			decode = alias
+		}
+		switch {
+		case decode == "", contains(DecodeTypes, decode):
+		case strings.HasPrefix(decode, "proto:") && len(decode) > len("proto:"):
+		case strings.HasPrefix(decode, "avro:") && len(decode) > len("avro:"):
+		default:
+			return nil, fmt.Errorf("unknown decode %q of %s, must be one of %s", decode, column, strings.Join(DecodeTypes, ", "))
+		}
+		decoders[column] = decode
+	}
+	return decoders, nil
+}
+
+// transform returns the transform of the name, added if missing
+func (c *Config) transform(name string) *Transform {
+	for _, t := range c.Transforms {
+		if t.Name == name {
+			return t
+		}
+	}
+	t := &Transform{Name: name}
+	c.Transforms = append(c.Transforms, t)
+	return t
+}
+
+// flagPassed reports whether the flag is given in the command line
+func flagPassed(name string) bool {
+	passed := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// HomeDir returns the home directory of the user, HOME isn't set on Windows
+func HomeDir() string {
+	if runtime.GOOS == "windows" {
+		if home := os.Getenv("USERPROFILE"); home != "" {
+			return home
+		}
+		return os.Getenv("HOMEDRIVE") + os.Getenv("HOMEPATH")
+	}
+	return os.Getenv("HOME")
 }
 
 // Load returns initialized configuration
 func Load() (*Config, error) {
-	filename := filepath.Join(os.Getenv("HOME"), ".cbtrc")
+	filename := filepath.Join(HomeDir(), ".cbtrc")
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
 		// silent fail if the file isn't there
 		if os.IsNotExist(err) {
-			return &Config{}, nil
+			return &Config{PageSize: defaultPageSize, ReadLimit: defaultReadLimit, MaxValueBytes: defaultMaxValueBytes, CompletionCacheTTL: defaultCompletionCacheTTL}, nil
 		}
 		return nil, fmt.Errorf("Reading %s: %v", filename, err)
 	}
@@ -54,6 +304,28 @@ func Load() (*Config, error) {
 			return nil, fmt.Errorf("Bad line in %s: %q", filename, line)
 		}
 		key, val := strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		// transform.<name> = <pipeline> and transform.<name>.columns = <regex>
+		if strings.HasPrefix(key, "transform.") {
+			name := strings.TrimPrefix(key, "transform.")
+			if strings.HasSuffix(name, ".columns") {
+				config.transform(strings.TrimSuffix(name, ".columns")).Columns = val
+			} else {
+				config.transform(name).Pipeline = val
+			}
+			continue
+		}
+		// idle_timeout.<instance> = <duration>
+		if strings.HasPrefix(key, "idle_timeout.") {
+			d, err := time.ParseDuration(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad %s in %s: %v", key, filename, err)
+			}
+			if config.IdleTimeouts == nil {
+				config.IdleTimeouts = map[string]time.Duration{}
+			}
+			config.IdleTimeouts[strings.TrimPrefix(key, "idle_timeout.")] = d
+			continue
+		}
 		switch key {
 		default:
 			return nil, fmt.Errorf("Unknown key in %s: %q", filename, key)
@@ -63,9 +335,100 @@ func Load() (*Config, error) {
 			config.Instance = val
 		case "creds":
 			config.Creds = val
+		case "app_profile":
+			config.AppProfile = val
+		case "idle_timeout":
+			d, err := time.ParseDuration(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad idle_timeout in %s: %v", filename, err)
+			}
+			config.IdleTimeout = d
+		case "request_timeout":
+			d, err := time.ParseDuration(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad request_timeout in %s: %v", filename, err)
+			}
+			config.RequestTimeout = d
+		case "completion_cache_ttl":
+			d, err := time.ParseDuration(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad completion_cache_ttl in %s: %v", filename, err)
+			}
+			config.CompletionCacheTTL = d
+		case "page_size":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad page_size in %s: %v", filename, err)
+			}
+			config.PageSize = n
+		case "read_limit":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad read_limit in %s: %v", filename, err)
+			}
+			config.ReadLimit = n
+		case "max_value_bytes":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad max_value_bytes in %s: %v", filename, err)
+			}
+			config.MaxValueBytes = n
+		case "number_format":
+			config.NumberFormat = val
+		case "format":
+			config.Format = val
+		case "template":
+			config.Template = val
+		case "audit_log":
+			config.AuditLog = val
+		case "proto_descriptors":
+			config.ProtoDescriptors = val
+		case "no_color":
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad no_color in %s: %v", filename, err)
+			}
+			config.NoColor = b
+		case "autodecode":
+			config.AutoDecode = val
+		case "locale":
+			config.Locale = val
+		case "timestamp_format":
+			config.TimestampFormat = val
+		case "utc":
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad utc in %s: %v", filename, err)
+			}
+			config.UTC = b
+		case "enable_experimental":
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad enable_experimental in %s: %v", filename, err)
+			}
+			config.EnableExperimental = b
+		case "plaintext_secrets":
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad plaintext_secrets in %s: %v", filename, err)
+			}
+			config.PlaintextSecrets = b
+		case "verbose":
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad verbose in %s: %v", filename, err)
+			}
+			config.Verbose = b
+		case "decoders":
+			decoders, err := ParseDecoders(val)
+			if err != nil {
+				return nil, fmt.Errorf("Bad decoders in %s: %v", filename, err)
+			}
+			config.Decoders = decoders
 		}
 	}
 
+	config.Secrets = NewSecretStore(config.PlaintextSecrets)
 	config.registerFlags()
 	if err := config.setFromGcloud(); err != nil {
 		return nil, err
@@ -150,6 +513,19 @@ func (c *Config) setFromGcloud() error {
 	gcloudCmdArgs := []string{"config", "config-helper",
 		"--format=json(configuration.properties.core.project,credential)"}
 
+	ts := &secretTokenSource{
+		store:  c.Secrets,
+		key:    gcloudTokenKey,
+		source: &GcloudCmdTokenSource{Command: gcloudCmd, Args: gcloudCmdArgs},
+	}
+	// gcloud isn't run while the cached token is valid unless the project is given by gcloud
+	if c.Project != "" {
+		if t, err := ts.cached(); err == nil && t.Valid() {
+			c.TokenSource = oauth2.ReuseTokenSource(t, ts)
+			return nil
+		}
+	}
+
 	gcloudConfig, err := loadGcloudConfig(gcloudCmd, gcloudCmdArgs)
 	if err != nil {
 		return err
@@ -162,9 +538,8 @@ func (c *Config) setFromGcloud() error {
 	}
 
 	if c.Creds == "" {
-		c.TokenSource = oauth2.ReuseTokenSource(
-			gcloudConfig.Credential.Token(),
-			&GcloudCmdTokenSource{Command: gcloudCmd, Args: gcloudCmdArgs})
+		ts.save(gcloudConfig.Credential.Token())
+		c.TokenSource = oauth2.ReuseTokenSource(gcloudConfig.Credential.Token(), ts)
 	}
 
 	return nil
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateIdleTimeouts(t *testing.T) {
+	timeouts := map[string]time.Duration{"prod": 5 * time.Minute}
+	cases := []struct {
+		instance string
+		expect   time.Duration
+	}{
+		{"prod", 5 * time.Minute},
+		{"dev", 15 * time.Minute},
+	}
+	for i, c := range cases {
+		conf := &Config{Instance: c.instance, IdleTimeout: 15 * time.Minute, IdleTimeouts: timeouts}
+		assert.NoError(t, conf.Validate(), "#%d", i)
+		assert.Equal(t, c.expect, conf.IdleTimeout, "#%d", i)
+	}
+}
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// secretService is the service name of the entries in the OS keyring
+const secretService = "btcli"
+
+// ErrSecretNotFound is returned when the secret doesn't exist in the store
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretStore represents storage of the credentials
+type SecretStore interface {
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// NewSecretStore returns the OS keyring store if available, the plaintext file store is used
+// only when plaintext is given, otherwise the secrets aren't kept
+func NewSecretStore(plaintext bool) SecretStore {
+	keyring := newKeyringSecretStore()
+	if !plaintext {
+		if keyring == nil {
+			return noSecretStore{}
+		}
+		return keyring
+	}
+	file := &fileSecretStore{
+		path: filepath.Join(HomeDir(), ".btcli", "secrets"),
+	}
+	log.Printf("plaintext_secrets is set, the secrets may be stored in the plaintext file %s", file.path)
+	if keyring == nil {
+		return file
+	}
+	return &fallbackSecretStore{
+		primary:   keyring,
+		secondary: file,
+	}
+}
+
+// noSecretStore keeps no secrets when the keyring is unavailable, the tokens are requested again by the next session
+type noSecretStore struct{}
+
+func (noSecretStore) Get(key string) (string, error) { return "", ErrSecretNotFound }
+
+func (noSecretStore) Set(key, value string) error { return nil }
+
+func (noSecretStore) Delete(key string) error { return nil }
+
+// keyringSecretStore stores secrets in the OS keyring via the platform command
+type keyringSecretStore struct {
+	command string
+}
+
+func newKeyringSecretStore() *keyringSecretStore {
+	var command string
+	switch runtime.GOOS {
+	case "darwin":
+		command = "security"
+	case "linux", "freebsd", "openbsd":
+		command = "secret-tool"
+	default:
+		return nil
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return nil
+	}
+	return &keyringSecretStore{command: command}
+}
+
+func (k *keyringSecretStore) Get(key string) (string, error) {
+	var args []string
+	switch k.command {
+	case "security":
+		args = []string{"find-generic-password", "-s", secretService, "-a", key, "-w"}
+	default:
+		args = []string{"lookup", "service", secretService, "account", key}
+	}
+	out, err := exec.Command(k.command, args...).Output()
+	if err != nil {
+		return "", ErrSecretNotFound
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func (k *keyringSecretStore) Set(key, value string) error {
+	if out, err := k.setCommand(key, value).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to store secret: %v: %s", err, out)
+	}
+	return nil
+}
+
+// setCommand returns the command storing the secret given by the stdin, not to be seen in the process list
+func (k *keyringSecretStore) setCommand(key, value string) *exec.Cmd {
+	switch k.command {
+	case "security":
+		// the interactive mode reads the command from the stdin, the value in hex needs no quoting
+		cmd := exec.Command(k.command, "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+			secretService, strconv.Quote(key), hex.EncodeToString([]byte(value))))
+		return cmd
+	default:
+		cmd := exec.Command(k.command, "store", "--label="+secretService, "service", secretService, "account", key)
+		cmd.Stdin = strings.NewReader(value)
+		return cmd
+	}
+}
+
+func (k *keyringSecretStore) Delete(key string) error {
+	var args []string
+	switch k.command {
+	case "security":
+		args = []string{"delete-generic-password", "-s", secretService, "-a", key}
+	default:
+		args = []string{"clear", "service", secretService, "account", key}
+	}
+	if out, err := exec.Command(k.command, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete secret: %v: %s", err, out)
+	}
+	return nil
+}
+
+// fileSecretStore stores secrets in the plaintext file that only readable by the owner,
+// used by plaintext_secrets when the keyring is unavailable
+type fileSecretStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (f *fileSecretStore) Get(key string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	secrets, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	v, ok := secrets[key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return v, nil
+}
+
+func (f *fileSecretStore) Set(key, value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	return f.save(secrets)
+}
+
+func (f *fileSecretStore) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+	delete(secrets, key)
+	return f.save(secrets)
+}
+
+func (f *fileSecretStore) load() (map[string]string, error) {
+	secrets := map[string]string{}
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return secrets, nil
+		}
+		return nil, fmt.Errorf("Reading %s: %v", f.path, err)
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("Parsing %s: %v", f.path, err)
+	}
+	return secrets, nil
+}
+
+func (f *fileSecretStore) save(secrets map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f.path, data, 0600)
+}
+
+// fallbackSecretStore uses the secondary store when the primary store is unavailable
+type fallbackSecretStore struct {
+	primary   SecretStore
+	secondary SecretStore
+}
+
+func (s *fallbackSecretStore) Get(key string) (string, error) {
+	if v, err := s.primary.Get(key); err == nil {
+		return v, nil
+	}
+	return s.secondary.Get(key)
+}
+
+func (s *fallbackSecretStore) Set(key, value string) error {
+	if err := s.primary.Set(key, value); err == nil {
+		return nil
+	}
+	return s.secondary.Set(key, value)
+}
+
+func (s *fallbackSecretStore) Delete(key string) error {
+	perr := s.primary.Delete(key)
+	serr := s.secondary.Delete(key)
+	if perr != nil && serr != nil {
+		return serr
+	}
+	return nil
+}
+
+// secretTokenSource caches the token of the source in the secret store
+type secretTokenSource struct {
+	store  SecretStore
+	key    string
+	source oauth2.TokenSource
+}
+
+// Token implements the oauth2.TokenSource interface
+func (s *secretTokenSource) Token() (*oauth2.Token, error) {
+	if t, err := s.cached(); err == nil && t.Valid() {
+		return t, nil
+	}
+
+	t, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.save(t)
+	return t, nil
+}
+
+func (s *secretTokenSource) save(t *oauth2.Token) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return
+	}
+	if err := s.store.Set(s.key, string(data)); err != nil {
+		log.Printf("failed to cache the token: %v", err)
+	}
+}
+
+func (s *secretTokenSource) cached() (*oauth2.Token, error) {
+	v, err := s.store.Get(s.key)
+	if err != nil {
+		return nil, err
+	}
+	var t oauth2.Token
+	if err := json.Unmarshal([]byte(v), &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestFileSecretStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store := &fileSecretStore{path: filepath.Join(dir, "secrets")}
+
+	_, err = store.Get("a")
+	assert.Equal(t, ErrSecretNotFound, err)
+
+	assert.NoError(t, store.Set("a", "1"))
+	v, err := store.Get("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", v)
+
+	info, err := os.Stat(store.path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	assert.NoError(t, store.Delete("a"))
+	_, err = store.Get("a")
+	assert.Equal(t, ErrSecretNotFound, err)
+}
+
+func TestKeyringSetCommand(t *testing.T) {
+	cases := []struct {
+		command     string
+		expectArgs  []string
+		expectStdin string
+	}{
+		{
+			"security",
+			[]string{"security", "-i"},
+			"add-generic-password -U -s btcli -a \"gcloud-token\" -X 7b2261223a2231227d\n",
+		},
+		{
+			"secret-tool",
+			[]string{"secret-tool", "store", "--label=btcli", "service", "btcli", "account", "gcloud-token"},
+			`{"a":"1"}`,
+		},
+	}
+	for i, c := range cases {
+		cmd := (&keyringSecretStore{command: c.command}).setCommand("gcloud-token", `{"a":"1"}`)
+		assert.Equal(t, c.expectArgs, cmd.Args, "#%d", i)
+		stdin, err := ioutil.ReadAll(cmd.Stdin)
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, c.expectStdin, string(stdin), "#%d", i)
+	}
+}
+
+type countTokenSource struct {
+	called int
+}
+
+func (c *countTokenSource) Token() (*oauth2.Token, error) {
+	c.called++
+	return &oauth2.Token{AccessToken: "token", Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+func TestSecretTokenSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	source := &countTokenSource{}
+	ts := &secretTokenSource{
+		store:  &fileSecretStore{path: filepath.Join(dir, "secrets")},
+		key:    "token",
+		source: source,
+	}
+	for i := 0; i < 2; i++ {
+		tok, err := ts.Token()
+		assert.NoError(t, err)
+		assert.Equal(t, "token", tok.AccessToken)
+	}
+	assert.Equal(t, 1, source.called)
+}
+
+func TestNewSecretStoreWithoutKeyring(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	// neither security nor secret-tool is found
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir)
+	defer os.Setenv("PATH", path)
+
+	assert.Equal(t, noSecretStore{}, NewSecretStore(false))
+	assert.IsType(t, &fileSecretStore{}, NewSecretStore(true))
+}
+
+func TestSetFromGcloudCachedToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	// gcloud isn't found
+	path, creds := os.Getenv("PATH"), os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	os.Setenv("PATH", dir)
+	os.Unsetenv("GOOGLE_APPLICATION_CREDENTIALS")
+	defer func() {
+		os.Setenv("PATH", path)
+		os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", creds)
+	}()
+
+	cases := []struct {
+		expiry    time.Time
+		expectErr string
+	}{
+		{time.Now().Add(time.Hour), ""},
+		// the expired token is requested again by gcloud
+		{time.Now().Add(-time.Hour), "Could not retrieve gcloud configuration"},
+	}
+	for i, c := range cases {
+		store := &fileSecretStore{path: filepath.Join(dir, "secrets")}
+		(&secretTokenSource{store: store, key: gcloudTokenKey}).save(&oauth2.Token{AccessToken: "cached", Expiry: c.expiry})
+
+		conf := &Config{Project: "p", Secrets: store}
+		err := conf.setFromGcloud()
+		if c.expectErr != "" {
+			assert.EqualError(t, err, c.expectErr, "#%d", i)
+			continue
+		}
+		assert.NoError(t, err, "#%d", i)
+		tok, err := conf.TokenSource.Token()
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, "cached", tok.AccessToken, "#%d", i)
+	}
+}
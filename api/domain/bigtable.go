@@ -20,4 +20,123 @@ type Column struct {
 	Qualifier string
 	Value     []byte
 	Version   time.Time
+	// Labels are applied to the cell by the label filters
+	Labels []string
 }
+
+// RangeStats represent statistics of the cells in a range of the rows
+type RangeStats struct {
+	Rows       int
+	Cells      int
+	Qualifiers int
+	// MinTimestamp and MaxTimestamp are zero when there is no cell
+	MinTimestamp time.Time
+	MaxTimestamp time.Time
+
+	KeyBytes       int64
+	QualifierBytes int64
+	// ValueBytes is zero when the values are stripped
+	ValueBytes int64
+}
+
+// KeyRead represent a row read by the key with the latency
+type KeyRead struct {
+	Key string
+	// Row has no columns when the row is missing
+	Row     *Row
+	Latency time.Duration
+	Err     error
+}
+
+// FamilyUsage represent the bytes of the cells in a column family
+type FamilyUsage struct {
+	Family string
+	Cells  int
+	// Bytes is a sum of the key, the qualifier and the value bytes of the cells
+	Bytes int64
+}
+
+// AppProfile represent an app profile of the instance
+type AppProfile struct {
+	ID string
+	// Cluster is the cluster of the single-cluster routing, empty for the multi-cluster routing
+	Cluster string
+	// Priority is one of the AppProfilePriority, empty for the data boost isolation
+	Priority string
+}
+
+// priorities of the requests of the AppProfile
+const (
+	AppProfilePriorityLow    = "low"
+	AppProfilePriorityMedium = "medium"
+	AppProfilePriorityHigh   = "high"
+)
+
+// KeySample represent a boundary of the tablets sampled by the server
+type KeySample struct {
+	// Key is empty at the end of the table
+	Key string
+	// Offset is an approximate number of bytes in the table before the key
+	Offset int64
+}
+
+// TableInfo represent settings of the table
+type TableInfo struct {
+	Name     string
+	Families []*Family
+
+	DeletionProtection bool
+	// ChangeStreamRetention is zero when the change stream is disabled
+	ChangeStreamRetention time.Duration
+	// BackupPolicy is nil when the automated backup is disabled
+	BackupPolicy *BackupPolicy
+}
+
+// BackupPolicy represent an automated backup policy of the table
+type BackupPolicy struct {
+	Retention time.Duration
+	Frequency time.Duration
+}
+
+// Family represent a column family of the table
+type Family struct {
+	Name     string
+	GCPolicy string
+
+	// MaxVersions and MaxAge are the GC policy of a new family, zero means no limit.
+	// the cells are collected by either of them
+	MaxVersions int
+	MaxAge      time.Duration
+}
+
+// RowChange represent a change of the row recorded in the change stream
+type RowChange struct {
+	Time time.Time
+	// Type is RowChangeUser or RowChangeGC
+	Type      string
+	Cluster   string
+	Mutations []*Mutation
+}
+
+// types of the RowChange
+const (
+	RowChangeUser = "user"
+	RowChangeGC   = "gc"
+)
+
+// Mutation represent a mutation in the RowChange
+type Mutation struct {
+	Type      string
+	Family    string
+	Qualifier string
+	Version   time.Time
+	Value     []byte
+}
+
+// types of the Mutation
+const (
+	MutationSetCell      = "set"
+	MutationDeleteColumn = "delete-column"
+	MutationDeleteFamily = "delete-family"
+	MutationDeleteRow    = "delete-row"
+)
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+)
+
+// bloomMagic is the header of the marshaled BloomFilter
+var bloomMagic = []byte("BTBF\x01")
+
+// BloomFilter represent a set of the row keys answering "may be present" or "absent"
+type BloomFilter struct {
+	// k is a number of the hash functions, m is a number of the bits
+	k    uint32
+	m    uint64
+	bits []uint64
+}
+
+// NewBloomFilter returns an empty BloomFilter sized for n keys with the false positive rate p
+func NewBloomFilter(n int, p float64) *BloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	// round up to the whole words
+	m = (m + 63) / 64 * 64
+	k := uint32(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &BloomFilter{k: k, m: m, bits: make([]uint64, m/64)}
+}
+
+// Add adds the key to the filter
+func (f *BloomFilter) Add(key string) {
+	h1, h2 := bloomHash(key)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		b := (h1 + i*h2) % f.m
+		f.bits[b/64] |= 1 << (b % 64)
+	}
+}
+
+// MayContain reports whether the key may be added, false means the key is never added
+func (f *BloomFilter) MayContain(key string) bool {
+	h1, h2 := bloomHash(key)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		b := (h1 + i*h2) % f.m
+		if f.bits[b/64]&(1<<(b%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHash returns two hashes of the key, the hash functions are derived by h1 + i*h2
+func bloomHash(key string) (uint64, uint64) {
+	a := fnv.New64a()
+	a.Write([]byte(key))
+	b := fnv.New64()
+	b.Write([]byte(key))
+	// odd h2 to visit the different bits
+	return a.Sum64(), b.Sum64() | 1
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, len(bloomMagic)+4+8+8*len(f.bits))
+	n := copy(data, bloomMagic)
+	binary.LittleEndian.PutUint32(data[n:], f.k)
+	binary.LittleEndian.PutUint64(data[n+4:], f.m)
+	for i, w := range f.bits {
+		binary.LittleEndian.PutUint64(data[n+12+8*i:], w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface
+func (f *BloomFilter) UnmarshalBinary(data []byte) error {
+	n := len(bloomMagic)
+	if len(data) < n+12 || string(data[:n]) != string(bloomMagic) {
+		return errors.New("not a bloom filter")
+	}
+	k := binary.LittleEndian.Uint32(data[n:])
+	m := binary.LittleEndian.Uint64(data[n+4:])
+	words := data[n+12:]
+	if k < 1 || m < 1 || m%64 != 0 || uint64(len(words)) != m/8 {
+		return errors.New("broken bloom filter")
+	}
+	f.k, f.m = k, m
+	f.bits = make([]uint64, m/64)
+	for i := range f.bits {
+		f.bits[i] = binary.LittleEndian.Uint64(words[8*i:])
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"cloud.google.com/go/bigtable"
 	"github.com/takashabe/btcli/api/domain"
@@ -12,9 +13,36 @@ import (
 // Bigtable represent repository of the bigtable
 type Bigtable interface {
 	Get(ctx context.Context, table, key string, opts ...bigtable.ReadOption) (*domain.Bigtable, error)
-	GetRows(ctx context.Context, table string, rr bigtable.RowRange, opts ...bigtable.ReadOption) (*domain.Bigtable, error)
-	Count(ctx context.Context, table string) (int, error)
+	GetRows(ctx context.Context, table string, rs bigtable.RowSet, opts ...bigtable.ReadOption) (*domain.Bigtable, error)
+	// ScanRows calls f with each row as it is read until f returns false
+	ScanRows(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error
+	Count(ctx context.Context, table string, rs bigtable.RowSet) (int, error)
+	Stats(ctx context.Context, table string, rs bigtable.RowSet, opts ...bigtable.ReadOption) (*domain.RangeStats, error)
+	Keys(ctx context.Context, table string, rs bigtable.RowSet) ([]string, error)
+	RowHistory(ctx context.Context, table, key string, since time.Time) ([]*domain.RowChange, error)
+	SampleKeys(ctx context.Context, table string) ([]*domain.KeySample, error)
+	// WriteRows sets the cells of the rows, the cells without the version are written at the server time
+	WriteRows(ctx context.Context, table string, rows []*domain.Row) error
+	// DeleteRows deletes the rows of the keys
+	DeleteRows(ctx context.Context, table string, keys []string) error
 
 	// TODO: Isolation data management client and table management client
 	Tables(ctx context.Context) ([]string, error)
+	AppProfiles(ctx context.Context) ([]*domain.AppProfile, error)
+	DeleteTable(ctx context.Context, table string) error
+	RestoreTable(ctx context.Context, table, cluster, backup string) error
+	TableInfo(ctx context.Context, table string) (*domain.TableInfo, error)
+	SetBackupPolicy(ctx context.Context, table string, policy *domain.BackupPolicy) error
+	SetDeletionProtection(ctx context.Context, table string, protected bool) error
+	SetChangeStream(ctx context.Context, table string, retention time.Duration) error
+	CreateTable(ctx context.Context, table string, families []*domain.Family, splits []string) error
+	// CreateFamily adds the column family with the GC policy of the family to the table
+	CreateFamily(ctx context.Context, table string, family *domain.Family) error
+	CreateTableLike(ctx context.Context, src, dst string) error
+	CopyRows(ctx context.Context, src, dst string) (int, error)
+}
+
+// CircuitBreaker represents the repository failing fast while the backend is unavailable
+type CircuitBreaker interface {
+	Reset()
 }
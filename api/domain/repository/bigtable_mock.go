@@ -10,6 +10,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/takashabe/btcli/api/domain"
 	reflect "reflect"
+	time "time"
 )
 
 // MockBigtable is a mock of Bigtable interface
@@ -54,8 +55,8 @@ func (mr *MockBigtableMockRecorder) Get(ctx, table, key interface{}, opts ...int
 }
 
 // GetRows mocks base method
-func (m *MockBigtable) GetRows(ctx context.Context, table string, rr bigtable.RowRange, opts ...bigtable.ReadOption) (*domain.Bigtable, error) {
-	varargs := []interface{}{ctx, table, rr}
+func (m *MockBigtable) GetRows(ctx context.Context, table string, rs bigtable.RowSet, opts ...bigtable.ReadOption) (*domain.Bigtable, error) {
+	varargs := []interface{}{ctx, table, rs}
 	for _, a := range opts {
 		varargs = append(varargs, a)
 	}
@@ -66,8 +67,8 @@ func (m *MockBigtable) GetRows(ctx context.Context, table string, rr bigtable.Ro
 }
 
 // GetRows indicates an expected call of GetRows
-func (mr *MockBigtableMockRecorder) GetRows(ctx, table, rr interface{}, opts ...interface{}) *gomock.Call {
-	varargs := append([]interface{}{ctx, table, rr}, opts...)
+func (mr *MockBigtableMockRecorder) GetRows(ctx, table, rs interface{}, opts ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx, table, rs}, opts...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRows", reflect.TypeOf((*MockBigtable)(nil).GetRows), varargs...)
 }
 
@@ -90,16 +91,16 @@ func (mr *MockBigtableMockRecorder) GetRowsWithPrefix(ctx, table, key interface{
 }
 
 // Count mocks base method
-func (m *MockBigtable) Count(ctx context.Context, table string) (int, error) {
-	ret := m.ctrl.Call(m, "Count", ctx, table)
+func (m *MockBigtable) Count(ctx context.Context, table string, rs bigtable.RowSet) (int, error) {
+	ret := m.ctrl.Call(m, "Count", ctx, table, rs)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Count indicates an expected call of Count
-func (mr *MockBigtableMockRecorder) Count(ctx, table interface{}) *gomock.Call {
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockBigtable)(nil).Count), ctx, table)
+func (mr *MockBigtableMockRecorder) Count(ctx, table, rs interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockBigtable)(nil).Count), ctx, table, rs)
 }
 
 // Tables mocks base method
@@ -114,3 +115,236 @@ func (m *MockBigtable) Tables(ctx context.Context) ([]string, error) {
 func (mr *MockBigtableMockRecorder) Tables(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tables", reflect.TypeOf((*MockBigtable)(nil).Tables), ctx)
 }
+
+// DeleteTable mocks base method
+func (m *MockBigtable) DeleteTable(ctx context.Context, table string) error {
+	ret := m.ctrl.Call(m, "DeleteTable", ctx, table)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTable indicates an expected call of DeleteTable
+func (mr *MockBigtableMockRecorder) DeleteTable(ctx, table interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTable", reflect.TypeOf((*MockBigtable)(nil).DeleteTable), ctx, table)
+}
+
+// RestoreTable mocks base method
+func (m *MockBigtable) RestoreTable(ctx context.Context, table, cluster, backup string) error {
+	ret := m.ctrl.Call(m, "RestoreTable", ctx, table, cluster, backup)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTable indicates an expected call of RestoreTable
+func (mr *MockBigtableMockRecorder) RestoreTable(ctx, table, cluster, backup interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTable", reflect.TypeOf((*MockBigtable)(nil).RestoreTable), ctx, table, cluster, backup)
+}
+
+// TableInfo mocks base method
+func (m *MockBigtable) TableInfo(ctx context.Context, table string) (*domain.TableInfo, error) {
+	ret := m.ctrl.Call(m, "TableInfo", ctx, table)
+	ret0, _ := ret[0].(*domain.TableInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TableInfo indicates an expected call of TableInfo
+func (mr *MockBigtableMockRecorder) TableInfo(ctx, table interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TableInfo", reflect.TypeOf((*MockBigtable)(nil).TableInfo), ctx, table)
+}
+
+// SetBackupPolicy mocks base method
+func (m *MockBigtable) SetBackupPolicy(ctx context.Context, table string, policy *domain.BackupPolicy) error {
+	ret := m.ctrl.Call(m, "SetBackupPolicy", ctx, table, policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBackupPolicy indicates an expected call of SetBackupPolicy
+func (mr *MockBigtableMockRecorder) SetBackupPolicy(ctx, table, policy interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBackupPolicy", reflect.TypeOf((*MockBigtable)(nil).SetBackupPolicy), ctx, table, policy)
+}
+
+// CreateTableLike mocks base method
+func (m *MockBigtable) CreateTableLike(ctx context.Context, src, dst string) error {
+	ret := m.ctrl.Call(m, "CreateTableLike", ctx, src, dst)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTableLike indicates an expected call of CreateTableLike
+func (mr *MockBigtableMockRecorder) CreateTableLike(ctx, src, dst interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTableLike", reflect.TypeOf((*MockBigtable)(nil).CreateTableLike), ctx, src, dst)
+}
+
+// CopyRows mocks base method
+func (m *MockBigtable) CopyRows(ctx context.Context, src, dst string) (int, error) {
+	ret := m.ctrl.Call(m, "CopyRows", ctx, src, dst)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyRows indicates an expected call of CopyRows
+func (mr *MockBigtableMockRecorder) CopyRows(ctx, src, dst interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyRows", reflect.TypeOf((*MockBigtable)(nil).CopyRows), ctx, src, dst)
+}
+
+// SetDeletionProtection mocks base method
+func (m *MockBigtable) SetDeletionProtection(ctx context.Context, table string, protected bool) error {
+	ret := m.ctrl.Call(m, "SetDeletionProtection", ctx, table, protected)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDeletionProtection indicates an expected call of SetDeletionProtection
+func (mr *MockBigtableMockRecorder) SetDeletionProtection(ctx, table, protected interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeletionProtection", reflect.TypeOf((*MockBigtable)(nil).SetDeletionProtection), ctx, table, protected)
+}
+
+// SetChangeStream mocks base method
+func (m *MockBigtable) SetChangeStream(ctx context.Context, table string, retention time.Duration) error {
+	ret := m.ctrl.Call(m, "SetChangeStream", ctx, table, retention)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetChangeStream indicates an expected call of SetChangeStream
+func (mr *MockBigtableMockRecorder) SetChangeStream(ctx, table, retention interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetChangeStream", reflect.TypeOf((*MockBigtable)(nil).SetChangeStream), ctx, table, retention)
+}
+
+// RowHistory mocks base method
+func (m *MockBigtable) RowHistory(ctx context.Context, table, key string, since time.Time) ([]*domain.RowChange, error) {
+	ret := m.ctrl.Call(m, "RowHistory", ctx, table, key, since)
+	ret0, _ := ret[0].([]*domain.RowChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RowHistory indicates an expected call of RowHistory
+func (mr *MockBigtableMockRecorder) RowHistory(ctx, table, key, since interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RowHistory", reflect.TypeOf((*MockBigtable)(nil).RowHistory), ctx, table, key, since)
+}
+
+// Keys mocks base method
+func (m *MockBigtable) Keys(ctx context.Context, table string, rs bigtable.RowSet) ([]string, error) {
+	ret := m.ctrl.Call(m, "Keys", ctx, table, rs)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Keys indicates an expected call of Keys
+func (mr *MockBigtableMockRecorder) Keys(ctx, table, rs interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Keys", reflect.TypeOf((*MockBigtable)(nil).Keys), ctx, table, rs)
+}
+
+// SampleKeys mocks base method
+func (m *MockBigtable) SampleKeys(ctx context.Context, table string) ([]*domain.KeySample, error) {
+	ret := m.ctrl.Call(m, "SampleKeys", ctx, table)
+	ret0, _ := ret[0].([]*domain.KeySample)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SampleKeys indicates an expected call of SampleKeys
+func (mr *MockBigtableMockRecorder) SampleKeys(ctx, table interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SampleKeys", reflect.TypeOf((*MockBigtable)(nil).SampleKeys), ctx, table)
+}
+
+// Stats mocks base method
+func (m *MockBigtable) Stats(ctx context.Context, table string, rs bigtable.RowSet, opts ...bigtable.ReadOption) (*domain.RangeStats, error) {
+	varargs := []interface{}{ctx, table, rs}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Stats", varargs...)
+	ret0, _ := ret[0].(*domain.RangeStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats
+func (mr *MockBigtableMockRecorder) Stats(ctx, table, rs interface{}, opts ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx, table, rs}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockBigtable)(nil).Stats), varargs...)
+}
+
+// AppProfiles mocks base method
+func (m *MockBigtable) AppProfiles(ctx context.Context) ([]*domain.AppProfile, error) {
+	ret := m.ctrl.Call(m, "AppProfiles", ctx)
+	ret0, _ := ret[0].([]*domain.AppProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AppProfiles indicates an expected call of AppProfiles
+func (mr *MockBigtableMockRecorder) AppProfiles(ctx interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppProfiles", reflect.TypeOf((*MockBigtable)(nil).AppProfiles), ctx)
+}
+
+// ScanRows mocks base method
+func (m *MockBigtable) ScanRows(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+	varargs := []interface{}{ctx, table, rs, f}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ScanRows", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScanRows indicates an expected call of ScanRows
+func (mr *MockBigtableMockRecorder) ScanRows(ctx, table, rs, f interface{}, opts ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{ctx, table, rs, f}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanRows", reflect.TypeOf((*MockBigtable)(nil).ScanRows), varargs...)
+}
+
+// CreateTable mocks base method
+func (m *MockBigtable) CreateTable(ctx context.Context, table string, families []*domain.Family, splits []string) error {
+	ret := m.ctrl.Call(m, "CreateTable", ctx, table, families, splits)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTable indicates an expected call of CreateTable
+func (mr *MockBigtableMockRecorder) CreateTable(ctx, table, families, splits interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTable", reflect.TypeOf((*MockBigtable)(nil).CreateTable), ctx, table, families, splits)
+}
+
+// CreateFamily mocks base method
+func (m *MockBigtable) CreateFamily(ctx context.Context, table string, family *domain.Family) error {
+	ret := m.ctrl.Call(m, "CreateFamily", ctx, table, family)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateFamily indicates an expected call of CreateFamily
+func (mr *MockBigtableMockRecorder) CreateFamily(ctx, table, family interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFamily", reflect.TypeOf((*MockBigtable)(nil).CreateFamily), ctx, table, family)
+}
+
+// WriteRows mocks base method
+func (m *MockBigtable) WriteRows(ctx context.Context, table string, rows []*domain.Row) error {
+	ret := m.ctrl.Call(m, "WriteRows", ctx, table, rows)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteRows indicates an expected call of WriteRows
+func (mr *MockBigtableMockRecorder) WriteRows(ctx, table, rows interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteRows", reflect.TypeOf((*MockBigtable)(nil).WriteRows), ctx, table, rows)
+}
+
+// DeleteRows mocks base method
+func (m *MockBigtable) DeleteRows(ctx context.Context, table string, keys []string) error {
+	ret := m.ctrl.Call(m, "DeleteRows", ctx, table, keys)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRows indicates an expected call of DeleteRows
+func (mr *MockBigtableMockRecorder) DeleteRows(ctx, table, keys interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRows", reflect.TypeOf((*MockBigtable)(nil).DeleteRows), ctx, table, keys)
+}
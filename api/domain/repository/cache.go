@@ -0,0 +1,13 @@
+package repository
+
+// MetadataCache represent local storage of the metadata of the instance shared by the sessions
+type MetadataCache interface {
+	// Tables returns the cached tables, ok is false when they aren't cached or expired
+	Tables() (tables []string, ok bool)
+	SaveTables(tables []string) error
+	// Families returns the cached column families of the table, ok is false when they aren't cached or expired
+	Families(table string) (families []string, ok bool)
+	SaveFamilies(table string, families []string) error
+	// Clear drops the cached metadata, e.g. after the tables are changed
+	Clear() error
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+type appProfileKey struct{}
+
+type timeoutKey struct{}
+
+// WithAppProfile returns the context requesting with the app profile
+func WithAppProfile(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, appProfileKey{}, id)
+}
+
+// AppProfile returns the app profile of the request, empty means the default app profile of the instance
+func AppProfile(ctx context.Context) string {
+	id, _ := ctx.Value(appProfileKey{}).(string)
+	return id
+}
+
+// WithTimeout returns the context giving up each request after the duration
+func WithTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, d)
+}
+
+// Timeout returns the timeout of each request, 0 means no timeout
+func Timeout(ctx context.Context) time.Duration {
+	d, _ := ctx.Value(timeoutKey{}).(time.Duration)
+	return d
+}
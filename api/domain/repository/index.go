@@ -0,0 +1,11 @@
+package repository
+
+// KeyIndex represent local storage of the row keys of the tables
+type KeyIndex interface {
+	// Save replaces the keys of the table
+	Save(table string, keys []string) error
+	// Tables returns the indexed tables
+	Tables() ([]string, error)
+	// Scan calls f with the keys having the prefix in order until f returns false
+	Scan(table, prefix string, f func(key string) bool) error
+}
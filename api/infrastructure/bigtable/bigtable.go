@@ -2,46 +2,99 @@ package bigtable
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/bigtable"
+	btapb "cloud.google.com/go/bigtable/admin/apiv2/adminpb"
+	btpb "cloud.google.com/go/bigtable/apiv2/bigtablepb"
 	"github.com/takashabe/btcli/api/domain"
 	"github.com/takashabe/btcli/api/domain/repository"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
 )
 
 type bigtableRepository struct {
+	project  string
+	instance string
+
 	client      *bigtable.Client
 	adminClient *bigtable.AdminClient
+	// opts are the options of all the clients e.g. the credentials
+	opts []option.ClientOption
+
+	mu sync.Mutex
+	// profileClients are the clients of the app profiles, connected at the first use
+	profileClients map[string]*bigtable.Client
+	// instanceAdminClient lists the app profiles, connected at the first use
+	instanceAdminClient *bigtable.InstanceAdminClient
+	// rawClient calls the data API not covered by the client library, connected at the first use
+	rawClient btpb.BigtableClient
 }
 
 // NewBigtableRepository returns initialized bigtableRepository
-func NewBigtableRepository(project, instance string) (repository.Bigtable, error) {
-	client, err := getClient(project, instance)
+func NewBigtableRepository(project, instance string, opts ...option.ClientOption) (repository.Bigtable, error) {
+	client, err := getClient(project, instance, opts...)
 	if err != nil {
 		return nil, err
 	}
-	adminClient, err := getAdminClient(project, instance)
+	adminClient, err := getAdminClient(project, instance, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return &bigtableRepository{
+		project:     project,
+		instance:    instance,
 		client:      client,
 		adminClient: adminClient,
+		opts:        opts,
 	}, nil
 }
 
-func getClient(project, instance string) (*bigtable.Client, error) {
-	// TODO: Support options
-	return bigtable.NewClient(context.Background(), project, instance)
+func getClient(project, instance string, opts ...option.ClientOption) (*bigtable.Client, error) {
+	return bigtable.NewClient(context.Background(), project, instance, opts...)
 }
 
-func getAdminClient(project, instance string) (*bigtable.AdminClient, error) {
-	// TODO: Support options
-	return bigtable.NewAdminClient(context.Background(), project, instance)
+// open returns the table with the client of the app profile in the context
+func (b *bigtableRepository) open(ctx context.Context, table string) (*bigtable.Table, error) {
+	profile := repository.AppProfile(ctx)
+	if profile == "" {
+		return b.client.Open(table), nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	client, ok := b.profileClients[profile]
+	if !ok {
+		var err error
+		client, err = bigtable.NewClientWithConfig(context.Background(), b.project, b.instance, bigtable.ClientConfig{
+			AppProfile: profile,
+		}, b.opts...)
+		if err != nil {
+			return nil, err
+		}
+		if b.profileClients == nil {
+			b.profileClients = map[string]*bigtable.Client{}
+		}
+		b.profileClients[profile] = client
+	}
+	return client.Open(table), nil
+}
+
+func getAdminClient(project, instance string, opts ...option.ClientOption) (*bigtable.AdminClient, error) {
+	return bigtable.NewAdminClient(context.Background(), project, instance, opts...)
 }
 
 func (b *bigtableRepository) Get(ctx context.Context, table, key string, opts ...bigtable.ReadOption) (*domain.Bigtable, error) {
-	tbl := b.client.Open(table)
+	tbl, err := b.open(ctx, table)
+	if err != nil {
+		return nil, err
+	}
 
 	row, err := tbl.ReadRow(ctx, key, opts...)
 	if err != nil {
@@ -55,11 +108,14 @@ func (b *bigtableRepository) Get(ctx context.Context, table, key string, opts ..
 	}, nil
 }
 
-func (b *bigtableRepository) GetRows(ctx context.Context, table string, rr bigtable.RowRange, opts ...bigtable.ReadOption) (*domain.Bigtable, error) {
-	tbl := b.client.Open(table)
+func (b *bigtableRepository) GetRows(ctx context.Context, table string, rs bigtable.RowSet, opts ...bigtable.ReadOption) (*domain.Bigtable, error) {
+	tbl, err := b.open(ctx, table)
+	if err != nil {
+		return nil, err
+	}
 
 	rows := []*domain.Row{}
-	err := tbl.ReadRows(ctx, rr, func(row bigtable.Row) bool {
+	err = tbl.ReadRows(ctx, rs, func(row bigtable.Row) bool {
 		rows = append(rows, readRow(row))
 		return true
 	}, opts...)
@@ -72,17 +128,67 @@ func (b *bigtableRepository) GetRows(ctx context.Context, table string, rr bigta
 	}, nil
 }
 
-func (b *bigtableRepository) Count(ctx context.Context, table string) (int, error) {
-	tbl := b.client.Open(table)
+func (b *bigtableRepository) ScanRows(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+	tbl, err := b.open(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	return tbl.ReadRows(ctx, rs, func(row bigtable.Row) bool {
+		return f(readRow(row))
+	}, opts...)
+}
+
+func (b *bigtableRepository) Count(ctx context.Context, table string, rs bigtable.RowSet) (int, error) {
+	tbl, err := b.open(ctx, table)
+	if err != nil {
+		return 0, err
+	}
 
 	cnt := 0
-	err := tbl.ReadRows(ctx, bigtable.InfiniteRange(""), func(_ bigtable.Row) bool {
+	err = tbl.ReadRows(ctx, rs, func(_ bigtable.Row) bool {
 		cnt++
 		return true
 	}, bigtable.RowFilter(bigtable.StripValueFilter()))
 	return cnt, err
 }
 
+func (b *bigtableRepository) Stats(ctx context.Context, table string, rs bigtable.RowSet, opts ...bigtable.ReadOption) (*domain.RangeStats, error) {
+	tbl, err := b.open(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.RangeStats{}
+	qualifiers := map[string]struct{}{}
+	err = tbl.ReadRows(ctx, rs, func(row bigtable.Row) bool {
+		stats.Rows++
+		stats.KeyBytes += int64(len(row.Key()))
+		for _, items := range row {
+			for _, item := range items {
+				stats.Cells++
+				stats.QualifierBytes += int64(len(item.Column))
+				stats.ValueBytes += int64(len(item.Value))
+				qualifiers[item.Column] = struct{}{}
+
+				t := item.Timestamp.Time()
+				if stats.MinTimestamp.IsZero() || t.Before(stats.MinTimestamp) {
+					stats.MinTimestamp = t
+				}
+				if t.After(stats.MaxTimestamp) {
+					stats.MaxTimestamp = t
+				}
+			}
+		}
+		return true
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	stats.Qualifiers = len(qualifiers)
+	return stats, nil
+}
+
 func readRow(r bigtable.Row) *domain.Row {
 	ret := &domain.Row{
 		Key:     r.Key(),
@@ -96,6 +202,7 @@ func readRow(r bigtable.Row) *domain.Row {
 				Qualifier: ri.Column,
 				Value:     ri.Value,
 				Version:   ri.Timestamp.Time(),
+				Labels:    ri.Labels,
 			}
 			ret.Columns = append(ret.Columns, c)
 		}
@@ -107,6 +214,171 @@ func readRow(r bigtable.Row) *domain.Row {
 	return ret
 }
 
+func (b *bigtableRepository) Keys(ctx context.Context, table string, rs bigtable.RowSet) ([]string, error) {
+	tbl, err := b.open(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	filter := bigtable.ChainFilters(bigtable.CellsPerRowLimitFilter(1), bigtable.StripValueFilter())
+	err = tbl.ReadRows(ctx, rs, func(row bigtable.Row) bool {
+		keys = append(keys, row.Key())
+		return true
+	}, bigtable.RowFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// SampleKeys calls the API directly, the client library drops the offsets of the samples
+func (b *bigtableRepository) SampleKeys(ctx context.Context, table string) ([]*domain.KeySample, error) {
+	client, err := b.dataClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := client.SampleRowKeys(ctx, &btpb.SampleRowKeysRequest{
+		TableName:    fmt.Sprintf("projects/%s/instances/%s/tables/%s", b.project, b.instance, table),
+		AppProfileId: repository.AppProfile(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	samples := []*domain.KeySample{}
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return samples, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, &domain.KeySample{
+			Key:    string(res.RowKey),
+			Offset: res.OffsetBytes,
+		})
+	}
+}
+
+func (b *bigtableRepository) WriteRows(ctx context.Context, table string, rows []*domain.Row) error {
+	tbl, err := b.open(ctx, table)
+	if err != nil {
+		return err
+	}
+	for len(rows) > 0 {
+		n := len(rows)
+		if n > copyBatchSize {
+			n = copyBatchSize
+		}
+		keys := make([]string, n)
+		muts := make([]*bigtable.Mutation, n)
+		for i, r := range rows[:n] {
+			mut := bigtable.NewMutation()
+			for _, c := range r.Columns {
+				ts := bigtable.ServerTime
+				if !c.Version.IsZero() {
+					ts = bigtable.Time(c.Version)
+				}
+				mut.Set(c.Family, strings.TrimPrefix(c.Qualifier, c.Family+":"), ts, c.Value)
+			}
+			keys[i], muts[i] = r.Key, mut
+		}
+		errs, err := tbl.ApplyBulk(ctx, keys, muts)
+		if err != nil {
+			return err
+		}
+		for i, err := range errs {
+			if err != nil {
+				return fmt.Errorf("row %q: %v", keys[i], err)
+			}
+		}
+		rows = rows[n:]
+	}
+	return nil
+}
+
+func (b *bigtableRepository) DeleteRows(ctx context.Context, table string, keys []string) error {
+	tbl, err := b.open(ctx, table)
+	if err != nil {
+		return err
+	}
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > copyBatchSize {
+			n = copyBatchSize
+		}
+		muts := make([]*bigtable.Mutation, n)
+		for i := range muts {
+			muts[i] = bigtable.NewMutation()
+			muts[i].DeleteRow()
+		}
+		errs, err := tbl.ApplyBulk(ctx, keys[:n], muts)
+		if err != nil {
+			return err
+		}
+		for i, err := range errs {
+			if err != nil {
+				return fmt.Errorf("row %q: %v", keys[i], err)
+			}
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
+
+func (b *bigtableRepository) AppProfiles(ctx context.Context) ([]*domain.AppProfile, error) {
+	b.mu.Lock()
+	if b.instanceAdminClient == nil {
+		client, err := bigtable.NewInstanceAdminClient(context.Background(), b.project, b.opts...)
+		if err != nil {
+			b.mu.Unlock()
+			return nil, err
+		}
+		b.instanceAdminClient = client
+	}
+	client := b.instanceAdminClient
+	b.mu.Unlock()
+
+	profiles := []*domain.AppProfile{}
+	it := client.ListAppProfiles(ctx, b.instance)
+	for {
+		p, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// the name is "projects/<project>/instances/<instance>/appProfiles/<id>"
+		profiles = append(profiles, &domain.AppProfile{
+			ID:       p.Name[strings.LastIndex(p.Name, "/")+1:],
+			Cluster:  p.GetSingleClusterRouting().GetClusterId(),
+			Priority: appProfilePriority(p),
+		})
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].ID < profiles[j].ID
+	})
+	return profiles, nil
+}
+
+func appProfilePriority(p *btapb.AppProfile) string {
+	if p.GetDataBoostIsolationReadOnly() != nil {
+		return ""
+	}
+	switch p.GetStandardIsolation().GetPriority() {
+	case btapb.AppProfile_PRIORITY_LOW:
+		return domain.AppProfilePriorityLow
+	case btapb.AppProfile_PRIORITY_MEDIUM:
+		return domain.AppProfilePriorityMedium
+	default:
+		// the unspecified priority is the legacy high priority
+		return domain.AppProfilePriorityHigh
+	}
+}
+
 func (b *bigtableRepository) Tables(ctx context.Context) ([]string, error) {
 	tbls, err := b.adminClient.Tables(ctx)
 	if err != nil {
@@ -115,3 +387,194 @@ func (b *bigtableRepository) Tables(ctx context.Context) ([]string, error) {
 	sort.Strings(tbls)
 	return tbls, nil
 }
+
+func (b *bigtableRepository) DeleteTable(ctx context.Context, table string) error {
+	return b.adminClient.DeleteTable(ctx, table)
+}
+
+func (b *bigtableRepository) RestoreTable(ctx context.Context, table, cluster, backup string) error {
+	return b.adminClient.RestoreTable(ctx, table, cluster, backup)
+}
+
+func (b *bigtableRepository) TableInfo(ctx context.Context, table string) (*domain.TableInfo, error) {
+	info, err := b.adminClient.TableInfo(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &domain.TableInfo{
+		Name:     table,
+		Families: make([]*domain.Family, 0, len(info.FamilyInfos)),
+	}
+	for _, f := range info.FamilyInfos {
+		ret.Families = append(ret.Families, &domain.Family{
+			Name:     f.Name,
+			GCPolicy: f.GCPolicy,
+		})
+	}
+	sort.Slice(ret.Families, func(i, j int) bool {
+		return ret.Families[i].Name < ret.Families[j].Name
+	})
+	ret.DeletionProtection = info.DeletionProtection == bigtable.Protected
+	if d, ok := info.ChangeStreamRetention.(time.Duration); ok {
+		ret.ChangeStreamRetention = d
+	}
+	if p, ok := info.AutomatedBackupConfig.(*bigtable.TableAutomatedBackupPolicy); ok && p != nil && !p.Disabled {
+		ret.BackupPolicy = &domain.BackupPolicy{}
+		if d, ok := p.RetentionPeriod.(time.Duration); ok {
+			ret.BackupPolicy.Retention = d
+		}
+		if d, ok := p.Frequency.(time.Duration); ok {
+			ret.BackupPolicy.Frequency = d
+		}
+	}
+	return ret, nil
+}
+
+func (b *bigtableRepository) SetBackupPolicy(ctx context.Context, table string, policy *domain.BackupPolicy) error {
+	if policy == nil {
+		return b.adminClient.UpdateTableDisableAutomatedBackupPolicy(ctx, table)
+	}
+
+	// nil fields keep the current values
+	p := bigtable.TableAutomatedBackupPolicy{}
+	if policy.Retention > 0 {
+		p.RetentionPeriod = policy.Retention
+	}
+	if policy.Frequency > 0 {
+		p.Frequency = policy.Frequency
+	}
+	return b.adminClient.UpdateTableWithAutomatedBackupPolicy(ctx, table, p)
+}
+
+func (b *bigtableRepository) SetDeletionProtection(ctx context.Context, table string, protected bool) error {
+	p := bigtable.Unprotected
+	if protected {
+		p = bigtable.Protected
+	}
+	return b.adminClient.UpdateTableWithDeletionProtection(ctx, table, p)
+}
+
+func (b *bigtableRepository) SetChangeStream(ctx context.Context, table string, retention time.Duration) error {
+	if retention == 0 {
+		return b.adminClient.UpdateTableDisableChangeStream(ctx, table)
+	}
+	return b.adminClient.UpdateTableWithChangeStream(ctx, table, retention)
+}
+
+func (b *bigtableRepository) CreateTable(ctx context.Context, table string, families []*domain.Family, splits []string) error {
+	conf := &bigtable.TableConf{
+		TableID:   table,
+		SplitKeys: splits,
+		Families:  make(map[string]bigtable.GCPolicy, len(families)),
+	}
+	for _, f := range families {
+		conf.Families[f.Name] = familyGCPolicy(f)
+	}
+	return b.adminClient.CreateTableFromConf(ctx, conf)
+}
+
+func (b *bigtableRepository) CreateFamily(ctx context.Context, table string, family *domain.Family) error {
+	if err := b.adminClient.CreateColumnFamily(ctx, table, family.Name); err != nil {
+		return err
+	}
+	return b.adminClient.SetGCPolicy(ctx, table, family.Name, familyGCPolicy(family))
+}
+
+// familyGCPolicy returns the GC policy of the new family collecting the cells by either of the limits
+func familyGCPolicy(f *domain.Family) bigtable.GCPolicy {
+	var policies []bigtable.GCPolicy
+	if f.MaxVersions > 0 {
+		policies = append(policies, bigtable.MaxVersionsPolicy(f.MaxVersions))
+	}
+	if f.MaxAge > 0 {
+		policies = append(policies, bigtable.MaxAgePolicy(f.MaxAge))
+	}
+	switch len(policies) {
+	case 0:
+		return bigtable.NoGcPolicy()
+	case 1:
+		return policies[0]
+	}
+	return bigtable.UnionPolicy(policies...)
+}
+
+func (b *bigtableRepository) CreateTableLike(ctx context.Context, src, dst string) error {
+	info, err := b.adminClient.TableInfo(ctx, src)
+	if err != nil {
+		return err
+	}
+
+	conf := &bigtable.TableConf{
+		TableID:  dst,
+		Families: make(map[string]bigtable.GCPolicy, len(info.FamilyInfos)),
+	}
+	for _, f := range info.FamilyInfos {
+		policy := f.FullGCPolicy
+		if policy == nil {
+			policy = bigtable.NoGcPolicy()
+		}
+		conf.Families[f.Name] = policy
+	}
+	return b.adminClient.CreateTableFromConf(ctx, conf)
+}
+
+// copyBatchSize is a number of rows in a bulk mutation
+const copyBatchSize = 1000
+
+func (b *bigtableRepository) CopyRows(ctx context.Context, src, dst string) (int, error) {
+	from, err := b.open(ctx, src)
+	if err != nil {
+		return 0, err
+	}
+	to, err := b.open(ctx, dst)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		keys []string
+		muts []*bigtable.Mutation
+		cnt  int
+	)
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		errs, err := to.ApplyBulk(ctx, keys, muts)
+		if err != nil {
+			return err
+		}
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+		cnt += len(keys)
+		keys, muts = keys[:0], muts[:0]
+		return nil
+	}
+
+	var applyErr error
+	err = from.ReadRows(ctx, bigtable.InfiniteRange(""), func(row bigtable.Row) bool {
+		mut := bigtable.NewMutation()
+		for fam, items := range row {
+			for _, item := range items {
+				mut.Set(fam, strings.TrimPrefix(item.Column, fam+":"), item.Timestamp, item.Value)
+			}
+		}
+		keys = append(keys, row.Key())
+		muts = append(muts, mut)
+		if len(keys) >= copyBatchSize {
+			applyErr = flush()
+		}
+		return applyErr == nil
+	})
+	if err != nil {
+		return cnt, err
+	}
+	if applyErr != nil {
+		return cnt, applyErr
+	}
+	return cnt, flush()
+}
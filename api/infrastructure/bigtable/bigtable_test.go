@@ -188,15 +188,17 @@ func TestCount(t *testing.T) {
 
 	cases := []struct {
 		table  string
+		rs     bigtable.RowSet
 		expect int
 	}{
-		{"users", 5},
+		{"users", bigtable.InfiniteRange(""), 5},
+		{"users", bigtable.PrefixRange("1"), 2},
 	}
 	for _, c := range cases {
 		r, err := NewBigtableRepository("test-project", "test-instance")
 		assert.NoError(t, err)
 
-		cnt, err := r.Count(context.Background(), c.table)
+		cnt, err := r.Count(context.Background(), c.table, c.rs)
 		assert.NoError(t, err)
 
 		assert.Equal(t, c.expect, cnt)
@@ -0,0 +1,326 @@
+package bigtable
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// circuit breaker defaults
+const (
+	defaultBreakerThreshold = 3
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// breakerRepository fails fast while the backend is unavailable
+type breakerRepository struct {
+	repository.Bigtable
+
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	lastErr  error
+}
+
+// NewBreakerRepository returns the repository that opens the circuit after repeated unavailable errors
+func NewBreakerRepository(r repository.Bigtable) repository.Bigtable {
+	return &breakerRepository{
+		Bigtable:  r,
+		threshold: defaultBreakerThreshold,
+		cooldown:  defaultBreakerCooldown,
+	}
+}
+
+// Reset closes the circuit
+func (b *breakerRepository) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.openedAt = time.Time{}
+	b.lastErr = nil
+}
+
+// allow returns an error while the circuit is open, and allows a trial request after the cooldown
+func (b *breakerRepository) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() || time.Since(b.openedAt) > b.cooldown {
+		return nil
+	}
+	return fmt.Errorf("bigtable seems unavailable after %d consecutive failures (last: %v), failing fast. run \"reset\" to retry now", b.failures, b.lastErr)
+}
+
+func (b *breakerRepository) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// the timeouts given by -request-timeout are neither the failures nor the successes of the backend
+	if isDeadlineExceeded(err) {
+		return
+	}
+	if !isUnavailable(err) {
+		b.failures = 0
+		b.openedAt = time.Time{}
+		return
+	}
+	b.failures++
+	b.lastErr = err
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+}
+
+// withRequestTimeout returns the context cancelled after the timeout of the request if given,
+// only the point requests are timed out, the streaming reads, the copies and the restores take their time
+func withRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d := repository.Timeout(ctx); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return ctx, func() {}
+}
+
+func isUnavailable(err error) bool {
+	return err != nil && status.Code(err) == codes.Unavailable
+}
+
+func isDeadlineExceeded(err error) bool {
+	return err == context.DeadlineExceeded || status.Code(err) == codes.DeadlineExceeded
+}
+
+func (b *breakerRepository) Get(ctx context.Context, table, key string, opts ...bigtable.ReadOption) (*domain.Bigtable, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	tbl, err := b.Bigtable.Get(ctx, table, key, opts...)
+	b.record(err)
+	return tbl, err
+}
+
+func (b *breakerRepository) GetRows(ctx context.Context, table string, rs bigtable.RowSet, opts ...bigtable.ReadOption) (*domain.Bigtable, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	tbl, err := b.Bigtable.GetRows(ctx, table, rs, opts...)
+	b.record(err)
+	return tbl, err
+}
+
+func (b *breakerRepository) ScanRows(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := b.Bigtable.ScanRows(ctx, table, rs, f, opts...)
+	b.record(err)
+	return err
+}
+
+func (b *breakerRepository) Count(ctx context.Context, table string, rs bigtable.RowSet) (int, error) {
+	if err := b.allow(); err != nil {
+		return 0, err
+	}
+	cnt, err := b.Bigtable.Count(ctx, table, rs)
+	b.record(err)
+	return cnt, err
+}
+
+func (b *breakerRepository) Stats(ctx context.Context, table string, rs bigtable.RowSet, opts ...bigtable.ReadOption) (*domain.RangeStats, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	stats, err := b.Bigtable.Stats(ctx, table, rs, opts...)
+	b.record(err)
+	return stats, err
+}
+
+func (b *breakerRepository) Keys(ctx context.Context, table string, rs bigtable.RowSet) ([]string, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	keys, err := b.Bigtable.Keys(ctx, table, rs)
+	b.record(err)
+	return keys, err
+}
+
+func (b *breakerRepository) RowHistory(ctx context.Context, table, key string, since time.Time) ([]*domain.RowChange, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	changes, err := b.Bigtable.RowHistory(ctx, table, key, since)
+	b.record(err)
+	return changes, err
+}
+
+func (b *breakerRepository) SampleKeys(ctx context.Context, table string) ([]*domain.KeySample, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	samples, err := b.Bigtable.SampleKeys(ctx, table)
+	b.record(err)
+	return samples, err
+}
+
+func (b *breakerRepository) WriteRows(ctx context.Context, table string, rows []*domain.Row) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	err := b.Bigtable.WriteRows(ctx, table, rows)
+	b.record(err)
+	return err
+}
+
+func (b *breakerRepository) DeleteRows(ctx context.Context, table string, keys []string) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	err := b.Bigtable.DeleteRows(ctx, table, keys)
+	b.record(err)
+	return err
+}
+
+func (b *breakerRepository) Tables(ctx context.Context) ([]string, error) {
+	if err := b.allow(); err != nil {
+		return []string{}, err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	tbls, err := b.Bigtable.Tables(ctx)
+	b.record(err)
+	return tbls, err
+}
+
+func (b *breakerRepository) AppProfiles(ctx context.Context) ([]*domain.AppProfile, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	profiles, err := b.Bigtable.AppProfiles(ctx)
+	b.record(err)
+	return profiles, err
+}
+
+func (b *breakerRepository) DeleteTable(ctx context.Context, table string) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	err := b.Bigtable.DeleteTable(ctx, table)
+	b.record(err)
+	return err
+}
+
+func (b *breakerRepository) RestoreTable(ctx context.Context, table, cluster, backup string) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	err := b.Bigtable.RestoreTable(ctx, table, cluster, backup)
+	b.record(err)
+	return err
+}
+
+func (b *breakerRepository) TableInfo(ctx context.Context, table string) (*domain.TableInfo, error) {
+	if err := b.allow(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	info, err := b.Bigtable.TableInfo(ctx, table)
+	b.record(err)
+	return info, err
+}
+
+func (b *breakerRepository) SetBackupPolicy(ctx context.Context, table string, policy *domain.BackupPolicy) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	err := b.Bigtable.SetBackupPolicy(ctx, table, policy)
+	b.record(err)
+	return err
+}
+
+func (b *breakerRepository) SetDeletionProtection(ctx context.Context, table string, protected bool) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	err := b.Bigtable.SetDeletionProtection(ctx, table, protected)
+	b.record(err)
+	return err
+}
+
+func (b *breakerRepository) SetChangeStream(ctx context.Context, table string, retention time.Duration) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	err := b.Bigtable.SetChangeStream(ctx, table, retention)
+	b.record(err)
+	return err
+}
+
+func (b *breakerRepository) CreateTable(ctx context.Context, table string, families []*domain.Family, splits []string) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	err := b.Bigtable.CreateTable(ctx, table, families, splits)
+	b.record(err)
+	return err
+}
+
+func (b *breakerRepository) CreateFamily(ctx context.Context, table string, family *domain.Family) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	err := b.Bigtable.CreateFamily(ctx, table, family)
+	b.record(err)
+	return err
+}
+
+func (b *breakerRepository) CreateTableLike(ctx context.Context, src, dst string) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+	ctx, cancel := withRequestTimeout(ctx)
+	defer cancel()
+	err := b.Bigtable.CreateTableLike(ctx, src, dst)
+	b.record(err)
+	return err
+}
+
+func (b *breakerRepository) CopyRows(ctx context.Context, src, dst string) (int, error) {
+	if err := b.allow(); err != nil {
+		return 0, err
+	}
+	cnt, err := b.Bigtable.CopyRows(ctx, src, dst)
+	b.record(err)
+	return cnt, err
+}
@@ -0,0 +1,105 @@
+package bigtable
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBreakerRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := repository.NewMockBigtable(ctrl)
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	mock.EXPECT().Count(gomock.Any(), "users", gomock.Any()).Return(0, unavailable).Times(defaultBreakerThreshold)
+	mock.EXPECT().Count(gomock.Any(), "users", gomock.Any()).Return(1, nil).Times(1)
+
+	r := NewBreakerRepository(mock)
+	for i := 0; i < defaultBreakerThreshold; i++ {
+		_, err := r.Count(context.Background(), "users", bigtable.InfiniteRange(""))
+		assert.Equal(t, unavailable, err)
+	}
+
+	// fail fast without calling the repository
+	_, err := r.Count(context.Background(), "users", bigtable.InfiniteRange(""))
+	assert.Error(t, err)
+
+	r.(repository.CircuitBreaker).Reset()
+	cnt, err := r.Count(context.Background(), "users", bigtable.InfiniteRange(""))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, cnt)
+}
+
+func TestBreakerRepositoryAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := repository.NewMockBigtable(ctrl)
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+	mock.EXPECT().TableInfo(gomock.Any(), "users").Return(nil, unavailable).Times(defaultBreakerThreshold)
+
+	r := NewBreakerRepository(mock)
+	for i := 0; i < defaultBreakerThreshold; i++ {
+		_, err := r.TableInfo(context.Background(), "users")
+		assert.Equal(t, unavailable, err)
+	}
+
+	// the admin requests also fail fast
+	assert.Error(t, r.DeleteTable(context.Background(), "users"))
+	_, err := r.CopyRows(context.Background(), "users", "users_copy")
+	assert.Error(t, err)
+}
+
+func TestBreakerRepositoryTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mock := repository.NewMockBigtable(ctrl)
+	mock.EXPECT().Tables(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]string, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}).Times(defaultBreakerThreshold)
+
+	// the scans aren't timed out
+	mock.EXPECT().ScanRows(gomock.Any(), "users", gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+		return nil
+	})
+
+	r := NewBreakerRepository(mock)
+	ctx := repository.WithTimeout(context.Background(), time.Millisecond)
+	for i := 0; i < defaultBreakerThreshold; i++ {
+		_, err := r.Tables(ctx)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	}
+	// the timeouts don't open the circuit
+	assert.NoError(t, r.ScanRows(ctx, "users", bigtable.RowList{"a"}, func(*domain.Row) bool { return true }))
+}
+
+func TestIsUnavailable(t *testing.T) {
+	cases := []struct {
+		err    error
+		expect bool
+	}{
+		{nil, false},
+		{errors.New("a"), false},
+		{context.DeadlineExceeded, false},
+		{status.Error(codes.DeadlineExceeded, "a"), false},
+		{status.Error(codes.Unavailable, "a"), true},
+		{status.Error(codes.NotFound, "a"), false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expect, isUnavailable(c.err))
+	}
+}
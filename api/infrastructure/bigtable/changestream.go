@@ -0,0 +1,258 @@
+package bigtable
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	btpb "cloud.google.com/go/bigtable/apiv2/bigtablepb"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+	"google.golang.org/api/option"
+	gtransport "google.golang.org/api/transport/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// changeStreamAddr is the endpoint of the data API, the client library doesn't read the change stream
+const changeStreamAddr = "bigtable.googleapis.com:443"
+
+func (b *bigtableRepository) dataClient(ctx context.Context) (btpb.BigtableClient, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rawClient != nil {
+		return b.rawClient, nil
+	}
+	conn, err := gtransport.Dial(ctx, b.dataClientOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	b.rawClient = btpb.NewBigtableClient(conn)
+	return b.rawClient, nil
+}
+
+// dataClientOptions returns the options of the raw data client, connected to BIGTABLE_EMULATOR_HOST
+// as the client library does, otherwise to the endpoint with the options of the repository
+func (b *bigtableRepository) dataClientOptions() []option.ClientOption {
+	if addr := os.Getenv("BIGTABLE_EMULATOR_HOST"); addr != "" {
+		return []option.ClientOption{
+			option.WithEndpoint(addr),
+			option.WithGRPCDialOption(grpc.WithInsecure()),
+			option.WithoutAuthentication(),
+		}
+	}
+	return append([]option.ClientOption{
+		option.WithEndpoint(changeStreamAddr),
+		option.WithScopes(bigtable.Scope),
+	}, b.opts...)
+}
+
+// streamCursor is a partition of the change stream and the position to start reading
+type streamCursor struct {
+	partition *btpb.StreamPartition
+	// token is nil at the initial partitions
+	token *btpb.StreamContinuationToken
+}
+
+func (b *bigtableRepository) RowHistory(ctx context.Context, table, key string, since time.Time) ([]*domain.RowChange, error) {
+	client, err := b.dataClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("projects/%s/instances/%s/tables/%s", b.project, b.instance, table)
+
+	cursors, err := initialPartitions(ctx, client, name, key)
+	if err != nil {
+		return nil, err
+	}
+
+	end := timestamppb.New(time.Now())
+	changes := []*domain.RowChange{}
+	for len(cursors) > 0 {
+		cur := cursors[0]
+		cursors = cursors[1:]
+
+		req := &btpb.ReadChangeStreamRequest{
+			TableName:    name,
+			AppProfileId: repository.AppProfile(ctx),
+			Partition:    cur.partition,
+			EndTime:      end,
+		}
+		if cur.token != nil {
+			req.StartFrom = &btpb.ReadChangeStreamRequest_ContinuationTokens{
+				ContinuationTokens: &btpb.StreamContinuationTokens{
+					Tokens: []*btpb.StreamContinuationToken{cur.token},
+				},
+			}
+		} else {
+			req.StartFrom = &btpb.ReadChangeStreamRequest_StartTime{
+				StartTime: timestamppb.New(since),
+			}
+		}
+
+		next, err := readPartitionChanges(ctx, client, req, key, &changes)
+		if err != nil {
+			return nil, err
+		}
+		cursors = append(cursors, next...)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Time.Before(changes[j].Time)
+	})
+	return changes, nil
+}
+
+// initialPartitions returns the partitions of the change stream containing the key
+func initialPartitions(ctx context.Context, client btpb.BigtableClient, name, key string) ([]*streamCursor, error) {
+	stream, err := client.GenerateInitialChangeStreamPartitions(ctx, &btpb.GenerateInitialChangeStreamPartitionsRequest{
+		TableName:    name,
+		AppProfileId: repository.AppProfile(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var cursors []*streamCursor
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return cursors, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if partitionContains(res.Partition, key) {
+			cursors = append(cursors, &streamCursor{partition: res.Partition})
+		}
+	}
+}
+
+// readPartitionChanges appends the changes of the key in the partition,
+// returns the split or merged partitions to continue reading
+func readPartitionChanges(ctx context.Context, client btpb.BigtableClient, req *btpb.ReadChangeStreamRequest, key string, changes *[]*domain.RowChange) ([]*streamCursor, error) {
+	stream, err := client.ReadChangeStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		next []*streamCursor
+		// current is the chunked change continued by the following messages
+		current *domain.RowChange
+	)
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			return next, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch r := res.StreamRecord.(type) {
+		case *btpb.ReadChangeStreamResponse_DataChange_:
+			dc := r.DataChange
+			if dc.Type != btpb.ReadChangeStreamResponse_DataChange_CONTINUATION {
+				current = nil
+				if string(dc.RowKey) == key {
+					current = newRowChange(dc)
+					*changes = append(*changes, current)
+				}
+			}
+			if current != nil {
+				appendMutations(current, dc.Chunks)
+			}
+			if dc.Done {
+				current = nil
+			}
+		case *btpb.ReadChangeStreamResponse_CloseStream_:
+			for _, t := range r.CloseStream.ContinuationTokens {
+				if partitionContains(t.Partition, key) {
+					next = append(next, &streamCursor{partition: t.Partition, token: t})
+				}
+			}
+		}
+	}
+}
+
+func newRowChange(dc *btpb.ReadChangeStreamResponse_DataChange) *domain.RowChange {
+	c := &domain.RowChange{
+		Time:    dc.CommitTimestamp.AsTime(),
+		Cluster: dc.SourceClusterId,
+	}
+	switch dc.Type {
+	case btpb.ReadChangeStreamResponse_DataChange_USER:
+		c.Type = domain.RowChangeUser
+	case btpb.ReadChangeStreamResponse_DataChange_GARBAGE_COLLECTION:
+		c.Type = domain.RowChangeGC
+	}
+	return c
+}
+
+// appendMutations appends the mutations, the chunked value is concatenated to the last mutation
+func appendMutations(c *domain.RowChange, chunks []*btpb.ReadChangeStreamResponse_MutationChunk) {
+	for _, chunk := range chunks {
+		if info := chunk.ChunkInfo; info != nil && info.ChunkedValueOffset > 0 && len(c.Mutations) > 0 {
+			last := c.Mutations[len(c.Mutations)-1]
+			last.Value = append(last.Value, chunk.Mutation.GetSetCell().GetValue()...)
+			continue
+		}
+
+		m := &domain.Mutation{}
+		switch mut := chunk.Mutation.GetMutation().(type) {
+		case *btpb.Mutation_SetCell_:
+			m.Type = domain.MutationSetCell
+			m.Family = mut.SetCell.FamilyName
+			m.Qualifier = string(mut.SetCell.ColumnQualifier)
+			m.Version = time.Unix(0, mut.SetCell.TimestampMicros*int64(time.Microsecond))
+			m.Value = append([]byte(nil), mut.SetCell.Value...)
+		case *btpb.Mutation_DeleteFromColumn_:
+			m.Type = domain.MutationDeleteColumn
+			m.Family = mut.DeleteFromColumn.FamilyName
+			m.Qualifier = string(mut.DeleteFromColumn.ColumnQualifier)
+		case *btpb.Mutation_DeleteFromFamily_:
+			m.Type = domain.MutationDeleteFamily
+			m.Family = mut.DeleteFromFamily.FamilyName
+		case *btpb.Mutation_DeleteFromRow_:
+			m.Type = domain.MutationDeleteRow
+		default:
+			continue
+		}
+		c.Mutations = append(c.Mutations, m)
+	}
+}
+
+// partitionContains reports whether the row range of the partition contains the key
+func partitionContains(p *btpb.StreamPartition, key string) bool {
+	rr := p.GetRowRange()
+	if rr == nil {
+		return true
+	}
+	switch s := rr.StartKey.(type) {
+	case *btpb.RowRange_StartKeyClosed:
+		if key < string(s.StartKeyClosed) {
+			return false
+		}
+	case *btpb.RowRange_StartKeyOpen:
+		if key <= string(s.StartKeyOpen) {
+			return false
+		}
+	}
+	switch e := rr.EndKey.(type) {
+	case *btpb.RowRange_EndKeyOpen:
+		if len(e.EndKeyOpen) > 0 && key >= string(e.EndKeyOpen) {
+			return false
+		}
+	case *btpb.RowRange_EndKeyClosed:
+		if len(e.EndKeyClosed) > 0 && key > string(e.EndKeyClosed) {
+			return false
+		}
+	}
+	return true
+}
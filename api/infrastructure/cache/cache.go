@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+const (
+	// lockRetry is an interval to retry the lock held by another session
+	lockRetry = 10 * time.Millisecond
+	// lockTimeout is a time to wait for the lock
+	lockTimeout = time.Second
+	// staleLock is an age of the lock left by a crashed session
+	staleLock = 10 * time.Second
+)
+
+// entry is the names fetched at the time
+type entry struct {
+	Names   []string  `json:"names"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// document is the content of the cache file
+type document struct {
+	Tables   *entry            `json:"tables,omitempty"`
+	Families map[string]*entry `json:"families,omitempty"`
+}
+
+// fileMetadataCache stores the metadata in a JSON file shared by the sessions,
+// the file is replaced by a rename under the lock file so that the readers don't need the lock
+type fileMetadataCache struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// NewFileMetadataCache returns the MetadataCache storing the file at the path, the entries expire after the ttl
+func NewFileMetadataCache(path string, ttl time.Duration) repository.MetadataCache {
+	return &fileMetadataCache{path: path, ttl: ttl, now: time.Now}
+}
+
+func (f *fileMetadataCache) Tables() ([]string, bool) {
+	return f.fresh(f.read().Tables)
+}
+
+func (f *fileMetadataCache) SaveTables(tables []string) error {
+	return f.update(func(doc *document) {
+		doc.Tables = &entry{Names: tables, Fetched: f.now()}
+	})
+}
+
+func (f *fileMetadataCache) Families(table string) ([]string, bool) {
+	return f.fresh(f.read().Families[table])
+}
+
+func (f *fileMetadataCache) SaveFamilies(table string, families []string) error {
+	return f.update(func(doc *document) {
+		if doc.Families == nil {
+			doc.Families = map[string]*entry{}
+		}
+		doc.Families[table] = &entry{Names: families, Fetched: f.now()}
+	})
+}
+
+func (f *fileMetadataCache) Clear() error {
+	return f.update(func(doc *document) {
+		*doc = document{}
+	})
+}
+
+func (f *fileMetadataCache) fresh(e *entry) ([]string, bool) {
+	if e == nil || f.now().Sub(e.Fetched) > f.ttl {
+		return nil, false
+	}
+	return e.Names, true
+}
+
+// read returns the document, a missing or broken file is an empty document
+func (f *fileMetadataCache) read() *document {
+	doc := &document{}
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return doc
+	}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return &document{}
+	}
+	return doc
+}
+
+// update modifies the document under the lock, the entries saved by the other sessions are kept
+func (f *fileMetadataCache) update(fn func(doc *document)) error {
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	unlock, err := f.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	doc := f.read()
+	fn(doc)
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(dir, filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// lock creates the lock file exclusively, the stale lock of a crashed session is taken over
+func (f *fileMetadataCache) lock() (func(), error) {
+	path := f.path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("cache %s is locked by another session", f.path)
+		}
+		time.Sleep(lockRetry)
+	}
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMetadataCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &fileMetadataCache{path: filepath.Join(dir, "p", "i.json"), ttl: time.Minute, now: func() time.Time { return now }}
+
+	_, ok := c.Tables()
+	assert.False(t, ok)
+	assert.NoError(t, c.SaveTables([]string{"t1", "t2"}))
+	assert.NoError(t, c.SaveFamilies("t1", []string{"d", "m"}))
+
+	// another session reads the same file
+	other := &fileMetadataCache{path: c.path, ttl: time.Minute, now: c.now}
+	tables, ok := other.Tables()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"t1", "t2"}, tables)
+	families, ok := other.Families("t1")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"d", "m"}, families)
+	_, ok = other.Families("t2")
+	assert.False(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = other.Tables()
+	assert.False(t, ok)
+
+	now = now.Add(-2 * time.Minute)
+	assert.NoError(t, other.Clear())
+	_, ok = c.Tables()
+	assert.False(t, ok)
+}
+
+func TestFileMetadataCacheConcurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "i.json")
+
+	tables := []string{"t0", "t1", "t2", "t3", "t4"}
+	var wg sync.WaitGroup
+	for _, table := range tables {
+		wg.Add(1)
+		go func(table string) {
+			defer wg.Done()
+			c := NewFileMetadataCache(path, time.Minute)
+			assert.NoError(t, c.SaveFamilies(table, []string{table + "-d"}))
+		}(table)
+	}
+	wg.Wait()
+
+	// no session loses the families saved by the others
+	c := NewFileMetadataCache(path, time.Minute)
+	var saved []string
+	for _, table := range tables {
+		families, ok := c.Families(table)
+		if assert.True(t, ok, table) {
+			saved = append(saved, families...)
+		}
+	}
+	sort.Strings(saved)
+	assert.Equal(t, []string{"t0-d", "t1-d", "t2-d", "t3-d", "t4-d"}, saved)
+	_, err = os.Stat(path + ".lock")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFileMetadataCacheStaleLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "i.json")
+
+	// the lock left by a crashed session
+	assert.NoError(t, ioutil.WriteFile(path+".lock", nil, 0600))
+	old := time.Now().Add(-time.Minute)
+	assert.NoError(t, os.Chtimes(path+".lock", old, old))
+
+	c := NewFileMetadataCache(path, time.Minute)
+	assert.NoError(t, c.SaveTables([]string{"t1"}))
+	tables, ok := c.Tables()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"t1"}, tables)
+}
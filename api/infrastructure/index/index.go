@@ -0,0 +1,151 @@
+package index
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+// fileExt is the extension of the index files
+const fileExt = ".idx"
+
+// maxKeySize is the size limit of the row keys of Bigtable, the larger sizes in the file are broken
+const maxKeySize = 4 << 10
+
+// fileKeyIndex stores the sorted keys of each table in the gzipped file,
+// a key is encoded as the length of the prefix shared with the previous key and the rest of the key
+type fileKeyIndex struct {
+	dir string
+}
+
+// NewFileKeyIndex returns the KeyIndex storing the files in the directory
+func NewFileKeyIndex(dir string) repository.KeyIndex {
+	return &fileKeyIndex{dir: dir}
+}
+
+func (f *fileKeyIndex) path(table string) string {
+	return filepath.Join(f.dir, table+fileExt)
+}
+
+func (f *fileKeyIndex) Save(table string, keys []string) error {
+	if err := os.MkdirAll(f.dir, 0700); err != nil {
+		return err
+	}
+	sorted := make([]string, len(keys))
+	copy(sorted, keys)
+	sort.Strings(sorted)
+
+	// write to the temporary file to keep the old index on failure
+	tmp, err := ioutil.TempFile(f.dir, table+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeKeys(tmp, sorted); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(table))
+}
+
+func writeKeys(w io.Writer, keys []string) error {
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+	buf := make([]byte, binary.MaxVarintLen64)
+
+	prev := ""
+	for _, k := range keys {
+		shared := 0
+		for shared < len(prev) && shared < len(k) && prev[shared] == k[shared] {
+			shared++
+		}
+		n := binary.PutUvarint(buf, uint64(shared))
+		bw.Write(buf[:n])
+		n = binary.PutUvarint(buf, uint64(len(k)-shared))
+		bw.Write(buf[:n])
+		bw.WriteString(k[shared:])
+		prev = k
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (f *fileKeyIndex) Tables() ([]string, error) {
+	files, err := ioutil.ReadDir(f.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	tables := []string{}
+	for _, file := range files {
+		if name := file.Name(); strings.HasSuffix(name, fileExt) {
+			tables = append(tables, strings.TrimSuffix(name, fileExt))
+		}
+	}
+	return tables, nil
+}
+
+func (f *fileKeyIndex) Scan(table, prefix string, fn func(key string) bool) error {
+	file, err := os.Open(f.path(table))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no index of %s, run \"index build %s\" first", table, table)
+		}
+		return err
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("broken index of %s: %v", table, err)
+	}
+	br := bufio.NewReader(zr)
+
+	var key []byte
+	for {
+		shared, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("broken index of %s: %v", table, err)
+		}
+		size, err := binary.ReadUvarint(br)
+		if err != nil || shared > uint64(len(key)) || size > maxKeySize-shared {
+			return fmt.Errorf("broken index of %s", table)
+		}
+		key = append(key[:shared], make([]byte, size)...)
+		if _, err := io.ReadFull(br, key[shared:]); err != nil {
+			return fmt.Errorf("broken index of %s: %v", table, err)
+		}
+
+		k := string(key)
+		if k < prefix {
+			continue
+		}
+		// the keys are sorted, no more keys with the prefix
+		if !strings.HasPrefix(k, prefix) {
+			return nil
+		}
+		if !fn(k) {
+			return nil
+		}
+	}
+}
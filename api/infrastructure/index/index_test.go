@@ -0,0 +1,62 @@
+package index
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileKeyIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "index")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	idx := NewFileKeyIndex(dir)
+	assert.NoError(t, idx.Save("users", []string{"user#2", "user#10", "admin", "user#1", "\x00\xff"}))
+
+	tables, err := idx.Tables()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"users"}, tables)
+
+	cases := []struct {
+		prefix string
+		expect []string
+	}{
+		{"", []string{"\x00\xff", "admin", "user#1", "user#10", "user#2"}},
+		{"user#1", []string{"user#1", "user#10"}},
+		{"b", nil},
+	}
+	for _, c := range cases {
+		var keys []string
+		err := idx.Scan("users", c.prefix, func(key string) bool {
+			keys = append(keys, key)
+			return true
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, c.expect, keys)
+	}
+
+	assert.Error(t, idx.Scan("unknown", "", func(string) bool { return true }))
+}
+
+func TestFileKeyIndexBroken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "index")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// a key of 1TiB isn't allocated
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte{0x00, 0x80, 0x80, 0x80, 0x80, 0x80, 0x20})
+	zw.Close()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "users"+fileExt), buf.Bytes(), 0600))
+
+	idx := NewFileKeyIndex(dir)
+	err = idx.Scan("users", "", func(string) bool { return true })
+	assert.EqualError(t, err, "broken index of users")
+}
@@ -0,0 +1,417 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/takashabe/btcli/api/domain"
+)
+
+func doBackupPolicy(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 2 {
+		e.failf(e.msg("Invalid args: %v\n"), "backuppolicy <table>")
+		return
+	}
+	table := args[1]
+	info, err := e.tableInteractor.GetTableInfo(ctx, table)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	printBackupPolicy(e, info.BackupPolicy)
+}
+
+func printBackupPolicy(e *Executor, p *domain.BackupPolicy) {
+	if p == nil {
+		fmt.Fprintln(e.outStream, "automated backup: disabled")
+		return
+	}
+	fmt.Fprintln(e.outStream, "automated backup: enabled")
+	fmt.Fprintf(e.outStream, "  retention: %s\n", p.Retention)
+	fmt.Fprintf(e.outStream, "  frequency: %s\n", p.Frequency)
+}
+
+func doDescribe(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 2 {
+		e.failf(e.msg("Invalid args: %v\n"), "describe <table>")
+		return
+	}
+	table := args[1]
+	info, err := e.tableInteractor.GetTableInfo(ctx, table)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+
+	fmt.Fprintf(e.outStream, "table: %s\n", info.Name)
+	fmt.Fprintln(e.outStream, "families:")
+	for _, f := range info.Families {
+		policy := f.GCPolicy
+		if policy == "" {
+			policy = "<never>"
+		}
+		fmt.Fprintf(e.outStream, "  %s: %s\n", f.Name, policy)
+	}
+	if info.DeletionProtection {
+		fmt.Fprintln(e.outStream, "deletion protection: enabled")
+	} else {
+		fmt.Fprintln(e.outStream, "deletion protection: disabled")
+	}
+	printChangeStream(e, info.ChangeStreamRetention)
+	printBackupPolicy(e, info.BackupPolicy)
+}
+
+func printChangeStream(e *Executor, retention time.Duration) {
+	if retention == 0 {
+		fmt.Fprintln(e.outStream, "change stream: disabled")
+		return
+	}
+	fmt.Fprintln(e.outStream, "change stream: enabled")
+	fmt.Fprintf(e.outStream, "  retention: %s\n", retention)
+}
+
+func doSetChangeStream(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "setchangestream <table> [retention=<duration>] [disable=true]")
+		return
+	}
+	table := args[1]
+
+	var retention time.Duration
+	disable := false
+	for _, arg := range args[2:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "retention":
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				e.failf("Invalid retention: %v\n", err)
+				return
+			}
+			if d <= 0 {
+				e.failf("Invalid retention: must be positive: %v\n", v)
+				return
+			}
+			retention = d
+		case "disable":
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				e.failf("Invalid disable: %v\n", err)
+				return
+			}
+			disable = b
+		}
+	}
+	if disable {
+		retention = 0
+	} else if retention == 0 {
+		e.failf(e.msg("Invalid args: %v\n"), "missing retention=<duration>")
+		return
+	}
+
+	if err := e.tableInteractor.SetChangeStream(ctx, table, retention); err != nil {
+		e.printError(err)
+		return
+	}
+	info, err := e.tableInteractor.GetTableInfo(ctx, table)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	printChangeStream(e, info.ChangeStreamRetention)
+}
+
+func doSetProtection(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "setprotection <table> enabled=<bool>")
+		return
+	}
+	table := args[1]
+
+	var protected *bool
+	for _, arg := range args[2:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "enabled":
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				e.failf("Invalid enabled: %v\n", err)
+				return
+			}
+			protected = &b
+		}
+	}
+	if protected == nil {
+		e.failf(e.msg("Invalid args: %v\n"), "missing enabled=<bool>")
+		return
+	}
+
+	if err := e.tableInteractor.SetDeletionProtection(ctx, table, *protected); err != nil {
+		e.printError(err)
+		return
+	}
+	if *protected {
+		fmt.Fprintf(e.errStream, "Enabled deletion protection of %s\n", table)
+	} else {
+		fmt.Fprintf(e.errStream, "Disabled deletion protection of %s\n", table)
+	}
+}
+
+func doCreateTable(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 2 {
+		e.failf(e.msg("Invalid args: %v\n"), "createtable <table> [families=<family>[:<policy>],...] [splits=<row>,...] [splits-file=<file>]")
+		return
+	}
+	table := args[1]
+
+	var families []*domain.Family
+	var splits []string
+	for _, arg := range args[2:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "families":
+			for _, spec := range strings.Split(v, ",") {
+				f, err := parseFamilySpec(spec)
+				if err != nil {
+					e.failf("Invalid families: %v\n", err)
+					return
+				}
+				families = append(families, f)
+			}
+		case "splits":
+			splits = append(splits, strings.Split(v, ",")...)
+		case "splits-file":
+			// the lines or the comma separated line of suggest-splits
+			lines, err := loadKeysFile(v)
+			if err != nil {
+				e.failf("Invalid splits-file: %v\n", err)
+				return
+			}
+			for _, l := range lines {
+				splits = append(splits, strings.Split(l, ",")...)
+			}
+		}
+	}
+	splits, err := normalizeSplits(splits)
+	if err != nil {
+		e.failf("Invalid splits: %v\n", err)
+		return
+	}
+
+	if err := e.tableInteractor.CreateTable(ctx, table, families, splits); err != nil {
+		e.printError(err)
+		return
+	}
+	e.clearMetadata()
+	fmt.Fprintf(e.errStream, "Created table %s with %s families and %s split points\n",
+		table, e.formatNumber(int64(len(families))), e.formatNumber(int64(len(splits))))
+}
+
+// parseFamilySpec parses "<family>[:<policy>]", the policy is "maxversions=<n>", "maxage=<duration>"
+// or both joined by "|" to collect the cells by either of them
+func parseFamilySpec(spec string) (*domain.Family, error) {
+	name, policy := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		name, policy = spec[:i], spec[i+1:]
+	}
+	if name == "" {
+		return nil, fmt.Errorf("empty family in %q", spec)
+	}
+	return parseFamilyPolicy(name, policy)
+}
+
+// parseFamilyPolicy returns the family of the name with the policy of parseFamilySpec
+func parseFamilyPolicy(name, policy string) (*domain.Family, error) {
+	f := &domain.Family{Name: name}
+	if policy == "" {
+		return f, nil
+	}
+	for _, p := range strings.Split(policy, "|") {
+		i := strings.Index(p, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid policy %q of %s", p, name)
+		}
+		k, v := p[:i], p[i+1:]
+		switch k {
+		case "maxversions":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid maxversions %q of %s", v, name)
+			}
+			f.MaxVersions = n
+		case "maxage":
+			d, err := parseAge(v)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid maxage %q of %s", v, name)
+			}
+			f.MaxAge = d
+		default:
+			return nil, fmt.Errorf("unknown policy %q of %s, must be maxversions or maxage", k, name)
+		}
+	}
+	return f, nil
+}
+
+// parseAge parses the duration accepting the days suffix "d" in addition to time.ParseDuration
+func parseAge(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// normalizeSplits returns the sorted distinct split points
+func normalizeSplits(splits []string) ([]string, error) {
+	seen := map[string]bool{}
+	ret := []string{}
+	for _, s := range splits {
+		if s == "" {
+			return nil, fmt.Errorf("empty split point")
+		}
+		if !seen[s] {
+			seen[s] = true
+			ret = append(ret, s)
+		}
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+func doDeleteTable(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 2 {
+		e.failf(e.msg("Invalid args: %v\n"), "deletetable <table>")
+		return
+	}
+	table := args[1]
+	if err := e.tableInteractor.DeleteTable(ctx, table); err != nil {
+		e.printError(err)
+		return
+	}
+	e.clearMetadata()
+	fmt.Fprintf(e.errStream, "Deleted table %s\n", table)
+}
+
+func doSetBackupPolicy(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "setbackuppolicy <table> [retention=<duration>] [frequency=<duration>] [disable=true]")
+		return
+	}
+	table := args[1]
+
+	policy := &domain.BackupPolicy{}
+	disable := false
+	for _, arg := range args[2:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "retention", "frequency":
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				e.failf("Invalid %s: %v\n", k, err)
+				return
+			}
+			if k == "retention" {
+				policy.Retention = d
+			} else {
+				policy.Frequency = d
+			}
+		case "disable":
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				e.failf("Invalid disable: %v\n", err)
+				return
+			}
+			disable = b
+		}
+	}
+	if disable {
+		policy = nil
+	}
+
+	if err := e.tableInteractor.SetBackupPolicy(ctx, table, policy); err != nil {
+		e.printError(err)
+		return
+	}
+	doBackupPolicy(ctx, e, "backuppolicy", table)
+}
+
+func doClone(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "clone <src> <dst> [schema-only=true]")
+		return
+	}
+	src, dst := args[1], args[2]
+
+	schemaOnly := false
+	for _, arg := range args[3:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "schema-only":
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				e.failf("Invalid schema-only: %v\n", err)
+				return
+			}
+			schemaOnly = b
+		}
+	}
+
+	cnt, err := e.tableInteractor.CloneTable(ctx, src, dst, schemaOnly)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	e.clearMetadata()
+	if schemaOnly {
+		fmt.Fprintf(e.errStream, "Created %s with the schema of %s\n", dst, src)
+		return
+	}
+	fmt.Fprintf(e.errStream, "Cloned %s to %s, copied %s rows\n", src, dst, e.formatNumber(int64(cnt)))
+}
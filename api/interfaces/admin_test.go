@@ -0,0 +1,201 @@
+package interfaces
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoAdminExecutor(t *testing.T) {
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"backuppolicy table",
+			"automated backup: disabled\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "table").Return(&domain.TableInfo{Name: "table"}, nil)
+			},
+		},
+		{
+			"setbackuppolicy table retention=72h frequency=24h",
+			"automated backup: enabled\n  retention: 72h0m0s\n  frequency: 24h0m0s\n",
+			func(mock *repository.MockBigtable) {
+				policy := &domain.BackupPolicy{Retention: 72 * time.Hour, Frequency: 24 * time.Hour}
+				mock.EXPECT().SetBackupPolicy(gomock.Any(), "table", policy).Return(nil)
+				mock.EXPECT().TableInfo(gomock.Any(), "table").Return(&domain.TableInfo{Name: "table", BackupPolicy: policy}, nil)
+			},
+		},
+		{
+			"setbackuppolicy table disable=true",
+			"automated backup: disabled\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().SetBackupPolicy(gomock.Any(), "table", (*domain.BackupPolicy)(nil)).Return(nil)
+				mock.EXPECT().TableInfo(gomock.Any(), "table").Return(&domain.TableInfo{Name: "table"}, nil)
+			},
+		},
+		{
+			"describe table",
+			"table: table\nfamilies:\n  d: versions() > 1\n  e: <never>\ndeletion protection: enabled\nchange stream: disabled\nautomated backup: disabled\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "table").Return(&domain.TableInfo{
+					Name: "table",
+					Families: []*domain.Family{
+						{Name: "d", GCPolicy: "versions() > 1"},
+						{Name: "e"},
+					},
+					DeletionProtection: true,
+				}, nil)
+			},
+		},
+		{
+			"setprotection table enabled=true",
+			"Enabled deletion protection of table\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().SetDeletionProtection(gomock.Any(), "table", true).Return(nil)
+			},
+		},
+		{
+			"setchangestream table retention=72h",
+			"change stream: enabled\n  retention: 72h0m0s\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().SetChangeStream(gomock.Any(), "table", 72*time.Hour).Return(nil)
+				mock.EXPECT().TableInfo(gomock.Any(), "table").Return(&domain.TableInfo{Name: "table", ChangeStreamRetention: 72 * time.Hour}, nil)
+			},
+		},
+		{
+			"setchangestream table disable=true",
+			"change stream: disabled\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().SetChangeStream(gomock.Any(), "table", time.Duration(0)).Return(nil)
+				mock.EXPECT().TableInfo(gomock.Any(), "table").Return(&domain.TableInfo{Name: "table"}, nil)
+			},
+		},
+		{
+			"deletetable table",
+			"Deleted table table\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "table").Return(&domain.TableInfo{Name: "table"}, nil)
+				mock.EXPECT().DeleteTable(gomock.Any(), "table").Return(nil)
+			},
+		},
+		{
+			"deletetable table",
+			"table table is protected from deletion, disable it by \"setprotection table enabled=false\"\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "table").Return(&domain.TableInfo{Name: "table", DeletionProtection: true}, nil)
+			},
+		},
+		{
+			"clone a b",
+			"Cloned a to b, copied 2 rows\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().CreateTableLike(gomock.Any(), "a", "b").Return(nil)
+				mock.EXPECT().CopyRows(gomock.Any(), "a", "b").Return(2, nil)
+			},
+		},
+		{
+			"clone a b schema-only=true",
+			"Created b with the schema of a\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().CreateTableLike(gomock.Any(), "a", "b").Return(nil)
+			},
+		},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		defer ctrl.Finish()
+
+		c.prepare(mockBtRepo)
+
+		var buf bytes.Buffer
+		executor := Executor{
+			outStream:       &buf,
+			errStream:       &buf,
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+		}
+
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, buf.String())
+	}
+}
+
+func TestDoCreateTable(t *testing.T) {
+	f, err := ioutil.TempFile("", "splits")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString("m,c\nx\n")
+	f.Close()
+
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"createtable table families=d:maxversions=1,m:maxage=30d|maxversions=3,e splits=b,a",
+			"Created table table with 3 families and 2 split points\n",
+			func(mock *repository.MockBigtable) {
+				families := []*domain.Family{
+					{Name: "d", MaxVersions: 1},
+					{Name: "m", MaxVersions: 3, MaxAge: 30 * 24 * time.Hour},
+					{Name: "e"},
+				}
+				mock.EXPECT().CreateTable(gomock.Any(), "table", families, []string{"a", "b"}).Return(nil)
+			},
+		},
+		{
+			"createtable table splits=m splits-file=" + f.Name(),
+			"Created table table with 0 families and 3 split points\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().CreateTable(gomock.Any(), "table", ([]*domain.Family)(nil), []string{"c", "m", "x"}).Return(nil)
+			},
+		},
+		{
+			"createtable table families=d:maxage=1w",
+			"Invalid families: invalid maxage \"1w\" of d\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"createtable table families=d:ttl=1h",
+			"Invalid families: unknown policy \"ttl\" of d, must be maxversions or maxage\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"createtable table families=:maxversions=1",
+			"Invalid families: empty family in \":maxversions=1\"\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"createtable table splits=a,,b",
+			"Invalid splits: empty split point\n",
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var buf bytes.Buffer
+		executor := Executor{
+			outStream:       &buf,
+			errStream:       &buf,
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, buf.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
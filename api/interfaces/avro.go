@@ -0,0 +1,390 @@
+package interfaces
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// decodeTypeAvroPrefix decodes the value as the Avro record of the schema file following the prefix
+const decodeTypeAvroPrefix = "avro:"
+
+// avroSingleObjectMagic is the header of the Avro single object encoding followed by the fingerprint
+var avroSingleObjectMagic = []byte{0xc3, 0x01}
+
+// avroSchema is a parsed schema of the Avro binary encoding
+// https://avro.apache.org/docs/current/specification/
+type avroSchema struct {
+	// typ is a primitive type, record, enum, array, map, fixed or union
+	typ     string
+	fields  []*avroField
+	symbols []string
+	// items are the items of an array or the values of a map
+	items *avroSchema
+	size  int
+	union []*avroSchema
+}
+
+type avroField struct {
+	name   string
+	schema *avroSchema
+}
+
+// loadAvroSchema reads the schema in the JSON file, e.g. an .avsc file
+func loadAvroSchema(file string) (*avroSchema, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s isn't a schema: %v", file, err)
+	}
+	return parseAvroSchema(doc, "", map[string]*avroSchema{})
+}
+
+// parseAvroSchema parses the schema, the named types are registered to the names for the references
+func parseAvroSchema(doc interface{}, namespace string, names map[string]*avroSchema) (*avroSchema, error) {
+	switch d := doc.(type) {
+	case string:
+		switch d {
+		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			return &avroSchema{typ: d}, nil
+		}
+		if s, ok := names[avroFullName(d, namespace)]; ok {
+			return s, nil
+		}
+		if s, ok := names[d]; ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("unknown type %q", d)
+	case []interface{}:
+		s := &avroSchema{typ: "union"}
+		for _, t := range d {
+			u, err := parseAvroSchema(t, namespace, names)
+			if err != nil {
+				return nil, err
+			}
+			s.union = append(s.union, u)
+		}
+		return s, nil
+	case map[string]interface{}:
+		return parseAvroComplex(d, namespace, names)
+	}
+	return nil, fmt.Errorf("invalid schema %v", doc)
+}
+
+func parseAvroComplex(d map[string]interface{}, namespace string, names map[string]*avroSchema) (*avroSchema, error) {
+	typ, ok := d["type"].(string)
+	if !ok {
+		// e.g. {"type": {"type": "array", ...}}
+		return parseAvroSchema(d["type"], namespace, names)
+	}
+	// register the named types before their fields for the recursive references
+	name, _ := d["name"].(string)
+	if ns, ok := d["namespace"].(string); ok {
+		namespace = ns
+	}
+	s := &avroSchema{typ: typ}
+	switch typ {
+	case "record", "error", "enum", "fixed":
+		if name == "" {
+			return nil, fmt.Errorf("%s has no name", typ)
+		}
+		full := avroFullName(name, namespace)
+		if i := strings.LastIndex(full, "."); i >= 0 {
+			namespace = full[:i]
+		}
+		names[full] = s
+	}
+
+	switch typ {
+	case "record", "error":
+		s.typ = "record"
+		fields, _ := d["fields"].([]interface{})
+		for _, f := range fields {
+			fm, ok := f.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid field of %s", name)
+			}
+			fname, _ := fm["name"].(string)
+			fs, err := parseAvroSchema(fm["type"], namespace, names)
+			if err != nil {
+				return nil, fmt.Errorf("field %s.%s: %v", name, fname, err)
+			}
+			s.fields = append(s.fields, &avroField{name: fname, schema: fs})
+		}
+	case "enum":
+		symbols, _ := d["symbols"].([]interface{})
+		for _, sym := range symbols {
+			str, _ := sym.(string)
+			s.symbols = append(s.symbols, str)
+		}
+	case "fixed":
+		size, ok := d["size"].(float64)
+		if !ok || size < 0 {
+			return nil, fmt.Errorf("fixed %s has no size", name)
+		}
+		s.size = int(size)
+	case "array", "map":
+		key := "items"
+		if typ == "map" {
+			key = "values"
+		}
+		items, err := parseAvroSchema(d[key], namespace, names)
+		if err != nil {
+			return nil, err
+		}
+		s.items = items
+	default:
+		// a primitive type with the attributes, e.g. the logical types
+		return parseAvroSchema(typ, namespace, names)
+	}
+	return s, nil
+}
+
+func avroFullName(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// decodeAvro returns the value decoded by the schema in the JSON, the unions are rendered as their values
+func decodeAvro(s *avroSchema, v []byte) ([]byte, error) {
+	if bytes.HasPrefix(v, avroSingleObjectMagic) && len(v) >= 10 {
+		v = v[10:]
+	}
+	d := &avroDecoder{data: v}
+	var buf bytes.Buffer
+	if err := d.decode(&buf, s); err != nil {
+		return nil, err
+	}
+	if rest := len(d.data) - d.pos; rest > 0 {
+		return nil, fmt.Errorf("%d trailing bytes", rest)
+	}
+	return buf.Bytes(), nil
+}
+
+var errAvroShort = errors.New("unexpected end of the value")
+
+// avroDecoder reads the Avro binary encoding
+type avroDecoder struct {
+	data []byte
+	pos  int
+}
+
+// long reads the zig-zag varint of the int and the long
+func (d *avroDecoder) long() (int64, error) {
+	n, size := binary.Varint(d.data[d.pos:])
+	if size <= 0 {
+		return 0, errAvroShort
+	}
+	d.pos += size
+	return n, nil
+}
+
+func (d *avroDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errAvroShort
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// bytes reads the length prefixed bytes of the bytes and the string
+func (d *avroDecoder) bytes() ([]byte, error) {
+	n, err := d.long()
+	if err != nil {
+		return nil, err
+	}
+	return d.next(int(n))
+}
+
+// blocks reads the blocks of the array and the map, each item is read by f
+func (d *avroDecoder) blocks(f func(i int) error) error {
+	i := 0
+	for {
+		n, err := d.long()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if n < 0 {
+			// a negative count is followed by the size of the block
+			n = -n
+			if _, err := d.long(); err != nil {
+				return err
+			}
+		}
+		// every item has a byte at least
+		if n > int64(len(d.data)-d.pos) {
+			return errAvroShort
+		}
+		for ; n > 0; n-- {
+			if err := f(i); err != nil {
+				return err
+			}
+			i++
+		}
+	}
+}
+
+func (d *avroDecoder) decode(buf *bytes.Buffer, s *avroSchema) error {
+	switch s.typ {
+	case "null":
+		buf.WriteString("null")
+	case "boolean":
+		b, err := d.next(1)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(strconv.FormatBool(b[0] != 0))
+	case "int", "long":
+		n, err := d.long()
+		if err != nil {
+			return err
+		}
+		buf.WriteString(strconv.FormatInt(n, 10))
+	case "float":
+		b, err := d.next(4)
+		if err != nil {
+			return err
+		}
+		writeJSONFloat(buf, float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), 32)
+	case "double":
+		b, err := d.next(8)
+		if err != nil {
+			return err
+		}
+		writeJSONFloat(buf, math.Float64frombits(binary.LittleEndian.Uint64(b)), 64)
+	case "bytes", "string":
+		b, err := d.bytes()
+		if err != nil {
+			return err
+		}
+		writeAvroString(buf, s.typ, b)
+	case "fixed":
+		b, err := d.next(s.size)
+		if err != nil {
+			return err
+		}
+		writeAvroString(buf, s.typ, b)
+	case "enum":
+		n, err := d.long()
+		if err != nil {
+			return err
+		}
+		if n < 0 || n >= int64(len(s.symbols)) {
+			return fmt.Errorf("invalid enum index %d", n)
+		}
+		writeJSONString(buf, s.symbols[n])
+	case "union":
+		n, err := d.long()
+		if err != nil {
+			return err
+		}
+		if n < 0 || n >= int64(len(s.union)) {
+			return fmt.Errorf("invalid union index %d", n)
+		}
+		return d.decode(buf, s.union[n])
+	case "record":
+		buf.WriteByte('{')
+		for i, f := range s.fields {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, f.name)
+			buf.WriteByte(':')
+			if err := d.decode(buf, f.schema); err != nil {
+				return fmt.Errorf("%s: %v", f.name, err)
+			}
+		}
+		buf.WriteByte('}')
+	case "array":
+		buf.WriteByte('[')
+		err := d.blocks(func(i int) error {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			return d.decode(buf, s.items)
+		})
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(']')
+	case "map":
+		buf.WriteByte('{')
+		err := d.blocks(func(i int) error {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			k, err := d.bytes()
+			if err != nil {
+				return err
+			}
+			writeJSONString(buf, string(k))
+			buf.WriteByte(':')
+			return d.decode(buf, s.items)
+		})
+		if err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unknown type %q", s.typ)
+	}
+	return nil
+}
+
+// writeAvroString writes the string, the bytes are written as the code points 0-255 like the Avro JSON encoding
+func writeAvroString(buf *bytes.Buffer, typ string, b []byte) {
+	if typ == "string" {
+		writeJSONString(buf, string(b))
+		return
+	}
+	rs := make([]rune, len(b))
+	for i, c := range b {
+		rs[i] = rune(c)
+	}
+	writeJSONString(buf, string(rs))
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	data, _ := json.Marshal(s)
+	buf.Write(data)
+}
+
+// writeJSONFloat writes the float, NaN and Inf aren't JSON numbers and written as the strings
+func writeJSONFloat(buf *bytes.Buffer, f float64, bitSize int) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		writeJSONString(buf, strconv.FormatFloat(f, 'g', -1, bitSize))
+		return
+	}
+	buf.WriteString(strconv.FormatFloat(f, 'g', -1, bitSize))
+}
+
+// avroValue returns the value decoded by the schema of the file, the schemas are loaded once by a printer
+func (w *Printer) avroValue(file string, v []byte) ([]byte, error) {
+	s, ok := w.avroSchemas[file]
+	if !ok {
+		var err error
+		if s, err = loadAvroSchema(file); err != nil {
+			return nil, err
+		}
+		if w.avroSchemas == nil {
+			w.avroSchemas = map[string]*avroSchema{}
+		}
+		w.avroSchemas[file] = s
+	}
+	return decodeAvro(s, v)
+}
@@ -0,0 +1,182 @@
+package interfaces
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/config"
+	"github.com/takashabe/btcli/api/domain"
+)
+
+const testAvroSchema = `{
+  "type": "record",
+  "name": "Event",
+  "namespace": "example",
+  "fields": [
+    {"name": "user", "type": "string"},
+    {"name": "count", "type": "long"},
+    {"name": "score", "type": "double"},
+    {"name": "ok", "type": "boolean"},
+    {"name": "note", "type": ["null", "string"]},
+    {"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["CLICK", "VIEW"]}},
+    {"name": "tags", "type": {"type": "array", "items": "int"}},
+    {"name": "attrs", "type": {"type": "map", "values": "long"}},
+    {"name": "id", "type": {"type": "fixed", "name": "Id", "size": 2}},
+    {"name": "next", "type": ["null", "Event"]}
+  ]
+}`
+
+func writeAvroSchema(t *testing.T, dir string) string {
+	file := filepath.Join(dir, "event.avsc")
+	if err := ioutil.WriteFile(file, []byte(testAvroSchema), 0600); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	return file
+}
+
+// avroEvent returns the encoded example.Event without the next event
+func avroEvent(user string, count int64) []byte {
+	var b []byte
+	long := func(n int64) {
+		buf := make([]byte, binary.MaxVarintLen64)
+		b = append(b, buf[:binary.PutVarint(buf, n)]...)
+	}
+	long(int64(len(user)))
+	b = append(b, user...)
+	long(count)
+	f := make([]byte, 8)
+	binary.LittleEndian.PutUint64(f, math.Float64bits(1.5))
+	b = append(b, f...)
+	b = append(b, 1)
+	// note: the string of the union
+	long(1)
+	long(2)
+	b = append(b, "hi"...)
+	// kind: VIEW
+	long(1)
+	// tags: a block of 2 items
+	long(2)
+	long(3)
+	long(-4)
+	long(0)
+	// attrs: a block of 1 item with the size
+	long(-1)
+	long(3)
+	long(1)
+	b = append(b, "a"...)
+	long(5)
+	long(0)
+	// id
+	b = append(b, 0xff, 0x01)
+	// next: null
+	long(0)
+	return b
+}
+
+func TestDecodeAvro(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	schema := writeAvroSchema(t, dir)
+	event := `{"user":"madoka","count":2,"score":1.5,"ok":true,"note":"hi","kind":"VIEW","tags":[3,-4],"attrs":{"a":5},"id":"ÿ\u0001","next":null}`
+
+	cases := []struct {
+		decode string
+		input  []byte
+		expect string
+	}{
+		{
+			"avro:" + schema,
+			avroEvent("madoka", 2),
+			event,
+		},
+		{
+			// the single object encoding
+			"avro:" + schema,
+			append([]byte{0xc3, 0x01, 1, 2, 3, 4, 5, 6, 7, 8}, avroEvent("madoka", 2)...),
+			event,
+		},
+		{
+			"avro:" + schema,
+			avroEvent("madoka", 2)[:7],
+			"<avro: count: unexpected end of the value>",
+		},
+		{
+			"avro:" + schema,
+			append(avroEvent("madoka", 2), 0),
+			"<avro: 1 trailing bytes>",
+		},
+		{
+			"avro:" + filepath.Join(dir, "none.avsc"),
+			avroEvent("madoka", 2),
+			"<avro: open " + filepath.Join(dir, "none.avsc") + ": no such file or directory>",
+		},
+	}
+	for i, c := range cases {
+		w := &Printer{decodeType: c.decode}
+		assert.Equal(t, c.expect, w.formatValue("d:event", c.input), "#%d", i)
+	}
+}
+
+func TestDecodeAvroRecursive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	schema, err := loadAvroSchema(writeAvroSchema(t, dir))
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	// replace the null next with the nested event
+	v := avroEvent("a", 1)
+	v = append(v[:len(v)-1], 2)
+	v = append(v, avroEvent("b", 2)...)
+	data, err := decodeAvro(schema, v)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	assert.Contains(t, string(data), `"next":{"user":"b","count":2,`)
+}
+
+func TestAvroFormats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	schema := writeAvroSchema(t, dir)
+
+	var buf bytes.Buffer
+	w := &Printer{
+		outStream:  &buf,
+		formatter:  rowFormatters["json"],
+		decodeType: "avro:" + schema,
+	}
+	w.printRow(&domain.Row{
+		Key: "1",
+		Columns: []*domain.Column{
+			{Family: "d", Qualifier: "d:event", Value: avroEvent("madoka", 2), Version: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	})
+	assert.Contains(t, buf.String(), `"value":{"user":"madoka","count":2,`)
+
+	transforms, err := compileTransforms([]*config.Transform{
+		{Name: "user", Columns: "^d:event$", Pipeline: "avro:" + schema + " | jsonpath:$.user"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	w = &Printer{decodeType: "string", transforms: transforms}
+	assert.Equal(t, `"madoka"`, w.formatValue("d:event", avroEvent("madoka", 2)))
+}
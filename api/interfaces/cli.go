@@ -5,11 +5,19 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
 
 	prompt "github.com/c-bata/go-prompt"
 	"github.com/takashabe/btcli/api/application"
 	"github.com/takashabe/btcli/api/config"
+	"github.com/takashabe/btcli/api/domain/repository"
 	"github.com/takashabe/btcli/api/infrastructure/bigtable"
+	"github.com/takashabe/btcli/api/infrastructure/cache"
+	"github.com/takashabe/btcli/api/infrastructure/index"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
 // exit codes
@@ -20,12 +28,15 @@ const (
 	ExitCodeError = 10 + iota
 	ExitCodeParseError
 	ExitCodeInvalidArgsError
+	ExitCodeSLOViolation
 )
 
 // CLI is the command line interface object
 type CLI struct {
 	OutStream io.Writer
 	ErrStream io.Writer
+	// InStream answers the checkpoints of the scripts, nil reads the stdin
+	InStream io.Reader
 }
 
 // Run invokes the CLI with the given arguments
@@ -35,8 +46,57 @@ func (c *CLI) Run(args []string) int {
 		fmt.Fprintf(c.ErrStream, "args parse error: %v\n", err)
 		return ExitCodeParseError
 	}
+	protoFiles, err := loadProtoFiles(conf.ProtoDescriptors)
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "args parse error: %v\n", err)
+		return ExitCodeParseError
+	}
+	transforms, err := compileTransforms(conf.Transforms, protoFiles)
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "args parse error: %v\n", err)
+		return ExitCodeParseError
+	}
+	var tmpl *template.Template
+	if conf.Template != "" {
+		if tmpl, err = parseRowTemplate(conf.Template); err != nil {
+			fmt.Fprintf(c.ErrStream, "args parse error: invalid template: %v\n", err)
+			return ExitCodeParseError
+		}
+	}
+
+	// the link of a row is looked up in the instance of the link
+	if arg := flag.Arg(0); strings.HasPrefix(arg, linkScheme) {
+		table, key, err := openRowLink(conf, arg)
+		if err != nil {
+			fmt.Fprintf(c.ErrStream, "args parse error: %v\n", err)
+			return ExitCodeParseError
+		}
+		executor, _ := c.prepareExecutor(conf, transforms, protoFiles, tmpl)
+		return executor.lookupLink(table, key)
+	}
+
+	// the daemon runs the command of -e without connecting again
+	if conf.Execute != "" {
+		return c.runCommand(conf, func() *Executor {
+			executor, _ := c.prepareExecutor(conf, transforms, protoFiles, tmpl)
+			return executor
+		})
+	}
 
-	p := c.preparePrompt(conf)
+	executor, completer := c.prepareExecutor(conf, transforms, protoFiles, tmpl)
+	if flag.Arg(0) == "daemon" {
+		return c.runDaemon(executor, conf)
+	}
+	if conf.Script != "" {
+		return c.runScript(executor, conf)
+	}
+
+	// the pager is only for the interactive shell
+	executor.pagerCommand = interactivePager()
+	executor.pagerOn = executor.pagerCommand != ""
+	executor.numberRows = true
+	executor.interactive = isTerminal(os.Stdout)
+	p := c.preparePrompt(executor, completer)
 	p.Run()
 
 	// TODO: This is dead code. Invoke os.Exit by the prompt.Run
@@ -53,6 +113,9 @@ func (c *CLI) loadConfig() (*config.Config, error) {
 		usage(c.OutStream)
 	}
 	flag.Parse()
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
 
 	return conf, nil
 }
@@ -63,26 +126,136 @@ func usage(w io.Writer) {
 	flag.CommandLine.PrintDefaults()
 }
 
-func (c *CLI) preparePrompt(conf *config.Config) *prompt.Prompt {
-	repository, err := bigtable.NewBigtableRepository(conf.Project, conf.Instance)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to initialized bigtable repository:%v", err)
+// runScript executes the script of the config and returns the exit code
+func (c *CLI) runScript(executor *Executor, conf *config.Config) int {
+	in := c.InStream
+	if in == nil {
+		in = os.Stdin
 	}
-	tableInteractor := application.NewTableInteractor(repository)
-	rowsInteractor := application.NewRowsInteractor(repository)
 
-	executor := Executor{
-		outStream:       c.OutStream,
-		errStream:       c.ErrStream,
-		rowsInteractor:  rowsInteractor,
-		tableInteractor: tableInteractor,
+	var audit io.Writer
+	if path := conf.AuditLog; path != "off" {
+		if path == "" {
+			path = filepath.Join(config.HomeDir(), ".btcli", "audit.log")
+		}
+		f, err := openAuditLog(path)
+		if err != nil {
+			fmt.Fprintf(c.ErrStream, "failed to open the audit log: %v\n", err)
+			return ExitCodeError
+		}
+		defer f.Close()
+		audit = f
 	}
-	completer := Completer{
-		tableInteractor: tableInteractor,
+
+	if err := executor.runScript(conf.Script, in, audit); err != nil {
+		fmt.Fprintf(c.ErrStream, "%v\n", err)
+		return ExitCodeError
 	}
+	return ExitCodeOK
+}
 
+// openAuditLog opens the audit log to append the steps
+func openAuditLog(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+}
+
+func (c *CLI) preparePrompt(executor *Executor, completer *Completer) *prompt.Prompt {
 	return prompt.New(
 		executor.Do,
 		completer.Do,
+		prompt.OptionAddKeyBind(executor.keyBindings()...),
 	)
 }
+
+// clientOptions returns the options of the Bigtable clients by the credentials of -creds
+func clientOptions(conf *config.Config) []option.ClientOption {
+	if conf.Creds == "" {
+		return nil
+	}
+	return []option.ClientOption{option.WithCredentialsFile(conf.Creds)}
+}
+
+func (c *CLI) prepareExecutor(conf *config.Config, transforms []*columnTransform, protoFiles *protoregistry.Files, tmpl *template.Template) (*Executor, *Completer) {
+	repository, err := bigtable.NewBigtableRepository(conf.Project, conf.Instance, clientOptions(conf)...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialized bigtable repository:%v", err)
+	}
+	repository = bigtable.NewBreakerRepository(repository)
+	tableInteractor := application.NewTableInteractor(repository)
+	rowsInteractor := application.NewRowsInteractor(repository)
+	connectionInteractor := application.NewConnectionInteractor(repository)
+	locale := conf.Locale
+	if locale == "" {
+		locale = detectLocale()
+	}
+	backupInteractor := application.NewBackupInteractor(repository)
+	keyIndex := index.NewFileKeyIndex(filepath.Join(config.HomeDir(), ".btcli", "index", conf.Project, conf.Instance))
+	indexInteractor := application.NewIndexInteractor(repository, keyIndex)
+	metadataInteractor := application.NewMetadataInteractor(repository, openMetadataCache(conf))
+	presetFile := filepath.Join(config.HomeDir(), ".btcli", "presets.json")
+	presets, err := loadPresets(presetFile)
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to load the presets: %v\n", err)
+		presets = map[string]map[string]string{}
+	}
+	openInstance := func(instance string) (*application.RowsInteractor, error) {
+		r, err := bigtable.NewBigtableRepository(conf.Project, instance, clientOptions(conf)...)
+		if err != nil {
+			return nil, err
+		}
+		return application.NewRowsInteractor(bigtable.NewBreakerRepository(r)), nil
+	}
+
+	executor := &Executor{
+		outStream:            c.OutStream,
+		errStream:            c.ErrStream,
+		project:              conf.Project,
+		instance:             conf.Instance,
+		appProfile:           conf.AppProfile,
+		rowsInteractor:       rowsInteractor,
+		tableInteractor:      tableInteractor,
+		connectionInteractor: connectionInteractor,
+		backupInteractor:     backupInteractor,
+		indexInteractor:      indexInteractor,
+		metadataInteractor:   metadataInteractor,
+		openInstance:         openInstance,
+		idleTimeout:          conf.IdleTimeout,
+		requestTimeout:       conf.RequestTimeout,
+		pageSize:             conf.PageSize,
+		readLimit:            conf.ReadLimit,
+		maxValueBytes:        conf.MaxValueBytes,
+		numberFormat:         conf.NumberFormat,
+		format:               conf.Format,
+		template:             tmpl,
+		transforms:           transforms,
+		protoFiles:           protoFiles,
+		decoders:             conf.Decoders,
+		autoDecode:           conf.AutoDecode,
+		locale:               locale,
+		timestampFormat:      conf.TimestampFormat,
+		utc:                  conf.UTC,
+		verbose:              conf.Verbose,
+		experimental:         conf.EnableExperimental,
+		color:                !conf.NoColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout),
+		presets:              presets,
+		presetFile:           presetFile,
+		exit:                 os.Exit,
+	}
+	completer := &Completer{
+		metadataInteractor: metadataInteractor,
+		locale:             executor.locale,
+	}
+	return executor, completer
+}
+
+// openMetadataCache returns the cache of the completion shared by the sessions of the instance, nil when disabled
+func openMetadataCache(conf *config.Config) repository.MetadataCache {
+	if conf.CompletionCacheTTL <= 0 {
+		return nil
+	}
+	path := filepath.Join(config.HomeDir(), ".btcli", "cache", conf.Project, conf.Instance+".json")
+	return cache.NewFileMetadataCache(path, conf.CompletionCacheTTL)
+}
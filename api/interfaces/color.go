@@ -0,0 +1,134 @@
+package interfaces
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// the SGR codes of the colored text output
+const (
+	colorKey       = "1;33"
+	colorQualifier = "36"
+	colorTimestamp = "90"
+	colorValue     = "32"
+	// colorMatch highlights the portions matched by the regex options
+	colorMatch = "1;4;31"
+
+	colorJSONKey     = "34"
+	colorJSONString  = "32"
+	colorJSONNumber  = "35"
+	colorJSONLiteral = "33"
+)
+
+// isTerminal reports whether the file is a terminal, the colors are disabled for the pipes and the files
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// paint returns the text in the color, as it is unless the printer is colored
+func (w *Printer) paint(code, s string) string {
+	if !w.color || s == "" {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// paintColumn returns the label of the column in the color padded by the spaces to the width of the runes,
+// the escapes aren't counted and qualifier-regex highlights the qualifier without the family and the suffix of the label
+func (w *Printer) paintColumn(column, label string, width int) string {
+	pad := ""
+	if n := width - utf8.RuneCountInString(label); n > 0 {
+		pad = strings.Repeat(" ", n)
+	}
+	if !w.color || w.qualifierMatch == nil || !strings.HasPrefix(label, column) {
+		return w.paint(colorQualifier, label) + pad
+	}
+	family, qualifier := "", column
+	if i := strings.Index(column, ":"); i >= 0 {
+		family, qualifier = column[:i+1], column[i+1:]
+	}
+	return w.paint(colorQualifier, family) + w.paintMatches(colorQualifier, qualifier, w.qualifierMatch) +
+		w.paint(colorQualifier, label[len(column):]) + pad
+}
+
+// paintMatches returns the text in the color with the portions matched by the regex highlighted
+func (w *Printer) paintMatches(code, s string, re *regexp.Regexp) string {
+	if !w.color || re == nil {
+		return w.paint(code, s)
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringIndex(s, -1) {
+		if m[0] == m[1] {
+			continue
+		}
+		b.WriteString(w.paint(code, s[last:m[0]]))
+		b.WriteString(w.paint(colorMatch, s[m[0]:m[1]]))
+		last = m[1]
+	}
+	b.WriteString(w.paint(code, s[last:]))
+	return b.String()
+}
+
+// paintValue returns the formatted value of the qualifier in the color, the JSON of the structured decodes is
+// colored by the syntax unless the value is highlighted by the regex
+func (w *Printer) paintValue(q, s string) string {
+	if !w.color {
+		return s
+	}
+	if w.valueMatch == nil && isStructuredDecode(w.decodeTypeOf(q)) && !strings.HasPrefix(s, "<") {
+		return w.paintJSON(s)
+	}
+	return w.paintMatches(colorValue, s, w.valueMatch)
+}
+
+// paintJSON returns the JSON colored by the syntax, the keys, the strings, the numbers and the literals
+func (w *Printer) paintJSON(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j < len(s) {
+				j++
+			}
+			code := colorJSONString
+			if k := strings.TrimLeft(s[j:], " \t\r\n"); strings.HasPrefix(k, ":") {
+				code = colorJSONKey
+			}
+			b.WriteString(w.paint(code, s[i:j]))
+			i = j
+		case c == '-' || '0' <= c && c <= '9':
+			j := i + 1
+			for j < len(s) && strings.IndexByte("0123456789.eE+-", s[j]) >= 0 {
+				j++
+			}
+			b.WriteString(w.paint(colorJSONNumber, s[i:j]))
+			i = j
+		case 'a' <= c && c <= 'z':
+			j := i + 1
+			for j < len(s) && 'a' <= s[j] && s[j] <= 'z' {
+				j++
+			}
+			b.WriteString(w.paint(colorJSONLiteral, s[i:j]))
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package interfaces
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/domain"
+)
+
+func TestPrintRowColor(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	row := &domain.Row{
+		Key: "a",
+		Columns: []*domain.Column{
+			{Family: "d", Qualifier: "d:row", Value: []byte("a1b1"), Version: tm},
+		},
+	}
+	wide := &domain.Row{
+		Key: "a",
+		Columns: []*domain.Column{
+			{Family: "d", Qualifier: "d:行", Value: []byte("a1b1"), Version: tm},
+		},
+	}
+	ts := tm.Format(timestampLayout)
+
+	cases := []struct {
+		printer *Printer
+		row     *domain.Row
+		expect  string
+	}{
+		{
+			&Printer{color: true},
+			row,
+			"\x1b[1;33ma\x1b[0m\n" +
+				"  \x1b[36md:row\x1b[0m                                    @ \x1b[90m" + ts + "\x1b[0m\n" +
+				"    \x1b[32m\"a1b1\"\x1b[0m\n",
+		},
+		{
+			// the matched portions are highlighted, the qualifier is matched without the family
+			&Printer{color: true, valueMatch: regexp.MustCompile(`b1`), qualifierMatch: regexp.MustCompile(`^row$`)},
+			row,
+			"\x1b[1;33ma\x1b[0m\n" +
+				"  \x1b[36md:\x1b[0m\x1b[1;4;31mrow\x1b[0m                                    @ \x1b[90m" + ts + "\x1b[0m\n" +
+				"    \x1b[32m\"a1\x1b[0m\x1b[1;4;31mb1\x1b[0m\x1b[32m\"\x1b[0m\n",
+		},
+		{
+			// no escapes without the color
+			&Printer{valueMatch: regexp.MustCompile(`b1`)},
+			row,
+			"a\n  d:row                                    @ " + ts + "\n    \"a1b1\"\n",
+		},
+		{
+			// padded by the runes
+			&Printer{},
+			wide,
+			"a\n  d:行" + strings.Repeat(" ", 37) + " @ " + ts + "\n    \"a1b1\"\n",
+		},
+	}
+	for i, c := range cases {
+		var out, errOut bytes.Buffer
+		c.printer.outStream = &out
+		c.printer.errStream = &errOut
+		c.printer.printRow(c.row)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+	}
+}
+
+func TestPaintJSON(t *testing.T) {
+	w := &Printer{color: true}
+	assert.Equal(t,
+		"{\x1b[34m\"a\\\"b\"\x1b[0m: [\x1b[35m-1.5e3\x1b[0m, \x1b[32m\"x\"\x1b[0m, \x1b[33mtrue\x1b[0m, \x1b[33mnull\x1b[0m]}",
+		w.paintJSON(`{"a\"b": [-1.5e3, "x", true, null]}`))
+
+	// the JSON of decode=json is colored by the syntax
+	var out bytes.Buffer
+	w = &Printer{outStream: &out, color: true, decodeType: "json"}
+	w.printValue("d:doc", []byte(`{"n":1}`))
+	assert.Equal(t, "    {\n      \x1b[34m\"n\"\x1b[0m: \x1b[35m1\x1b[0m\n    }\n", out.String())
+}
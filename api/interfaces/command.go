@@ -12,6 +12,19 @@ type Command struct {
 	Description string
 	Usage       string
 	Runner      func(context.Context, *Executor, ...string)
+
+	// Write marks the command modifying the data or the schema
+	Write bool
+	// ReadOnlyArgs reports whether the args make the write command only read, e.g. validate-only=true
+	ReadOnlyArgs func(args []string) bool
+	// WriteArgs reports whether the args make the read command write, e.g. from-backup=<backup> restoring a table
+	WriteArgs func(args []string) bool
+	// Paged pipes the output through the pager of the interactive shell, see the pager setting
+	Paged bool
+	// Experimental gates the command unless enabled by -enable-experimental, the behavior may change
+	Experimental bool
+	// Deprecated is the replacement of the deprecated command, printed as a warning before the command runs
+	Deprecated string
 }
 
 var commands = []Command{
@@ -20,40 +33,503 @@ var commands = []Command{
 		Description: "help command",
 		Usage:       "help [<command>]",
 		Runner:      doHelp,
+		Paged:       true,
 	},
 	{
 		Name:        "ls",
 		Description: "List tables",
 		Usage:       "ls",
 		Runner:      doLS,
+		Paged:       true,
 	},
 	{
 		Name:        "count",
 		Description: "Count table rows",
-		Usage:       "count <table>",
-		Runner:      doCount,
+		Usage: `count <table> [start=<row>] [end=<row>] [prefix=<prefix>]
+	start     Start counting at this row
+	end       Stop counting before this row
+	prefix    Count rows with this prefix`,
+		Runner: doCount,
+	},
+	{
+		Name:        "stats",
+		Description: "Show statistics of the cells in a range",
+		Usage: `stats <table> [start=<row>] [end=<row>] [prefix=<prefix>] [family=<regex>] [versions=all|<n>] [columns=<family:qualifier>,...] [qualifier-regex=<regex>] [from=<timestamp>] [to=<timestamp>] [values=true] [app-profile=<id>]
+	start            Start scanning at this row
+	end              Stop scanning before this row
+	prefix           Scan rows with this prefix
+	family           Count only column families matching <regex>
+	versions         Count latest <n> versions of each column or all versions (default all)
+	columns          Count only the given columns
+	qualifier-regex  Count only columns whose qualifier matches <regex>
+	from             Count only cells written at or after <timestamp>
+	to               Count only cells written before <timestamp>
+	values           Read the values to count the value bytes, only the keys and the qualifiers are read by default
+	app-profile      Read with the app profile <id> (default -app-profile flag)`,
+		Runner: doStats,
+	},
+	{
+		Name:        "samplekeys",
+		Description: "Show the sampled boundary keys of the tablets",
+		Usage:       "samplekeys <table>",
+		Runner:      doSampleKeys,
+		Paged:       true,
+	},
+	{
+		Name:        "usage",
+		Description: "Estimate the bytes of each column family by the sampled rows",
+		Usage: `usage <table> [sample=<p>] [app-profile=<id>]
+	sample       Read the rows at the probability <p>, 1 reads all rows (default 0.01)
+	app-profile  Read with the app profile <id> (default -app-profile flag)`,
+		Runner: doUsage,
+	},
+	{
+		Name:        "suggest-splits",
+		Description: "Suggest the split points of a new table by the sampled keys of a similar table",
+		Usage: `suggest-splits <src-table> target=<n>
+	target  Divide into <n> tablets of the similar bytes`,
+		Runner: doSuggestSplits,
+	},
+	{
+		Name:        "range",
+		Description: "Split or intersect the ranges of the row keys in the byte-wise order",
+		Usage: `range split <start> <end> parts=<n> | range split <range> parts=<n> | range intersect <range> <range> ...
+	split      Divide the range into <n> ranges of the similar widths of the keys, parts=<n> is also given as --parts <n>
+	intersect  Print the range of the keys in all ranges
+	<start>    The first key of the range
+	<end>      The key after the range, '' is unbounded
+	<range>    <start>..<end> with the optional sides e.g. a..m or a.., or <prefix>* up to the prefix successor`,
+		Runner: doRange,
 	},
 	{
 		Name:        "lookup",
 		Description: "Read from a single row",
-		Usage: `lookup <table> <row> [family=<column_family>] [version=<n>]
-	family    Read only columns family with <columns_family>
-	version   Read only latest <n> columns`,
+		Usage: `lookup <table> <row> [<row> ...] [<family:qualifier> ...]|keys=<row>,...|keys-file=<file>|spec=<file> [family=<regex>] [versions=all|<n>] [columns=<family:qualifier>,...] [qualifier-regex=<regex>] [from=<timestamp>] [to=<timestamp>] [asof=<timestamp>] [cells-per-row=<n>] [label=<label>] [app-profile=<id>] [priority=low|medium|high] [qualifier-time=<unit>] [pivot=true] [full-values=true] [fanout=<n>] [slow=<duration>] [format=<format>] [template=<template>] [preset=<name>]
+	keys             Read the given rows, use it for the keys starting with a family of the table and ":"
+	keys-file        Read the rows listed in a file, one key per line
+	spec             Read the keys and their columns in a JSON file {"<row>": ["<family:qualifier>", ...], ...}
+	family           Read only column families matching <regex>
+	versions         Read latest <n> versions of each column or all versions (default 1)
+	columns          Read only the given columns
+	qualifier-regex  Read only columns whose qualifier matches <regex>
+	from             Read only cells written at or after <timestamp>
+	to               Read only cells written before <timestamp>
+	asof             Read the latest versions at <timestamp> as long as the older versions are kept by the GC policy
+	cells-per-row    Read only the first <n> cells of each row
+	label            Label the cells with <label>, printed after the timestamp
+	app-profile      Read with the app profile <id> (default -app-profile flag)
+	priority         Read with an app profile of the request priority, low for the heavy scans
+	qualifier-time   Label and sort qualifiers by the trailing unix time in <s|ms|us|ns>
+	pivot            Print time-bucketed qualifiers as a series, requires qualifier-time
+	full-values      Print the values without truncating them at -max-value-bytes
+	fanout           Read each row by a request with <n> requests in parallel and report the latency of the keys
+	slow             Report the keys read in <duration> or longer by fanout (default 100ms)
+	format           Print the rows in <format> instead of the format of the session
+	template         Print the rows by <template> instead of the template of the session, implies format=template
+	preset           Read with the options of the preset saved by "preset save", the given options win`,
 		Runner: doLookup,
+		Paged:  true,
+	},
+	{
+		Name:        "quorum-read",
+		Description: "Check whether the replicas of the clusters agree on a row",
+		Usage: `quorum-read <table> <row> [profiles=<id>,...] [versions=all|<n>] [family=<regex>] [columns=<family:qualifier>,...]
+	profiles  Read with the single-cluster app profiles <id> (default a profile of each cluster)
+	versions  Compare latest <n> versions of each column or all versions (default 1)
+	family    Compare only column families matching <regex>
+	columns   Compare only the given columns`,
+		Runner: doQuorumRead,
+		Paged:  true,
 	},
 	{
 		Name:        "read",
 		Description: "Read from a multi rows",
-		Usage: `read <table> [start=<row>] [end=<row>] [prefix=<prefix>] [family=<column_family>] [version=<n>]
-	start     Start reading at this row
-	end       Stop reading before this row
-	prefix    Read rows with this prefix
-	family    Read only columns family with <columns_family>
-	version   Read only latest <n> columns`,
-		Runner: doRead,
+		Usage: `read <table> [start=<row>] [end=<row>] [prefix=<prefix>] [count=<n>] [offset=<n>] [family=<regex>] [versions=all|<n>] [columns=<family:qualifier>,...] [qualifier-regex=<regex>] [value-regex=<regex>] [sample=<probability>] [from=<timestamp>] [to=<timestamp>] [asof=<timestamp>] [cells-per-row=<n>] [label=<label>] [from-backup=<backup> cluster=<cluster>] [page=<n>] [page-size=<n>] [app-profile=<id>] [priority=low|medium|high] [qualifier-time=<unit>] [pivot=true] [full-values=true] [format=<format>] [template=<template>] [preset=<name>]
+	start            Start reading at this row
+	end              Stop reading before this row
+	prefix           Read rows with this prefix
+	count            Read at most <n> rows, 0 reads all rows (default -read-limit flag unless paginated)
+	offset           Skip the first <n> matching rows
+	family           Read only column families matching <regex>
+	versions         Read latest <n> versions of each column or all versions (default 1)
+	columns          Read only the given columns
+	qualifier-regex  Read only columns whose qualifier matches <regex>
+	value-regex      Read only cells whose value matches <regex>
+	sample           Read a random sample of rows with <probability> (e.g. 0.01)
+	from             Read only cells written at or after <timestamp>
+	to               Read only cells written before <timestamp>
+	asof             Read the latest versions at <timestamp> as long as the older versions are kept by the GC policy
+	cells-per-row    Read only the first <n> cells of each row
+	label            Label the cells with <label>, printed after the timestamp
+	from-backup      Read from <backup> restored into a temporary table, deleted after reading, a write for "set dryrun" and the lock
+	cluster          Cluster of the backup
+	page             Print the <n>th page of the rows, run "next" for the following page
+	page-size        Print <n> rows per page, 0 prints all rows (default -page-size flag in the terminal)
+	app-profile      Read with the app profile <id> (default -app-profile flag)
+	priority         Read with an app profile of the request priority, low for the heavy scans
+	qualifier-time   Label and sort qualifiers by the trailing unix time in <s|ms|us|ns>
+	pivot            Print time-bucketed qualifiers as a series, requires qualifier-time
+	full-values      Print the values without truncating them at -max-value-bytes
+	format           Print the rows in <format> instead of the format of the session
+	template         Print the rows by <template> instead of the template of the session, implies format=template
+	preset           Read with the options of the preset saved by "preset save", the given options win`,
+		Runner:    doRead,
+		Paged:     true,
+		WriteArgs: readFromBackup,
+	},
+	{
+		Name:        "export",
+		Description: "Export rows to a file",
+		Usage: `export <table> <file> [format=<format>] [manifest=true] [binary=base64|raw] [start=<row>] [end=<row>] [prefix=<prefix>] [count=<n>] [family=<regex>] [versions=all|<n>] [columns=<family:qualifier>,...] [qualifier-regex=<regex>] [decode=<type>] [decode_columns=<column>:<type>,...] [app-profile=<id>]
+	format           Write the rows in ndjson (default), json, csv, tsv or yaml
+	manifest         Write the columns, the decodings, the key format, the row count and the fingerprint to <file>.schema.json
+	binary           Write the binary keys, qualifiers and values of csv and tsv in base64 listed by the last column e.g. "key+value" (default), or raw
+	start            Start reading at this row
+	end              Stop reading before this row
+	prefix           Read rows with this prefix
+	count            Read at most <n> rows (default all rows)
+	family           Read only column families matching <regex>
+	versions         Read latest <n> versions of each column or all versions (default 1)
+	columns          Read only the given columns
+	qualifier-regex  Read only columns whose qualifier matches <regex>
+	decode           Decode the values as <type>, recorded in the manifest
+	decode_columns   Decode the values of the columns as <type>, recorded in the manifest
+	app-profile      Read with the app profile <id> (default -app-profile flag)`,
+		Runner: doExport,
+	},
+	{
+		Name:        "import",
+		Description: "Import rows from a file written by export",
+		Usage: `import <table> <file> [format=<format>] [manifest=<file>] [decode=<type>] [decode_columns=<column>:<type>,...] [validate-only=true] [create-family=true] [family-policy=<policy>] [ignore-decoders=true] [app-profile=<id>]
+	format          Read the rows in ndjson, csv or tsv (default the format of the manifest or the extension, then ndjson)
+	manifest        Validate the file by the schema manifest (default <file>.schema.json if exists)
+	decode          Encode the values printed as <type> (default the decodes of the manifest)
+	decode_columns  Encode the values of the columns printed as <type>
+	validate-only   Parse the file, check the families, the columns and the values, and report the errors without writing
+	create-family   Create the families missing in the table before writing instead of the errors
+	family-policy   GC policy of the created families, maxversions=<n>, maxage=<duration> or both joined by | (default no GC policy)
+	ignore-decoders Write the values not matching the decoders of the columns e.g. the text into int
+	app-profile     Write with the app profile <id> (default -app-profile flag)`,
+		Runner:       doImport,
+		Write:        true,
+		ReadOnlyArgs: importValidateOnly,
+	},
+	{
+		Name:        "plan",
+		Description: "Write the affected keys of a bulk change to a file for apply",
+		Usage: `plan deleterows|update <table> <file> [set=<family:qualifier>=<value> ...] [decode=<type>] [start=<row>] [end=<row>] [prefix=<prefix>] [count=<n>] [family=<regex>] [columns=<family:qualifier>,...] [qualifier-regex=<regex>] [value-regex=<regex>] [app-profile=<id>]
+	deleterows       Plan deleting the rows
+	update           Plan setting the cells of set to the rows
+	set              Set <value> to the column by update, repeated for the columns
+	decode           Encode the values of set as <type> like import (default string)
+	start            Start reading at this row
+	end              Stop reading before this row
+	prefix           Read rows with this prefix
+	count            Plan at most <n> rows
+	family           Plan only the rows having the column families matching <regex>
+	columns          Plan only the rows having the given columns
+	qualifier-regex  Plan only the rows having the columns whose qualifier matches <regex>
+	value-regex      Plan only the rows having the cells whose value matches <regex>
+	app-profile      Read with the app profile <id> (default -app-profile flag)`,
+		Runner: doPlan,
+	},
+	{
+		Name:        "apply",
+		Description: "Execute the bulk change of the keys written by plan",
+		Usage:       "apply <file>",
+		Runner:      doApply,
+		Write:       true,
+	},
+	{
+		Name:        "explain",
+		Description: "Print the request of read or lookup without sending it",
+		Usage:       "explain read|lookup <table> [args ...]",
+		Runner:      doExplain,
+	},
+	{
+		Name:        "next",
+		Description: "Print the next page of the last read",
+		Usage:       "next",
+		Runner:      doNext,
+		Paged:       true,
+	},
+	{
+		Name:        "exists-batch",
+		Description: "Check which of the keys exist",
+		Usage: `exists-batch <table> keys=<row>,...|keys-file=<file> [format=csv|ndjson] [batch-size=<n>] [app-profile=<id>]
+	keys         Check the given rows
+	keys-file    Check the rows listed in a file, one key per line
+	format       Print the keys and the existence in csv or ndjson (default csv)
+	batch-size   Read <n> keys in a request (default 1000)
+	app-profile  Read with the app profile <id> (default -app-profile flag)`,
+		Runner: doExistsBatch,
+		Paged:  true,
+	},
+	{
+		Name:        "grep",
+		Description: "Read the rows having a value matching the pattern",
+		Usage: `grep <table> <pattern> [start=<row>] [end=<row>] [prefix=<prefix>] [limit=<n>] [family=<regex>] [versions=all|<n>] [columns=<family:qualifier>,...] [qualifier-regex=<regex>] [from=<timestamp>] [to=<timestamp>] [app-profile=<id>]
+	start            Start scanning at this row
+	end              Stop scanning before this row
+	prefix           Scan rows with this prefix
+	limit            Scan the first <n> rows at most (default 10000)
+	family           Search only column families matching <regex>
+	versions         Search latest <n> versions of each column or all versions (default 1)
+	columns          Search only the given columns
+	qualifier-regex  Search only columns whose qualifier matches <regex>
+	from             Search only cells written at or after <timestamp>
+	to               Search only cells written before <timestamp>
+	app-profile      Read with the app profile <id> (default -app-profile flag)`,
+		Runner: doGrep,
+		Paged:  true,
+	},
+	{
+		Name:        "watch-row",
+		Description: "Watch changes of a single row",
+		Usage: `watch-row <table> <row> [interval=<duration>] [count=<n>] [family=<regex>] [columns=<family:qualifier>,...]
+	interval  Poll the row every <duration> (default 2s)
+	count     Stop after <n> polls, watch until Ctrl+C if unset
+	family    Watch only column families matching <regex>
+	columns   Watch only the given columns`,
+		Runner: doWatchRow,
+	},
+	{
+		Name:        "tail",
+		Description: "Print new cells in the rows periodically",
+		Usage: `tail <table> [start=<row>] [end=<row>] [prefix=<prefix>] [family=<regex>] [columns=<family:qualifier>,...] [qualifier-regex=<regex>] [value-regex=<regex>] [interval=<duration>] [count=<n>] [since=<timestamp>]
+	start            Start reading at this row
+	end              Stop reading before this row
+	prefix           Read rows with this prefix
+	family           Read only column families matching <regex>
+	columns          Read only the given columns
+	qualifier-regex  Read only columns whose qualifier matches <regex>
+	value-regex      Read only cells whose value matches <regex>
+	interval         Poll the rows every <duration> (default 2s)
+	count            Stop after <n> polls, tail until Ctrl+C if unset
+	since            Print cells written at or after <timestamp> (default now)`,
+		Runner: doTail,
+	},
+	{
+		Name:        "probe",
+		Description: "Read a row periodically and report the latency SLO compliance",
+		Usage: `probe <table> <row> [interval=<duration>] [slo-p99=<duration>] [report=<duration>] [violations=<n>] [count=<n>] [app-profile=<id>]
+	interval     Read the row every <duration> (default 1s)
+	slo-p99      99% of the reads should succeed within <duration> (default 100ms)
+	report       Print the summary of the reads every <duration> (default 1m)
+	violations   Exit btcli with the code 14 after <n> consecutive reports violating the SLO, never exit if 0 (default 3)
+	count        Stop after <n> reads, probe until Ctrl+C if unset
+	app-profile  Read with the app profile <id> (default -app-profile flag)`,
+		Runner: doProbe,
+	},
+	{
+		Name:        "index",
+		Description: "Build and search the local index of the row keys",
+		Usage: `index build <table> | index search <pattern> [table=<table>] [limit=<n>] | index bloom <table> <file> [fp-rate=<p>]
+	build    Store all row keys of the table in the local index
+	search   Print the indexed keys matching <regex>
+	table    Search only the table
+	limit    Print at most <n> keys
+	bloom    Write the bloom filter of all row keys of the table to <file>, checked by "membership check"
+	fp-rate  False positive rate of the bloom filter (default 0.01)`,
+		Runner: doIndex,
+	},
+	{
+		Name:        "membership",
+		Description: "Check the keys in a file against the bloom filter of index bloom",
+		Usage:       "membership check <keys-file> <filter-file>",
+		Runner:      doMembership,
+	},
+	{
+		Name:        "history",
+		Description: "Show the changes of a row recorded in the change stream",
+		Usage: `history <table> <row> [since=<duration>] [full-values=true]
+	since        Show the changes in the last <duration> (default 1h)
+	full-values  Print the values without truncating them at -max-value-bytes`,
+		Runner: doHistory,
+		Paged:  true,
+	},
+	{
+		Name:        "diff",
+		Description: "Show the differences of the cells between two rows",
+		Usage: `diff <table> <row1> <row2>|<row> table2=<table>|instance2=<instance> [versions=all|<n>] [family=<regex>] [columns=<family:qualifier>,...] [app-profile=<id>]
+	table2       Compare <row> with the same row in <table>
+	instance2    Compare with the table in <instance> of the project
+	versions     Compare latest <n> versions of each column or all versions (default 1)
+	family       Compare only column families matching <regex>
+	columns      Compare only the given columns
+	app-profile  Read with the app profile <id> (default -app-profile flag)`,
+		Runner: doDiff,
+		Paged:  true,
+	},
+	{
+		Name:        "describe",
+		Description: "Show the settings of a table",
+		Usage:       "describe <table>",
+		Runner:      doDescribe,
+		Paged:       true,
+	},
+	{
+		Name:        "backuppolicy",
+		Description: "Show the automated backup policy of a table",
+		Usage:       "backuppolicy <table>",
+		Runner:      doBackupPolicy,
+	},
+	{
+		Name:        "setbackuppolicy",
+		Description: "Set the automated backup policy of a table",
+		Usage: `setbackuppolicy <table> [retention=<duration>] [frequency=<duration>] [disable=true]
+	retention  Keep the automated backups for <duration> (e.g. 72h)
+	frequency  Create the automated backups every <duration> (e.g. 24h)
+	disable    Disable the automated backups`,
+		Runner: doSetBackupPolicy,
+		Write:  true,
+	},
+	{
+		Name:        "setprotection",
+		Description: "Enable or disable the deletion protection of a table",
+		Usage: `setprotection <table> enabled=<bool>
+	enabled  Protect the table from deletion`,
+		Runner: doSetProtection,
+		Write:  true,
+	},
+	{
+		Name:        "setchangestream",
+		Description: "Enable or disable the change stream of a table",
+		Usage: `setchangestream <table> [retention=<duration>] [disable=true]
+	retention  Enable the change stream and keep the changes for <duration> (e.g. 72h)
+	disable    Disable the change stream`,
+		Runner: doSetChangeStream,
+		Write:  true,
+	},
+	{
+		Name:        "createtable",
+		Description: "Create a table with the column families and the initial split points",
+		Usage: `createtable <table> [families=<family>[:<policy>],...] [splits=<row>,...] [splits-file=<file>]
+	families     Create the families with the GC policy maxversions=<n>, maxage=<duration> (e.g. 30d) or both joined by "|"
+	splits       Split the table at the rows
+	splits-file  Split the table at the rows of <file>, a row per line or the line printed by suggest-splits`,
+		Runner: doCreateTable,
+		Write:  true,
+	},
+	{
+		Name:        "deletetable",
+		Description: "Delete a table",
+		Usage:       "deletetable <table>",
+		Runner:      doDeleteTable,
+		Write:       true,
+	},
+	{
+		Name:        "clone",
+		Description: "Create a copy of a table",
+		Usage: `clone <src> <dst> [schema-only=true]
+	schema-only  Copy only the column families and GC policies without the rows`,
+		Runner: doClone,
+		Write:  true,
 	},
 
 	// btcli commands
+	{
+		Name:        "again",
+		Description: "Re-execute the previous command with the options overridden",
+		Usage:       "again [<key>=<value> ...]",
+		Runner:      doAgain,
+	},
+	{
+		Name:        "show",
+		Description: "Print a row of the last result by the number of the row",
+		Usage: `show <n> [decode=<type>] [decode_columns=<column>:<type>,...] [qualifier-time=<unit>] [pivot=true] [full-values=true] [format=<format>] [template=<template>]
+	decode          Decode the values by <type> instead of the decodes of the session
+	decode_columns  Decode the values of the given columns by <type>
+	qualifier-time  Label and sort qualifiers by the trailing unix time in <s|ms|us|ns>
+	pivot           Print time-bucketed qualifiers as a series, requires qualifier-time
+	full-values     Print the values without truncating them at -max-value-bytes
+	format          Print the row in <format> instead of the format of the session
+	template        Print the row by <template> instead of the template of the session, implies format=template`,
+		Runner: doShow,
+		Paged:  true,
+	},
+	{
+		Name:        "link",
+		Description: "Print the links of a row to share it",
+		Usage:       "link <table> <row>",
+		Runner:      doLink,
+	},
+	{
+		Name:        "let",
+		Description: "Read the value of a cell into a variable expanded as $name in the later commands",
+		Usage:       "let [<name> = read|lookup <table> [args ...]]",
+		Runner:      doLet,
+		// the read restoring the backup is a write of the captured command
+		WriteArgs: readFromBackup,
+	},
+	{
+		Name:        "format",
+		Description: "Show or change the output format of the rows",
+		Usage: `format [text|json|ndjson|csv|tsv|yaml|table|wide|plain|template [<template>]]
+	text      Print the rows in the text layout (default)
+	json      Print a row of lookup as a JSON object, the rows of read and the tables of ls as a JSON array
+	ndjson    Print each row and each table as a JSON line, the rows of read are printed as they are read
+	csv       Print each cell as a "rowkey,family,qualifier,timestamp,value" line and each table as a line, the rows of read are printed as they are read
+	tsv       Print each cell as a "rowkey<TAB>family:qualifier<TAB>timestamp<TAB>value" line escaping \t, \n, \r and \\, the rows of read are printed as they are read
+	yaml      Print the rows in the layout of the fixtures of bt-fixture and the tables as a YAML list
+	table     Print the cells and the tables as a grid aligned by the widest cell of each column
+	wide      Print each row as a line of the grid with the qualifiers as the columns, the latest cell of each column is printed
+	plain     Print each cell as a "rowkey<TAB>family:qualifier<TAB>value" line of the decoded value without the separators and the timestamps, the rows of read are printed as they are read
+	template  Print each row by the text/template of .Key, .Cells of the latest values by "family:qualifier" and .Columns of the JSON cells, e.g. '{{.Key}}\t{{index .Cells "d:name"}}', the template is kept for the session`,
+		Runner: doFormat,
+	},
+	{
+		Name:        "preset",
+		Description: "Show, save or use the presets of the read and lookup options",
+		Usage: `preset [save <name> <key>=<value> ...|use <name>|off|delete <name>]
+	save    Save the options version, versions, family, decode, decode_columns, format and count as the preset applied by preset=<name> of read and lookup
+	use     Apply the preset to read and lookup without preset=<name>, off stops it
+	delete  Delete the preset`,
+		Runner: doPreset,
+	},
+	{
+		Name:        "decode",
+		Description: "Show or change the decodes of the columns",
+		Usage: `decode [<family:qualifier>=<type> ...]
+	type  Decode the values of the column by string, int, float, hex, base64, json, proto:<message> or avro:<schema.json>,
+	      int64 and float64 are the aliases, an empty type removes the decoder,
+	      the decode and decode_columns options win over the decoders`,
+		Runner: doDecode,
+	},
+	{
+		Name:        "autodecode",
+		Description: "Show or change the types guessed by the values without the decodes",
+		Usage: `autodecode [off|int|float|all]
+	off    Print the values as the texts or the hex dumps
+	int    Print the values of 8 bytes as the ints
+	float  Print the values of 8 bytes as the floats if the exponents look like the floats, the others as off
+	all    Print the values of 8 bytes as the floats or the ints by the exponents (default)`,
+		Runner: doAutoDecode,
+	},
+	{
+		Name:        "set",
+		Description: "Show or change the session settings",
+		Usage: `set [dryrun|color|pager|utc|verbose on|off] [timestamp-format <format>]
+	dryrun            Print the write commands instead of executing them
+	color             Paint the rows of the text format (default on for the terminal unless -no-color)
+	pager             Pipe the output of the read commands through $PAGER (default on for the interactive shell on the terminal unless $PAGER is empty)
+	utc               Print the timestamps in UTC instead of the local time (default -utc flag)
+	verbose           Print the byte size, the labels and the raw value in hex of each cell (default -verbose flag)
+	timestamp-format  Print the timestamps in default (2018/01/01-00:00:00.000000), rfc3339, unix-micros or relative e.g. "3h ago" (default -timestamp-format flag)`,
+		Runner: doSet,
+	},
+	{
+		Name:        "reset",
+		Description: "Retry requests failing fast after the backend was unavailable",
+		Usage:       "reset",
+		Runner:      doReset,
+	},
+	{
+		Name:        "unlock",
+		Description: "Unlock write commands locked by the idle timeout",
+		Usage:       "unlock",
+		Runner:      doUnlock,
+	},
 	{
 		Name:        "exit",
 		Description: "Exit this prompt",
@@ -68,10 +544,10 @@ var commands = []Command{
 	},
 }
 
-func getAllSuggests() []prompt.Suggest {
+func getAllSuggests(locale string) []prompt.Suggest {
 	ss := make([]prompt.Suggest, 0, len(commands))
 	for _, c := range commands {
-		ss = append(ss, prompt.Suggest{Text: c.Name, Description: c.Description})
+		ss = append(ss, prompt.Suggest{Text: c.Name, Description: translate(locale, c.Description)})
 	}
 	return ss
 }
@@ -6,11 +6,15 @@ import (
 
 	prompt "github.com/c-bata/go-prompt"
 	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/config"
 )
 
 // Completer provides completion command handler
 type Completer struct {
-	tableInteractor *application.TableInteractor
+	metadataInteractor *application.MetadataInteractor
+
+	// locale is the language of the command descriptions
+	locale string
 }
 
 // Do provide completion to prompt
@@ -25,24 +29,517 @@ func (c *Completer) Do(d prompt.Document) []prompt.Suggest {
 
 func (c *Completer) completeWithArguments(args ...string) []prompt.Suggest {
 	if len(args) <= 1 {
-		return prompt.FilterHasPrefix(getAllSuggests(), args[0], true)
+		return prompt.FilterHasPrefix(getAllSuggests(c.locale), args[0], true)
 	}
 
 	cmd := args[0]
+	if s, ok := c.completeFamilies(args); ok {
+		return s
+	}
 
 	second := args[1]
 	switch cmd {
+	case "format":
+		if len(args) == 2 {
+			suggests := make([]prompt.Suggest, 0, len(config.Formats))
+			for _, f := range config.Formats {
+				suggests = append(suggests, prompt.Suggest{Text: f})
+			}
+			return prompt.FilterHasPrefix(suggests, second, true)
+		}
+	case "let":
+		if len(args) == 4 && args[2] == "=" {
+			suggests := make([]prompt.Suggest, 0, len(letCommands))
+			for _, name := range letCommands {
+				suggests = append(suggests, prompt.Suggest{Text: name})
+			}
+			return prompt.FilterHasPrefix(suggests, args[3], true)
+		}
+		if len(args) > 4 && args[2] == "=" && containsString(letCommands, args[3]) {
+			return c.completeWithArguments(args[3:]...)
+		}
+	case "show":
+		if len(args) > 2 {
+			subcommands := []prompt.Suggest{
+				{Text: "decode"},
+				{Text: "decode_columns"},
+				{Text: "qualifier-time"},
+				{Text: "pivot"},
+				{Text: "full-values"},
+				{Text: "format"},
+				{Text: "template"},
+			}
+			distinctCommands := filterDuplicateCommands(args, subcommands)
+			latestCmd := args[len(args)-1]
+			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+		}
+	case "range":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix([]prompt.Suggest{{Text: "split"}, {Text: "intersect"}}, second, true)
+		}
+		if len(args) == 4 && second == "split" {
+			return prompt.FilterHasPrefix([]prompt.Suggest{{Text: "parts"}}, args[3], true)
+		}
+	case "preset":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix([]prompt.Suggest{{Text: "save"}, {Text: "use"}, {Text: "delete"}}, second, true)
+		}
+		if len(args) > 3 && second == "save" {
+			subcommands := make([]prompt.Suggest, 0, len(presetOptions))
+			for _, o := range presetOptions {
+				subcommands = append(subcommands, prompt.Suggest{Text: o})
+			}
+			distinctCommands := filterDuplicateCommands(args, subcommands)
+			latestCmd := args[len(args)-1]
+			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+		}
+	case "autodecode":
+		if len(args) == 2 {
+			suggests := make([]prompt.Suggest, 0, len(config.AutoDecodes))
+			for _, a := range config.AutoDecodes {
+				suggests = append(suggests, prompt.Suggest{Text: a})
+			}
+			return prompt.FilterHasPrefix(suggests, second, true)
+		}
+	case "decode":
+		// complete the type after "<family:qualifier>="
+		latest := args[len(args)-1]
+		i := strings.Index(latest, "=")
+		if i < 0 {
+			return []prompt.Suggest{}
+		}
+		suggests := make([]prompt.Suggest, 0, len(config.DecodeTypes))
+		for _, t := range config.DecodeTypes {
+			suggests = append(suggests, prompt.Suggest{Text: latest[:i+1] + t})
+		}
+		return prompt.FilterHasPrefix(suggests, latest, true)
+	case "set":
+		if len(args) == 2 {
+			suggests := make([]prompt.Suggest, 0, len(settingNames))
+			for _, n := range settingNames {
+				suggests = append(suggests, prompt.Suggest{Text: n})
+			}
+			suggests = append(suggests, prompt.Suggest{Text: settingTimestampFormat})
+			return prompt.FilterHasPrefix(suggests, second, true)
+		}
+		if len(args) == 3 && second == settingTimestampFormat {
+			suggests := make([]prompt.Suggest, 0, len(config.TimestampFormats))
+			for _, f := range config.TimestampFormats {
+				suggests = append(suggests, prompt.Suggest{Text: f})
+			}
+			return prompt.FilterHasPrefix(suggests, args[2], true)
+		}
+		if len(args) == 3 {
+			return prompt.FilterHasPrefix([]prompt.Suggest{{Text: "on"}, {Text: "off"}}, args[2], true)
+		}
+	case "explain":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix([]prompt.Suggest{{Text: "read"}, {Text: "lookup"}}, second, true)
+		}
+		// complete the arguments of the explained command
+		return c.completeWithArguments(args[1:]...)
 	case "count":
 		if len(args) == 2 {
 			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
 		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "start"},
+			{Text: "end"},
+			{Text: "prefix"},
+		}
+		distinctCommands := filterDuplicateCommands(args, subcommands)
+		latestCmd := args[len(args)-1]
+		return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+	case "export":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+		if len(args) == 3 {
+			// a file to write
+			return []prompt.Suggest{}
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "format"},
+			{Text: "manifest"},
+			{Text: "binary"},
+			{Text: "start"},
+			{Text: "end"},
+			{Text: "prefix"},
+			{Text: "count"},
+			{Text: "family"},
+			{Text: "versions"},
+			{Text: "columns"},
+			{Text: "qualifier-regex"},
+			{Text: "decode"},
+			{Text: "decode_columns"},
+			{Text: "app-profile"},
+		}
+		distinctCommands := filterDuplicateCommands(args, subcommands)
+		latestCmd := args[len(args)-1]
+		return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+	case "import":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+		if len(args) == 3 {
+			// a file to read
+			return []prompt.Suggest{}
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "format"},
+			{Text: "manifest"},
+			{Text: "decode"},
+			{Text: "decode_columns"},
+			{Text: "validate-only"},
+			{Text: "create-family"},
+			{Text: "family-policy"},
+			{Text: "ignore-decoders"},
+			{Text: "app-profile"},
+		}
+		distinctCommands := filterDuplicateCommands(args, subcommands)
+		latestCmd := args[len(args)-1]
+		return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+	case "plan":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix([]prompt.Suggest{{Text: "deleterows"}, {Text: "update"}}, second, true)
+		}
+		if len(args) == 3 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), args[2], true)
+		}
+		if len(args) == 4 {
+			// a file to write
+			return []prompt.Suggest{}
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "start"},
+			{Text: "end"},
+			{Text: "prefix"},
+			{Text: "count"},
+			{Text: "family"},
+			{Text: "columns"},
+			{Text: "qualifier-regex"},
+			{Text: "value-regex"},
+			{Text: "app-profile"},
+		}
+		if second == "update" {
+			// set is repeated for the columns
+			subcommands = append(subcommands, prompt.Suggest{Text: "decode"})
+			distinctCommands := append(filterDuplicateCommands(args, subcommands), prompt.Suggest{Text: "set"})
+			return prompt.FilterHasPrefix(distinctCommands, args[len(args)-1], true)
+		}
+		distinctCommands := filterDuplicateCommands(args, subcommands)
+		latestCmd := args[len(args)-1]
+		return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+	case "usage":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "sample"},
+			{Text: "app-profile"},
+		}
+		distinctCommands := filterDuplicateCommands(args, subcommands)
+		latestCmd := args[len(args)-1]
+		return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+	case "suggest-splits":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "target"},
+		}
+		distinctCommands := filterDuplicateCommands(args, subcommands)
+		latestCmd := args[len(args)-1]
+		return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+	case "stats":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "start"},
+			{Text: "end"},
+			{Text: "prefix"},
+			{Text: "family"},
+			{Text: "versions"},
+			{Text: "columns"},
+			{Text: "qualifier-regex"},
+			{Text: "from"},
+			{Text: "to"},
+			{Text: "values"},
+			{Text: "app-profile"},
+		}
+		distinctCommands := filterDuplicateCommands(args, subcommands)
+		latestCmd := args[len(args)-1]
+		return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+	case "createtable":
+		// the new table isn't suggested
+		if len(args) > 2 {
+			subcommands := []prompt.Suggest{
+				{Text: "families"},
+				{Text: "splits"},
+				{Text: "splits-file"},
+			}
+			distinctCommands := filterDuplicateCommands(args, subcommands)
+			latestCmd := args[len(args)-1]
+			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+		}
+		return []prompt.Suggest{}
+	case "clone":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+		if len(args) > 3 {
+			subcommands := []prompt.Suggest{
+				{Text: "schema-only"},
+			}
+			distinctCommands := filterDuplicateCommands(args, subcommands)
+			latestCmd := args[len(args)-1]
+			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+		}
+	case "backuppolicy", "describe", "deletetable", "samplekeys", "link":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+	case "setprotection":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "enabled"},
+		}
+		distinctCommands := filterDuplicateCommands(args, subcommands)
+		latestCmd := args[len(args)-1]
+		return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+	case "setchangestream":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "retention"},
+			{Text: "disable"},
+		}
+		distinctCommands := filterDuplicateCommands(args, subcommands)
+		latestCmd := args[len(args)-1]
+		return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+	case "setbackuppolicy":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "retention"},
+			{Text: "frequency"},
+			{Text: "disable"},
+		}
+		distinctCommands := filterDuplicateCommands(args, subcommands)
+		latestCmd := args[len(args)-1]
+		return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
 	case "lookup":
 		if len(args) == 2 {
 			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
 		}
 
 		subcommands := []prompt.Suggest{
-			{Text: "version"},
+			{Text: "keys"},
+			{Text: "keys-file"},
+			{Text: "spec"},
+			{Text: "versions"},
+			{Text: "family"},
+			{Text: "columns"},
+			{Text: "qualifier-regex"},
+			{Text: "from"},
+			{Text: "to"},
+			{Text: "asof"},
+			{Text: "cells-per-row"},
+			{Text: "label"},
+			{Text: "app-profile"},
+			{Text: "priority"},
+			{Text: "qualifier-time"},
+			{Text: "pivot"},
+			{Text: "full-values"},
+			{Text: "fanout"},
+			{Text: "slow"},
+			{Text: "format"},
+			{Text: "template"},
+			{Text: "preset"},
+		}
+		if len(args) > 3 {
+			distinctCommands := filterDuplicateCommands(args, subcommands)
+			latestCmd := args[len(args)-1]
+			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+		}
+	case "tail":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "start"},
+			{Text: "end"},
+			{Text: "prefix"},
+			{Text: "family"},
+			{Text: "columns"},
+			{Text: "qualifier-regex"},
+			{Text: "value-regex"},
+			{Text: "interval"},
+			{Text: "count"},
+			{Text: "since"},
+		}
+		distinctCommands := filterDuplicateCommands(args, subcommands)
+		latestCmd := args[len(args)-1]
+		return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+	case "index":
+		if len(args) == 2 {
+			subcommands := []prompt.Suggest{
+				{Text: "build"},
+				{Text: "search"},
+				{Text: "bloom"},
+			}
+			return prompt.FilterHasPrefix(subcommands, second, true)
+		}
+		if len(args) == 3 && (args[1] == "build" || args[1] == "bloom") {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), args[2], true)
+		}
+		if len(args) > 4 && args[1] == "bloom" {
+			subcommands := []prompt.Suggest{
+				{Text: "fp-rate"},
+			}
+			distinctCommands := filterDuplicateCommands(args, subcommands)
+			latestCmd := args[len(args)-1]
+			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+		}
+		if len(args) > 3 && args[1] == "search" {
+			subcommands := []prompt.Suggest{
+				{Text: "table"},
+				{Text: "limit"},
+			}
+			distinctCommands := filterDuplicateCommands(args, subcommands)
+			latestCmd := args[len(args)-1]
+			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+		}
+	case "membership":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix([]prompt.Suggest{{Text: "check"}}, second, true)
+		}
+	case "history":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+		if len(args) > 3 {
+			subcommands := []prompt.Suggest{
+				{Text: "since"},
+				{Text: "full-values"},
+			}
+			distinctCommands := filterDuplicateCommands(args, subcommands)
+			latestCmd := args[len(args)-1]
+			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+		}
+	case "diff":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+		if len(args) > 3 {
+			subcommands := []prompt.Suggest{
+				{Text: "table2"},
+				{Text: "instance2"},
+				{Text: "versions"},
+				{Text: "family"},
+				{Text: "columns"},
+				{Text: "app-profile"},
+			}
+			distinctCommands := filterDuplicateCommands(args, subcommands)
+			latestCmd := args[len(args)-1]
+			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+		}
+	case "quorum-read":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+		if len(args) > 3 {
+			subcommands := []prompt.Suggest{
+				{Text: "profiles"},
+				{Text: "versions"},
+				{Text: "family"},
+				{Text: "columns"},
+			}
+			distinctCommands := filterDuplicateCommands(args, subcommands)
+			latestCmd := args[len(args)-1]
+			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+		}
+	case "exists-batch":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "keys"},
+			{Text: "keys-file"},
+			{Text: "format"},
+			{Text: "batch-size"},
+			{Text: "app-profile"},
+		}
+		distinctCommands := filterDuplicateCommands(args, subcommands)
+		latestCmd := args[len(args)-1]
+		return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+	case "grep":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+		if len(args) > 3 {
+			subcommands := []prompt.Suggest{
+				{Text: "start"},
+				{Text: "end"},
+				{Text: "prefix"},
+				{Text: "limit"},
+				{Text: "family"},
+				{Text: "versions"},
+				{Text: "columns"},
+				{Text: "qualifier-regex"},
+				{Text: "from"},
+				{Text: "to"},
+				{Text: "app-profile"},
+			}
+			distinctCommands := filterDuplicateCommands(args, subcommands)
+			latestCmd := args[len(args)-1]
+			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+		}
+	case "probe":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "interval"},
+			{Text: "slo-p99"},
+			{Text: "report"},
+			{Text: "violations"},
+			{Text: "count"},
+			{Text: "app-profile"},
+		}
+		if len(args) > 3 {
+			distinctCommands := filterDuplicateCommands(args, subcommands)
+			latestCmd := args[len(args)-1]
+			return prompt.FilterHasPrefix(distinctCommands, latestCmd, true)
+		}
+	case "watch-row":
+		if len(args) == 2 {
+			return prompt.FilterHasPrefix(c.getTableSuggestions(), second, true)
+		}
+
+		subcommands := []prompt.Suggest{
+			{Text: "interval"},
+			{Text: "count"},
+			{Text: "family"},
+			{Text: "columns"},
 		}
 		if len(args) > 3 {
 			distinctCommands := filterDuplicateCommands(args, subcommands)
@@ -58,8 +555,31 @@ func (c *Completer) completeWithArguments(args ...string) []prompt.Suggest {
 			{Text: "start"},
 			{Text: "end"},
 			{Text: "prefix"},
-			{Text: "version"},
+			{Text: "count"},
+			{Text: "offset"},
+			{Text: "versions"},
 			{Text: "family"},
+			{Text: "columns"},
+			{Text: "qualifier-regex"},
+			{Text: "value-regex"},
+			{Text: "sample"},
+			{Text: "from"},
+			{Text: "to"},
+			{Text: "asof"},
+			{Text: "cells-per-row"},
+			{Text: "label"},
+			{Text: "from-backup"},
+			{Text: "cluster"},
+			{Text: "page"},
+			{Text: "page-size"},
+			{Text: "app-profile"},
+			{Text: "priority"},
+			{Text: "qualifier-time"},
+			{Text: "pivot"},
+			{Text: "full-values"},
+			{Text: "format"},
+			{Text: "template"},
+			{Text: "preset"},
 		}
 		if len(args) > 2 {
 			distinctCommands := filterDuplicateCommands(args, subcommands)
@@ -88,8 +608,38 @@ func filterDuplicateCommands(args []string, subcommands []prompt.Suggest) []prom
 	return ret
 }
 
+// completeFamilies completes the values of family= and columns= by the families of the table,
+// ok is false for the other arguments
+func (c *Completer) completeFamilies(args []string) ([]prompt.Suggest, bool) {
+	switch args[0] {
+	case "read", "lookup", "export", "quorum-read":
+	default:
+		return nil, false
+	}
+	latest := args[len(args)-1]
+	if len(args) < 3 || !(strings.HasPrefix(latest, "family=") || strings.HasPrefix(latest, "columns=")) {
+		return nil, false
+	}
+	families, err := c.metadataInteractor.Families(context.Background(), args[1])
+	if err != nil {
+		return []prompt.Suggest{}, true
+	}
+
+	// the columns are separated by the commas
+	base := latest[:strings.LastIndexAny(latest, "=,")+1]
+	suffix := ""
+	if strings.HasPrefix(latest, "columns=") {
+		suffix = ":"
+	}
+	s := make([]prompt.Suggest, 0, len(families))
+	for _, f := range families {
+		s = append(s, prompt.Suggest{Text: base + f + suffix})
+	}
+	return prompt.FilterHasPrefix(s, latest, true), true
+}
+
 func (c *Completer) getTableSuggestions() []prompt.Suggest {
-	tbls, err := c.tableInteractor.GetTables(context.Background())
+	tbls, err := c.metadataInteractor.Tables(context.Background())
 	if err != nil {
 		return []prompt.Suggest{}
 	}
@@ -1,10 +1,19 @@
 package interfaces
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	prompt "github.com/c-bata/go-prompt"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+	"github.com/takashabe/btcli/api/infrastructure/cache"
 )
 
 func TestFilterDuplicateCommands(t *testing.T) {
@@ -32,3 +41,47 @@ func TestFilterDuplicateCommands(t *testing.T) {
 		assert.Equal(t, c.expect, actual)
 	}
 }
+
+func TestCompleteFamilies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	// the families are cached after the first completion
+	mockBtRepo.EXPECT().TableInfo(gomock.Any(), "users").Return(&domain.TableInfo{
+		Name:     "users",
+		Families: []*domain.Family{{Name: "d"}, {Name: "m"}},
+	}, nil).Times(1)
+	c := &Completer{
+		metadataInteractor: application.NewMetadataInteractor(mockBtRepo, cache.NewFileMetadataCache(filepath.Join(dir, "i.json"), time.Minute)),
+	}
+
+	cases := []struct {
+		args   []string
+		expect []prompt.Suggest
+	}{
+		{
+			[]string{"read", "users", "family="},
+			[]prompt.Suggest{{Text: "family=d"}, {Text: "family=m"}},
+		},
+		{
+			[]string{"lookup", "users", "1", "columns=d:name,m"},
+			[]prompt.Suggest{{Text: "columns=d:name,m:"}},
+		},
+	}
+	for i, cs := range cases {
+		actual, ok := c.completeFamilies(cs.args)
+		assert.True(t, ok, "#%d", i)
+		assert.Equal(t, cs.expect, actual, "#%d", i)
+	}
+
+	_, ok := c.completeFamilies([]string{"read", "users", "versions="})
+	assert.False(t, ok)
+	_, ok = c.completeFamilies([]string{"ls", "users", "family="})
+	assert.False(t, ok)
+}
@@ -0,0 +1,348 @@
+package interfaces
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/takashabe/btcli/api/config"
+)
+
+// daemonDialTimeout is a time the -e waits for the daemon before executing the command by itself
+const daemonDialTimeout = 100 * time.Millisecond
+
+// daemonRequestTimeout is a time the daemon waits for the request of a connection not to be blocked by the client
+const daemonRequestTimeout = 5 * time.Second
+
+// localCommands run until interrupted, they are executed by -e itself to be stopped by Ctrl-C
+var localCommands = []string{"watch-row", "tail", "probe"}
+
+// daemonRequest is a command of -e sent to the daemon with the settings of the client
+type daemonRequest struct {
+	Command string `json:"command"`
+	// Dir and Env are the working directory and the environment of the client, e.g. the relative files and env()
+	Dir string   `json:"dir,omitempty"`
+	Env []string `json:"env,omitempty"`
+	// Color is whether the output of the client is colored
+	Color bool `json:"color"`
+
+	// the flags of the client used instead of the daemon's
+	AppProfile      string            `json:"app_profile,omitempty"`
+	RequestTimeout  time.Duration     `json:"request_timeout,omitempty"`
+	PageSize        int               `json:"page_size,omitempty"`
+	ReadLimit       int               `json:"read_limit,omitempty"`
+	MaxValueBytes   int               `json:"max_value_bytes,omitempty"`
+	NumberFormat    string            `json:"number_format,omitempty"`
+	Format          string            `json:"format,omitempty"`
+	Template        string            `json:"template,omitempty"`
+	AutoDecode      string            `json:"autodecode,omitempty"`
+	Decoders        map[string]string `json:"decoders,omitempty"`
+	Locale          string            `json:"locale,omitempty"`
+	TimestampFormat string            `json:"timestamp_format,omitempty"`
+	UTC             bool              `json:"utc,omitempty"`
+	Verbose         bool              `json:"verbose,omitempty"`
+	Experimental    bool              `json:"experimental,omitempty"`
+}
+
+// newDaemonRequest returns the request of the command of -e with the directory, the environment and the flags
+func newDaemonRequest(conf *config.Config, color bool) daemonRequest {
+	dir, _ := os.Getwd()
+	locale := conf.Locale
+	if locale == "" {
+		locale = detectLocale()
+	}
+	return daemonRequest{
+		Command:         conf.Execute,
+		Dir:             dir,
+		Env:             os.Environ(),
+		Color:           color,
+		AppProfile:      conf.AppProfile,
+		RequestTimeout:  conf.RequestTimeout,
+		PageSize:        conf.PageSize,
+		ReadLimit:       conf.ReadLimit,
+		MaxValueBytes:   conf.MaxValueBytes,
+		NumberFormat:    conf.NumberFormat,
+		Format:          conf.Format,
+		Template:        conf.Template,
+		AutoDecode:      conf.AutoDecode,
+		Decoders:        conf.Decoders,
+		Locale:          locale,
+		TimestampFormat: conf.TimestampFormat,
+		UTC:             conf.UTC,
+		Verbose:         conf.Verbose,
+		Experimental:    conf.EnableExperimental,
+	}
+}
+
+// apply sets the flags of the client to the session
+func (req *daemonRequest) apply(session *Executor) error {
+	session.color = req.Color
+	session.appProfile = req.AppProfile
+	session.requestTimeout = req.RequestTimeout
+	session.pageSize = req.PageSize
+	session.readLimit = req.ReadLimit
+	session.maxValueBytes = req.MaxValueBytes
+	session.numberFormat = req.NumberFormat
+	session.format = req.Format
+	session.template = nil
+	if req.Template != "" {
+		tmpl, err := parseRowTemplate(req.Template)
+		if err != nil {
+			return fmt.Errorf("invalid template: %v", err)
+		}
+		session.template = tmpl
+	}
+	session.autoDecode = req.AutoDecode
+	session.decoders = req.Decoders
+	session.locale = req.Locale
+	session.timestampFormat = req.TimestampFormat
+	session.utc = req.UTC
+	session.verbose = req.Verbose
+	session.experimental = req.Experimental
+	return nil
+}
+
+// applyClientEnv changes the working directory and the environment of the process to the client's until restored,
+// the daemon serves the commands one by one so that they don't see the others
+func applyClientEnv(dir string, env []string) (func(), error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	saved := os.Environ()
+	if dir != "" {
+		if err := os.Chdir(dir); err != nil {
+			return nil, err
+		}
+	}
+	if env != nil {
+		setEnv(env)
+	}
+	return func() {
+		os.Chdir(wd)
+		if env != nil {
+			setEnv(saved)
+		}
+	}, nil
+}
+
+// setEnv replaces the environment by the "key=value" variables
+func setEnv(env []string) {
+	os.Clearenv()
+	for _, kv := range env {
+		// the variables of the drives on Windows start with "="
+		if i := strings.Index(kv, "="); i > 0 {
+			os.Setenv(kv[:i], kv[i+1:])
+		}
+	}
+}
+
+// daemonFrame is a chunk of the output of the command sent back to the client, the last frame has the exit code
+type daemonFrame struct {
+	Stream string `json:"stream,omitempty"`
+	Data   []byte `json:"data,omitempty"`
+	Exit   *int   `json:"exit,omitempty"`
+}
+
+// frameWriter writes the output of a stream as the frames
+type frameWriter struct {
+	enc    *json.Encoder
+	stream string
+}
+
+func (w *frameWriter) Write(p []byte) (int, error) {
+	if err := w.enc.Encode(daemonFrame{Stream: w.stream, Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// daemonSocket returns the unix socket of the daemon of the instance
+func daemonSocket(conf *config.Config) string {
+	return filepath.Join(config.HomeDir(), ".btcli", "daemon", conf.Project+"."+conf.Instance+".sock")
+}
+
+// runDaemon serves the commands of -e by the connection of the executor until interrupted
+func (c *CLI) runDaemon(executor *Executor, conf *config.Config) int {
+	path := daemonSocket(conf)
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		fmt.Fprintf(c.ErrStream, "the daemon of %s/%s is already running on %s\n", conf.Project, conf.Instance, path)
+		return ExitCodeError
+	}
+	if err := prepareSocketDir(filepath.Dir(path)); err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to start the daemon: %v\n", err)
+		return ExitCodeError
+	}
+	// the socket left by the daemon killed
+	os.Remove(path)
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to start the daemon: %v\n", err)
+		return ExitCodeError
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		fmt.Fprintf(c.ErrStream, "failed to start the daemon: %v\n", err)
+		return ExitCodeError
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		l.Close()
+	}()
+
+	fmt.Fprintf(c.ErrStream, "Serving %s/%s on %s\n", conf.Project, conf.Instance, path)
+	executor.serveDaemon(l)
+	return ExitCodeOK
+}
+
+// prepareSocketDir makes the directory of the socket accessible only by the user,
+// the directory made by another user can't be changed and fails
+func prepareSocketDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s isn't a directory", dir)
+	}
+	if err := os.Chmod(dir, 0700); err != nil {
+		return err
+	}
+	return nil
+}
+
+// serveDaemon executes the commands of the connections one by one until the listener is closed
+func (e *Executor) serveDaemon(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		e.serveDaemonConn(conn)
+	}
+}
+
+// serveDaemonConn executes the command of the connection in a session of its own,
+// the settings changed by the command aren't kept for the next commands
+func (e *Executor) serveDaemonConn(conn net.Conn) {
+	defer conn.Close()
+	// the commands of the other users aren't executed by the connection of the user
+	if uid, ok := peerUID(conn); ok && uid != os.Getuid() {
+		return
+	}
+	conn.SetReadDeadline(time.Now().Add(daemonRequestTimeout))
+	var req daemonRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	enc := json.NewEncoder(conn)
+	code := ExitCodeOK
+
+	// the requests of the command are cancelled when the client disconnects, e.g. by Ctrl-C
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		// the client sends nothing after the request
+		io.Copy(ioutil.Discard, conn)
+		cancel()
+	}()
+
+	session := *e
+	session.ctx = ctx
+	session.outStream = &frameWriter{enc: enc, stream: "out"}
+	session.errStream = &frameWriter{enc: enc, stream: "err"}
+	// the pager of the daemon can't be shown to the client
+	session.pagerOn = false
+	session.exit = func(c int) {
+		code = c
+	}
+	if err := req.apply(&session); err != nil {
+		fmt.Fprintf(session.errStream, "args parse error: %v\n", err)
+		code = ExitCodeParseError
+		enc.Encode(daemonFrame{Exit: &code})
+		return
+	}
+	restore, err := applyClientEnv(req.Dir, req.Env)
+	if err != nil {
+		fmt.Fprintf(session.errStream, "failed to apply the environment of the client: %v\n", err)
+		code = ExitCodeError
+		enc.Encode(daemonFrame{Exit: &code})
+		return
+	}
+	session.Do(req.Command)
+	restore()
+	if code == ExitCodeOK && session.failed {
+		code = ExitCodeError
+	}
+	enc.Encode(daemonFrame{Exit: &code})
+}
+
+// executeByDaemon executes the command by the daemon listening on the socket and returns the exit code,
+// ok is false if the daemon isn't running
+func executeByDaemon(path string, req daemonRequest, out, errOut io.Writer) (code int, ok bool) {
+	conn, err := net.DialTimeout("unix", path, daemonDialTimeout)
+	if err != nil {
+		return 0, false
+	}
+	defer conn.Close()
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return 0, false
+	}
+
+	dec := json.NewDecoder(conn)
+	for {
+		var f daemonFrame
+		if err := dec.Decode(&f); err != nil {
+			fmt.Fprintf(errOut, "lost the daemon on %s: %v\n", path, err)
+			return ExitCodeError, true
+		}
+		if f.Exit != nil {
+			return *f.Exit, true
+		}
+		switch f.Stream {
+		case "out":
+			out.Write(f.Data)
+		case "err":
+			errOut.Write(f.Data)
+		}
+	}
+}
+
+// runCommand executes the command of -e by the daemon if running, otherwise by the executor and returns the exit code
+func (c *CLI) runCommand(conf *config.Config, prepare func() *Executor) int {
+	color := !conf.NoColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+	if !containsString(localCommands, strings.SplitN(strings.TrimSpace(conf.Execute), " ", 2)[0]) {
+		req := newDaemonRequest(conf, color)
+		if code, ok := executeByDaemon(daemonSocket(conf), req, c.OutStream, c.ErrStream); ok {
+			return code
+		}
+	}
+
+	executor := prepare()
+	code := ExitCodeOK
+	executor.exit = func(c int) {
+		code = c
+	}
+	executor.Do(conf.Execute)
+	if code == ExitCodeOK && executor.failed {
+		code = ExitCodeError
+	}
+	return code
+}
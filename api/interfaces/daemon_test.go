@@ -0,0 +1,196 @@
+package interfaces
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/config"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestServeDaemon(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "daemon.sock")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	mockBtRepo.EXPECT().Tables(gomock.Any()).Return([]string{"a", "b"}, nil).Times(4)
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	var daemonOut, daemonErr bytes.Buffer
+	executor := &Executor{
+		outStream:       &daemonOut,
+		errStream:       &daemonErr,
+		tableInteractor: application.NewTableInteractor(mockBtRepo),
+		rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+	}
+	done := make(chan struct{})
+	go func() {
+		executor.serveDaemon(l)
+		close(done)
+	}()
+
+	cases := []struct {
+		req        daemonRequest
+		expect     string
+		expectErr  string
+		expectCode int
+	}{
+		{daemonRequest{Command: "ls"}, "a\nb\n", "", ExitCodeOK},
+		{daemonRequest{Command: "set dryrun on"}, "", "dryrun: on\n", ExitCodeOK},
+		// the setting of the previous command isn't kept
+		{daemonRequest{Command: "set"}, "dryrun: off\ncolor: off\npager: off\nutc: off\nverbose: off\ntimestamp-format: default\n", "", ExitCodeOK},
+		{daemonRequest{Command: "ls"}, "a\nb\n", "", ExitCodeOK},
+		{daemonRequest{Command: "foo"}, "", "Unknown command: foo\n", ExitCodeError},
+		// the flags of the client
+		{daemonRequest{Command: "ls", Format: "json"}, "[\"a\",\"b\"]\n", "", ExitCodeOK},
+		{daemonRequest{Command: "set", UTC: true, Verbose: true}, "dryrun: off\ncolor: off\npager: off\nutc: on\nverbose: on\ntimestamp-format: default\n", "", ExitCodeOK},
+		{daemonRequest{Command: "ls", Template: "{{"}, "", "args parse error: invalid template: template: row:1: unclosed action\n", ExitCodeParseError},
+		// the relative file is in the directory of the client
+		{daemonRequest{Command: "ls > tables.txt", Dir: dir}, "", "", ExitCodeOK},
+	}
+	for i, c := range cases {
+		var out, errOut bytes.Buffer
+		code, ok := executeByDaemon(path, c.req, &out, &errOut)
+		assert.True(t, ok, "#%d", i)
+		assert.Equal(t, c.expectCode, code, "#%d", i)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+	}
+	assert.Equal(t, "", daemonOut.String())
+	assert.Equal(t, "", daemonErr.String())
+	data, err := ioutil.ReadFile(filepath.Join(dir, "tables.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a\nb\n", string(data))
+
+	l.Close()
+	<-done
+	_, ok := executeByDaemon(path, daemonRequest{Command: "ls"}, ioutil.Discard, ioutil.Discard)
+	assert.False(t, ok)
+}
+
+func TestApplyClientEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	dir, _ = filepath.EvalSymlinks(dir)
+	wd, _ := os.Getwd()
+	os.Setenv("BTCLI_DAEMON", "daemon")
+	defer os.Unsetenv("BTCLI_DAEMON")
+
+	restore, err := applyClientEnv(dir, []string{"BTCLI_CLIENT=a=b"})
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	got, _ := os.Getwd()
+	assert.Equal(t, dir, got)
+	assert.Equal(t, "a=b", os.Getenv("BTCLI_CLIENT"))
+	_, ok := os.LookupEnv("BTCLI_DAEMON")
+	assert.False(t, ok)
+
+	restore()
+	got, _ = os.Getwd()
+	assert.Equal(t, wd, got)
+	assert.Equal(t, "daemon", os.Getenv("BTCLI_DAEMON"))
+	_, ok = os.LookupEnv("BTCLI_CLIENT")
+	assert.False(t, ok)
+
+	_, err = applyClientEnv(filepath.Join(dir, "missing"), nil)
+	assert.Error(t, err)
+}
+
+func TestRunCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", dir)
+	defer os.Setenv("HOME", home)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	mockBtRepo.EXPECT().Tables(gomock.Any()).Return([]string{"a", "b"}, nil).Times(2)
+	newExecutor := func(out, errOut *bytes.Buffer) *Executor {
+		return &Executor{
+			outStream:       out,
+			errStream:       errOut,
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+		}
+	}
+	run := func(conf *config.Config) (code int, prepared bool, out, errOut string) {
+		var o, e bytes.Buffer
+		c := &CLI{OutStream: &o, ErrStream: &e}
+		code = c.runCommand(conf, func() *Executor {
+			prepared = true
+			return newExecutor(&o, &e)
+		})
+		return code, prepared, o.String(), e.String()
+	}
+
+	// executed by itself without the daemon
+	conf := &config.Config{Project: "p", Instance: "i", Execute: "ls"}
+	code, prepared, out, _ := run(conf)
+	assert.Equal(t, ExitCodeOK, code)
+	assert.True(t, prepared)
+	assert.Equal(t, "a\nb\n", out)
+
+	path := daemonSocket(conf)
+	if err := prepareSocketDir(filepath.Dir(path)); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	fi, err := os.Stat(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), fi.Mode().Perm())
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	var daemonOut, daemonErr bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		newExecutor(&daemonOut, &daemonErr).serveDaemon(l)
+		close(done)
+	}()
+
+	// executed by the daemon with the flags of the client
+	conf.Format = "json"
+	code, prepared, out, _ = run(conf)
+	assert.Equal(t, ExitCodeOK, code)
+	assert.False(t, prepared)
+	assert.Equal(t, "[\"a\",\"b\"]\n", out)
+
+	conf.Execute = "foo"
+	code, prepared, _, errOut := run(conf)
+	assert.Equal(t, ExitCodeError, code)
+	assert.False(t, prepared)
+	assert.Equal(t, "Unknown command: foo\n", errOut)
+
+	// the commands running until interrupted are executed by itself
+	conf.Execute = "tail"
+	_, prepared, _, _ = run(conf)
+	assert.True(t, prepared)
+
+	l.Close()
+	<-done
+}
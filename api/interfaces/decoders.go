@@ -0,0 +1,77 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/takashabe/btcli/api/config"
+)
+
+// doDecode shows or changes the decodes of the columns used unless the decode options are given
+func doDecode(ctx context.Context, e *Executor, args ...string) {
+	if len(args) == 1 {
+		columns := make([]string, 0, len(e.decoders))
+		for column := range e.decoders {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		for _, column := range columns {
+			fmt.Fprintf(e.outStream, "%s=%s\n", column, e.decoders[column])
+		}
+		return
+	}
+
+	decoders, err := config.ParseDecoders(strings.Join(args[1:], ","))
+	if err != nil {
+		e.failf(e.msg("Invalid args: %v\n"), err)
+		return
+	}
+	columns := make([]string, 0, len(decoders))
+	for column := range decoders {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	if e.decoders == nil {
+		e.decoders = map[string]string{}
+	}
+	for _, column := range columns {
+		decode := decoders[column]
+		if decode == "" {
+			delete(e.decoders, column)
+			fmt.Fprintf(e.errStream, "Removed the decoder of %s\n", column)
+			continue
+		}
+		e.decoders[column] = decode
+		fmt.Fprintf(e.errStream, "Decode %s as %s\n", column, decode)
+	}
+}
+
+// doAutoDecode shows or changes the types guessed by the values without the decodes
+func doAutoDecode(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 2 {
+		autoDecode := e.autoDecode
+		if autoDecode == "" {
+			autoDecode = "all"
+		}
+		fmt.Fprintln(e.outStream, autoDecode)
+		return
+	}
+	for _, a := range config.AutoDecodes {
+		if args[1] == a {
+			e.autoDecode = a
+			fmt.Fprintf(e.errStream, "Guess %s of the values without the decodes\n", autoDecodeDescriptions[a])
+			return
+		}
+	}
+	e.failf("Unknown autodecode: %s, must be one of %s\n", args[1], strings.Join(config.AutoDecodes, ", "))
+}
+
+// autoDecodeDescriptions are the guesses of the autodecode types printed by the autodecode command
+var autoDecodeDescriptions = map[string]string{
+	"off":   "no numbers",
+	"int":   "the ints",
+	"float": "the floats",
+	"all":   "the ints and the floats",
+}
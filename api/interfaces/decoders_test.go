@@ -0,0 +1,53 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoDecode(t *testing.T) {
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream: &out,
+		errStream: &errOut,
+		decoders:  map[string]string{"d:count": "int"},
+	}
+
+	cases := []struct {
+		input     string
+		expectOut string
+		expectErr string
+	}{
+		{"decode", "d:count=int\n", ""},
+		{"decode d:score=float64 d:payload=proto:my.Msg", "", "Decode d:payload as proto:my.Msg\nDecode d:score as float\n"},
+		{"decode", "d:count=int\nd:payload=proto:my.Msg\nd:score=float\n", ""},
+		{"decode d:count=", "", "Removed the decoder of d:count\n"},
+		{"decode d:score=uint", "", "Invalid args: unknown decode \"uint\" of d:score, must be one of string, int, float, hex, base64, json, proto:<message>, avro:<schema.json>\n"},
+		{"decode score=int", "", "Invalid args: invalid decoder \"score=int\", must be <family:qualifier>=<type>\n"},
+		{"decode", "d:payload=proto:my.Msg\nd:score=float\n", ""},
+	}
+	for i, c := range cases {
+		out.Reset()
+		errOut.Reset()
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+	}
+}
+
+func TestDoAutoDecode(t *testing.T) {
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream: &out,
+		errStream: &errOut,
+	}
+	executor.Do("autodecode")
+	executor.Do("autodecode off")
+	executor.Do("autodecode")
+	executor.Do("autodecode string")
+	assert.Equal(t, "all\noff\n", out.String())
+	assert.Equal(t, "Guess no numbers of the values without the decodes\nUnknown autodecode: string, must be one of off, int, float, all\n", errOut.String())
+	assert.Equal(t, "off", executor.newPrinter(map[string]string{}).autoDecode)
+}
@@ -0,0 +1,177 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+)
+
+func doDiff(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 4 {
+		e.failf(e.msg("Invalid args: %v\n"), "diff <table> <row1> <row2>|<row> table2=<table>|instance2=<instance> [versions=all|<n>] [family=<regex>] [columns=<family:qualifier>,...]")
+		return
+	}
+	table := args[1]
+	// compare the same row in another table when the second row isn't given
+	key1, key2 := args[2], args[3]
+	rest := args[4:]
+	if strings.Contains(key2, "=") {
+		key2 = key1
+		rest = args[3:]
+	}
+
+	parsed := make(map[string]string)
+	for _, arg := range rest {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "decode", "decode_columns":
+			parsed[k] = v
+		case "app-profile":
+			parsed[k] = v
+		case "table2", "instance2":
+			parsed[k] = v
+		case "version", "versions", "family", "columns":
+			parsed[k] = v
+		}
+	}
+	table2, instance2 := parsed["table2"], parsed["instance2"]
+	if table2 == "" {
+		table2 = table
+	}
+	if instance2 == e.instance {
+		instance2 = ""
+	}
+	if key1 == key2 && table2 == table && instance2 == "" {
+		e.failln(`the second row, "table2" or "instance2" is required`)
+		return
+	}
+	if err := validatePrinterOption(parsed); err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+	defaultVersions(parsed)
+	ro, err := readOption(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+
+	ctx = e.requestContext(parsed)
+	row1, err := e.rowsInteractor.GetRow(ctx, table, key1, ro...)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	rows2 := e.rowsInteractor
+	label1, label2 := table+"/"+escapeKey(key1), table2+"/"+escapeKey(key2)
+	if instance2 != "" {
+		rows2, err = e.instanceRowsInteractor(instance2)
+		if err != nil {
+			e.printError(err)
+			return
+		}
+		label1, label2 = e.instance+"/"+label1, instance2+"/"+label2
+	}
+	row2, err := rows2.GetRow(ctx, table2, key2, ro...)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+
+	p := e.newPrinter(parsed)
+	n := p.printCellDiff(label1, label2, row1, row2)
+	if n == 0 {
+		fmt.Fprintln(e.errStream, "No differences")
+	}
+}
+
+// instanceRowsInteractor returns the RowsInteractor connecting to another instance of the project
+func (e *Executor) instanceRowsInteractor(instance string) (*application.RowsInteractor, error) {
+	if r, ok := e.instanceRows[instance]; ok {
+		return r, nil
+	}
+	if e.openInstance == nil {
+		return nil, fmt.Errorf("can't connect to the instance %s", instance)
+	}
+	r, err := e.openInstance(instance)
+	if err != nil {
+		return nil, err
+	}
+	if e.instanceRows == nil {
+		e.instanceRows = map[string]*application.RowsInteractor{}
+	}
+	e.instanceRows[instance] = r
+	return r, nil
+}
+
+// printCellDiff prints the cells of the rows in the unified diff format,
+// the cells only in the row a are prefixed by "-" and the cells only in the row b by "+".
+// returns a number of the different cells
+func (w *Printer) printCellDiff(labelA, labelB string, a, b *domain.Row) int {
+	cellsA, cellsB := groupByQualifier(a), groupByQualifier(b)
+	qualifiers := make([]string, 0, len(cellsA)+len(cellsB))
+	for q := range cellsA {
+		qualifiers = append(qualifiers, q)
+	}
+	for q := range cellsB {
+		if _, ok := cellsA[q]; !ok {
+			qualifiers = append(qualifiers, q)
+		}
+	}
+	sort.Strings(qualifiers)
+
+	fmt.Fprintf(w.outStream, "--- %s\n", labelA)
+	fmt.Fprintf(w.outStream, "+++ %s\n", labelB)
+	diffs := 0
+	for _, q := range qualifiers {
+		// cells of the qualifier are ordered by the newest version
+		ca, cb := cellsA[q], cellsB[q]
+		for len(ca) > 0 || len(cb) > 0 {
+			switch {
+			case len(cb) == 0 || len(ca) > 0 && ca[0].Version.After(cb[0].Version):
+				w.printDiffCell("-", ca[0])
+				ca = ca[1:]
+				diffs++
+			case len(ca) == 0 || cb[0].Version.After(ca[0].Version):
+				w.printDiffCell("+", cb[0])
+				cb = cb[1:]
+				diffs++
+			case bytes.Equal(ca[0].Value, cb[0].Value):
+				w.printDiffCell(" ", ca[0])
+				ca, cb = ca[1:], cb[1:]
+			default:
+				w.printDiffCell("-", ca[0])
+				w.printDiffCell("+", cb[0])
+				ca, cb = ca[1:], cb[1:]
+				diffs++
+			}
+		}
+	}
+	return diffs
+}
+
+func (w *Printer) printDiffCell(mark string, c *domain.Column) {
+	fmt.Fprintf(w.outStream, "%s %-40s @ %s\n", mark, c.Qualifier, w.timestamps.format(c.Version))
+	fmt.Fprintf(w.outStream, "%s   %s\n", mark, w.formatValue(c.Qualifier, c.Value))
+}
+
+func groupByQualifier(r *domain.Row) map[string][]*domain.Column {
+	cells := map[string][]*domain.Column{}
+	for _, c := range r.Columns {
+		cells[c.Qualifier] = append(cells[c.Qualifier], c)
+	}
+	return cells
+}
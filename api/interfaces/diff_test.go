@@ -0,0 +1,181 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoDiff(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"diff table a b decode=string",
+			"--- table/a\n" +
+				"+++ table/b\n" +
+				"- d:only-a                                 @ 2018/01/01-00:00:00.000000\n" +
+				"-   \"x\"\n" +
+				"+ d:only-b                                 @ 2018/01/01-00:00:00.000000\n" +
+				"+   \"y\"\n" +
+				"  d:same                                   @ 2018/01/01-00:00:00.000000\n" +
+				"    \"v\"\n" +
+				"- d:value                                  @ 2018/01/01-00:00:00.000000\n" +
+				"-   \"1\"\n" +
+				"+ d:value                                  @ 2018/01/01-00:00:00.000000\n" +
+				"+   \"2\"\n" +
+				"- d:version                                @ 2018/01/01-00:00:01.000000\n" +
+				"-   \"t\"\n" +
+				"+ d:version                                @ 2018/01/01-00:00:00.000000\n" +
+				"+   \"t\"\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Get(gomock.Any(), "table", "a", latest).Return(&domain.Bigtable{
+					Rows: []*domain.Row{{Key: "a", Columns: []*domain.Column{
+						{Family: "d", Qualifier: "d:same", Value: []byte("v"), Version: tm},
+						{Family: "d", Qualifier: "d:only-a", Value: []byte("x"), Version: tm},
+						{Family: "d", Qualifier: "d:value", Value: []byte("1"), Version: tm},
+						{Family: "d", Qualifier: "d:version", Value: []byte("t"), Version: tm.Add(time.Second)},
+					}}},
+				}, nil)
+				mock.EXPECT().Get(gomock.Any(), "table", "b", latest).Return(&domain.Bigtable{
+					Rows: []*domain.Row{{Key: "b", Columns: []*domain.Column{
+						{Family: "d", Qualifier: "d:same", Value: []byte("v"), Version: tm},
+						{Family: "d", Qualifier: "d:only-b", Value: []byte("y"), Version: tm},
+						{Family: "d", Qualifier: "d:value", Value: []byte("2"), Version: tm},
+						{Family: "d", Qualifier: "d:version", Value: []byte("t"), Version: tm},
+					}}},
+				}, nil)
+			},
+		},
+		{
+			"diff table a b decode=string",
+			"--- table/a\n" +
+				"+++ table/b\n" +
+				"  d:row                                    @ 2018/01/01-00:00:00.000000\n" +
+				"    \"v\"\n" +
+				"No differences\n",
+			func(mock *repository.MockBigtable) {
+				row := &domain.Row{Columns: []*domain.Column{
+					{Family: "d", Qualifier: "d:row", Value: []byte("v"), Version: tm},
+				}}
+				mock.EXPECT().Get(gomock.Any(), "table", gomock.Any(), latest).Return(&domain.Bigtable{
+					Rows: []*domain.Row{row},
+				}, nil).Times(2)
+			},
+		},
+		{
+			"diff table a",
+			"Invalid args: diff <table> <row1> <row2>|<row> table2=<table>|instance2=<instance> [versions=all|<n>] [family=<regex>] [columns=<family:qualifier>,...]\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"diff table a table2=copy decode=string",
+			"--- table/a\n" +
+				"+++ copy/a\n" +
+				"- d:row                                    @ 2018/01/01-00:00:00.000000\n" +
+				"-   \"v\"\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Get(gomock.Any(), "table", "a", latest).Return(&domain.Bigtable{
+					Rows: []*domain.Row{{Key: "a", Columns: []*domain.Column{
+						{Family: "d", Qualifier: "d:row", Value: []byte("v"), Version: tm},
+					}}},
+				}, nil)
+				mock.EXPECT().Get(gomock.Any(), "copy", "a", latest).Return(&domain.Bigtable{
+					Rows: []*domain.Row{{}},
+				}, nil)
+			},
+		},
+		{
+			"diff table a instance=1",
+			"Unknown arg: instance=1\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"diff table a versions=1",
+			"the second row, \"table2\" or \"instance2\" is required\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"diff table a b unknown=1",
+			"Unknown arg: unknown=1\n",
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		defer ctrl.Finish()
+
+		c.prepare(mockBtRepo)
+
+		var buf bytes.Buffer
+		executor := Executor{
+			outStream:      &buf,
+			errStream:      &buf,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		}
+
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, buf.String(), c.input)
+	}
+}
+
+func TestDoDiffInstance(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	mockBtRepo.EXPECT().Get(gomock.Any(), "table", "a", latest).Return(&domain.Bigtable{
+		Rows: []*domain.Row{{Key: "a", Columns: []*domain.Column{
+			{Family: "d", Qualifier: "d:row", Value: []byte("v"), Version: tm},
+		}}},
+	}, nil).Times(2)
+	otherRepo := repository.NewMockBigtable(ctrl)
+	otherRepo.EXPECT().Get(gomock.Any(), "table", "a", latest).Return(&domain.Bigtable{
+		Rows: []*domain.Row{{Key: "a", Columns: []*domain.Column{
+			{Family: "d", Qualifier: "d:row", Value: []byte("w"), Version: tm},
+		}}},
+	}, nil).Times(2)
+
+	opened := 0
+	var buf bytes.Buffer
+	executor := Executor{
+		outStream:      &buf,
+		errStream:      &buf,
+		instance:       "prod",
+		rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		openInstance: func(instance string) (*application.RowsInteractor, error) {
+			assert.Equal(t, "staging", instance)
+			opened++
+			return application.NewRowsInteractor(otherRepo), nil
+		},
+	}
+
+	expect := "--- prod/table/a\n" +
+		"+++ staging/table/a\n" +
+		"- d:row                                    @ 2018/01/01-00:00:00.000000\n" +
+		"-   \"v\"\n" +
+		"+ d:row                                    @ 2018/01/01-00:00:00.000000\n" +
+		"+   \"w\"\n"
+	executor.Do("diff table a instance2=staging decode=string")
+	assert.Equal(t, expect, buf.String())
+
+	// the connection is reused
+	buf.Reset()
+	executor.Do("diff table a instance2=staging decode=string")
+	assert.Equal(t, expect, buf.String())
+	assert.Equal(t, 1, opened)
+}
@@ -2,27 +2,44 @@ package interfaces
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
 	"cloud.google.com/go/bigtable"
 	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/config"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
 // Avoid to circular dependencies
 var (
-	doHelpFn func(context.Context, *Executor, ...string)
+	doHelpFn  func(context.Context, *Executor, ...string)
+	doAgainFn func(context.Context, *Executor, ...string)
 )
 
 func doHelp(ctx context.Context, e *Executor, args ...string) {
 	doHelpFn(ctx, e, args...)
 }
 
+func doAgain(ctx context.Context, e *Executor, args ...string) {
+	doAgainFn(ctx, e, args...)
+}
+
 func init() {
 	doHelpFn = lazyDoHelp
+	doAgainFn = lazyDoAgain
 }
 
 // Executor provides exec command handler
@@ -30,8 +47,112 @@ type Executor struct {
 	outStream io.Writer
 	errStream io.Writer
 
+	// connected project and instance
+	project  string
+	instance string
+	// appProfile is the default app profile of the requests
+	appProfile string
+	// priorityProfiles are the app profiles of the priority option found at the first use
+	priorityProfiles map[string]string
+
 	tableInteractor *application.TableInteractor
 	rowsInteractor  *application.RowsInteractor
+
+	connectionInteractor *application.ConnectionInteractor
+	backupInteractor     *application.BackupInteractor
+	indexInteractor      *application.IndexInteractor
+	// metadataInteractor is shared with the completer, nil in the tests
+	metadataInteractor *application.MetadataInteractor
+
+	// openInstance connects to another instance of the project, the connections are kept in instanceRows
+	openInstance func(instance string) (*application.RowsInteractor, error)
+	instanceRows map[string]*application.RowsInteractor
+
+	// requestTimeout gives up each point request to bigtable after the duration, 0 waits for the response
+	requestTimeout time.Duration
+	// ctx is the parent of the requests cancelled when the client of the daemon disconnects, nil is Background
+	ctx context.Context
+
+	// idle session lock for the write commands
+	idleTimeout time.Duration
+	lastActive  time.Time
+	locked      bool
+
+	// pagination of the read command, 0 pageSize reads all rows at once
+	// interactive paginates the read in the terminal of the interactive shell by default, see pageOption
+	interactive bool
+	pageSize    int
+	pager       *pager
+	// readLimit is a number of rows read at most by the unpaginated read without count, 0 reads all rows
+	readLimit int
+	// maxValueBytes is a number of bytes of a value printed at most by the text output, 0 prints all bytes
+	maxValueBytes int
+
+	// numberFormat is a thousands separator of the counts, see config.NumberFormats
+	numberFormat string
+	// format is an output format of the rows, see config.Formats
+	format string
+	// template writes each row of the template format, nil unless given by -template or the format command
+	template *template.Template
+	// transforms are the pipelines of config.Transforms applied by the printers
+	transforms []*columnTransform
+	// decoders are the decodes of the columns, config.Decoders changed by the decode command
+	decoders map[string]string
+	// presets are the options of read and lookup saved by the preset command, written to presetFile if given
+	presets      map[string]map[string]string
+	presetFile   string
+	activePreset string
+
+	// color paints the rows of the text format, see isTerminal and config.NoColor
+	color bool
+
+	// autoDecode is the types guessed by the values, config.AutoDecode changed by the autodecode command
+	autoDecode string
+
+	// pagerOn pipes the output of the paged commands through pagerCommand, toggled by "set pager"
+	pagerOn      bool
+	pagerCommand string
+	// paged is set while the output is piped through the pager, the separators of the rows are piped with the rows
+	paged bool
+	// numberRows numbers the rows of the interactive shell, and lastRows are the numbered rows printed last for show
+	numberRows bool
+	lastRows   []*domain.Row
+
+	// redirected is true while the output is written to the file of the redirection
+	redirected bool
+
+	// locale is the language of the messages in the catalogs, empty is English
+	locale string
+
+	// timestampFormat and utc print the timestamps, config.TimestampFormat and config.UTC changed by set
+	timestampFormat string
+	utc             bool
+	// verbose prints the metadata of the cells, config.Verbose changed by set
+	verbose bool
+	// experimental enables the experimental commands, config.EnableExperimental
+	experimental bool
+	// protoFiles are the descriptors of config.ProtoDescriptors, nil if not given
+	protoFiles *protoregistry.Files
+
+	// exit exits the process, replaced in the tests
+	exit func(code int)
+
+	// explain prints the requests of read and lookup instead of sending them
+	explain bool
+	// dryRun prints the write commands instead of executing them, toggled by "set dryrun"
+	dryRun bool
+
+	// lastArgs is the previous command re-executed by the again command
+	lastArgs []string
+	// line is the command being executed as it's written
+	line string
+	// failed tells the command printed an error, the script stops and -e exits with ExitCodeError
+	failed bool
+
+	// variables are the values read by let and expanded as $name in the commands
+	variables map[string]string
+	// capture receives the rows of let instead of printing them, nil prints the rows
+	capture func(*Printer, *domain.Row)
 }
 
 // Do provides execute command
@@ -41,22 +162,167 @@ func (e *Executor) Do(s string) {
 		return
 	}
 
-	ctx := context.Background()
-	args := strings.Split(s, " ")
+	ctx := e.requestContext(nil)
+	e.line, e.failed = s, false
+	tokens, err := tokenizeCommand(s)
+	if err != nil {
+		e.failf(e.msg("Invalid args: %v\n"), err)
+		return
+	}
+	// the redirection is parsed before the expansions not to be given by the values
+	tokens, r, err := parseRedirection(tokens, e.variables)
+	if err != nil {
+		e.failf(e.msg("Invalid redirection: %v\n"), err)
+		return
+	}
+	tokens, err = expandExprs(tokens, e.variables)
+	if err != nil {
+		e.failf(e.msg("Invalid expression: %v\n"), err)
+		return
+	}
+	args, err := expandVariables(tokens, e.variables)
+	if err != nil {
+		e.failf("Invalid variable: %v\n", err)
+		return
+	}
+	if r != nil {
+		restore, err := e.redirect(r)
+		if err != nil {
+			e.printError(err)
+			return
+		}
+		defer restore()
+	}
 	cmd := args[0]
 
 	for _, c := range commands {
 		if cmd == c.Name {
+			if c.Experimental && !e.experimental {
+				e.failf(e.msg("%s is experimental, run btcli with -enable-experimental to use it\n"), c.Name)
+				return
+			}
+			if c.Deprecated != "" {
+				fmt.Fprintf(e.errStream, e.msg("Deprecated: %s will be removed, use \"%s\" instead\n"), c.Name, c.Deprecated)
+			}
+			if c.ReadOnlyArgs != nil && c.ReadOnlyArgs(args) {
+				c.Write = false
+			}
+			if c.WriteArgs != nil && c.WriteArgs(args) {
+				c.Write = true
+			}
+			if e.checkLock(c) {
+				e.failf(e.msg("Session is locked after being idle for %s, run \"unlock\" to continue\n"), e.idleTimeout)
+				return
+			}
+			if c.Name != "again" {
+				e.lastArgs = args
+			}
+			if e.dryRun && c.Write {
+				e.printDryRun(c, args)
+				return
+			}
+			if e.pagerOn && c.Paged && !e.redirected {
+				e.runPaged(ctx, c, args)
+				return
+			}
 			// TODO: extract args[0]
 			c.Runner(ctx, e, args...)
 			return
 		}
 	}
-	fmt.Fprintf(e.errStream, "Unknown command: %s\n", cmd)
+	e.failf(e.msg("Unknown command: %s\n"), cmd)
+}
+
+// clearMetadata drops the cached metadata of the completion after the tables are changed
+func (e *Executor) clearMetadata() {
+	if e.metadataInteractor == nil {
+		return
+	}
+	if err := e.metadataInteractor.Clear(); err != nil {
+		e.failf("Failed to clear the completion cache: %v\n", err)
+	}
+}
+
+// checkLock reports whether the command is rejected by the idle session lock
+func (e *Executor) checkLock(c Command) bool {
+	now := time.Now()
+	if e.idleTimeout > 0 && !e.lastActive.IsZero() && now.Sub(e.lastActive) > e.idleTimeout {
+		e.locked = true
+	}
+	e.lastActive = now
+
+	return e.locked && c.Write
+}
+
+func lazyDoAgain(ctx context.Context, e *Executor, args ...string) {
+	if e.lastArgs == nil {
+		fmt.Fprintln(e.errStream, e.msg("No previous command"))
+		return
+	}
+	merged, err := mergeArgs(e.lastArgs, args[1:])
+	if err != nil {
+		e.failf(e.msg("Invalid args: %v\n"), err)
+		return
+	}
+	cmd := joinCommand(merged)
+	fmt.Fprintln(e.errStream, cmd)
+	e.Do(cmd)
+}
+
+// mergeArgs returns the args with the options overridden by the same keys, or appended
+func mergeArgs(args, options []string) ([]string, error) {
+	merged := make([]string, len(args))
+	copy(merged, args)
+	for _, o := range options {
+		key := optionName(o)
+		if key == "" {
+			return nil, fmt.Errorf("%v", o)
+		}
+
+		replaced := false
+		for j, arg := range merged {
+			if j > 0 && optionName(arg) == key {
+				merged[j] = o
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, o)
+		}
+	}
+	return merged, nil
+}
+
+// optionAliases are the other names of the options
+var optionAliases = map[string]string{"version": "versions"}
+
+// optionName returns the name of the option given as "<name>=<value>" by the name of the alias, empty unless an option
+func optionName(arg string) string {
+	i := strings.Index(arg, "=")
+	if i <= 0 {
+		return ""
+	}
+	if name, ok := optionAliases[arg[:i]]; ok {
+		return name
+	}
+	return arg[:i]
+}
+
+func doReset(ctx context.Context, e *Executor, args ...string) {
+	if !e.connectionInteractor.ResetCircuit() {
+		fmt.Fprintln(e.errStream, "Circuit breaker is not enabled")
+		return
+	}
+	fmt.Fprintln(e.errStream, "Reset the connection")
+}
+
+func doUnlock(ctx context.Context, e *Executor, args ...string) {
+	e.locked = false
+	fmt.Fprintln(e.errStream, "Unlocked")
 }
 
 func doExit(ctx context.Context, e *Executor, args ...string) {
-	fmt.Fprintln(e.outStream, "Bye!")
+	fmt.Fprintln(e.errStream, "Bye!")
 	os.Exit(0)
 }
 
@@ -69,16 +335,26 @@ func lazyDoHelp(ctx context.Context, e *Executor, args ...string) {
 	for _, c := range commands {
 		if c.Name == cmd {
 			fmt.Fprintln(e.outStream, c.Usage)
+			if c.Experimental {
+				fmt.Fprintln(e.outStream, e.msg("Experimental, enabled by -enable-experimental"))
+			}
+			if c.Deprecated != "" {
+				fmt.Fprintf(e.outStream, e.msg("Deprecated, use \"%s\" instead\n"), c.Deprecated)
+			}
 			return
 		}
 	}
-	fmt.Fprintf(e.errStream, "Unknown command: %s\n", cmd)
+	e.failf(e.msg("Unknown command: %s\n"), cmd)
 }
 
 func doLS(ctx context.Context, e *Executor, args ...string) {
 	tables, err := e.tableInteractor.GetTables(ctx)
 	if err != nil {
-		fmt.Fprintf(e.errStream, "%v", err)
+		e.printError(err)
+		return
+	}
+	if f, ok := rowFormatters[e.format]; ok {
+		f.writeNames(e.outStream, tables)
 		return
 	}
 	for _, tbl := range tables {
@@ -88,145 +364,680 @@ func doLS(ctx context.Context, e *Executor, args ...string) {
 
 func doCount(ctx context.Context, e *Executor, args ...string) {
 	if len(args) < 2 {
-		fmt.Fprintln(e.errStream, "Invalid args: count <table>")
+		e.failf(e.msg("Invalid args: %v\n"), "count <table> [prefix=<prefix>] [start=<row>] [end=<row>]")
+		return
+	}
+	table := args[1]
+
+	parsed := make(map[string]string)
+	for _, arg := range args[2:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "start", "end", "prefix":
+			parsed[k] = v
+		}
+	}
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		e.failln(`"start"/"end" may not be mixed with "prefix"`)
+		return
+	}
+
+	var rs bigtable.RowSet = bigtable.InfiniteRange("")
+	if len(parsed) > 0 {
+		rr, err := rowRange(parsed)
+		if err != nil {
+			e.failf(e.msg("Invalid range: %v\n"), err)
+			return
+		}
+		rs = rr
+	}
+	cnt, err := e.rowsInteractor.GetRowCount(ctx, table, rs)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	fmt.Fprintln(e.outStream, e.formatNumber(int64(cnt)))
+}
+
+func doSampleKeys(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 2 {
+		e.failf(e.msg("Invalid args: %v\n"), "samplekeys <table>")
 		return
 	}
 	table := args[1]
-	cnt, err := e.rowsInteractor.GetRowCount(ctx, table)
+
+	samples, err := e.rowsInteractor.SampleKeys(ctx, table)
 	if err != nil {
-		fmt.Fprintf(e.errStream, "%v", err)
+		e.printError(err)
 		return
 	}
-	fmt.Fprintln(e.outStream, cnt)
+	// the last sample has the empty key as the end of the table
+	for _, s := range samples {
+		fmt.Fprintf(e.outStream, "%s\t%s\n", printedKey(s.Key), e.formatNumber(s.Offset))
+	}
 }
 
+// lookupKeysOptions give the row keys instead of the <row> argument
+var lookupKeysOptions = []string{"spec=", "keys=", "keys-file="}
+
 func doLookup(ctx context.Context, e *Executor, args ...string) {
 	if len(args) < 3 {
-		fmt.Fprintln(e.errStream, "Invalid args: lookup <table> <row>")
+		e.failf(e.msg("Invalid args: %v\n"), "lookup <table> <row>")
 		return
 	}
 	table := args[1]
-	key := args[2]
-	e.lookupWithOptions(table, key, args[3:]...)
+	for _, o := range lookupKeysOptions {
+		if strings.HasPrefix(args[2], o) {
+			e.lookupWithOptions(table, nil, args[2:]...)
+			return
+		}
+	}
+
+	// the arguments until the first option are the row keys,
+	// or the columns in "family:qualifier" of the families of the table after the first key
+	keys := []string{args[2]}
+	var columns []string
+	rest := args[3:]
+	var families []string
+	for len(rest) > 0 && !strings.Contains(rest[0], "=") {
+		i := strings.Index(rest[0], ":")
+		if i > 0 && families == nil {
+			var err error
+			families, err = e.metadataInteractor.Families(e.requestContext(nil), table)
+			if err != nil {
+				e.printError(err)
+				return
+			}
+		}
+		if i > 0 && containsString(families, rest[0][:i]) {
+			columns = append(columns, rest[0])
+		} else {
+			keys = append(keys, rest[0])
+		}
+		rest = rest[1:]
+	}
+	if len(columns) > 0 {
+		rest = append(rest, "columns="+strings.Join(columns, ","))
+	}
+	e.lookupWithOptions(table, keys, rest...)
 }
 
 func doRead(ctx context.Context, e *Executor, args ...string) {
 	if len(args) < 2 {
-		fmt.Fprintln(e.errStream, "Invalid args: read <table> [args ...]")
+		e.failf(e.msg("Invalid args: %v\n"), "read <table> [args ...]")
 		return
 	}
 	table := args[1]
 	e.readWithOptions(table, args[2:]...)
 }
 
-func (e *Executor) lookupWithOptions(table, key string, args ...string) {
+func (e *Executor) lookupWithOptions(table string, keys []string, args ...string) {
+	args, err := e.withPreset(args, lookupPresetOptions)
+	if err != nil {
+		e.failf("Invalid preset: %v\n", err)
+		return
+	}
 	parsed := make(map[string]string)
 	for _, arg := range args {
 		i := strings.Index(arg, "=")
 		if i < 0 {
-			fmt.Fprintf(e.errStream, "Invalid args: %v\n", arg)
+			e.failf(e.msg("Invalid args: %v\n"), arg)
 			return
 		}
 		// TODO: Improve parsing args
 		k, v := arg[:i], arg[i+1:]
 		switch k {
 		default:
-			fmt.Fprintf(e.errStream, "Unknown arg: %v\n", arg)
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
 			return
-		case "decode", "decode_columns":
+		case "decode", "decode_columns", "qualifier-time", "pivot", "format", "template", "full-values":
+			parsed[k] = v
+		case "spec", "keys", "keys-file":
+			parsed[k] = v
+		case "fanout", "slow":
+			parsed[k] = v
+		case "app-profile", "priority":
 			parsed[k] = v
-		case "version":
+		case "columns":
+			if parsed[k] != "" {
+				v = parsed[k] + "," + v
+			}
 			parsed[k] = v
+		case "version", "versions", "family", "qualifier-regex", "from", "to", "asof", "cells-per-row", "label":
+			parsed[k] = v
+		}
+	}
+	if parsed["spec"] != "" && (len(keys) > 0 || parsed["keys"] != "" || parsed["keys-file"] != "") {
+		e.failln(`"spec" may not be mixed with the row keys`)
+		return
+	}
+	if v := parsed["keys"]; v != "" {
+		keys = append(keys, strings.Split(v, ",")...)
+	}
+	// the keys of the args are escaped as printed, the keys of the files are raw
+	for i, k := range keys {
+		if keys[i], err = unescapeKey(k); err != nil {
+			e.failf("Invalid key: %v\n", err)
+			return
 		}
 	}
+	if v := parsed["keys-file"]; v != "" {
+		fileKeys, err := loadKeysFile(v)
+		if err != nil {
+			e.failf("Invalid keys-file: %v\n", err)
+			return
+		}
+		keys = append(keys, fileKeys...)
+	}
 
+	// fanout reads each key by a request in parallel and reports the latency of the keys
+	fanout := 0
+	if v := parsed["fanout"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			e.failf("Invalid fanout: %v\n", v)
+			return
+		}
+		fanout = n
+	}
+	slow := defaultSlowLookup
+	if v := parsed["slow"]; v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			e.failf("Invalid slow: %v\n", v)
+			return
+		}
+		slow = d
+	}
+
+	if err := validatePrinterOption(parsed); err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+	defaultVersions(parsed)
 	ro, err := readOption(parsed)
 	if err != nil {
-		fmt.Fprintf(e.errStream, "Invalid options: %v\n", err)
+		e.failf(e.msg("Invalid options: %v\n"), err)
 		return
 	}
 
-	ctx := context.Background()
-	row, err := e.rowsInteractor.GetRow(ctx, table, key, ro...)
+	if err := e.resolvePriority(e.requestContext(nil), parsed); err != nil {
+		e.printError(err)
+		return
+	}
+	ctx := e.requestContext(parsed)
+	if spec := parsed["spec"]; spec != "" {
+		e.lookupWithSpec(ctx, table, spec, parsed)
+		return
+	}
+	if len(keys) == 0 {
+		e.failf(e.msg("Invalid args: %v\n"), "lookup <table> <row>")
+		return
+	}
+	if e.explain {
+		// already checked by readOption
+		filters, _ := readFilters(parsed)
+		e.printReadPlan(table, bigtable.RowList(keys), filters, 0, parsed)
+		return
+	}
+	if fanout > 0 {
+		e.lookupFanout(ctx, table, keys, fanout, slow, parsed, ro...)
+		return
+	}
+	if len(keys) > 1 {
+		rows, err := e.rowsInteractor.GetRows(ctx, table, bigtable.RowList(keys), ro...)
+		if err != nil {
+			e.printError(err)
+			return
+		}
+		p := e.newPrinter(parsed)
+		p.table = table
+		p.printRows(rows)
+		return
+	}
+	row, err := e.rowsInteractor.GetRow(ctx, table, keys[0], ro...)
 	if err != nil {
-		fmt.Fprintf(e.errStream, "%v", err)
+		e.printError(err)
 		return
 	}
 
-	// decode options
-	p := &Printer{
-		outStream: e.outStream,
-		errStream: e.errStream,
+	p := e.newPrinter(parsed)
+	p.table = table
+	p.printRow(row)
+}
 
-		decodeType:       parsed["decode"],
-		decodeColumnType: decodeColumnOption(parsed),
+// lookupWithSpec reads the rows with the columns of each key in a single request
+func (e *Executor) lookupWithSpec(ctx context.Context, table, specFile string, parsed map[string]string) {
+	spec, err := loadLookupSpec(specFile)
+	if err != nil {
+		e.failf("Invalid spec: %v\n", err)
+		return
 	}
-	p.printRow(row)
+	filters, err := readFilters(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+	rl, filter := lookupSpecFilter(spec)
+	filters = append([]bigtable.Filter{filter}, filters...)
+	if e.explain {
+		e.printReadPlan(table, rl, filters, 0, parsed)
+		return
+	}
+	ro := rowFilterOption(filters...)
+
+	rows, err := e.rowsInteractor.GetRows(ctx, table, rl, ro...)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+
+	p := e.newPrinter(parsed)
+	p.table = table
+	p.printRows(rows)
+}
+
+// loadLookupSpec reads the JSON file mapping the row keys to the columns
+// spec format {"key1": ["family:qualifier", ...], "key2": [], ...}, empty columns mean all columns
+func loadLookupSpec(file string) (map[string][]string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	spec := map[string][]string{}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	if len(spec) == 0 {
+		return nil, fmt.Errorf("no keys in %s", file)
+	}
+	return spec, nil
+}
+
+// loadKeysFile reads the row keys from the file, one key per line
+func loadKeysFile(file string) ([]string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if key := strings.TrimSpace(line); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys in %s", file)
+	}
+	return keys, nil
+}
+
+// lookupSpecFilter returns the row keys and the filter selecting the columns of each key
+func lookupSpecFilter(spec map[string][]string) (bigtable.RowList, bigtable.Filter) {
+	keys := make([]string, 0, len(spec))
+	for k := range spec {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	filters := make([]bigtable.Filter, 0, len(keys))
+	for _, k := range keys {
+		f := bigtable.RowKeyFilter(fmt.Sprintf("^%s$", regexp.QuoteMeta(k)))
+		if columns := spec[k]; len(columns) > 0 {
+			f = bigtable.ChainFilters(f, columnsFilter(strings.Join(columns, ",")))
+		}
+		filters = append(filters, f)
+	}
+	if len(filters) == 1 {
+		return bigtable.RowList(keys), filters[0]
+	}
+	return bigtable.RowList(keys), bigtable.InterleaveFilters(filters...)
 }
 
 func (e *Executor) readWithOptions(table string, args ...string) {
+	args, err := e.withPreset(args, presetOptions)
+	if err != nil {
+		e.failf("Invalid preset: %v\n", err)
+		return
+	}
 	parsed := make(map[string]string)
 	for _, arg := range args {
 		i := strings.Index(arg, "=")
 		if i < 0 {
-			fmt.Fprintf(os.Stderr, "Invalid args: %v\n", arg)
+			e.failf(e.msg("Invalid args: %v\n"), arg)
 			return
 		}
 		// TODO: Improve parsing args
 		key, val := arg[:i], arg[i+1:]
 		switch key {
 		default:
-			fmt.Fprintf(os.Stderr, "Unknown arg: %v\n", arg)
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
 			return
-		case "decode", "decode_columns":
+		case "decode", "decode_columns", "qualifier-time", "pivot", "format", "template", "full-values":
+			parsed[key] = val
+		case "count", "offset", "start", "end", "prefix", "version", "versions", "family", "columns", "qualifier-regex", "value-regex", "from", "to", "asof", "cells-per-row", "label", "sample":
+			parsed[key] = val
+		case "from-backup", "cluster":
 			parsed[key] = val
-		case "count", "start", "end", "prefix", "version", "family":
+		case "page", "page-size":
+			parsed[key] = val
+		case "app-profile", "priority":
 			parsed[key] = val
 		}
 	}
 
 	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
-		fmt.Fprintf(e.errStream, `"start"/"end" may not be mixed with "prefix"`)
+		e.failln(`"start"/"end" may not be mixed with "prefix"`)
 		return
 	}
 
+	if err := validatePrinterOption(parsed); err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
 	rr, err := rowRange(parsed)
 	if err != nil {
-		fmt.Fprintf(e.errStream, "Invlaid range: %v\n", err)
+		e.failf(e.msg("Invalid range: %v\n"), err)
 		return
 	}
+	defaultVersions(parsed)
 	ro, err := readOption(parsed)
 	if err != nil {
-		fmt.Fprintf(e.errStream, "Invalid options: %v\n", err)
+		e.failf(e.msg("Invalid options: %v\n"), err)
 		return
 	}
 
-	ctx := context.Background()
-	rows, err := e.rowsInteractor.GetRows(ctx, table, rr, ro...)
+	if err := e.resolvePriority(e.requestContext(nil), parsed); err != nil {
+		e.printError(err)
+		return
+	}
+	ctx := e.requestContext(parsed)
+	size, page, err := e.pageOption(parsed)
 	if err != nil {
-		fmt.Fprintf(e.errStream, "%v", err)
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+	// already checked by readOption
+	offset, _ := readOffset(parsed)
+	if size > 0 {
+		if e.explain {
+			// the first page is read with an extra row to know whether the next page exists
+			filters, _ := readFilters(parsed)
+			e.printReadPlan(table, rr, filters, int64(offset+size+1), parsed)
+			return
+		}
+		e.pager = &pager{
+			table:  table,
+			parsed: parsed,
+			rs:     rr,
+			end:    rangeEnd(parsed),
+			opts:   ro,
+			size:   size,
+			offset: offset,
+		}
+		e.readPage(ctx, page)
 		return
 	}
 
-	// decode options
-	p := &Printer{
-		outStream: e.outStream,
-		errStream: e.errStream,
+	// an accidental full table scan is limited unless count is given
+	limit := 0
+	if parsed["count"] == "" {
+		limit = e.readLimit
+	}
+	if limit > 0 {
+		// read an extra row to know whether the rows are truncated
+		ro = append(ro, bigtable.LimitRows(int64(offset+limit+1)))
+	}
+	if e.explain {
+		// already checked by readOption
+		filters, _ := readFilters(parsed)
+		n, _ := strconv.ParseInt(parsed["count"], 0, 64)
+		if n > 0 {
+			n += int64(offset)
+		}
+		if limit > 0 {
+			n = int64(offset + limit + 1)
+		}
+		e.printReadPlan(table, rr, filters, n, parsed)
+		return
+	}
 
-		decodeType:       parsed["decode"],
-		decodeColumnType: decodeColumnOption(parsed),
+	if streamFormats[e.rowFormat(parsed)] && parsed["from-backup"] == "" {
+		e.streamRows(ctx, table, rr, offset, limit, parsed, ro...)
+		return
 	}
+
+	var rows []*domain.Row
+	if backup := parsed["from-backup"]; backup != "" {
+		if parsed["cluster"] == "" {
+			e.failln(`"from-backup" requires "cluster"`)
+			return
+		}
+		fmt.Fprintf(e.errStream, "Restoring backup %s into a temporary table...\n", backup)
+		rows, err = e.backupInteractor.GetRows(ctx, table, parsed["cluster"], backup, rr, ro...)
+	} else {
+		rows, err = e.rowsInteractor.GetRows(ctx, table, rr, ro...)
+	}
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	rows = skipRows(rows, offset)
+	truncated := limit > 0 && len(rows) > limit
+	if truncated {
+		rows = rows[:limit]
+	}
+
+	p := e.newPrinter(parsed)
+	p.table = table
 	p.printRows(rows)
+	if truncated {
+		fmt.Fprintf(e.errStream, "-- truncated at %s rows, use count=0 for unlimited --\n", e.formatNumber(int64(limit)))
+	}
+}
+
+// readFromBackup reports whether the read restores and deletes a temporary table by from-backup=<backup>
+func readFromBackup(args []string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "from-backup=") && arg != "from-backup=" {
+			return true
+		}
+	}
+	return false
+}
+
+// streamRows prints the rows as they are read, the large scans aren't held in memory
+func (e *Executor) streamRows(ctx context.Context, table string, rs bigtable.RowSet, offset, limit int, parsed map[string]string, opts ...bigtable.ReadOption) {
+	p := e.newPrinter(parsed)
+	skipped, printed := 0, 0
+	truncated := false
+	err := e.rowsInteractor.ScanRows(ctx, table, rs, func(r *domain.Row) bool {
+		if skipped < offset {
+			skipped++
+			return true
+		}
+		if limit > 0 && printed >= limit {
+			truncated = true
+			return false
+		}
+		p.printRow(r)
+		printed++
+		return true
+	}, opts...)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	if truncated {
+		fmt.Fprintf(e.errStream, "-- truncated at %s rows, use count=0 for unlimited --\n", e.formatNumber(int64(limit)))
+	}
+}
+
+// resolvePriority sets the app profile of the priority option, the priority is the setting of the app profile
+func (e *Executor) resolvePriority(ctx context.Context, parsedArgs map[string]string) error {
+	priority := parsedArgs["priority"]
+	if priority == "" {
+		return nil
+	}
+	if parsedArgs["app-profile"] != "" {
+		return fmt.Errorf(`"priority" may not be mixed with "app-profile"`)
+	}
+	switch priority {
+	case domain.AppProfilePriorityLow, domain.AppProfilePriorityMedium, domain.AppProfilePriorityHigh:
+	default:
+		return fmt.Errorf("priority must be low, medium or high: %q", priority)
+	}
+
+	id, ok := e.priorityProfiles[priority]
+	if !ok {
+		var err error
+		id, err = e.rowsInteractor.PriorityProfile(ctx, priority)
+		if err != nil {
+			return err
+		}
+		if e.priorityProfiles == nil {
+			e.priorityProfiles = map[string]string{}
+		}
+		e.priorityProfiles[priority] = id
+	}
+	parsedArgs["app-profile"] = id
+	return nil
+}
+
+// requestContext returns the context with the request timeout and the app profile of the option or the default
+func (e *Executor) requestContext(parsedArgs map[string]string) context.Context {
+	ctx := e.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if e.requestTimeout > 0 {
+		ctx = repository.WithTimeout(ctx, e.requestTimeout)
+	}
+	if p := parsedArgs["app-profile"]; p != "" {
+		return repository.WithAppProfile(ctx, p)
+	}
+	if e.appProfile != "" {
+		return repository.WithAppProfile(ctx, e.appProfile)
+	}
+	return ctx
+}
+
+// optionRegexp returns the regex of the option highlighted by the colors, nil if empty or invalid for Go
+func optionRegexp(s string) *regexp.Regexp {
+	if s == "" {
+		return nil
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// rowFormat returns the output format of the format option or the session, the template option implies the template format
+func (e *Executor) rowFormat(parsedArgs map[string]string) string {
+	if f := parsedArgs["format"]; f != "" {
+		return f
+	}
+	if parsedArgs["template"] != "" {
+		return "template"
+	}
+	return e.format
+}
+
+// newPrinter returns the Printer with the decode options
+func (e *Executor) newPrinter(parsedArgs map[string]string) *Printer {
+	// already checked by validatePrinterOption
+	pivot, _ := strconv.ParseBool(parsedArgs["pivot"])
+	maxValueBytes := e.maxValueBytes
+	if full, _ := strconv.ParseBool(parsedArgs["full-values"]); full {
+		maxValueBytes = 0
+	}
+	tmpl := e.template
+	if t := parsedArgs["template"]; t != "" {
+		tmpl, _ = parseRowTemplate(t)
+	}
+	var record func(*domain.Row) int
+	if e.numberRows {
+		// the rows of the printer replace the rows of the last result
+		var rows []*domain.Row
+		n := 0
+		record = func(r *domain.Row) int {
+			n++
+			if len(rows) < maxShowRows {
+				rows = append(rows, r)
+			}
+			e.lastRows = rows
+			return n
+		}
+	}
+	p := &Printer{
+		record:         record,
+		outStream:      e.outStream,
+		errStream:      e.errStream,
+		separatorsOut:  e.paged,
+		formatter:      rowFormatters[e.rowFormat(parsedArgs)],
+		template:       tmpl,
+		transforms:     e.transforms,
+		protoFiles:     e.protoFiles,
+		columnDecoders: e.decoders,
+		autoDecode:     e.autoDecode,
+		timestamps:     e.timestamps(),
+		verbose:        e.verbose,
+		maxValueBytes:  maxValueBytes,
+		color:          e.color && !e.redirected,
+		valueMatch:     optionRegexp(parsedArgs["value-regex"]),
+		qualifierMatch: optionRegexp(parsedArgs["qualifier-regex"]),
+
+		decodeType:       parsedArgs["decode"],
+		decodeColumnType: decodeColumnOption(parsedArgs),
+		qualifierTime:    parsedArgs["qualifier-time"],
+		pivot:            pivot,
+	}
+	if e.capture != nil {
+		// the rows of let are captured instead of printed
+		p.outStream, p.errStream = ioutil.Discard, ioutil.Discard
+		p.record = func(r *domain.Row) int {
+			e.capture(p, r)
+			return 0
+		}
+	}
+	return p
 }
 
+// defaultVersions reads only the latest cell of each column unless the versions are given
+func defaultVersions(parsedArgs map[string]string) {
+	if parsedArgs["version"] == "" && parsedArgs["versions"] == "" {
+		parsedArgs["versions"] = "1"
+	}
+}
+
+// rowRange returns the range of start, end and prefix, the keys are escaped as printed by escapeKey
 func rowRange(parsedArgs map[string]string) (bigtable.RowRange, error) {
+	keys := map[string]string{}
+	for _, k := range []string{"start", "end", "prefix"} {
+		v, err := unescapeKey(parsedArgs[k])
+		if err != nil {
+			return bigtable.RowRange{}, fmt.Errorf("%s: %v", k, err)
+		}
+		keys[k] = v
+	}
+
 	var rr bigtable.RowRange
-	if start, end := parsedArgs["start"], parsedArgs["end"]; end != "" {
+	if start, end := keys["start"], keys["end"]; end != "" {
 		rr = bigtable.NewRange(start, end)
 	} else if start != "" {
 		rr = bigtable.InfiniteRange(start)
 	}
-	if prefix := parsedArgs["prefix"]; prefix != "" {
+	if prefix := keys["prefix"]; prefix != "" {
 		rr = bigtable.PrefixRange(prefix)
 	}
 
@@ -235,30 +1046,215 @@ func rowRange(parsedArgs map[string]string) (bigtable.RowRange, error) {
 
 func readOption(parsedArgs map[string]string) ([]bigtable.ReadOption, error) {
 	var opts []bigtable.ReadOption
+	offset, err := readOffset(parsedArgs)
+	if err != nil {
+		return nil, err
+	}
 	if count := parsedArgs["count"]; count != "" {
 		n, err := strconv.ParseInt(count, 0, 64)
 		if err != nil {
 			return nil, err
 		}
-		opts = append(opts, bigtable.LimitRows(n))
+		// 0 reads all rows, the skipped rows are read as well
+		if n > 0 {
+			opts = append(opts, bigtable.LimitRows(n+int64(offset)))
+		}
+	}
+	filters, err := readFilters(parsedArgs)
+	if err != nil {
+		return nil, err
+	}
+	return append(opts, rowFilterOption(filters...)...), nil
+}
+
+// readOffset returns a number of the leading rows to skip
+func readOffset(parsedArgs map[string]string) (int, error) {
+	v := parsedArgs["offset"]
+	if v == "" {
+		return 0, nil
 	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("offset must be a non-negative integer: %q", v)
+	}
+	return n, nil
+}
+
+// skipRows returns the rows after the offset
+func skipRows(rows []*domain.Row, offset int) []*domain.Row {
+	if offset >= len(rows) {
+		return []*domain.Row{}
+	}
+	return rows[offset:]
+}
+
+// readFilters returns the filters in the order of applying
+func readFilters(parsedArgs map[string]string) ([]bigtable.Filter, error) {
+	var filters []bigtable.Filter
 	if regex := parsedArgs["regex"]; regex != "" {
-		opts = append(opts, bigtable.RowFilter(bigtable.RowKeyFilter(regex)))
+		filters = append(filters, bigtable.RowKeyFilter(regex))
 	}
-	if version := parsedArgs["version"]; version != "" {
-		n, err := strconv.ParseInt(version, 0, 64)
+	if sample := parsedArgs["sample"]; sample != "" {
+		p, err := strconv.ParseFloat(sample, 64)
 		if err != nil {
 			return nil, err
 		}
-		opts = append(opts, bigtable.RowFilter(bigtable.LatestNFilter(int(n))))
+		if p <= 0 || p >= 1 {
+			return nil, fmt.Errorf("sample must be between 0 and 1 exclusive: %v", sample)
+		}
+		filters = append(filters, bigtable.RowSampleFilter(p))
+	}
+	if from, to, asof := parsedArgs["from"], parsedArgs["to"], parsedArgs["asof"]; from != "" || to != "" || asof != "" {
+		if to != "" && asof != "" {
+			return nil, fmt.Errorf(`"asof" may not be mixed with "to"`)
+		}
+		var start, end time.Time
+		var err error
+		if from != "" {
+			if start, err = parseTimestamp(from); err != nil {
+				return nil, err
+			}
+		}
+		if to != "" {
+			if end, err = parseTimestamp(to); err != nil {
+				return nil, err
+			}
+		}
+		// asof reads the latest versions at the time, the versions are 1 by default
+		if asof != "" {
+			if end, err = parseTimestamp(asof); err != nil {
+				return nil, err
+			}
+			// the end of the range is exclusive, and the versions are in milliseconds
+			end = end.Add(time.Millisecond)
+		}
+		filters = append(filters, bigtable.TimestampRangeFilter(start, end))
+	}
+	// "version" is an alias of "versions"
+	versions := parsedArgs["versions"]
+	if versions == "" {
+		versions = parsedArgs["version"]
+	} else if parsedArgs["version"] != "" {
+		return nil, fmt.Errorf(`"version" may not be mixed with "versions"`)
+	}
+	if versions != "" && versions != "all" {
+		n, err := strconv.ParseInt(versions, 0, 64)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, bigtable.LatestNFilter(int(n)))
 	}
 	if family := parsedArgs["family"]; family != "" {
-		opts = append(opts, bigtable.RowFilter(bigtable.FamilyFilter(fmt.Sprintf("^%s$", family))))
+		// anchor the regex to match a whole family name
+		filters = append(filters, bigtable.FamilyFilter(fmt.Sprintf("^(?:%s)$", family)))
+	}
+	if columns := parsedArgs["columns"]; columns != "" {
+		filters = append(filters, columnsFilter(columns))
+	}
+	if qualifierRegex := parsedArgs["qualifier-regex"]; qualifierRegex != "" {
+		filters = append(filters, bigtable.ColumnFilter(qualifierRegex))
+	}
+	if valueRegex := parsedArgs["value-regex"]; valueRegex != "" {
+		filters = append(filters, bigtable.ValueFilter(valueRegex))
+	}
+	if cells := parsedArgs["cells-per-row"]; cells != "" {
+		n, err := strconv.ParseInt(cells, 0, 64)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, bigtable.CellsPerRowLimitFilter(int(n)))
+	}
+	// the label is applied to the cells passing the other filters
+	if label := parsedArgs["label"]; label != "" {
+		filters = append(filters, bigtable.LabelFilter(label))
 	}
 
 	// TODO: Add read options. refs hbase-shell
 
-	return opts, nil
+	return filters, nil
+}
+
+// rowFilterOption combines the filters into a chain,
+// because multiple RowFilter options overwrite each other
+func rowFilterOption(filters ...bigtable.Filter) []bigtable.ReadOption {
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return []bigtable.ReadOption{bigtable.RowFilter(filters[0])}
+	default:
+		return []bigtable.ReadOption{bigtable.RowFilter(bigtable.ChainFilters(filters...))}
+	}
+}
+
+// timestamp formats accepted by the options
+var timestampFormats = []string{
+	time.RFC3339Nano,
+	timestampLayout,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseTimestamp parses the timestamp option in the local timezone unless the zone is given
+func parseTimestamp(s string) (time.Time, error) {
+	for _, f := range timestampFormats {
+		if t, err := time.ParseInLocation(f, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q, expected RFC3339 or %q", s, timestampLayout)
+}
+
+// columnsFilter returns the filter matching any of the columns
+// columns format "family1:qualifier1,family2:qualifier2,..."
+func columnsFilter(columns string) bigtable.Filter {
+	var filters []bigtable.Filter
+	for _, c := range strings.Split(columns, ",") {
+		fq := strings.SplitN(c, ":", 2)
+		if len(fq) != 2 {
+			filters = append(filters, bigtable.ColumnFilter(fmt.Sprintf("^%s$", regexp.QuoteMeta(c))))
+			continue
+		}
+		filters = append(filters, bigtable.ChainFilters(
+			bigtable.FamilyFilter(fmt.Sprintf("^%s$", regexp.QuoteMeta(fq[0]))),
+			bigtable.ColumnFilter(fmt.Sprintf("^%s$", regexp.QuoteMeta(fq[1]))),
+		))
+	}
+	if len(filters) == 1 {
+		return filters[0]
+	}
+	return bigtable.InterleaveFilters(filters...)
+}
+
+// validatePrinterOption checks the options of the Printer
+func validatePrinterOption(parsedArgs map[string]string) error {
+	if f := parsedArgs["format"]; f != "" && !containsString(config.Formats, f) {
+		return fmt.Errorf("format must be one of %s: %q", strings.Join(config.Formats, ", "), f)
+	}
+	unit := parsedArgs["qualifier-time"]
+	if _, ok := qualifierTimeUnits[unit]; unit != "" && !ok {
+		return fmt.Errorf("qualifier-time must be one of s, ms, us, ns: %q", unit)
+	}
+	if pivot := parsedArgs["pivot"]; pivot != "" {
+		b, err := strconv.ParseBool(pivot)
+		if err != nil {
+			return fmt.Errorf("pivot must be a boolean: %q", pivot)
+		}
+		if b && unit == "" {
+			return errors.New("pivot requires qualifier-time")
+		}
+	}
+	if full := parsedArgs["full-values"]; full != "" {
+		if _, err := strconv.ParseBool(full); err != nil {
+			return fmt.Errorf("full-values must be a boolean: %q", full)
+		}
+	}
+	if t := parsedArgs["template"]; t != "" {
+		if _, err := parseRowTemplate(t); err != nil {
+			return fmt.Errorf("template: %v", err)
+		}
+	}
+	return nil
 }
 
 func decodeColumnOption(parsedArgs map[string]string) map[string]string {
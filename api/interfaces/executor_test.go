@@ -2,6 +2,11 @@ package interfaces
 
 import (
 	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +36,13 @@ func TestRowRange(t *testing.T) {
 			},
 			bigtable.NewRange("1", "2"),
 		},
+		{
+			map[string]string{
+				"start": `a\x00`,
+				"end":   `a\xff`,
+			},
+			bigtable.NewRange("a\x00", "a\xff"),
+		},
 	}
 	for _, c := range cases {
 		actual, err := rowRange(c.input)
@@ -67,7 +79,128 @@ func TestReadOption(t *testing.T) {
 				"family": "d",
 			},
 			[]bigtable.ReadOption{
-				bigtable.RowFilter(bigtable.FamilyFilter("^d$")),
+				bigtable.RowFilter(bigtable.FamilyFilter("^(?:d)$")),
+			},
+		},
+		{
+			map[string]string{
+				"family": "d|m",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.FamilyFilter("^(?:d|m)$")),
+			},
+		},
+		{
+			map[string]string{
+				"version":     "1",
+				"value-regex": "^a",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.ChainFilters(
+					bigtable.LatestNFilter(1),
+					bigtable.ValueFilter("^a"),
+				)),
+			},
+		},
+		{
+			map[string]string{
+				"columns": "d:title,d:content",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.InterleaveFilters(
+					bigtable.ChainFilters(bigtable.FamilyFilter("^d$"), bigtable.ColumnFilter("^title$")),
+					bigtable.ChainFilters(bigtable.FamilyFilter("^d$"), bigtable.ColumnFilter("^content$")),
+				)),
+			},
+		},
+		{
+			map[string]string{
+				"from": "2018-01-01T00:00:00Z",
+				"to":   "2018-01-02T00:00:00Z",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.TimestampRangeFilter(
+					time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+					time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC),
+				)),
+			},
+		},
+		{
+			map[string]string{
+				"asof":     "2018-01-02T00:00:00Z",
+				"versions": "1",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.ChainFilters(
+					bigtable.TimestampRangeFilter(time.Time{}, time.Date(2018, 1, 2, 0, 0, 0, int(time.Millisecond), time.UTC)),
+					bigtable.LatestNFilter(1),
+				)),
+			},
+		},
+		{
+			map[string]string{
+				"versions": "all",
+			},
+			nil,
+		},
+		{
+			map[string]string{
+				"versions": "3",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.LatestNFilter(3)),
+			},
+		},
+		{
+			map[string]string{
+				"sample": "0.5",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.RowSampleFilter(0.5)),
+			},
+		},
+		{
+			map[string]string{
+				"count":  "10",
+				"offset": "5",
+			},
+			[]bigtable.ReadOption{
+				bigtable.LimitRows(15),
+			},
+		},
+		{
+			map[string]string{
+				"count":  "0",
+				"offset": "5",
+				"family": "d",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.FamilyFilter("^(?:d)$")),
+			},
+		},
+		{
+			map[string]string{
+				"cells-per-row": "2",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.CellsPerRowLimitFilter(2)),
+			},
+		},
+		{
+			map[string]string{
+				"family": "d",
+				"label":  "l1",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.ChainFilters(bigtable.FamilyFilter("^(?:d)$"), bigtable.LabelFilter("l1"))),
+			},
+		},
+		{
+			map[string]string{
+				"qualifier-regex": "^t",
+			},
+			[]bigtable.ReadOption{
+				bigtable.RowFilter(bigtable.ColumnFilter("^t")),
 			},
 		},
 	}
@@ -78,6 +211,41 @@ func TestReadOption(t *testing.T) {
 	}
 }
 
+func TestReadOptionAsofWithTo(t *testing.T) {
+	_, err := readOption(map[string]string{
+		"asof": "2018-01-02T00:00:00Z",
+		"to":   "2018-01-02T00:00:00Z",
+	})
+	assert.EqualError(t, err, `"asof" may not be mixed with "to"`)
+}
+
+func TestLookupSpecFilter(t *testing.T) {
+	rl, filter := lookupSpecFilter(map[string][]string{
+		"2": {},
+		"1": {"d:title"},
+	})
+	assert.Equal(t, bigtable.RowList{"1", "2"}, rl)
+	assert.Equal(t, bigtable.InterleaveFilters(
+		bigtable.ChainFilters(
+			bigtable.RowKeyFilter("^1$"),
+			bigtable.ChainFilters(bigtable.FamilyFilter("^d$"), bigtable.ColumnFilter("^title$")),
+		),
+		bigtable.RowKeyFilter("^2$"),
+	), filter)
+}
+
+func TestLoadKeysFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "keys")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString("1\n 2 \n\n3\n")
+	f.Close()
+
+	keys, err := loadKeysFile(f.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, keys)
+}
+
 func TestDoReadRowExecutor(t *testing.T) {
 	tm, _ := time.Parse("2006-01-02 15:04:05", "2018-01-01 00:00:00")
 	cases := []struct {
@@ -115,6 +283,118 @@ func TestDoReadRowExecutor(t *testing.T) {
 					}, nil).Times(1)
 			},
 		},
+		{
+			`lookup table 'a\x00' keys='b\xff'`,
+			"----------------------------------------\n'a\\x00'\n----------------------------------------\n'b\\xff'\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowList{"a\x00", "b\xff"}, bigtable.RowFilter(bigtable.LatestNFilter(1))).Return(
+					&domain.Bigtable{
+						Table: "table",
+						Rows: []*domain.Row{
+							&domain.Row{Key: "a\x00"},
+							&domain.Row{Key: "b\xff"},
+						},
+					}, nil).Times(1)
+			},
+		},
+		{
+			"lookup table a b",
+			"----------------------------------------\na\n----------------------------------------\nb\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowList{"a", "b"}, bigtable.RowFilter(bigtable.LatestNFilter(1))).Return(
+					&domain.Bigtable{
+						Table: "table",
+						Rows: []*domain.Row{
+							&domain.Row{Key: "a"},
+							&domain.Row{Key: "b"},
+						},
+					}, nil).Times(1)
+			},
+		},
+		{
+			"lookup table a d:row",
+			"----------------------------------------\na\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "table").Return(&domain.TableInfo{Families: []*domain.Family{{Name: "d"}}}, nil)
+				filter := bigtable.ChainFilters(
+					bigtable.LatestNFilter(1),
+					bigtable.ChainFilters(bigtable.FamilyFilter("^d$"), bigtable.ColumnFilter("^row$")),
+				)
+				mock.EXPECT().Get(gomock.Any(), "table", "a", bigtable.RowFilter(filter)).Return(
+					&domain.Bigtable{
+						Table: "table",
+						Rows:  []*domain.Row{&domain.Row{Key: "a"}},
+					}, nil).Times(1)
+			},
+		},
+		{
+			// the keys of the other prefixes than the families aren't columns
+			"lookup table user:1 user:2",
+			"----------------------------------------\nuser:1\n----------------------------------------\nuser:2\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "table").Return(&domain.TableInfo{Families: []*domain.Family{{Name: "d"}}}, nil)
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowList{"user:1", "user:2"}, bigtable.RowFilter(bigtable.LatestNFilter(1))).Return(
+					&domain.Bigtable{
+						Table: "table",
+						Rows: []*domain.Row{
+							&domain.Row{Key: "user:1"},
+							&domain.Row{Key: "user:2"},
+						},
+					}, nil).Times(1)
+			},
+		},
+		{
+			"lookup table keys=a,b family=d",
+			"----------------------------------------\na\n----------------------------------------\nb\n",
+			func(mock *repository.MockBigtable) {
+				filter := bigtable.ChainFilters(bigtable.LatestNFilter(1), bigtable.FamilyFilter("^(?:d)$"))
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowList{"a", "b"}, bigtable.RowFilter(filter)).Return(
+					&domain.Bigtable{
+						Table: "table",
+						Rows: []*domain.Row{
+							&domain.Row{Key: "a"},
+							&domain.Row{Key: "b"},
+						},
+					}, nil).Times(1)
+			},
+		},
+		{
+			"read table prefix=a decode=string",
+			"----------------------------------------\na\n  d:row                                    @ 2018/01/01-00:00:00.000000\n    \"a1\"\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("a"), bigtable.RowFilter(bigtable.LatestNFilter(1))).Return(
+					&domain.Bigtable{
+						Table: "table",
+						Rows: []*domain.Row{
+							&domain.Row{
+								Key: "a",
+								Columns: []*domain.Column{
+									&domain.Column{
+										Family:    "d",
+										Qualifier: "d:row",
+										Value:     []byte("a1"),
+										Version:   tm,
+									},
+								},
+							},
+						},
+					}, nil).Times(1)
+			},
+		},
+		{
+			"read table count=1 offset=1",
+			"----------------------------------------\nb\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowRange{}, bigtable.LimitRows(2), bigtable.RowFilter(bigtable.LatestNFilter(1))).Return(
+					&domain.Bigtable{
+						Table: "table",
+						Rows: []*domain.Row{
+							&domain.Row{Key: "a"},
+							&domain.Row{Key: "b"},
+						},
+					}, nil).Times(1)
+			},
+		},
 		{
 			"read table prefix=a version=1 decode=int decode_columns=row:string,404:float",
 			"----------------------------------------\na\n  d:row                                    @ 2018/01/01-00:00:00.000000\n    \"a1\"\n",
@@ -151,10 +431,11 @@ func TestDoReadRowExecutor(t *testing.T) {
 		// var r io.Reader = &buf
 		// r = io.TeeReader(r, os.Stdout)
 		executor := Executor{
-			outStream:       &buf,
-			errStream:       &buf,
-			tableInteractor: application.NewTableInteractor(mockBtRepo),
-			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			outStream:          &buf,
+			errStream:          &buf,
+			tableInteractor:    application.NewTableInteractor(mockBtRepo),
+			rowsInteractor:     application.NewRowsInteractor(mockBtRepo),
+			metadataInteractor: application.NewMetadataInteractor(mockBtRepo, nil),
 		}
 
 		executor.Do(c.input)
@@ -162,6 +443,164 @@ func TestDoReadRowExecutor(t *testing.T) {
 	}
 }
 
+func TestDoReadFromBackupExecutor(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	rows := &domain.Bigtable{
+		Rows: []*domain.Row{
+			&domain.Row{Key: "a"},
+		},
+	}
+	cases := []struct {
+		ctx       context.Context
+		dryRun    bool
+		expectOut string
+		expectErr string
+		// prepare expects the calls with the temporary table
+		prepare func(*repository.MockBigtable, gomock.Matcher)
+	}{
+		{
+			nil,
+			false,
+			"a\n",
+			"Restoring backup b1 into a temporary table...\n----------------------------------------\n",
+			func(mock *repository.MockBigtable, tmp gomock.Matcher) {
+				gomock.InOrder(
+					mock.EXPECT().RestoreTable(gomock.Any(), tmp, "c1", "b1").Return(nil),
+					mock.EXPECT().GetRows(gomock.Any(), tmp, bigtable.PrefixRange("a"), bigtable.RowFilter(bigtable.LatestNFilter(1))).Return(rows, nil),
+					mock.EXPECT().DeleteTable(gomock.Any(), tmp).Return(nil),
+				)
+			},
+		},
+		{
+			nil,
+			true,
+			"",
+			"Dry run: read table prefix=a from-backup=b1 cluster=c1 would read from a multi rows, run \"set dryrun off\" to execute\n",
+			func(mock *repository.MockBigtable, tmp gomock.Matcher) {},
+		},
+		{
+			// the table created before the cancel is deleted
+			canceled,
+			false,
+			"",
+			"Restoring backup b1 into a temporary table...\n",
+			func(mock *repository.MockBigtable, tmp gomock.Matcher) {
+				gomock.InOrder(
+					mock.EXPECT().RestoreTable(gomock.Any(), tmp, "c1", "b1").Return(context.Canceled),
+					mock.EXPECT().DeleteTable(gomock.Any(), tmp).Return(nil),
+				)
+			},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo, &temporaryTableMatcher{table: "table", since: time.Now()})
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:        &out,
+			errStream:        &errOut,
+			ctx:              c.ctx,
+			dryRun:           c.dryRun,
+			backupInteractor: application.NewBackupInteractor(mockBtRepo),
+		}
+		executor.Do("read table prefix=a from-backup=b1 cluster=c1")
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.True(t, strings.HasPrefix(errOut.String(), c.expectErr), "#%d: got %q", i, errOut.String())
+		ctrl.Finish()
+	}
+}
+
+// temporaryTableMatcher matches the temporary table of the table named since the time, and the same table after matched
+type temporaryTableMatcher struct {
+	table string
+	since time.Time
+	name  string
+}
+
+func (m *temporaryTableMatcher) Matches(x interface{}) bool {
+	s, ok := x.(string)
+	if !ok {
+		return false
+	}
+	if m.name != "" {
+		return s == m.name
+	}
+	for sec := m.since.Unix(); sec <= time.Now().Unix(); sec++ {
+		if s == application.TemporaryTable(m.table, time.Unix(sec, 0)) {
+			m.name = s
+			return true
+		}
+	}
+	return false
+}
+
+func (m *temporaryTableMatcher) String() string {
+	return fmt.Sprintf("is the temporary table of %s", m.table)
+}
+
+func TestDoReadLimitExecutor(t *testing.T) {
+	rows := &domain.Bigtable{
+		Rows: []*domain.Row{
+			&domain.Row{Key: "a"},
+			&domain.Row{Key: "b"},
+			&domain.Row{Key: "c"},
+		},
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+	cases := []struct {
+		input     string
+		expectOut string
+		expectErr string
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			"read table",
+			"a\nb\n",
+			"----------------------------------------\n----------------------------------------\n-- truncated at 2 rows, use count=0 for unlimited --\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowRange{}, latest, bigtable.LimitRows(3)).Return(rows, nil)
+			},
+		},
+		{
+			"read table offset=1",
+			"b\nc\n",
+			"----------------------------------------\n----------------------------------------\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowRange{}, latest, bigtable.LimitRows(4)).Return(rows, nil)
+			},
+		},
+		{
+			"read table count=0",
+			"a\nb\nc\n",
+			"----------------------------------------\n----------------------------------------\n----------------------------------------\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowRange{}, latest).Return(rows, nil)
+			},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:      &out,
+			errStream:      &errOut,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+			readLimit:      2,
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
 func TestDoCountExecutor(t *testing.T) {
 	cases := []struct {
 		input   string
@@ -172,7 +611,14 @@ func TestDoCountExecutor(t *testing.T) {
 			"count table",
 			"1\n",
 			func(mock *repository.MockBigtable) {
-				mock.EXPECT().Count(gomock.Any(), "table").Return(1, nil)
+				mock.EXPECT().Count(gomock.Any(), "table", bigtable.InfiniteRange("")).Return(1, nil)
+			},
+		},
+		{
+			"count table prefix=a",
+			"2\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Count(gomock.Any(), "table", bigtable.PrefixRange("a")).Return(2, nil)
 			},
 		},
 	}
@@ -197,3 +643,229 @@ func TestDoCountExecutor(t *testing.T) {
 		assert.Equal(t, c.expect, buf.String())
 	}
 }
+
+func TestDoSampleKeysExecutor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	mockBtRepo.EXPECT().SampleKeys(gomock.Any(), "table").Return([]*domain.KeySample{
+		{Key: "m", Offset: 1234567},
+		{Key: "", Offset: 2345678},
+	}, nil)
+
+	var buf bytes.Buffer
+	executor := Executor{
+		outStream:      &buf,
+		errStream:      &buf,
+		rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		numberFormat:   "comma",
+	}
+	executor.Do("samplekeys table")
+	assert.Equal(t, "m\t1,234,567\n\t2,345,678\n", buf.String())
+}
+
+func TestMergeArgs(t *testing.T) {
+	cases := []struct {
+		args    []string
+		options []string
+		expect  []string
+	}{
+		{
+			[]string{"read", "table", "prefix=a", "versions=1"},
+			[]string{"versions=all"},
+			[]string{"read", "table", "prefix=a", "versions=all"},
+		},
+		{
+			// "version" is the same option as "versions"
+			[]string{"read", "table", "prefix=a", "versions=1"},
+			[]string{"version=2"},
+			[]string{"read", "table", "prefix=a", "version=2"},
+		},
+		{
+			[]string{"read", "table", "prefix=a"},
+			[]string{"family=d", "prefix=b"},
+			[]string{"read", "table", "prefix=b", "family=d"},
+		},
+		{
+			[]string{"read", "table"},
+			nil,
+			[]string{"read", "table"},
+		},
+	}
+	for _, c := range cases {
+		actual, err := mergeArgs(c.args, c.options)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expect, actual)
+	}
+
+	_, err := mergeArgs([]string{"read", "table"}, []string{"table2"})
+	assert.Error(t, err)
+}
+
+func TestDoAgain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	gomock.InOrder(
+		mockBtRepo.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("a"), bigtable.RowFilter(bigtable.LatestNFilter(1))).Return(&domain.Bigtable{}, nil),
+		mockBtRepo.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("a"), bigtable.RowFilter(bigtable.LatestNFilter(2))).Return(&domain.Bigtable{}, nil),
+	)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:      &out,
+		errStream:      &errOut,
+		rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+	}
+	executor.Do("again")
+	executor.Do("read table prefix=a")
+	executor.Do("again versions=2")
+	assert.Equal(t, "No previous command\nread table prefix=a versions=2\n", errOut.String())
+}
+
+func TestCheckLock(t *testing.T) {
+	e := &Executor{
+		outStream:   &bytes.Buffer{},
+		errStream:   &bytes.Buffer{},
+		idleTimeout: time.Minute,
+		lastActive:  time.Now().Add(-2 * time.Minute),
+	}
+	assert.False(t, e.checkLock(Command{Name: "read"}))
+	assert.True(t, e.checkLock(Command{Name: "set", Write: true}))
+
+	doUnlock(context.Background(), e)
+	assert.False(t, e.checkLock(Command{Name: "set", Write: true}))
+}
+
+func TestExperimentalCommand(t *testing.T) {
+	saved := commands
+	defer func() { commands = saved }()
+	run := func(ctx context.Context, e *Executor, args ...string) {
+		fmt.Fprintf(e.outStream, "ran %s\n", args[0])
+	}
+	commands = append(commands[:len(commands):len(commands)],
+		Command{Name: "newcmd", Usage: "newcmd <table>", Runner: run, Experimental: true},
+		Command{Name: "oldcmd", Usage: "oldcmd <table>", Runner: run, Deprecated: "newcmd <table>"},
+	)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream: &out,
+		errStream: &errOut,
+	}
+	executor.Do("newcmd")
+	assert.True(t, executor.failed)
+	executor.experimental = true
+	executor.Do("newcmd")
+	executor.Do("oldcmd")
+	executor.Do("help oldcmd")
+	assert.Equal(t, "ran newcmd\nran oldcmd\noldcmd <table>\nDeprecated, use \"newcmd <table>\" instead\n", out.String())
+	assert.Equal(t, "newcmd is experimental, run btcli with -enable-experimental to use it\n"+
+		"Deprecated: oldcmd will be removed, use \"newcmd <table>\" instead\n", errOut.String())
+}
+
+// appProfileMatcher matches the context requesting with the app profile
+type appProfileMatcher string
+
+func (m appProfileMatcher) Matches(x interface{}) bool {
+	ctx, ok := x.(context.Context)
+	return ok && repository.AppProfile(ctx) == string(m)
+}
+
+func (m appProfileMatcher) String() string {
+	return fmt.Sprintf("context with app profile %q", string(m))
+}
+
+func TestAppProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	gomock.InOrder(
+		mockBtRepo.EXPECT().Get(appProfileMatcher("p1"), "table", "a", gomock.Any()).Return(&domain.Bigtable{Rows: []*domain.Row{{Key: "a"}}}, nil),
+		mockBtRepo.EXPECT().Get(appProfileMatcher("default"), "table", "a", gomock.Any()).Return(&domain.Bigtable{Rows: []*domain.Row{{Key: "a"}}}, nil),
+		mockBtRepo.EXPECT().Count(appProfileMatcher("default"), "table", gomock.Any()).Return(1, nil),
+	)
+
+	var buf bytes.Buffer
+	executor := Executor{
+		outStream:      &buf,
+		errStream:      &buf,
+		rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		appProfile:     "default",
+	}
+	executor.Do("lookup table a app-profile=p1")
+	executor.Do("lookup table a")
+	executor.Do("count table")
+}
+
+func TestPriority(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	mockBtRepo.EXPECT().AppProfiles(gomock.Any()).Return([]*domain.AppProfile{
+		{ID: "default", Priority: domain.AppProfilePriorityHigh},
+		{ID: "batch", Priority: domain.AppProfilePriorityLow},
+	}, nil).Times(2)
+	mockBtRepo.EXPECT().Get(appProfileMatcher("batch"), "table", "a", gomock.Any()).Return(&domain.Bigtable{Rows: []*domain.Row{{Key: "a"}}}, nil)
+	mockBtRepo.EXPECT().GetRows(appProfileMatcher("batch"), "table", gomock.Any(), gomock.Any()).Return(&domain.Bigtable{}, nil)
+
+	var buf bytes.Buffer
+	executor := Executor{
+		outStream:      &buf,
+		errStream:      &buf,
+		rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+	}
+	// the app profile of the priority is listed once
+	executor.Do("lookup table a priority=low")
+	executor.Do("read table priority=low")
+
+	cases := []struct {
+		input  string
+		expect string
+	}{
+		{"read table priority=medium", "no app profile has the medium priority, create an app profile with the standard isolation of the priority\n"},
+		{"read table priority=urgent", "priority must be low, medium or high: \"urgent\"\n"},
+		{"lookup table a priority=low app-profile=p1", "\"priority\" may not be mixed with \"app-profile\"\n"},
+	}
+	for _, c := range cases {
+		buf.Reset()
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, buf.String(), c.input)
+	}
+}
+
+func TestDoLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	// only the write after unlock is run
+	mockBtRepo.EXPECT().TableInfo(gomock.Any(), "table").Return(&domain.TableInfo{Name: "table"}, nil).Times(1)
+	mockBtRepo.EXPECT().DeleteTable(gomock.Any(), "table").Return(nil).Times(1)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:       &out,
+		errStream:       &errOut,
+		tableInteractor: application.NewTableInteractor(mockBtRepo),
+		idleTimeout:     time.Minute,
+		lastActive:      time.Now().Add(-2 * time.Minute),
+	}
+	executor.Do("deletetable table")
+	assert.Equal(t, "Session is locked after being idle for 1m0s, run \"unlock\" to continue\n", errOut.String())
+	assert.True(t, executor.locked)
+
+	// the read commands aren't locked
+	errOut.Reset()
+	executor.Do("help deletetable")
+	assert.Contains(t, out.String(), "deletetable <table>")
+	assert.Equal(t, "", errOut.String())
+
+	errOut.Reset()
+	executor.Do("unlock")
+	executor.Do("deletetable table")
+	assert.Equal(t, "Unlocked\nDeleted table table\n", errOut.String())
+	assert.False(t, executor.locked)
+}
@@ -0,0 +1,105 @@
+package interfaces
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultExistsBatchSize is a number of the keys read in a request by exists-batch
+const defaultExistsBatchSize = 1000
+
+func doExistsBatch(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "exists-batch <table> keys=<row>,...|keys-file=<file> [format=csv|ndjson] [batch-size=<n>] [app-profile=<id>]")
+		return
+	}
+	table := args[1]
+
+	parsed := make(map[string]string)
+	for _, arg := range args[2:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "keys", "keys-file":
+			parsed[k] = v
+		case "format", "batch-size", "app-profile":
+			parsed[k] = v
+		}
+	}
+
+	var keys []string
+	if v := parsed["keys"]; v != "" {
+		keys = append(keys, strings.Split(v, ",")...)
+	}
+	if v := parsed["keys-file"]; v != "" {
+		fileKeys, err := loadKeysFile(v)
+		if err != nil {
+			e.failf("Invalid keys-file: %v\n", err)
+			return
+		}
+		keys = append(keys, fileKeys...)
+	}
+	if len(keys) == 0 {
+		e.failln(`"keys" or "keys-file" is required`)
+		return
+	}
+	format := parsed["format"]
+	switch format {
+	case "":
+		format = "csv"
+	case "csv", "ndjson":
+	default:
+		e.failf("Invalid format: %v\n", format)
+		return
+	}
+	batchSize := defaultExistsBatchSize
+	if v := parsed["batch-size"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			e.failf("Invalid batch-size: %v\n", v)
+			return
+		}
+		batchSize = n
+	}
+
+	existing, err := e.rowsInteractor.ExistingKeys(e.requestContext(parsed), table, keys, batchSize)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+
+	present := 0
+	for _, k := range keys {
+		if existing[k] {
+			present++
+		}
+	}
+	if format == "ndjson" {
+		enc := json.NewEncoder(e.outStream)
+		for _, k := range keys {
+			enc.Encode(struct {
+				Key    string `json:"key"`
+				Exists bool   `json:"exists"`
+			}{k, existing[k]})
+		}
+	} else {
+		w := csv.NewWriter(e.outStream)
+		w.Write([]string{"key", "exists"})
+		for _, k := range keys {
+			w.Write([]string{k, strconv.FormatBool(existing[k])})
+		}
+		w.Flush()
+	}
+	fmt.Fprintf(e.errStream, "%s of %s keys exist\n", e.formatNumber(int64(present)), e.formatNumber(int64(len(keys))))
+}
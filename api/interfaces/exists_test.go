@@ -0,0 +1,69 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoExistsBatchExecutor(t *testing.T) {
+	cases := []struct {
+		input     string
+		expectOut string
+		expectErr string
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			"exists-batch users keys=u#1,u#2,u\"3 batch-size=2",
+			"key,exists\nu#1,true\nu#2,false\n\"u\"\"3\",true\n",
+			"2 of 3 keys exist\n",
+			func(mock *repository.MockBigtable) {
+				gomock.InOrder(
+					mock.EXPECT().Keys(gomock.Any(), "users", bigtable.RowList{"u#1", "u#2"}).Return([]string{"u#1"}, nil),
+					mock.EXPECT().Keys(gomock.Any(), "users", bigtable.RowList{"u\"3"}).Return([]string{"u\"3"}, nil),
+				)
+			},
+		},
+		{
+			"exists-batch users keys=u#1,u#2 format=ndjson",
+			"{\"key\":\"u#1\",\"exists\":false}\n{\"key\":\"u#2\",\"exists\":true}\n",
+			"1 of 2 keys exist\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Keys(gomock.Any(), "users", bigtable.RowList{"u#1", "u#2"}).Return([]string{"u#2"}, nil)
+			},
+		},
+		{
+			"exists-batch users format=ndjson",
+			"",
+			"\"keys\" or \"keys-file\" is required\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"exists-batch users keys=u#1 format=xml",
+			"",
+			"Invalid format: xml\n",
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:      &out,
+			errStream:      &errOut,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
@@ -0,0 +1,75 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+)
+
+func doExplain(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 || (args[1] != "read" && args[1] != "lookup") {
+		e.failf(e.msg("Invalid args: %v\n"), "explain read|lookup <table> [args ...]")
+		return
+	}
+
+	// the command prints the request by printReadPlan instead of sending it
+	e.explain = true
+	defer func() { e.explain = false }()
+	if args[1] == "read" {
+		doRead(ctx, e, args[1:]...)
+		return
+	}
+	doLookup(ctx, e, args[1:]...)
+}
+
+// printReadPlan prints the row set, the filters and the limit of the request,
+// 0 limit means all rows
+func (e *Executor) printReadPlan(table string, rs bigtable.RowSet, filters []bigtable.Filter, limit int64, parsed map[string]string) {
+	fmt.Fprintf(e.outStream, "table: %s\n", table)
+	fmt.Fprintf(e.outStream, "rows: %s\n", rowSetString(rs))
+	switch len(filters) {
+	case 0:
+		fmt.Fprintln(e.outStream, "filter: none")
+	case 1:
+		fmt.Fprintf(e.outStream, "filter: %s\n", filters[0])
+	default:
+		// the filters are chained by rowFilterOption
+		fmt.Fprintln(e.outStream, "filter: chain")
+		for _, f := range filters {
+			fmt.Fprintf(e.outStream, "  %s\n", f)
+		}
+	}
+	if limit > 0 {
+		fmt.Fprintf(e.outStream, "limit: %s rows\n", e.formatNumber(limit))
+	} else {
+		fmt.Fprintln(e.outStream, "limit: none")
+	}
+	profile := parsed["app-profile"]
+	if profile == "" {
+		profile = e.appProfile
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	fmt.Fprintf(e.outStream, "app-profile: %s\n", profile)
+}
+
+// rowSetString returns the printable row set
+func rowSetString(rs bigtable.RowSet) string {
+	switch v := rs.(type) {
+	case bigtable.RowRange:
+		return v.String()
+	case bigtable.RowRangeList:
+		ranges := make([]string, 0, len(v))
+		for _, r := range v {
+			ranges = append(ranges, r.String())
+		}
+		return strings.Join(ranges, ", ")
+	case bigtable.RowList:
+		return fmt.Sprintf("%q", []string(v))
+	default:
+		return fmt.Sprint(rs)
+	}
+}
@@ -0,0 +1,65 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoExplainExecutor(t *testing.T) {
+	cases := []struct {
+		input     string
+		expectOut string
+		expectErr string
+	}{
+		{
+			"explain read users prefix=a version=1 value-regex=x",
+			"table: users\nrows: [\"a\",\"b\")\nfilter: chain\n  col(*,1)\n  value_match(x)\nlimit: 1,001 rows\napp-profile: default\n",
+			"",
+		},
+		{
+			"explain read users count=10 offset=5 app-profile=batch",
+			"table: users\nrows: (∞,∞)\nfilter: col(*,1)\nlimit: 15 rows\napp-profile: batch\n",
+			"",
+		},
+		{
+			"explain read users page-size=20",
+			"table: users\nrows: (∞,∞)\nfilter: col(*,1)\nlimit: 21 rows\napp-profile: default\n",
+			"",
+		},
+		{
+			"explain lookup users a b versions=all",
+			"table: users\nrows: [\"a\" \"b\"]\nfilter: none\nlimit: none\napp-profile: default\n",
+			"",
+		},
+		{
+			"explain count users",
+			"",
+			"Invalid args: explain read|lookup <table> [args ...]\n",
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		// no request is sent
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:      &out,
+			errStream:      &errOut,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+			readLimit:      1000,
+			numberFormat:   "comma",
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		assert.False(t, executor.explain, "#%d", i)
+		assert.Nil(t, executor.pager, "#%d", i)
+		ctrl.Finish()
+	}
+}
@@ -0,0 +1,218 @@
+package interfaces
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/takashabe/btcli/api/domain"
+)
+
+// exportFormats are the formats of export, the text and the table aren't parsed back
+var exportFormats = []string{"ndjson", "json", "csv", "tsv", "yaml"}
+
+// defaultExportFormat is a format of export without the format option
+const defaultExportFormat = "ndjson"
+
+// manifestSuffix is appended to the exported file for the schema manifest
+const manifestSuffix = ".schema.json"
+
+// exportManifest describes an exported file, so that the consumers validate the file without guessing
+type exportManifest struct {
+	Table  string `json:"table"`
+	Format string `json:"format"`
+	// KeyFormat is utf8 when all keys are valid UTF-8, otherwise binary
+	KeyFormat string `json:"key_format"`
+	// BinaryEncoding is base64 when csv and tsv have the marker column of the base64 fields
+	BinaryEncoding string           `json:"binary_encoding,omitempty"`
+	Columns        []manifestColumn `json:"columns"`
+	Rows           int              `json:"rows"`
+	Fingerprint    string           `json:"fingerprint"`
+	ExportedAt     time.Time        `json:"exported_at"`
+}
+
+// manifestColumn is a column of the exported cells and its decoding, auto is guessed by the values
+type manifestColumn struct {
+	Column string `json:"column"`
+	Decode string `json:"decode"`
+}
+
+// key formats of the manifest
+const (
+	keyFormatUTF8   = "utf8"
+	keyFormatBinary = "binary"
+)
+
+func doExport(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "export <table> <file> [args ...]")
+		return
+	}
+	table, file := args[1], args[2]
+
+	parsed := make(map[string]string)
+	for _, arg := range args[3:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "format", "manifest", "binary":
+			parsed[k] = v
+		case "decode", "decode_columns":
+			parsed[k] = v
+		case "start", "end", "prefix", "count", "family", "versions", "columns", "qualifier-regex":
+			parsed[k] = v
+		case "app-profile":
+			parsed[k] = v
+		}
+	}
+
+	format := defaultExportFormat
+	if v := parsed["format"]; v != "" {
+		format = ""
+		for _, f := range exportFormats {
+			if v == f {
+				format = f
+			}
+		}
+		if format == "" {
+			e.failf("Invalid format: %v, must be one of %s\n", v, strings.Join(exportFormats, ", "))
+			return
+		}
+	}
+	// the binary fields of csv and tsv are written in base64 unless raw is given
+	binaryEncoding := ""
+	if format == "csv" || format == "tsv" {
+		binaryEncoding = binaryEncodingBase64
+	}
+	if v := parsed["binary"]; v != "" {
+		if !containsString(binaryEncodings, v) {
+			e.failf("Invalid binary: %v, must be one of %s\n", v, strings.Join(binaryEncodings, ", "))
+			return
+		}
+		if binaryEncoding == "" {
+			e.failf("Invalid binary: %v, only for csv and tsv\n", v)
+			return
+		}
+		if v == binaryEncodingRaw {
+			binaryEncoding = ""
+		}
+	}
+	manifest := false
+	if v := parsed["manifest"]; v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			e.failf("Invalid manifest: %v\n", v)
+			return
+		}
+		manifest = b
+	}
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		e.failln(`"start"/"end" may not be mixed with "prefix"`)
+		return
+	}
+	rr, err := rowRange(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid range: %v\n"), err)
+		return
+	}
+	defaultVersions(parsed)
+	ro, err := readOption(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	defer f.Close()
+	// the fingerprint is the digest of the exported bytes
+	h := sha256.New()
+	p := e.newPrinter(parsed)
+	p.outStream = io.MultiWriter(f, h)
+	p.formatter = rowFormatters[format]
+	p.binaryEncoding = binaryEncoding
+	p.cellTypes = true
+	if parsed["binary"] == binaryEncodingRaw {
+		p.binaryEncoding = binaryEncodingRaw
+	}
+	p.table = table
+
+	m := &exportManifest{
+		Table:          table,
+		Format:         format,
+		KeyFormat:      keyFormatUTF8,
+		BinaryEncoding: binaryEncoding,
+	}
+	decodes := map[string]string{}
+	var rows []*domain.Row
+	err = e.rowsInteractor.ScanRows(e.requestContext(parsed), table, rr, func(r *domain.Row) bool {
+		m.Rows++
+		if !utf8.ValidString(r.Key) {
+			m.KeyFormat = keyFormatBinary
+		}
+		for _, c := range r.Columns {
+			decodes[c.Qualifier] = p.decodeTypeOf(c.Qualifier)
+		}
+		if streamFormats[format] {
+			p.formatter.writeRow(p, r)
+		} else {
+			rows = append(rows, r)
+		}
+		return true
+	}, ro...)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	if !streamFormats[format] {
+		p.formatter.writeRows(p, rows)
+	}
+	fmt.Fprintf(e.errStream, "Exported %s rows to %s\n", e.formatNumber(int64(m.Rows)), file)
+	if !manifest {
+		return
+	}
+
+	for q, d := range decodes {
+		if d == "" {
+			d = "auto"
+		}
+		m.Columns = append(m.Columns, manifestColumn{Column: q, Decode: d})
+	}
+	sort.Slice(m.Columns, func(i, j int) bool { return m.Columns[i].Column < m.Columns[j].Column })
+	m.Fingerprint = "sha256:" + hex.EncodeToString(h.Sum(nil))
+	m.ExportedAt = time.Now().UTC()
+	if err := writeManifest(file+manifestSuffix, m); err != nil {
+		e.printError(err)
+		return
+	}
+	fmt.Fprintf(e.errStream, "Wrote the schema manifest to %s\n", file+manifestSuffix)
+}
+
+// writeManifest writes the manifest in the indented JSON
+func writeManifest(file string, m *exportManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, append(data, '\n'), 0644)
+}
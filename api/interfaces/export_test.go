@@ -0,0 +1,192 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoExport(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []*domain.Row{
+		&domain.Row{
+			Key: "a",
+			Columns: []*domain.Column{
+				&domain.Column{Family: "d", Qualifier: "d:name", Value: []byte("a1"), Version: tm},
+				&domain.Column{Family: "d", Qualifier: "d:count", Value: []byte{0, 0, 0, 0, 0, 0, 0, 2}, Version: tm},
+			},
+		},
+		&domain.Row{
+			Key: "b\xff",
+			Columns: []*domain.Column{
+				&domain.Column{Family: "d", Qualifier: "d:name", Value: []byte("b1"), Version: tm},
+			},
+		},
+	}
+	scan := func(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+		for _, r := range rows {
+			f(r)
+		}
+		return nil
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "out")
+
+	cases := []struct {
+		input          string
+		expectFile     string
+		expectErr      string
+		expectManifest *exportManifest
+		prepare        func(*repository.MockBigtable)
+	}{
+		{
+			"export table " + file + " format=csv prefix=a binary=raw",
+			"a,d,name,2018-01-01T00:00:00Z,a1\na,d,count,2018-01-01T00:00:00Z,2\nb\xff,d,name,2018-01-01T00:00:00Z,b1\n",
+			"Exported 2 rows to " + file + "\n",
+			nil,
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.PrefixRange("a"), gomock.Any(), latest).DoAndReturn(scan)
+			},
+		},
+		{
+			// the binary fields are written in base64 with the marker column
+			"export table " + file + " format=tsv manifest=true",
+			"a\td:name\t2018-01-01T00:00:00Z\ta1\t\na\td:count\t2018-01-01T00:00:00Z\t2\tint\nYv8=\td:name\t2018-01-01T00:00:00Z\tb1\tkey\n",
+			"Exported 2 rows to " + file + "\nWrote the schema manifest to " + file + ".schema.json\n",
+			&exportManifest{
+				Table:          "table",
+				Format:         "tsv",
+				KeyFormat:      "binary",
+				BinaryEncoding: "base64",
+				Columns: []manifestColumn{
+					{Column: "d:count", Decode: "auto"},
+					{Column: "d:name", Decode: "auto"},
+				},
+				Rows: 2,
+			},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), latest).DoAndReturn(scan)
+			},
+		},
+		{
+			"export table " + file + " decode_columns=count:int manifest=true",
+			`{"key":"a","cells":[{"family":"d","qualifier":"name","value":"a1","timestamp":"2018-01-01T00:00:00Z"},{"family":"d","qualifier":"count","value":2,"timestamp":"2018-01-01T00:00:00Z"}]}` + "\n" +
+				`{"key":"Yv8=","key_encoding":"base64","cells":[{"family":"d","qualifier":"name","value":"b1","timestamp":"2018-01-01T00:00:00Z"}]}` + "\n",
+			"Exported 2 rows to " + file + "\nWrote the schema manifest to " + file + ".schema.json\n",
+			&exportManifest{
+				Table:     "table",
+				Format:    "ndjson",
+				KeyFormat: "binary",
+				Columns: []manifestColumn{
+					{Column: "d:count", Decode: "int"},
+					{Column: "d:name", Decode: "auto"},
+				},
+				Rows: 2,
+			},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), latest).DoAndReturn(scan)
+			},
+		},
+		{
+			"export table " + file + " format=table",
+			"",
+			"Invalid format: table, must be one of ndjson, json, csv, tsv, yaml\n",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"export table " + file + " binary=raw",
+			"",
+			"Invalid binary: raw, only for csv and tsv\n",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"export table " + file + " format=csv binary=hex",
+			"",
+			"Invalid binary: hex, must be one of base64, raw\n",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"export table " + file + " manifest=yes",
+			"",
+			"Invalid manifest: yes\n",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"export table",
+			"",
+			"Invalid args: export <table> <file> [args ...]\n",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for i, c := range cases {
+		os.Remove(file)
+		os.Remove(file + manifestSuffix)
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:      &out,
+			errStream:      &errOut,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		}
+		executor.Do(c.input)
+		assert.Equal(t, "", out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+		if c.expectFile == "" {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("#%d: want no error, got %v", i, err)
+		}
+		assert.Equal(t, c.expectFile, string(data), "#%d", i)
+		if c.expectManifest == nil {
+			_, err := os.Stat(file + manifestSuffix)
+			assert.True(t, os.IsNotExist(err), "#%d", i)
+			continue
+		}
+		mdata, err := ioutil.ReadFile(file + manifestSuffix)
+		if err != nil {
+			t.Fatalf("#%d: want no error, got %v", i, err)
+		}
+		var m exportManifest
+		if err := json.Unmarshal(mdata, &m); err != nil {
+			t.Fatalf("#%d: want no error, got %v", i, err)
+		}
+		sum := sha256.Sum256(data)
+		c.expectManifest.Fingerprint = "sha256:" + hex.EncodeToString(sum[:])
+		c.expectManifest.ExportedAt = m.ExportedAt
+		assert.Equal(t, c.expectManifest, &m, "#%d", i)
+		assert.True(t, strings.HasSuffix(string(mdata), "\n"), "#%d", i)
+	}
+}
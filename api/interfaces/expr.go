@@ -0,0 +1,442 @@
+package interfaces
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// exprFunctions are the functions of the expressions in the option values
+var exprFunctions = map[string]func(args []interface{}) (interface{}, error){
+	"now": func(args []interface{}) (interface{}, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("now() takes no arguments")
+		}
+		return time.Now(), nil
+	},
+	"env": func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("env() takes a name")
+		}
+		name, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("env() takes a string, got %s", formatExprValue(args[0]))
+		}
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("env(%q) is not set", name)
+		}
+		return v, nil
+	},
+	"concat": func(args []interface{}) (interface{}, error) {
+		var b strings.Builder
+		for _, a := range args {
+			b.WriteString(formatExprValue(a))
+		}
+		return b.String(), nil
+	},
+}
+
+// exprOptions are the options of the numbers, the times and the durations having the expressions,
+// the regexes and the templates are kept as they are because the parentheses are the characters of them
+var exprOptions = map[string]bool{
+	"asof":          true,
+	"batch-size":    true,
+	"cells-per-row": true,
+	"count":         true,
+	"fanout":        true,
+	"fp-rate":       true,
+	"frequency":     true,
+	"from":          true,
+	"interval":      true,
+	"limit":         true,
+	"maxage":        true,
+	"maxversions":   true,
+	"offset":        true,
+	"page":          true,
+	"page-size":     true,
+	"parts":         true,
+	"report":        true,
+	"retention":     true,
+	"sample":        true,
+	"since":         true,
+	"slow":          true,
+	"target":        true,
+	"to":            true,
+	"violations":    true,
+}
+
+// keyOptions are the options of the row keys, only the function calls of them are evaluated
+// because "(...)" is a key, e.g. prefix=concat("user#", env("USER_ID"))
+var keyOptions = map[string]bool{
+	"end":    true,
+	"prefix": true,
+	"start":  true,
+}
+
+// isExprOption reports whether the value of the option is evaluated
+func isExprOption(key string) bool {
+	return exprOptions[key]
+}
+
+// isExprValue reports whether the value of the option is an expression evaluated by expandExprs
+func isExprValue(key, v string) bool {
+	if keyOptions[key] {
+		return isFunctionCall(v)
+	}
+	return isExprOption(key) && isExpr(v)
+}
+
+// isPatternOption reports whether the value of the option is a regex or a template,
+// $ of them is the end of the line or the variable of the template
+func isPatternOption(key string) bool {
+	return key == "family" || key == "template" || strings.HasSuffix(key, "regex")
+}
+
+// isExpr reports whether the value is an expression of "(...)" or a function call
+func isExpr(v string) bool {
+	return strings.HasPrefix(v, "(") || isFunctionCall(v)
+}
+
+// isFunctionCall reports whether the value is a call of the functions of the expressions
+func isFunctionCall(v string) bool {
+	for name := range exprFunctions {
+		if strings.HasPrefix(v, name+"(") {
+			return true
+		}
+	}
+	return false
+}
+
+// expandExprs evaluates the expressions in the values of the key=value arguments,
+// the spaces of the expressions are kept by tokenizeCommand and the quoted values aren't the expressions.
+// $name of the variables in the expressions are the numbers or the strings of the values
+func expandExprs(tokens []token, variables map[string]string) ([]token, error) {
+	ret := make([]token, 0, len(tokens))
+	for _, t := range tokens {
+		if !t.expr {
+			ret = append(ret, t)
+			continue
+		}
+		eq := strings.Index(t.text, "=")
+		v, err := evalExpr(t.text[eq+1:], variables)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", t.text[:eq], err)
+		}
+		// the value is literal not to be expanded again
+		ret = append(ret, literalToken(t.text[:eq+1], formatExprValue(v)))
+	}
+	return ret, nil
+}
+
+// formatExprValue returns the value in the text of the options, the times are in RFC3339 read by parseTimestamp
+func formatExprValue(v interface{}) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// exprParser evaluates the expressions of the numbers, the durations e.g. 2h, the strings, the operators
+// + - * / % and the function calls
+type exprParser struct {
+	tokens    []string
+	pos       int
+	variables map[string]string
+}
+
+func evalExpr(s string, variables map[string]string) (interface{}, error) {
+	tokens, err := lexExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens, variables: variables}
+	v, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in %q", p.tokens[p.pos], s)
+	}
+	return v, nil
+}
+
+func lexExpr(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case c == ' ':
+			i++
+		case strings.ContainsRune("()+-*/%,", c):
+			tokens = append(tokens, s[i:i+1])
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string in %q", s)
+			}
+			tokens = append(tokens, s[i:j+1])
+			i = j + 1
+		case c == '$':
+			_, n := variableRef(s[i:])
+			if n == 0 {
+				return nil, fmt.Errorf("unexpected %q in %q", c, s)
+			}
+			tokens = append(tokens, s[i:i+n])
+			i += n
+		case unicode.IsDigit(c) || c == '.' || unicode.IsLetter(c) || c == '_':
+			j := i
+			for ; j < len(s); j++ {
+				r := rune(s[j])
+				if !(unicode.IsDigit(r) || r == '.' || unicode.IsLetter(r) || r == '_') {
+					break
+				}
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", c, s)
+		}
+	}
+	return tokens, nil
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) expect(t string) error {
+	if p.peek() != t {
+		if p.peek() == "" {
+			return fmt.Errorf("missing %q", t)
+		}
+		return fmt.Errorf("expected %q, got %q", t, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+func (p *exprParser) parseSum() (interface{}, error) {
+	v, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == "+" || op == "-"; op = p.peek() {
+		p.pos++
+		w, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		if v, err = applyExprOp(op, v, w); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (p *exprParser) parseProduct() (interface{}, error) {
+	v, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == "*" || op == "/" || op == "%"; op = p.peek() {
+		p.pos++
+		w, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if v, err = applyExprOp(op, v, w); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (p *exprParser) parseUnary() (interface{}, error) {
+	if p.peek() == "-" {
+		p.pos++
+		v, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return applyExprOp("*", int64(-1), v)
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (interface{}, error) {
+	t := p.peek()
+	if t == "" {
+		return nil, fmt.Errorf("unexpected end of the expression")
+	}
+	p.pos++
+	switch {
+	case t == "(":
+		v, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		return v, p.expect(")")
+	case strings.HasPrefix(t, `"`):
+		return strconv.Unquote(t)
+	case strings.HasPrefix(t, "$"):
+		name, _ := variableRef(t)
+		v, ok := p.variables[name]
+		if !ok {
+			return nil, fmt.Errorf("undefined $%s", name)
+		}
+		if n, err := parseExprNumber(v); err == nil {
+			return n, nil
+		}
+		return v, nil
+	case unicode.IsDigit(rune(t[0])) || t[0] == '.':
+		return parseExprNumber(t)
+	}
+
+	f, ok := exprFunctions[t]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", t)
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []interface{}
+	for p.peek() != ")" {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		v, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	p.pos++
+	return f(args)
+}
+
+// parseExprNumber parses the ints, the floats and the durations of time.ParseDuration
+func parseExprNumber(t string) (interface{}, error) {
+	if n, err := strconv.ParseInt(t, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(t, 64); err == nil {
+		return f, nil
+	}
+	if d, err := time.ParseDuration(t); err == nil {
+		return d, nil
+	}
+	return nil, fmt.Errorf("invalid number %q", t)
+}
+
+// applyExprOp applies the operator to the numbers, the strings joined by +, the times and the durations
+func applyExprOp(op string, a, b interface{}) (interface{}, error) {
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(type) {
+		case int64:
+			switch op {
+			case "+":
+				return a + b, nil
+			case "-":
+				return a - b, nil
+			case "*":
+				return a * b, nil
+			case "/", "%":
+				if b == 0 {
+					return nil, fmt.Errorf("division by zero")
+				}
+				if op == "/" {
+					return a / b, nil
+				}
+				return a % b, nil
+			}
+		case float64:
+			return applyExprOp(op, float64(a), b)
+		case time.Duration:
+			if op == "*" {
+				return time.Duration(a) * b, nil
+			}
+		}
+	case float64:
+		switch b := b.(type) {
+		case int64:
+			return applyExprOp(op, a, float64(b))
+		case float64:
+			switch op {
+			case "+":
+				return a + b, nil
+			case "-":
+				return a - b, nil
+			case "*":
+				return a * b, nil
+			case "/":
+				if b == 0 {
+					return nil, fmt.Errorf("division by zero")
+				}
+				return a / b, nil
+			}
+		}
+	case string:
+		if b, ok := b.(string); ok && op == "+" {
+			return a + b, nil
+		}
+	case time.Time:
+		switch b := b.(type) {
+		case time.Duration:
+			switch op {
+			case "+":
+				return a.Add(b), nil
+			case "-":
+				return a.Add(-b), nil
+			}
+		case time.Time:
+			if op == "-" {
+				return a.Sub(b), nil
+			}
+		}
+	case time.Duration:
+		switch b := b.(type) {
+		case time.Duration:
+			switch op {
+			case "+":
+				return a + b, nil
+			case "-":
+				return a - b, nil
+			}
+		case int64:
+			switch op {
+			case "*":
+				return a * time.Duration(b), nil
+			case "/":
+				if b == 0 {
+					return nil, fmt.Errorf("division by zero")
+				}
+				return a / time.Duration(b), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("invalid operation %s %s %s", formatExprValue(a), op, formatExprValue(b))
+}
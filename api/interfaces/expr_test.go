@@ -0,0 +1,82 @@
+package interfaces
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandExprs(t *testing.T) {
+	os.Setenv("BTCLI_TEST_USER_ID", "42")
+	defer os.Unsetenv("BTCLI_TEST_USER_ID")
+
+	cases := []struct {
+		input     string
+		expect    []string
+		expectErr string
+	}{
+		{"read t count=(10*1000)", []string{"read", "t", "count=10000"}, ""},
+		{`read t count=concat(env("BTCLI_TEST_USER_ID"), "0")`, []string{"read", "t", "count=420"}, ""},
+		{`read t from=("2018-01-01" + "T00:00:00Z")`, []string{"read", "t", "from=2018-01-01T00:00:00Z"}, ""},
+		// the parentheses of the keys and the quoted values aren't evaluated
+		{`read t start=(a) end=\(a\) prefix="env(\"X\")" count="(1+2)"`, []string{"read", "t", "start=(a)", "end=(a)", `prefix=env("X")`, "count=(1+2)"}, ""},
+		// the function calls of the keys are evaluated
+		{`read t prefix=concat("user#", env("BTCLI_TEST_USER_ID"))`, []string{"read", "t", "prefix=user#42"}, ""},
+		{`read t start=concat("a", "b") end=env("BTCLI_TEST_USER_ID")`, []string{"read", "t", "start=ab", "end=42"}, ""},
+		// the variables are the numbers or the strings
+		{`read t count=($n * 2) from=concat(${s}, "-01-01T00:00:00Z")`, []string{"read", "t", "count=6", "from=2018-01-01T00:00:00Z"}, ""},
+		{"read t count=($m + 1)", nil, "count: undefined $m"},
+		{"read t sample=(1/4.0) count=(7%4)", []string{"read", "t", "sample=0.25", "count=3"}, ""},
+		{"watch-row t a interval=(2*1h30m)", []string{"watch-row", "t", "a", "interval=3h0m0s"}, ""},
+		{"read t count=-(2-5)", []string{"read", "t", "count=-(2-5)"}, ""},
+		// the regexes and the values without the expressions are kept
+		{"read t value-regex=(a|b) family=(d|e) start=a(b)", []string{"read", "t", "value-regex=(a|b)", "family=(d|e)", "start=a(b)"}, ""},
+		{"read t count=(1/0)", nil, "count: division by zero"},
+		{`read t to=env("BTCLI_TEST_UNSET")`, nil, `to: env("BTCLI_TEST_UNSET") is not set`},
+		{"read t count=(1+", nil, "count: unexpected end of the expression"},
+		{`read t count=("a"*2)`, nil, "count: invalid operation a * 2"},
+		{"read t count=(now(1))", nil, "count: now() takes no arguments"},
+		{"read t count=(1 2)", nil, `count: expected ")", got "2"`},
+	}
+	for i, c := range cases {
+		tokens, err := tokenizeCommand(c.input)
+		if err != nil {
+			t.Fatalf("#%d: want no error, got %v", i, err)
+		}
+		actual, err := expandExprs(tokens, map[string]string{"n": "3", "s": "2018"})
+		if c.expectErr != "" {
+			assert.EqualError(t, err, c.expectErr, "#%d", i)
+			continue
+		}
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, c.expect, tokenTexts(actual), "#%d", i)
+	}
+}
+
+func TestExpandExprsNow(t *testing.T) {
+	before := time.Now()
+	tokens, err := tokenizeCommand("read t from=now()-2h")
+	assert.NoError(t, err)
+	tokens, err = expandExprs(tokens, nil)
+	assert.NoError(t, err)
+
+	from, err := parseTimestamp(strings.TrimPrefix(tokens[2].text, "from="))
+	assert.NoError(t, err)
+	assert.False(t, from.Before(before.Add(-2*time.Hour)))
+	assert.False(t, from.After(time.Now().Add(-2*time.Hour)))
+}
+
+func TestDoInvalidExpr(t *testing.T) {
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream: &out,
+		errStream: &errOut,
+	}
+	executor.Do("read t count=(1/0)")
+	assert.Equal(t, "", out.String())
+	assert.Equal(t, "Invalid expression: count: division by zero\n", errOut.String())
+}
@@ -0,0 +1,89 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/takashabe/btcli/api/domain"
+)
+
+// defaultSlowLookup is a latency of the keys reported as slow by the fanout lookup
+const defaultSlowLookup = 100 * time.Millisecond
+
+// maxReportedKeys is a number of the slow and the missing keys printed in the summary
+const maxReportedKeys = 10
+
+// lookupFanout reads the keys in parallel and prints the rows and the summary of the latencies of the keys
+func (e *Executor) lookupFanout(ctx context.Context, table string, keys []string, parallel int, slow time.Duration, parsed map[string]string, opts ...bigtable.ReadOption) {
+	reads := e.rowsInteractor.FanoutRows(ctx, table, keys, parallel, opts...)
+
+	var rows []*domain.Row
+	var missing []string
+	var latencies []time.Duration
+	errors := 0
+	for _, r := range reads {
+		if r.Err != nil {
+			e.failf("%s: %v\n", escapeKey(r.Key), r.Err)
+			errors++
+			continue
+		}
+		latencies = append(latencies, r.Latency)
+		if len(r.Row.Columns) == 0 {
+			missing = append(missing, r.Key)
+			continue
+		}
+		rows = append(rows, r.Row)
+	}
+	p := e.newPrinter(parsed)
+	p.table = table
+	p.printRows(rows)
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	fmt.Fprintf(e.errStream, "keys: %s  found: %s  missing: %s  errors: %s  p50: %s  p99: %s  max: %s\n",
+		e.formatNumber(int64(len(keys))),
+		e.formatNumber(int64(len(rows))),
+		e.formatNumber(int64(len(missing))),
+		e.formatNumber(int64(errors)),
+		percentile(latencies, 0.50),
+		percentile(latencies, 0.99),
+		percentile(latencies, 1),
+	)
+
+	// the slowest keys first
+	var slowReads []*domain.KeyRead
+	for _, r := range reads {
+		if r.Err == nil && r.Latency >= slow {
+			slowReads = append(slowReads, r)
+		}
+	}
+	sort.SliceStable(slowReads, func(i, j int) bool { return slowReads[i].Latency > slowReads[j].Latency })
+	if len(slowReads) > 0 {
+		slowKeys := make([]string, 0, len(slowReads))
+		for _, r := range slowReads {
+			slowKeys = append(slowKeys, fmt.Sprintf("%s (%s)", r.Key, r.Latency.Round(time.Microsecond)))
+		}
+		fmt.Fprintf(e.errStream, "slow over %s: %s\n", slow, reportedKeys(slowKeys))
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(e.errStream, "missing: %s\n", reportedKeys(missing))
+	}
+}
+
+// reportedKeys returns the first keys of maxReportedKeys joined by commas
+func reportedKeys(keys []string) string {
+	escaped := make([]string, 0, maxReportedKeys)
+	for _, k := range keys {
+		if len(escaped) == maxReportedKeys {
+			break
+		}
+		escaped = append(escaped, escapeKey(k))
+	}
+	if len(keys) <= maxReportedKeys {
+		return strings.Join(escaped, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(escaped, ", "), len(keys)-maxReportedKeys)
+}
@@ -0,0 +1,105 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestLookupFanout(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)
+	found := func(k string) *domain.Bigtable {
+		return &domain.Bigtable{Rows: []*domain.Row{&domain.Row{
+			Key:     k,
+			Columns: []*domain.Column{&domain.Column{Family: "d", Qualifier: "d:row", Value: []byte(k + "1"), Version: tm}},
+		}}}
+	}
+	missing := &domain.Bigtable{Rows: []*domain.Row{&domain.Row{}}}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+
+	cases := []struct {
+		input     string
+		expectOut string
+		expectErr []string
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			"lookup table keys=a,b,c,d fanout=2 slow=1h",
+			"a\n  d:row                                    @ 2018/01/01-00:00:00.000000\n    \"a1\"\n" +
+				"d\n  d:row                                    @ 2018/01/01-00:00:00.000000\n    \"d1\"\n",
+			[]string{
+				"c: unavailable\n",
+				"keys: 4  found: 2  missing: 1  errors: 1  p50: ",
+				"missing: b\n",
+			},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Get(gomock.Any(), "table", "a", latest).Return(found("a"), nil)
+				mock.EXPECT().Get(gomock.Any(), "table", "b", latest).Return(missing, nil)
+				mock.EXPECT().Get(gomock.Any(), "table", "c", latest).Return(nil, errors.New("unavailable"))
+				mock.EXPECT().Get(gomock.Any(), "table", "d", latest).Return(found("d"), nil)
+			},
+		},
+		{
+			"lookup table keys=a,b fanout=2 slow=20ms",
+			"a\n  d:row                                    @ 2018/01/01-00:00:00.000000\n    \"a1\"\n" +
+				"b\n  d:row                                    @ 2018/01/01-00:00:00.000000\n    \"b1\"\n",
+			[]string{
+				"keys: 2  found: 2  missing: 0  errors: 0  p50: ",
+				"slow over 20ms: b (",
+			},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Get(gomock.Any(), "table", "a", latest).Return(found("a"), nil)
+				mock.EXPECT().Get(gomock.Any(), "table", "b", latest).DoAndReturn(
+					func(ctx context.Context, table, key string, opts ...bigtable.ReadOption) (*domain.Bigtable, error) {
+						time.Sleep(30 * time.Millisecond)
+						return found("b"), nil
+					})
+			},
+		},
+		{
+			"lookup table keys=a,b fanout=0",
+			"",
+			[]string{"Invalid fanout: 0\n"},
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"lookup table keys=a,b fanout=2 slow=fast",
+			"",
+			[]string{"Invalid slow: fast\n"},
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:      &out,
+			errStream:      &errOut,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		for _, s := range c.expectErr {
+			assert.Contains(t, errOut.String(), s, "#%d", i)
+		}
+		ctrl.Finish()
+	}
+}
+
+func TestReportedKeys(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"}
+	assert.Equal(t, "a, b", reportedKeys(keys[:2]))
+	assert.Equal(t, "a, b, c, d, e, f, g, h, i, j and 2 more", reportedKeys(keys))
+}
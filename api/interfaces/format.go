@@ -0,0 +1,708 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/takashabe/btcli/api/config"
+	"github.com/takashabe/btcli/api/domain"
+)
+
+// rowFormatter writes the rows in an output format other than the text
+type rowFormatter interface {
+	// writeRow writes a row of lookup
+	writeRow(w *Printer, r *domain.Row)
+	// writeRows writes the rows of read
+	writeRows(w *Printer, rs []*domain.Row)
+	// writeNames writes the names of ls
+	writeNames(out io.Writer, names []string)
+}
+
+// rowFormatters are the formatters of config.Formats, the text format isn't included
+var rowFormatters = map[string]rowFormatter{
+	"json":     jsonFormatter{},
+	"ndjson":   ndjsonFormatter{},
+	"csv":      csvFormatter{},
+	"tsv":      tsvFormatter{},
+	"yaml":     yamlFormatter{},
+	"table":    gridFormatter{},
+	"wide":     wideFormatter{},
+	"plain":    plainFormatter{},
+	"template": templateFormatter{},
+}
+
+// documentFormats are the formats writing the rows as a document, they aren't paginated unless given by page
+var documentFormats = map[string]bool{
+	"json": true,
+	"yaml": true,
+}
+
+// streamFormats are the formats writing the rows of read as they are read
+var streamFormats = map[string]bool{
+	"ndjson":   true,
+	"csv":      true,
+	"tsv":      true,
+	"plain":    true,
+	"template": true,
+}
+
+func doFormat(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 2 {
+		format := e.format
+		if format == "" {
+			format = "text"
+		}
+		fmt.Fprintln(e.outStream, format)
+		return
+	}
+	if args[1] == "template" {
+		doFormatTemplate(e, args[2:])
+		return
+	}
+	for _, f := range config.Formats {
+		if args[1] == f {
+			e.format = f
+			fmt.Fprintf(e.errStream, "Print the rows in %s\n", f)
+			return
+		}
+	}
+	e.failf("Unknown format: %s, must be one of %s\n", args[1], strings.Join(config.Formats, ", "))
+}
+
+// doFormatTemplate changes the format to the template of the args, or to the template of the session without the args
+func doFormatTemplate(e *Executor, args []string) {
+	if len(args) > 0 {
+		// the template of the args is written as it is with the spaces, or quoted
+		text := args[0]
+		if len(args) > 1 {
+			text = rawArgs(e.line, 2)
+		}
+		tmpl, err := parseRowTemplate(text)
+		if err != nil {
+			e.failf("Invalid template: %v\n", err)
+			return
+		}
+		e.template = tmpl
+	}
+	if e.template == nil {
+		e.failf(e.msg("Invalid args: %v\n"), "format template <template>")
+		return
+	}
+	e.format = "template"
+	fmt.Fprintln(e.errStream, "Print the rows in template")
+}
+
+// jsonCell is a cell in the JSON format
+type jsonCell struct {
+	Family    string      `json:"family"`
+	Qualifier string      `json:"qualifier"`
+	Value     interface{} `json:"value"`
+	Timestamp string      `json:"timestamp"`
+	Labels    []string    `json:"labels,omitempty"`
+	// Type is int or float of the value guessed as a number, written by export
+	Type string `json:"type,omitempty"`
+	// QualifierEncoding and ValueEncoding are base64 for the fields of invalid UTF-8 written in base64
+	QualifierEncoding string `json:"qualifier_encoding,omitempty"`
+	ValueEncoding     string `json:"value_encoding,omitempty"`
+	// Size and Raw are the byte size and the raw value in base64 printed in verbose
+	Size *int   `json:"size,omitempty"`
+	Raw  string `json:"raw,omitempty"`
+}
+
+// jsonRow is a row in the JSON format
+type jsonRow struct {
+	Key string `json:"key"`
+	// KeyEncoding is base64 for the key of invalid UTF-8 written in base64
+	KeyEncoding string     `json:"key_encoding,omitempty"`
+	Cells       []jsonCell `json:"cells"`
+}
+
+// jsonText returns the text and empty encoding, or the text in base64 and its encoding if the text isn't UTF-8
+// replaced by U+FFFD in JSON
+func jsonText(s string) (string, string) {
+	if utf8.ValidString(s) {
+		return s, ""
+	}
+	return base64.StdEncoding.EncodeToString([]byte(s)), binaryEncodingBase64
+}
+
+// jsonFormatter writes a row as a JSON object and the rows as a JSON array
+type jsonFormatter struct{}
+
+func (jsonFormatter) writeRow(w *Printer, r *domain.Row) {
+	json.NewEncoder(w.outStream).Encode(w.jsonRow(r))
+}
+
+func (jsonFormatter) writeRows(w *Printer, rs []*domain.Row) {
+	rows := make([]jsonRow, 0, len(rs))
+	for _, r := range rs {
+		rows = append(rows, w.jsonRow(r))
+	}
+	json.NewEncoder(w.outStream).Encode(rows)
+}
+
+func (jsonFormatter) writeNames(out io.Writer, names []string) {
+	if names == nil {
+		names = []string{}
+	}
+	json.NewEncoder(out).Encode(names)
+}
+
+func (w *Printer) jsonRow(r *domain.Row) jsonRow {
+	cells := make([]jsonCell, 0, len(r.Columns))
+	for _, c := range w.sortColumns(r.Columns) {
+		cell := jsonCell{
+			Family:    c.Family,
+			Value:     w.typedValue(c.Qualifier, c.Value),
+			Timestamp: c.Version.Format(time.RFC3339Nano),
+			Labels:    c.Labels,
+			Type:      w.cellType(c.Qualifier, c.Value),
+		}
+		cell.Qualifier, cell.QualifierEncoding = jsonText(c.Qualifier[strings.Index(c.Qualifier, ":")+1:])
+		if s, ok := cell.Value.(string); ok {
+			cell.Value, cell.ValueEncoding = jsonText(s)
+		}
+		if w.verbose {
+			size := len(c.Value)
+			cell.Size = &size
+			cell.Raw = base64.StdEncoding.EncodeToString(c.Value)
+		}
+		cells = append(cells, cell)
+	}
+	row := jsonRow{Cells: cells}
+	row.Key, row.KeyEncoding = jsonText(r.Key)
+	return row
+}
+
+// typedValue returns the value decoded by the option of the qualifier as a number or a string
+func (w *Printer) typedValue(q string, v []byte) interface{} {
+	v, err := w.transformValue(q, v)
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	decode := w.decodeTypeOf(q)
+	if decode == "" {
+		// same guess as guessDecode
+		decode = w.guessNumber(v)
+	}
+	if (decode == decodeTypeInt || decode == decodeTypeFloat) && len(v) != 8 {
+		return w.decode(decode, v)
+	}
+	switch decode {
+	case decodeTypeInt:
+		return w.byte2Int(v)
+	case decodeTypeFloat:
+		// NaN and Inf aren't JSON numbers
+		if f := w.byte2Float(v); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return f
+		}
+		return w.decode(decode, v)
+	case decodeTypeBase64:
+		return w.decode(decode, v)
+	default:
+		if data, ok, err := w.structuredValue(decode, v); ok {
+			if err != nil {
+				return fmt.Sprintf("<%v>", err)
+			}
+			return json.RawMessage(data)
+		}
+		return string(v)
+	}
+}
+
+// cellType returns the type of the value guessed as a number with cellTypes, otherwise empty
+func (w *Printer) cellType(q string, v []byte) string {
+	if !w.cellTypes || w.decodeTypeOf(q) != "" {
+		return ""
+	}
+	v, err := w.transformValue(q, v)
+	if err != nil {
+		return ""
+	}
+	return w.guessNumber(v)
+}
+
+// typedMarker appends the type of the value to the marker column, e.g. "key+float"
+func (w *Printer) typedMarker(marker []string, q string, v []byte) []string {
+	t := w.cellType(q, v)
+	if t == "" || marker == nil {
+		return marker
+	}
+	if marker[0] == "" {
+		return []string{t}
+	}
+	return []string{marker[0] + "+" + t}
+}
+
+// ndjsonFormatter writes each row as a JSON object in a line
+type ndjsonFormatter struct{}
+
+func (ndjsonFormatter) writeRow(w *Printer, r *domain.Row) {
+	json.NewEncoder(w.outStream).Encode(w.jsonRow(r))
+}
+
+func (f ndjsonFormatter) writeRows(w *Printer, rs []*domain.Row) {
+	for _, r := range rs {
+		f.writeRow(w, r)
+	}
+}
+
+func (ndjsonFormatter) writeNames(out io.Writer, names []string) {
+	enc := json.NewEncoder(out)
+	for _, n := range names {
+		enc.Encode(n)
+	}
+}
+
+// csvFormatter writes each cell as a "rowkey,family,qualifier,timestamp,value" line
+type csvFormatter struct{}
+
+func (csvFormatter) writeRow(w *Printer, r *domain.Row) {
+	cw := csv.NewWriter(w.outStream)
+	for _, c := range w.sortColumns(r.Columns) {
+		key, qualifier, value, marker := w.binarySafeFields(r.Key, c.Qualifier[strings.Index(c.Qualifier, ":")+1:], fmt.Sprint(w.typedValue(c.Qualifier, c.Value)))
+		marker = w.typedMarker(marker, c.Qualifier, c.Value)
+		// the binary fields printed without the encoding are escaped not to corrupt the terminal
+		if w.binaryEncoding == "" {
+			key, qualifier, value = escapeBinaryText(key), escapeBinaryText(qualifier), escapeBinaryText(value)
+		}
+		cw.Write(append([]string{
+			key,
+			c.Family,
+			qualifier,
+			c.Version.Format(time.RFC3339Nano),
+			value,
+		}, marker...))
+	}
+	cw.Flush()
+}
+
+func (f csvFormatter) writeRows(w *Printer, rs []*domain.Row) {
+	for _, r := range rs {
+		f.writeRow(w, r)
+	}
+}
+
+func (csvFormatter) writeNames(out io.Writer, names []string) {
+	cw := csv.NewWriter(out)
+	for _, n := range names {
+		cw.Write([]string{n})
+	}
+	cw.Flush()
+}
+
+// binaryEncodingBase64 writes the binary fields of csv and tsv in base64 with the marker column
+const binaryEncodingBase64 = "base64"
+
+// binaryEncodingRaw writes the binary fields of csv and tsv as they are
+const binaryEncodingRaw = "raw"
+
+// binaryEncodings are the encodings of the binary fields of csv and tsv
+var binaryEncodings = []string{binaryEncodingBase64, binaryEncodingRaw}
+
+// binarySafeFields returns the fields encoded by binaryEncoding and the marker column listing the base64 fields,
+// e.g. "key+value", no marker column without base64. The marker also has the type of the value by typedMarker
+func (w *Printer) binarySafeFields(key, qualifier, value string) (string, string, string, []string) {
+	if w.binaryEncoding != binaryEncodingBase64 {
+		return key, qualifier, value, nil
+	}
+	var encoded []string
+	for _, f := range []struct {
+		name string
+		v    *string
+	}{{"key", &key}, {"qualifier", &qualifier}, {"value", &value}} {
+		if isBinaryText(*f.v) {
+			*f.v = base64.StdEncoding.EncodeToString([]byte(*f.v))
+			encoded = append(encoded, f.name)
+		}
+	}
+	return key, qualifier, value, []string{strings.Join(encoded, "+")}
+}
+
+// isBinaryText reports whether the text isn't kept by csv and tsv, the invalid UTF-8 and the control characters
+// other than the line breaks and the tabs quoted or escaped by them
+func isBinaryText(s string) bool {
+	if !utf8.ValidString(s) {
+		return true
+	}
+	for _, r := range s {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeBinaryText returns the binary text escaped by escapeText, the other texts are kept as they are
+func escapeBinaryText(s string) string {
+	if isBinaryText(s) {
+		return escapeText(s)
+	}
+	return s
+}
+
+// tsvEscaper escapes the separators of the tsv fields
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// tsvText returns the binary text escaped by escapeText, otherwise the separators escaped by tsvEscaper
+func tsvText(s string) string {
+	if isBinaryText(s) {
+		return escapeText(s)
+	}
+	return tsvEscaper.Replace(s)
+}
+
+// tsvFormatter writes each cell as a "rowkey\tfamily:qualifier\ttimestamp\tvalue" line
+type tsvFormatter struct{}
+
+func (tsvFormatter) writeRow(w *Printer, r *domain.Row) {
+	for _, c := range w.sortColumns(r.Columns) {
+		key, qualifier, value, marker := w.binarySafeFields(r.Key, c.Qualifier[strings.Index(c.Qualifier, ":")+1:], fmt.Sprint(w.typedValue(c.Qualifier, c.Value)))
+		marker = w.typedMarker(marker, c.Qualifier, c.Value)
+		escape := tsvEscaper.Replace
+		if w.binaryEncoding == "" {
+			escape = tsvText
+		}
+		fields := []string{
+			escape(key),
+			escape(c.Family + ":" + qualifier),
+			c.Version.Format(time.RFC3339Nano),
+			escape(value),
+		}
+		fmt.Fprintln(w.outStream, strings.Join(append(fields, marker...), "\t"))
+	}
+}
+
+func (f tsvFormatter) writeRows(w *Printer, rs []*domain.Row) {
+	for _, r := range rs {
+		f.writeRow(w, r)
+	}
+}
+
+func (tsvFormatter) writeNames(out io.Writer, names []string) {
+	for _, n := range names {
+		fmt.Fprintln(out, tsvEscaper.Replace(n))
+	}
+}
+
+// plainFormatter writes each cell as a "rowkey<TAB>family:qualifier<TAB>value" line of the decoded value
+// without the separators, the timestamps and the quotes of the strings for the scripts
+type plainFormatter struct{}
+
+func (plainFormatter) writeRow(w *Printer, r *domain.Row) {
+	for _, c := range w.sortColumns(r.Columns) {
+		fmt.Fprintf(w.outStream, "%s\t%s\t%s\n",
+			tsvText(r.Key), tsvText(c.Qualifier), tsvText(fmt.Sprint(w.typedValue(c.Qualifier, c.Value))))
+	}
+}
+
+func (f plainFormatter) writeRows(w *Printer, rs []*domain.Row) {
+	for _, r := range rs {
+		f.writeRow(w, r)
+	}
+}
+
+func (plainFormatter) writeNames(out io.Writer, names []string) {
+	for _, n := range names {
+		fmt.Fprintln(out, n)
+	}
+}
+
+// templateRow is a row given to the templates of the template format
+type templateRow struct {
+	Key string
+	// Cells are the decoded values of the latest cells by "family:qualifier"
+	Cells map[string]interface{}
+	// Columns are all cells of the row in the layout of the JSON format
+	Columns []jsonCell
+}
+
+// templateFormatter writes each row by the template, followed by a newline unless the template ends with one
+type templateFormatter struct{}
+
+func (templateFormatter) writeRow(w *Printer, r *domain.Row) {
+	w.executeTemplate(r)
+}
+
+func (templateFormatter) writeRows(w *Printer, rs []*domain.Row) {
+	for _, r := range rs {
+		if err := w.executeTemplate(r); err != nil {
+			return
+		}
+	}
+}
+
+func (templateFormatter) writeNames(out io.Writer, names []string) {
+	for _, n := range names {
+		fmt.Fprintln(out, n)
+	}
+}
+
+// executeTemplate writes the row by the template, the error is printed once for the rows of read
+func (w *Printer) executeTemplate(r *domain.Row) error {
+	if w.templateErr != nil {
+		return w.templateErr
+	}
+	if w.template == nil {
+		w.templateErr = errors.New("no template, give -template, \"format template <template>\" or template=<template>")
+		fmt.Fprintf(w.errStream, "Invalid template: %v\n", w.templateErr)
+		return w.templateErr
+	}
+	row := templateRow{Key: r.Key, Cells: map[string]interface{}{}, Columns: w.jsonRow(r).Cells}
+	for _, c := range row.Columns {
+		// the cells of a column are read from the latest
+		q := c.Family + ":" + c.Qualifier
+		if _, ok := row.Cells[q]; !ok {
+			row.Cells[q] = c.Value
+		}
+	}
+	var b bytes.Buffer
+	if err := w.template.Execute(&b, row); err != nil {
+		w.templateErr = err
+		fmt.Fprintf(w.errStream, "Invalid template: %v\n", err)
+		return err
+	}
+	if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+		b.WriteByte('\n')
+	}
+	_, err := w.outStream.Write(b.Bytes())
+	return err
+}
+
+// templateUnescaper unescapes the tabs and the line breaks of the templates written in a line,
+// the other backslashes are kept for the strings of the templates
+var templateUnescaper = strings.NewReplacer(`\t`, "\t", `\n`, "\n")
+
+// parseRowTemplate parses the template of the template format, \t and \n are unescaped
+func parseRowTemplate(s string) (*template.Template, error) {
+	return template.New("row").Parse(templateUnescaper.Replace(s))
+}
+
+// yamlTimestampLayout is the version format of the fixtures
+const yamlTimestampLayout = "2006-01-02 15:04:05.999999 -07:00"
+
+// yamlPlain matches the strings written without the quotes
+var yamlPlain = regexp.MustCompile(`^[A-Za-z0-9_./][A-Za-z0-9_./ -]*$`)
+
+// yamlString returns the string as a plain scalar if possible, otherwise a double-quoted scalar,
+// the invalid UTF-8 is written in base64 with the !!binary tag
+func yamlString(s string) string {
+	if !utf8.ValidString(s) {
+		return "!!binary " + base64.StdEncoding.EncodeToString([]byte(s))
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+		return strconv.Quote(s)
+	}
+	if yamlPlain.MatchString(s) && !strings.HasSuffix(s, " ") {
+		return s
+	}
+	// the escapes of Go are valid in the double-quoted scalars
+	return strconv.Quote(s)
+}
+
+// yamlFormatter writes the rows in the layout of the fixtures of bt-fixture,
+// the cells are grouped by the family, the qualifier and the version
+type yamlFormatter struct{}
+
+func (f yamlFormatter) writeRow(w *Printer, r *domain.Row) {
+	f.writeRows(w, []*domain.Row{r})
+}
+
+func (yamlFormatter) writeRows(w *Printer, rs []*domain.Row) {
+	type yamlColumn struct {
+		qualifier string
+		version   time.Time
+		rows      [][2]string
+	}
+	type yamlFamily struct {
+		family  string
+		columns []*yamlColumn
+	}
+	var families []*yamlFamily
+	for _, r := range rs {
+		for _, c := range w.sortColumns(r.Columns) {
+			var fam *yamlFamily
+			for _, f := range families {
+				if f.family == c.Family {
+					fam = f
+				}
+			}
+			if fam == nil {
+				fam = &yamlFamily{family: c.Family}
+				families = append(families, fam)
+			}
+			q := c.Qualifier[strings.Index(c.Qualifier, ":")+1:]
+			var col *yamlColumn
+			for _, cl := range fam.columns {
+				if cl.qualifier == q && cl.version.Equal(c.Version) {
+					col = cl
+				}
+			}
+			if col == nil {
+				col = &yamlColumn{qualifier: q, version: c.Version}
+				fam.columns = append(fam.columns, col)
+			}
+			col.rows = append(col.rows, [2]string{r.Key, string(c.Value)})
+		}
+	}
+
+	if w.table != "" {
+		fmt.Fprintf(w.outStream, "table: %s\n", yamlString(w.table))
+	}
+	if len(families) == 0 {
+		fmt.Fprintln(w.outStream, "column_families: []")
+		return
+	}
+	fmt.Fprintln(w.outStream, "column_families:")
+	for _, f := range families {
+		fmt.Fprintf(w.outStream, "  - family: %s\n", yamlString(f.family))
+		fmt.Fprintln(w.outStream, "    columns:")
+		for _, c := range f.columns {
+			fmt.Fprintf(w.outStream, "      - key: %s\n", yamlString(c.qualifier))
+			fmt.Fprintf(w.outStream, "        version: %s\n", c.version.Format(yamlTimestampLayout))
+			fmt.Fprintln(w.outStream, "        rows:")
+			for _, kv := range c.rows {
+				fmt.Fprintf(w.outStream, "          %s: %s\n", yamlString(kv[0]), yamlString(kv[1]))
+			}
+		}
+	}
+}
+
+func (yamlFormatter) writeNames(out io.Writer, names []string) {
+	if len(names) == 0 {
+		fmt.Fprintln(out, "[]")
+		return
+	}
+	for _, n := range names {
+		fmt.Fprintf(out, "- %s\n", yamlString(n))
+	}
+}
+
+// gridFormatter writes the cells as an aligned grid sized by the widest cell of each column
+type gridFormatter struct{}
+
+func (f gridFormatter) writeRow(w *Printer, r *domain.Row) {
+	f.writeRows(w, []*domain.Row{r})
+}
+
+func (gridFormatter) writeRows(w *Printer, rs []*domain.Row) {
+	lines := [][]string{}
+	for _, r := range rs {
+		for _, c := range w.sortColumns(r.Columns) {
+			lines = append(lines, []string{
+				printedKey(r.Key),
+				w.qualifierLabel(c.Qualifier),
+				w.timestamps.format(c.Version),
+				w.truncateValue(w.formatValue(c.Qualifier, c.Value)),
+			})
+		}
+	}
+	writeGrid(w.outStream, []string{"key", "qualifier", "timestamp", "value"}, lines)
+}
+
+func (gridFormatter) writeNames(out io.Writer, names []string) {
+	lines := make([][]string, 0, len(names))
+	for _, n := range names {
+		lines = append(lines, []string{n})
+	}
+	writeGrid(out, []string{"table"}, lines)
+}
+
+// wideFormatter writes each row as a line of the grid with the qualifiers as the columns, the latest cells are printed
+type wideFormatter struct{}
+
+func (f wideFormatter) writeRow(w *Printer, r *domain.Row) {
+	f.writeRows(w, []*domain.Row{r})
+}
+
+func (wideFormatter) writeRows(w *Printer, rs []*domain.Row) {
+	// the columns in the order first read
+	header := []string{"key"}
+	index := map[string]int{}
+	values := make([]map[int]string, 0, len(rs))
+	for _, r := range rs {
+		v := map[int]string{}
+		for _, c := range w.sortColumns(r.Columns) {
+			i, ok := index[c.Qualifier]
+			if !ok {
+				i = len(header)
+				index[c.Qualifier] = i
+				header = append(header, w.qualifierLabel(c.Qualifier))
+			}
+			if _, ok := v[i]; !ok {
+				v[i] = w.truncateValue(w.formatValue(c.Qualifier, c.Value))
+			}
+		}
+		values = append(values, v)
+	}
+
+	lines := make([][]string, 0, len(rs))
+	for i, r := range rs {
+		l := make([]string, len(header))
+		l[0] = printedKey(r.Key)
+		for j, v := range values[i] {
+			l[j] = v
+		}
+		lines = append(lines, l)
+	}
+	writeGrid(w.outStream, header, lines)
+}
+
+func (wideFormatter) writeNames(out io.Writer, names []string) {
+	gridFormatter{}.writeNames(out, names)
+}
+
+// gridEscaper keeps a cell of the grid in a line
+var gridEscaper = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// writeGrid writes the header and the lines in the grid
+func writeGrid(out io.Writer, header []string, lines [][]string) {
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, l := range lines {
+		for i := range l {
+			l[i] = gridEscaper.Replace(l[i])
+			if n := utf8.RuneCountInString(l[i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	border := "+"
+	for _, n := range widths {
+		border += strings.Repeat("-", n+2) + "+"
+	}
+	writeLine := func(l []string) {
+		s := "|"
+		for i, v := range l {
+			s += " " + v + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(v)) + " |"
+		}
+		fmt.Fprintln(out, s)
+	}
+	fmt.Fprintln(out, border)
+	writeLine(header)
+	fmt.Fprintln(out, border)
+	for _, l := range lines {
+		writeLine(l)
+	}
+	if len(lines) > 0 {
+		fmt.Fprintln(out, border)
+	}
+}
@@ -0,0 +1,810 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestJSONFormat(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	row := &domain.Row{
+		Key: "a",
+		Columns: []*domain.Column{
+			&domain.Column{Family: "d", Qualifier: "d:name", Value: []byte("a1"), Version: tm},
+			&domain.Column{Family: "d", Qualifier: "d:count", Value: []byte{0, 0, 0, 0, 0, 0, 0, 2}, Version: tm, Labels: []string{"l1"}},
+		},
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+	cell := `{"key":"a","cells":[{"family":"d","qualifier":"name","value":"a1","timestamp":"2018-01-01T00:00:00Z"},{"family":"d","qualifier":"count","value":2,"timestamp":"2018-01-01T00:00:00Z","labels":["l1"]}]}`
+
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"lookup table a",
+			cell + "\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Get(gomock.Any(), "table", "a", latest).Return(&domain.Bigtable{Rows: []*domain.Row{row}}, nil)
+			},
+		},
+		{
+			"lookup table a decode_columns=name:base64",
+			strings.Replace(cell, `"value":"a1"`, `"value":"YTE="`, 1) + "\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Get(gomock.Any(), "table", "a", latest).Return(&domain.Bigtable{Rows: []*domain.Row{row}}, nil)
+			},
+		},
+		{
+			// the JSON values are compacted into the cells
+			"lookup table b decode_columns=doc:json",
+			`{"key":"b","cells":[{"family":"d","qualifier":"doc","value":{"x":[1,2]},"timestamp":"2018-01-01T00:00:00Z"}]}` + "\n",
+			func(mock *repository.MockBigtable) {
+				doc := &domain.Row{
+					Key:     "b",
+					Columns: []*domain.Column{{Family: "d", Qualifier: "d:doc", Value: []byte("{\"x\": [1, 2]}\n"), Version: tm}},
+				}
+				mock.EXPECT().Get(gomock.Any(), "table", "b", latest).Return(&domain.Bigtable{Rows: []*domain.Row{doc}}, nil)
+			},
+		},
+		{
+			"read table",
+			"[" + cell + "]\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowRange{}, latest).Return(&domain.Bigtable{Rows: []*domain.Row{row}}, nil)
+			},
+		},
+		{
+			"read table prefix=z",
+			"[]\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("z"), latest).Return(&domain.Bigtable{}, nil)
+			},
+		},
+		{
+			"ls",
+			"[\"t1\",\"t2\"]\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1", "t2"}, nil)
+			},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+			format:          "json",
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+		assert.Equal(t, "", errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestDoFormat(t *testing.T) {
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream: &out,
+		errStream: &errOut,
+	}
+	executor.Do("format")
+	executor.Do("format json")
+	executor.Do("format")
+	executor.Do("format xml")
+	assert.Equal(t, "text\njson\n", out.String())
+	assert.Equal(t, "Print the rows in json\nUnknown format: xml, must be one of text, json, ndjson, csv, tsv, yaml, table, wide, plain, template\n", errOut.String())
+	assert.Equal(t, "json", executor.format)
+}
+
+func TestNDJSONFormat(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []*domain.Row{}
+	for _, k := range []string{"a", "b", "c", "d"} {
+		rows = append(rows, &domain.Row{
+			Key:     k,
+			Columns: []*domain.Column{&domain.Column{Family: "d", Qualifier: "d:row", Value: []byte(k + "1"), Version: tm}},
+		})
+	}
+	scan := func(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+		for _, r := range rows {
+			if !f(r) {
+				break
+			}
+		}
+		return nil
+	}
+	line := func(k string) string {
+		return `{"key":"` + k + `","cells":[{"family":"d","qualifier":"row","value":"` + k + `1","timestamp":"2018-01-01T00:00:00Z"}]}` + "\n"
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+
+	cases := []struct {
+		input     string
+		expectOut string
+		expectErr string
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			"read table offset=1",
+			line("b") + line("c"),
+			"-- truncated at 2 rows, use count=0 for unlimited --\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), latest, bigtable.LimitRows(4)).DoAndReturn(scan)
+			},
+		},
+		{
+			"read table count=0",
+			line("a") + line("b") + line("c") + line("d"),
+			"",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), latest).DoAndReturn(scan)
+			},
+		},
+		{
+			"ls",
+			"\"t1\"\n\"t2\"\n",
+			"",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1", "t2"}, nil)
+			},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+			format:          "ndjson",
+			readLimit:       2,
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestCSVFormat(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	row := &domain.Row{
+		Key: "a,1",
+		Columns: []*domain.Column{
+			&domain.Column{Family: "d", Qualifier: "d:name", Value: []byte(`say "hi!"`), Version: tm},
+			&domain.Column{Family: "d", Qualifier: "d:count", Value: []byte{0, 0, 0, 0, 0, 0, 0, 2}, Version: tm},
+		},
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+	cells := `"a,1",d,name,2018-01-01T00:00:00Z,"say ""hi!"""` + "\n" + `"a,1",d,count,2018-01-01T00:00:00Z,2` + "\n"
+
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"lookup table a,1",
+			cells,
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Get(gomock.Any(), "table", "a,1", latest).Return(&domain.Bigtable{Rows: []*domain.Row{row}}, nil)
+			},
+		},
+		{
+			"read table count=0",
+			cells,
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), latest).DoAndReturn(
+					func(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+						f(row)
+						return nil
+					})
+			},
+		},
+		{
+			"ls",
+			"t1\n\"t,2\"\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1", "t,2"}, nil)
+			},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+			format:          "csv",
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+		assert.Equal(t, "", errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestTSVFormat(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	row := &domain.Row{
+		Key: "a\t1",
+		Columns: []*domain.Column{
+			&domain.Column{Family: "d", Qualifier: "d:name", Value: []byte("x\\y\nz"), Version: tm},
+			&domain.Column{Family: "d", Qualifier: "d:count", Value: []byte{0, 0, 0, 0, 0, 0, 0, 2}, Version: tm},
+		},
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+	cells := "a\\t1\td:name\t2018-01-01T00:00:00Z\tx\\\\y\\nz\n" + "a\\t1\td:count\t2018-01-01T00:00:00Z\t2\n"
+
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"lookup table a",
+			cells,
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Get(gomock.Any(), "table", "a", latest).Return(&domain.Bigtable{Rows: []*domain.Row{row}}, nil)
+			},
+		},
+		{
+			"read table count=0",
+			cells,
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), latest).DoAndReturn(
+					func(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+						f(row)
+						return nil
+					})
+			},
+		},
+		{
+			"ls",
+			"t1\nt2\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1", "t2"}, nil)
+			},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+			format:          "tsv",
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+		assert.Equal(t, "", errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestPlainFormat(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	row := &domain.Row{
+		Key: "a\t1",
+		Columns: []*domain.Column{
+			&domain.Column{Family: "d", Qualifier: "d:name", Value: []byte("x\ny"), Version: tm},
+			&domain.Column{Family: "d", Qualifier: "d:count", Value: []byte{0, 0, 0, 0, 0, 0, 0, 2}, Version: tm},
+			// the control characters don't reach the terminal
+			&domain.Column{Family: "d", Qualifier: "d:raw", Value: []byte("\x1b[2Jz"), Version: tm},
+		},
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+	cells := "a\\t1\td:name\tx\\ny\n" + "a\\t1\td:count\t2\n" + "a\\t1\td:raw\t\\x1b[2Jz\n"
+
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"lookup table a",
+			cells,
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Get(gomock.Any(), "table", "a", latest).Return(&domain.Bigtable{Rows: []*domain.Row{row}}, nil)
+			},
+		},
+		{
+			"read table count=0",
+			cells,
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), latest).DoAndReturn(
+					func(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+						f(row)
+						return nil
+					})
+			},
+		},
+		{
+			"ls",
+			"t1\nt2\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1", "t2"}, nil)
+			},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+			format:          "plain",
+			color:           true,
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+		// no separators
+		assert.Equal(t, "", errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestTemplateFormat(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []*domain.Row{
+		&domain.Row{
+			Key: "a",
+			Columns: []*domain.Column{
+				&domain.Column{Family: "d", Qualifier: "d:name", Value: []byte("x"), Version: tm},
+				&domain.Column{Family: "d", Qualifier: "d:count", Value: []byte{0, 0, 0, 0, 0, 0, 0, 2}, Version: tm},
+			},
+		},
+		&domain.Row{Key: "b"},
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+	lookup := func(mock *repository.MockBigtable) {
+		mock.EXPECT().Get(gomock.Any(), "table", "a", latest).Return(&domain.Bigtable{Rows: rows[:1]}, nil)
+	}
+	read := func(mock *repository.MockBigtable) {
+		mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), latest).DoAndReturn(
+			func(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+				for _, r := range rows {
+					f(r)
+				}
+				return nil
+			})
+	}
+
+	cases := []struct {
+		input     string
+		template  string
+		expect    string
+		expectErr string
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			`lookup table a template='{{.Key}}\t{{index .Cells "d:name"}}'`,
+			"",
+			"a\tx\n",
+			"",
+			lookup,
+		},
+		{
+			"lookup table a format=template",
+			`{{range .Columns}}{{.Qualifier}}={{.Value}} {{end}}\n`,
+			"name=x count=2 \n",
+			"",
+			lookup,
+		},
+		{
+			"read table count=0 format=template",
+			`{{.Key}}:{{index .Cells "d:count"}}`,
+			"a:2\nb:<no value>\n",
+			"",
+			read,
+		},
+		{
+			"read table count=0 template='{{.Key.Foo}}'",
+			"",
+			"",
+			"Invalid template: template: row:1:6: executing \"row\" at <.Key.Foo>: can't evaluate field Foo in type string\n",
+			read,
+		},
+		{
+			"lookup table a format=template",
+			"",
+			"",
+			"Invalid template: no template, give -template, \"format template <template>\" or template=<template>\n",
+			lookup,
+		},
+		{
+			"lookup table a template='{{.Key'",
+			"",
+			"",
+			"Invalid options: template: template: row:1: unclosed action\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"ls",
+			"{{.Key}}",
+			"t1\nt2\n",
+			"",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1", "t2"}, nil)
+			},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+		}
+		if c.template != "" {
+			tmpl, err := parseRowTemplate(c.template)
+			if err != nil {
+				t.Fatalf("#%d: want no error, got %v", i, err)
+			}
+			executor.format = "template"
+			executor.template = tmpl
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestDoFormatTemplate(t *testing.T) {
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream: &out,
+		errStream: &errOut,
+	}
+	executor.Do("format template")
+	executor.Do("format template '{{.Key'")
+	executor.Do("format template {{.Key}}  {{len .Columns}}\t{{\"a\\\"b\"}}")
+	executor.Do("format json")
+	executor.Do("format template")
+	assert.Equal(t, "", out.String())
+	assert.Equal(t, "Invalid args: format template <template>\n"+
+		"Invalid template: template: row:1: unclosed action\n"+
+		"Print the rows in template\nPrint the rows in json\nPrint the rows in template\n", errOut.String())
+	assert.Equal(t, "template", executor.format)
+
+	// the spaces and the backslashes other than \t and \n are kept
+	var b bytes.Buffer
+	executor.template.Execute(&b, templateRow{Key: "a", Columns: make([]jsonCell, 2)})
+	assert.Equal(t, "a  2\ta\"b", b.String())
+
+	executor.Do("format template '{{.Key}}  x'")
+	b.Reset()
+	executor.template.Execute(&b, templateRow{Key: "a"})
+	assert.Equal(t, "a  x", b.String())
+}
+
+func TestYAMLFormat(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []*domain.Row{
+		&domain.Row{
+			Key: "madoka",
+			Columns: []*domain.Column{
+				&domain.Column{Family: "d", Qualifier: "d:1", Value: []byte("1"), Version: tm},
+				&domain.Column{Family: "d'", Qualifier: "d':10", Value: []byte("a: b"), Version: tm},
+			},
+		},
+		&domain.Row{
+			Key: "homura",
+			Columns: []*domain.Column{
+				&domain.Column{Family: "d", Qualifier: "d:1", Value: []byte("2"), Version: tm},
+				&domain.Column{Family: "d", Qualifier: "d:1", Value: []byte("true"), Version: tm.Add(90 * time.Minute)},
+			},
+		},
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"read table",
+			"table: table\n" +
+				"column_families:\n" +
+				"  - family: d\n" +
+				"    columns:\n" +
+				"      - key: 1\n" +
+				"        version: 2018-01-01 00:00:00 +00:00\n" +
+				"        rows:\n" +
+				"          madoka: 1\n" +
+				"          homura: 2\n" +
+				"      - key: 1\n" +
+				"        version: 2018-01-01 01:30:00 +00:00\n" +
+				"        rows:\n" +
+				"          homura: \"true\"\n" +
+				"  - family: \"d'\"\n" +
+				"    columns:\n" +
+				"      - key: 10\n" +
+				"        version: 2018-01-01 00:00:00 +00:00\n" +
+				"        rows:\n" +
+				"          madoka: \"a: b\"\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowRange{}, latest).Return(&domain.Bigtable{Rows: rows}, nil)
+			},
+		},
+		{
+			"read table prefix=z",
+			"table: table\ncolumn_families: []\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("z"), latest).Return(&domain.Bigtable{}, nil)
+			},
+		},
+		{
+			"ls",
+			"- t1\n- \"t 2 \"\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1", "t 2 "}, nil)
+			},
+		},
+		// the invalid UTF-8 in base64 with the !!binary tag
+		{
+			"read table prefix=b",
+			"table: table\n" +
+				"column_families:\n" +
+				"  - family: d\n" +
+				"    columns:\n" +
+				"      - key: !!binary /g==\n" +
+				"        version: 2018-01-01 00:00:00 +00:00\n" +
+				"        rows:\n" +
+				"          !!binary Yv8=: !!binary 44E=\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("b"), latest).Return(&domain.Bigtable{Rows: []*domain.Row{
+					{Key: "b\xff", Columns: []*domain.Column{{Family: "d", Qualifier: "d:\xfe", Value: []byte("\xe3\x81"), Version: rows[0].Columns[0].Version}}},
+				}}, nil)
+			},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+			format:          "yaml",
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+		assert.Equal(t, "", errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestGridFormat(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)
+	rows := []*domain.Row{
+		&domain.Row{
+			Key: "a",
+			Columns: []*domain.Column{
+				&domain.Column{Family: "d", Qualifier: "d:a_long_qualifier_of_the_column", Value: []byte("a1"), Version: tm},
+			},
+		},
+		&domain.Row{
+			Key: "bbbbbb",
+			Columns: []*domain.Column{
+				&domain.Column{Family: "d", Qualifier: "d:q", Value: []byte("b1"), Version: tm},
+			},
+		},
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"read table decode=string",
+			"+--------+----------------------------------+----------------------------+-------+\n" +
+				"| key    | qualifier                        | timestamp                  | value |\n" +
+				"+--------+----------------------------------+----------------------------+-------+\n" +
+				"| a      | d:a_long_qualifier_of_the_column | 2018/01/01-00:00:00.000000 | \"a1\"  |\n" +
+				"| bbbbbb | d:q                              | 2018/01/01-00:00:00.000000 | \"b1\"  |\n" +
+				"+--------+----------------------------------+----------------------------+-------+\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowRange{}, latest).Return(&domain.Bigtable{Rows: rows}, nil)
+			},
+		},
+		{
+			"read table prefix=z",
+			"+-----+-----------+-----------+-------+\n" +
+				"| key | qualifier | timestamp | value |\n" +
+				"+-----+-----------+-----------+-------+\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("z"), latest).Return(&domain.Bigtable{}, nil)
+			},
+		},
+		{
+			"ls",
+			"+--------+\n| table  |\n+--------+\n| t1     |\n| events |\n+--------+\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1", "events"}, nil)
+			},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+			format:          "table",
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+		assert.Equal(t, "", errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestWideFormat(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)
+	rows := []*domain.Row{
+		&domain.Row{
+			Key: "a",
+			Columns: []*domain.Column{
+				&domain.Column{Family: "d", Qualifier: "d:name", Value: []byte("x"), Version: tm},
+				&domain.Column{Family: "d", Qualifier: "d:count", Value: []byte{0, 0, 0, 0, 0, 0, 0, 2}, Version: tm},
+			},
+		},
+		&domain.Row{
+			Key: "b\x00",
+			Columns: []*domain.Column{
+				&domain.Column{Family: "d", Qualifier: "d:name", Value: []byte("y2"), Version: tm.Add(time.Second)},
+				&domain.Column{Family: "d", Qualifier: "d:name", Value: []byte("y1"), Version: tm},
+				&domain.Column{Family: "e", Qualifier: "e:flag", Value: []byte("on"), Version: tm},
+			},
+		},
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"read table",
+			"+---------+--------+---------+--------+\n" +
+				"| key     | d:name | d:count | e:flag |\n" +
+				"+---------+--------+---------+--------+\n" +
+				"| a       | \"x\"    | 2       |        |\n" +
+				"| 'b\\x00' | \"y2\"   |         | \"on\"   |\n" +
+				"+---------+--------+---------+--------+\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowRange{}, latest).Return(&domain.Bigtable{Rows: rows}, nil)
+			},
+		},
+		{
+			"read table prefix=z",
+			"+-----+\n| key |\n+-----+\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("z"), latest).Return(&domain.Bigtable{}, nil)
+			},
+		},
+		{
+			"ls",
+			"+-------+\n| table |\n+-------+\n| t1    |\n+-------+\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1"}, nil)
+			},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+			format:          "wide",
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+		assert.Equal(t, "", errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestBinarySafeFields(t *testing.T) {
+	cases := []struct {
+		key, qualifier, value string
+		expect                []string
+	}{
+		{"a", "name", "line1\nline2\ttab", []string{"a", "name", "line1\nline2\ttab", ""}},
+		{"a\x00b", "name", "\xff\x00", []string{"YQBi", "name", "/wA=", "key+value"}},
+		{"a", "\x01", "日本", []string{"a", "AQ==", "日本", "qualifier"}},
+	}
+	w := &Printer{binaryEncoding: binaryEncodingBase64}
+	for i, c := range cases {
+		key, qualifier, value, marker := w.binarySafeFields(c.key, c.qualifier, c.value)
+		assert.Equal(t, c.expect, append([]string{key, qualifier, value}, marker...), "#%d", i)
+
+		typ, err := decodeBinaryFields(marker[0], &key, &qualifier, &value)
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, "", typ, "#%d", i)
+		assert.Equal(t, []string{c.key, c.qualifier, c.value}, []string{key, qualifier, value}, "#%d", i)
+	}
+
+	// raw writes the bytes without the marker column
+	key, qualifier, value, marker := (&Printer{binaryEncoding: binaryEncodingRaw}).binarySafeFields("a\x00", "q", "\xff")
+	assert.Equal(t, []string{"a\x00", "q", "\xff"}, []string{key, qualifier, value})
+	assert.Nil(t, marker)
+}
+
+func TestTSVText(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+	}{
+		{"a\tb\\c", `a\tb\\c`},
+		{"q\x1b[2J\t", `q\x1b[2J\t`},
+		{"\xff日本", `\xff日本`},
+	}
+	for i, c := range cases {
+		assert.Equal(t, c.expect, tsvText(c.input), "#%d", i)
+	}
+}
@@ -0,0 +1,120 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/takashabe/btcli/api/domain"
+)
+
+// defaultGrepLimit is a number of the rows scanned by the grep command at most
+const defaultGrepLimit = 10000
+
+func doGrep(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "grep <table> <pattern> [start=<row>] [end=<row>] [prefix=<prefix>] [limit=<n>] [args ...]")
+		return
+	}
+	table := args[1]
+	re, err := regexp.Compile(args[2])
+	if err != nil {
+		e.failf("Invalid pattern: %v\n", err)
+		return
+	}
+
+	parsed := make(map[string]string)
+	for _, arg := range args[3:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "decode", "decode_columns":
+			parsed[k] = v
+		case "start", "end", "prefix", "limit", "app-profile":
+			parsed[k] = v
+		case "version", "versions", "family", "columns", "qualifier-regex", "from", "to":
+			parsed[k] = v
+		}
+	}
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		e.failln(`"start"/"end" may not be mixed with "prefix"`)
+		return
+	}
+	limit := defaultGrepLimit
+	if v := parsed["limit"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			e.failf("Invalid limit: %v\n", v)
+			return
+		}
+		limit = n
+	}
+	if err := validatePrinterOption(parsed); err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+	defaultVersions(parsed)
+
+	rr, err := rowRange(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid range: %v\n"), err)
+		return
+	}
+	filters, err := readFilters(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+	// read an extra row to know whether the range has more rows than the limit
+	opts := append([]bigtable.ReadOption{bigtable.LimitRows(int64(limit + 1))}, rowFilterOption(filters...)...)
+
+	rows, err := e.rowsInteractor.GetRows(e.requestContext(parsed), table, rr, opts...)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	truncated := len(rows) > limit
+	if truncated {
+		rows = rows[:limit]
+	}
+
+	p := e.newPrinter(parsed)
+	p.valueMatch = re
+	for _, r := range rows {
+		if p.matchRow(re, r) {
+			p.printRow(r)
+		}
+	}
+	if truncated {
+		fmt.Fprintf(e.errStream, "Stopped after scanning %s rows, the rest of the range isn't searched. raise \"limit\" to scan more\n", e.formatNumber(int64(limit)))
+	}
+}
+
+// matchRow reports whether the row has a cell whose decoded value matches the pattern
+func (w *Printer) matchRow(re *regexp.Regexp, r *domain.Row) bool {
+	for _, c := range r.Columns {
+		if re.MatchString(w.plainValue(c.Qualifier, c.Value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// plainValue returns the decoded value without quoting the strings
+func (w *Printer) plainValue(q string, v []byte) string {
+	s := w.formatValue(q, v)
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
@@ -0,0 +1,82 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoGrep(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+	rows := &domain.Bigtable{
+		Rows: []*domain.Row{
+			{Key: "a1", Columns: []*domain.Column{
+				{Family: "d", Qualifier: "d:status", Value: []byte{0, 0, 0, 0, 0, 0, 0x01, 0xf4}, Version: tm}, // 500
+			}},
+			{Key: "a2", Columns: []*domain.Column{
+				{Family: "d", Qualifier: "d:status", Value: []byte{0, 0, 0, 0, 0, 0, 0, 0xc8}, Version: tm}, // 200
+			}},
+			{Key: "a3", Columns: []*domain.Column{
+				{Family: "d", Qualifier: "d:name", Value: []byte("error: timeout"), Version: tm},
+			}},
+		},
+	}
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"grep table ^5 prefix=a",
+			"----------------------------------------\n" +
+				"a1\n" +
+				"  d:status                                 @ 2018/01/01-00:00:00.000000\n" +
+				"    500\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("a"), bigtable.LimitRows(10001), latest).Return(rows, nil)
+			},
+		},
+		{
+			"grep table ^error: limit=2",
+			"Stopped after scanning 2 rows, the rest of the range isn't searched. raise \"limit\" to scan more\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowRange{}, bigtable.LimitRows(3), latest).Return(rows, nil)
+			},
+		},
+		{
+			"grep table ( prefix=a",
+			"Invalid pattern: error parsing regexp: missing closing ): `(`\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"grep table a limit=0",
+			"Invalid limit: 0\n",
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		defer ctrl.Finish()
+
+		c.prepare(mockBtRepo)
+
+		var buf bytes.Buffer
+		executor := Executor{
+			outStream:      &buf,
+			errStream:      &buf,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		}
+
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, buf.String(), c.input)
+	}
+}
@@ -0,0 +1,55 @@
+package interfaces
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorHints maps the gRPC codes to the remediation hints
+// {project} and {instance} in the hints are replaced with the connected ones
+var errorHints = map[codes.Code]string{
+	codes.PermissionDenied: `The credential lacks an IAM permission on project "{project}".
+Grant "roles/bigtable.reader" to read, or "roles/bigtable.user" to write, and "roles/bigtable.admin" for the table management.
+Check the roles with: gcloud projects get-iam-policy {project}`,
+	codes.NotFound: `The instance or the table is not found.
+Check the instance "{instance}" with: gcloud bigtable instances list --project={project}
+Check the table names with: ls`,
+	codes.ResourceExhausted: `The quota or the resource is exhausted.
+Check the quota of project "{project}": https://console.cloud.google.com/iam-admin/quotas?project={project}`,
+	codes.Unauthenticated: `The credential is invalid or expired.
+Run "gcloud auth application-default login", or give a credential file with -creds`,
+	codes.Unavailable:      `Bigtable is unavailable. Check the network and the status: https://status.cloud.google.com`,
+	codes.DeadlineExceeded: `The request timed out. Narrow the range with prefix=/start=/end= or count=`,
+}
+
+// printError prints the error and the remediation hint, the command is failed
+func (e *Executor) printError(err error) {
+	e.failed = true
+	fmt.Fprintf(e.errStream, "%v\n", err)
+	if hint := errorHint(err, e.project, e.instance, e.locale); hint != "" {
+		fmt.Fprintf(e.errStream, e.msg("Hint: %s\n"), hint)
+	}
+}
+
+// failf prints the error of the command failed by the args or the state
+func (e *Executor) failf(format string, a ...interface{}) {
+	e.failed = true
+	fmt.Fprintf(e.errStream, format, a...)
+}
+
+// failln is failf printing the operands in a line
+func (e *Executor) failln(a ...interface{}) {
+	e.failed = true
+	fmt.Fprintln(e.errStream, a...)
+}
+
+func errorHint(err error, project, instance, locale string) string {
+	hint, ok := errorHints[status.Code(err)]
+	if !ok {
+		return ""
+	}
+	return strings.NewReplacer("{project}", project, "{instance}", instance).Replace(translate(locale, hint))
+}
@@ -0,0 +1,37 @@
+package interfaces
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPrintError(t *testing.T) {
+	cases := []struct {
+		err    error
+		expect string
+	}{
+		{
+			errors.New("a"),
+			"a\n",
+		},
+		{
+			status.Error(codes.ResourceExhausted, "a"),
+			"rpc error: code = ResourceExhausted desc = a\nHint: The quota or the resource is exhausted.\nCheck the quota of project \"p\": https://console.cloud.google.com/iam-admin/quotas?project=p\n",
+		},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		e := &Executor{
+			errStream: &buf,
+			project:   "p",
+			instance:  "i",
+		}
+		e.printError(c.err)
+		assert.Equal(t, c.expect, buf.String())
+	}
+}
@@ -0,0 +1,85 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/takashabe/btcli/api/domain"
+)
+
+const defaultHistorySince = time.Hour
+
+func doHistory(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "history <table> <row> [since=<duration>] [full-values=true]")
+		return
+	}
+	table := args[1]
+	key := args[2]
+
+	parsed := make(map[string]string)
+	for _, arg := range args[3:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "decode", "decode_columns", "full-values":
+			parsed[k] = v
+		case "since":
+			parsed[k] = v
+		}
+	}
+
+	since := defaultHistorySince
+	if v := parsed["since"]; v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			e.failf("Invalid since: %v\n", err)
+			return
+		}
+		since = d
+	}
+
+	changes, err := e.rowsInteractor.GetRowHistory(ctx, table, key, time.Now().Add(-since))
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Fprintf(e.errStream, "No changes of %s in the last %s\n", escapeKey(key), since)
+		return
+	}
+
+	p := e.newPrinter(parsed)
+	p.printRowChanges(changes)
+}
+
+// printRowChanges prints the timeline of the changes, the change stream doesn't record the user of the change
+func (w *Printer) printRowChanges(changes []*domain.RowChange) {
+	for _, c := range changes {
+		fmt.Fprintln(w.errStream, strings.Repeat("-", 40))
+		fmt.Fprintf(w.outStream, "%s  %s  cluster=%s\n", w.timestamps.format(c.Time), c.Type, c.Cluster)
+		for _, m := range c.Mutations {
+			switch m.Type {
+			case domain.MutationSetCell:
+				q := m.Family + ":" + m.Qualifier
+				fmt.Fprintf(w.outStream, "  %s %-36s @ %s\n", m.Type, q, w.timestamps.format(m.Version))
+				w.printValue(q, m.Value)
+			case domain.MutationDeleteColumn:
+				fmt.Fprintf(w.outStream, "  %s %s:%s\n", m.Type, m.Family, m.Qualifier)
+			case domain.MutationDeleteFamily:
+				fmt.Fprintf(w.outStream, "  %s %s\n", m.Type, m.Family)
+			default:
+				fmt.Fprintf(w.outStream, "  %s\n", m.Type)
+			}
+		}
+	}
+}
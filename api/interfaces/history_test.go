@@ -0,0 +1,79 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoHistory(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"history table a decode=string",
+			"----------------------------------------\n" +
+				"2018/01/01-00:00:00.000000  user  cluster=c1\n" +
+				"  set d:row                                @ 2018/01/01-00:00:00.000000\n" +
+				"    \"a1\"\n" +
+				"  delete-column d:old\n" +
+				"----------------------------------------\n" +
+				"2018/01/01-00:00:01.000000  gc  cluster=c1\n" +
+				"  delete-family e\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().RowHistory(gomock.Any(), "table", "a", gomock.Any()).Return([]*domain.RowChange{
+					{
+						Time:    tm,
+						Type:    domain.RowChangeUser,
+						Cluster: "c1",
+						Mutations: []*domain.Mutation{
+							{Type: domain.MutationSetCell, Family: "d", Qualifier: "row", Version: tm, Value: []byte("a1")},
+							{Type: domain.MutationDeleteColumn, Family: "d", Qualifier: "old"},
+						},
+					},
+					{
+						Time:    tm.Add(time.Second),
+						Type:    domain.RowChangeGC,
+						Cluster: "c1",
+						Mutations: []*domain.Mutation{
+							{Type: domain.MutationDeleteFamily, Family: "e"},
+						},
+					},
+				}, nil)
+			},
+		},
+		{
+			"history table a since=2h",
+			"No changes of a in the last 2h0m0s\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().RowHistory(gomock.Any(), "table", "a", gomock.Any()).Return([]*domain.RowChange{}, nil)
+			},
+		},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		defer ctrl.Finish()
+
+		c.prepare(mockBtRepo)
+
+		var buf bytes.Buffer
+		executor := Executor{
+			outStream:      &buf,
+			errStream:      &buf,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		}
+
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, buf.String())
+	}
+}
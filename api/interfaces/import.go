@@ -0,0 +1,664 @@
+package interfaces
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/takashabe/btcli/api/domain"
+)
+
+// importFormats are the formats of import, the stream formats written by export
+var importFormats = []string{"ndjson", "csv", "tsv"}
+
+// maxImportErrors is a number of the errors reported by import
+const maxImportErrors = 20
+
+func doImport(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "import <table> <file> [args ...]")
+		return
+	}
+	table, file := args[1], args[2]
+
+	parsed := make(map[string]string)
+	for _, arg := range args[3:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "format", "manifest", "validate-only", "create-family", "family-policy", "ignore-decoders":
+			parsed[k] = v
+		case "decode", "decode_columns":
+			parsed[k] = v
+		case "app-profile":
+			parsed[k] = v
+		}
+	}
+	validateOnly := false
+	if v := parsed["validate-only"]; v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			e.failf("Invalid validate-only: %v\n", v)
+			return
+		}
+		validateOnly = b
+	}
+	createFamily := false
+	if v := parsed["create-family"]; v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			e.failf("Invalid create-family: %v\n", v)
+			return
+		}
+		createFamily = b
+	}
+	ignoreDecoders := false
+	if v := parsed["ignore-decoders"]; v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			e.failf("Invalid ignore-decoders: %v\n", v)
+			return
+		}
+		ignoreDecoders = b
+	}
+	// the GC policy of the created families, no GC policy unless given
+	policy, err := parseFamilyPolicy("the created families", parsed["family-policy"])
+	if err != nil {
+		e.failf("Invalid family-policy: %v\n", err)
+		return
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	// the manifest written by export is used unless given
+	manifestFile := parsed["manifest"]
+	if manifestFile == "" {
+		if _, err := os.Stat(file + manifestSuffix); err == nil {
+			manifestFile = file + manifestSuffix
+		}
+	}
+	var m *exportManifest
+	if manifestFile != "" {
+		if m, err = readManifest(manifestFile); err != nil {
+			e.printError(err)
+			return
+		}
+	}
+	format := importFormat(parsed["format"], m, file)
+	if !streamFormats[format] {
+		e.failf("Invalid format: %v, must be one of %s\n", format, strings.Join(importFormats, ", "))
+		return
+	}
+
+	info, err := e.tableInteractor.GetTableInfo(ctx, table)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	im := newImporter(info, m, parsed)
+	im.columnDecoders = e.decoders
+	im.createFamily = createFamily
+	im.ignoreDecoders = ignoreDecoders
+	if m != nil {
+		im.checkManifest(m, format, data)
+	}
+	switch format {
+	case "ndjson":
+		im.readNDJSON(data)
+	case "csv":
+		im.readCSV(data)
+	case "tsv":
+		im.readTSV(data)
+	}
+	if m != nil && m.Rows != len(im.rows) {
+		im.fileError("%d rows unlike %d rows of the manifest", len(im.rows), m.Rows)
+	}
+	im.printErrors(e.errStream)
+
+	if validateOnly {
+		if len(im.newFamilies) > 0 {
+			fmt.Fprintf(e.errStream, "Families %s would be created\n", strings.Join(im.newFamilies, ", "))
+		}
+		fmt.Fprintf(e.errStream, "Validated %s rows and %s cells of %s, %s errors\n",
+			e.formatNumber(int64(len(im.rows))), e.formatNumber(int64(im.cells)), file, e.formatNumber(int64(len(im.errs))))
+		return
+	}
+	if len(im.errs) > 0 {
+		e.failf("Aborted by %s errors, no rows are written\n", e.formatNumber(int64(len(im.errs))))
+		return
+	}
+	for _, name := range im.newFamilies {
+		f := *policy
+		f.Name = name
+		if err := e.tableInteractor.CreateFamily(ctx, table, &f); err != nil {
+			e.printError(err)
+			return
+		}
+		fmt.Fprintf(e.errStream, "Created family %s of %s\n", name, table)
+	}
+	if len(im.newFamilies) > 0 {
+		e.clearMetadata()
+	}
+	if err := e.rowsInteractor.WriteRows(e.requestContext(parsed), table, im.rows); err != nil {
+		e.printError(err)
+		return
+	}
+	fmt.Fprintf(e.errStream, "Imported %s rows and %s cells to %s\n",
+		e.formatNumber(int64(len(im.rows))), e.formatNumber(int64(im.cells)), table)
+}
+
+// importValidateOnly reports whether import only validates the file
+func importValidateOnly(args []string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "validate-only=") {
+			b, _ := strconv.ParseBool(strings.TrimPrefix(arg, "validate-only="))
+			return b
+		}
+	}
+	return false
+}
+
+// importFormat returns the format of the option, the manifest or the extension of the file in the order
+func importFormat(format string, m *exportManifest, file string) string {
+	switch {
+	case format != "":
+		return format
+	case m != nil:
+		return m.Format
+	}
+	switch filepath.Ext(file) {
+	case ".csv":
+		return "csv"
+	case ".tsv":
+		return "tsv"
+	}
+	return defaultExportFormat
+}
+
+func readManifest(file string) (*exportManifest, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	m := &exportManifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("%s isn't a manifest: %v", file, err)
+	}
+	return m, nil
+}
+
+// importError is an error of a line, the line is 0 for the errors of the file
+type importError struct {
+	line int
+	msg  string
+}
+
+// importer parses the rows of the file, the errors of the rows and the fields are collected instead of stopping
+type importer struct {
+	families map[string]bool
+	// decodeType and decodeColumnType are the decode options, same as the Printer
+	decodeType       string
+	decodeColumnType map[string]string
+	// manifestDecodes are the decodes of the columns of the manifest, nil without the manifest
+	manifestDecodes map[string]string
+	// columnDecoders are the configured decodes of the columns used without the manifest,
+	// the values encoded by the other decodes are checked against them unless ignoreDecoders
+	columnDecoders map[string]string
+	ignoreDecoders bool
+	utf8Keys       bool
+	// createFamily adds the unknown families to newFamilies instead of the errors, created before writing the rows
+	createFamily bool
+	newFamilies  []string
+
+	rows  []*domain.Row
+	cells int
+	errs  []importError
+}
+
+func newImporter(info *domain.TableInfo, m *exportManifest, parsed map[string]string) *importer {
+	im := &importer{
+		families:         map[string]bool{},
+		decodeType:       parsed["decode"],
+		decodeColumnType: decodeColumnOption(parsed),
+	}
+	for _, f := range info.Families {
+		im.families[f.Name] = true
+	}
+	if m != nil {
+		im.manifestDecodes = map[string]string{}
+		for _, c := range m.Columns {
+			im.manifestDecodes[c.Column] = c.Decode
+		}
+		im.utf8Keys = m.KeyFormat == keyFormatUTF8
+	}
+	return im
+}
+
+// checkManifest checks the file and the format against the manifest
+func (im *importer) checkManifest(m *exportManifest, format string, data []byte) {
+	if m.Format != format {
+		im.fileError("format %s unlike %s of the manifest", format, m.Format)
+	}
+	sum := sha256.Sum256(data)
+	if fp := "sha256:" + hex.EncodeToString(sum[:]); fp != m.Fingerprint {
+		im.fileError("fingerprint %s unlike %s of the manifest, the file is modified", fp, m.Fingerprint)
+	}
+}
+
+func (im *importer) fileError(format string, args ...interface{}) {
+	im.errs = append(im.errs, importError{msg: fmt.Sprintf(format, args...)})
+}
+
+func (im *importer) lineError(line int, format string, args ...interface{}) {
+	im.errs = append(im.errs, importError{line: line, msg: fmt.Sprintf(format, args...)})
+}
+
+func (im *importer) printErrors(out io.Writer) {
+	for i, err := range im.errs {
+		if i == maxImportErrors {
+			fmt.Fprintf(out, "... %d more errors\n", len(im.errs)-maxImportErrors)
+			return
+		}
+		if err.line == 0 {
+			fmt.Fprintf(out, "%s\n", err.msg)
+		} else {
+			fmt.Fprintf(out, "line %d: %s\n", err.line, err.msg)
+		}
+	}
+}
+
+// importValue is a value of the file, number is true for the JSON numbers
+type importValue struct {
+	text   string
+	number bool
+	// typ is the type of the number recorded by export, used by the values without the decode
+	typ string
+}
+
+// addCell appends the cell to the last row of the key, or a new row
+func (im *importer) addCell(line int, key, family, qualifier, timestamp string, v importValue) {
+	if key == "" {
+		im.lineError(line, "empty key")
+		return
+	}
+	if im.utf8Keys && !utf8.ValidString(key) {
+		im.lineError(line, "key %q isn't UTF-8 unlike the manifest", key)
+		return
+	}
+	if !im.families[family] {
+		if !im.createFamily || family == "" {
+			im.lineError(line, "unknown family %q", family)
+			return
+		}
+		im.families[family] = true
+		im.newFamilies = append(im.newFamilies, family)
+	}
+	column := family + ":" + qualifier
+	if im.manifestDecodes != nil {
+		if _, ok := im.manifestDecodes[column]; !ok {
+			im.lineError(line, "column %s isn't in the manifest", column)
+			return
+		}
+	}
+	var version time.Time
+	if timestamp != "" {
+		t, err := time.Parse(time.RFC3339Nano, timestamp)
+		if err != nil {
+			im.lineError(line, "%s: invalid timestamp %q", column, timestamp)
+			return
+		}
+		// the zero time of the cells without the version
+		if !t.Equal(time.Time{}) {
+			version = t
+		}
+	}
+	decode := im.decodeTypeOf(column)
+	value, err := encodeValue(decode, v)
+	if err != nil {
+		im.lineError(line, "%s: %v", column, err)
+		return
+	}
+	if d := im.columnDecoders[column]; d != "" && !im.ignoreDecoders {
+		if err := checkColumnDecoder(d, value); err != nil {
+			im.lineError(line, "%s: %v, written by ignore-decoders=true", column, err)
+			return
+		}
+	}
+
+	c := &domain.Column{Family: family, Qualifier: column, Value: value, Version: version}
+	if n := len(im.rows); n > 0 && im.rows[n-1].Key == key {
+		im.rows[n-1].Columns = append(im.rows[n-1].Columns, c)
+	} else {
+		im.rows = append(im.rows, &domain.Row{Key: key, Columns: []*domain.Column{c}})
+	}
+	im.cells++
+}
+
+// decodeTypeOf returns the decode of the column by the options, then by the manifest
+func (im *importer) decodeTypeOf(column string) string {
+	if d, ok := im.decodeColumnType[column[strings.Index(column, ":")+1:]]; ok {
+		return d
+	}
+	if im.decodeType != "" {
+		return im.decodeType
+	}
+	if im.manifestDecodes != nil {
+		return im.manifestDecodes[column]
+	}
+	return im.columnDecoders[column]
+}
+
+// checkColumnDecoder checks the value against the decoder of the column,
+// so that e.g. the text isn't written into the counters of int
+func checkColumnDecoder(decoder string, value []byte) error {
+	switch decoder {
+	case decodeTypeInt, decodeTypeFloat:
+		if len(value) != 8 {
+			return fmt.Errorf("%d bytes value isn't %s of the decoders", len(value), decoder)
+		}
+	case decodeTypeJSON:
+		if !json.Valid(value) {
+			return fmt.Errorf("value %q isn't json of the decoders", value)
+		}
+	case decodeTypeString:
+		if !utf8.Valid(value) {
+			return fmt.Errorf("value %q isn't string of the decoders", value)
+		}
+	}
+	return nil
+}
+
+// encodeValue returns the bytes of the value printed by the decode
+func encodeValue(decode string, v importValue) ([]byte, error) {
+	switch decode {
+	case decodeTypeInt:
+		n, err := strconv.ParseInt(v.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int %q", v.text)
+		}
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, uint64(n))
+		return b, nil
+	case decodeTypeFloat:
+		f, err := strconv.ParseFloat(v.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %q", v.text)
+		}
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, math.Float64bits(f))
+		return b, nil
+	case decodeTypeBase64:
+		b, err := base64.StdEncoding.DecodeString(v.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 %q", v.text)
+		}
+		return b, nil
+	case decodeTypeString, decodeTypeHex:
+		// the JSON, the csv and the tsv have the raw values of them
+		return []byte(v.text), nil
+	case decodeTypeJSON:
+		// the compacted JSON of the values of export
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(v.text)); err != nil {
+			return nil, fmt.Errorf("invalid JSON %q", v.text)
+		}
+		return buf.Bytes(), nil
+	case "", "auto":
+		if v.typ == decodeTypeInt || v.typ == decodeTypeFloat {
+			return encodeValue(v.typ, v)
+		}
+		if !v.number {
+			return []byte(v.text), nil
+		}
+		// the 8 bytes numbers guessed by export
+		if _, err := strconv.ParseInt(v.text, 10, 64); err == nil {
+			return encodeValue(decodeTypeInt, v)
+		}
+		return encodeValue(decodeTypeFloat, v)
+	}
+	if isStructuredDecode(decode) {
+		return nil, fmt.Errorf("%s values can't be encoded", decode)
+	}
+	return nil, fmt.Errorf("unknown decode %q", decode)
+}
+
+func (im *importer) readNDJSON(data []byte) {
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(nil, len(data)+1)
+	for line := 1; s.Scan(); line++ {
+		if len(bytes.TrimSpace(s.Bytes())) == 0 {
+			continue
+		}
+		d := json.NewDecoder(bytes.NewReader(s.Bytes()))
+		d.UseNumber()
+		var r jsonRow
+		if err := d.Decode(&r); err != nil {
+			im.lineError(line, "invalid JSON: %v", err)
+			continue
+		}
+		if len(r.Cells) == 0 {
+			im.lineError(line, "row %q has no cells", r.Key)
+			continue
+		}
+		key, err := decodeJSONText(r.Key, r.KeyEncoding)
+		if err != nil {
+			im.lineError(line, "key: %v", err)
+			continue
+		}
+		for _, c := range r.Cells {
+			if c.Qualifier, err = decodeJSONText(c.Qualifier, c.QualifierEncoding); err != nil {
+				im.lineError(line, "%s: qualifier: %v", c.Family, err)
+				continue
+			}
+			var v importValue
+			switch value := c.Value.(type) {
+			case string:
+				if v.text, err = decodeJSONText(value, c.ValueEncoding); err != nil {
+					im.lineError(line, "%s:%s: %v", c.Family, c.Qualifier, err)
+					continue
+				}
+			case json.Number:
+				v = importValue{text: value.String(), number: true}
+			default:
+				if im.decodeTypeOf(c.Family+":"+c.Qualifier) != decodeTypeJSON {
+					im.lineError(line, "%s:%s: invalid value %v", c.Family, c.Qualifier, c.Value)
+					continue
+				}
+			}
+			if c.Type != "" && c.Type != decodeTypeInt && c.Type != decodeTypeFloat {
+				im.lineError(line, "%s:%s: invalid type %q, must be int or float", c.Family, c.Qualifier, c.Type)
+				continue
+			}
+			// the type is also given to NaN and Inf of the floats written as the strings
+			v.typ = c.Type
+			if im.decodeTypeOf(c.Family+":"+c.Qualifier) == decodeTypeJSON {
+				// the values of decode=json are written as the JSON values
+				data, _ := json.Marshal(c.Value)
+				v = importValue{text: string(data)}
+			}
+			im.addCell(line, key, c.Family, c.Qualifier, c.Timestamp, v)
+		}
+	}
+}
+
+// decodeJSONText returns the text of the field of the JSON, decoded if written in base64 by jsonText
+func decodeJSONText(s, encoding string) (string, error) {
+	switch encoding {
+	case "":
+		return s, nil
+	case binaryEncodingBase64:
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid base64 %q", s)
+		}
+		return string(b), nil
+	}
+	return "", fmt.Errorf("invalid encoding %q, must be base64", encoding)
+}
+
+func (im *importer) readCSV(data []byte) {
+	lines := strings.SplitAfter(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := i + 1
+		// a quoted field continues on the following lines until the quotes are closed
+		text := lines[i]
+		for strings.Count(text, `"`)%2 == 1 && i+1 < len(lines) {
+			i++
+			text += lines[i]
+		}
+		if strings.TrimRight(text, "\r\n") == "" {
+			continue
+		}
+		record, err := csv.NewReader(strings.NewReader(text)).Read()
+		if err != nil {
+			if pe, ok := err.(*csv.ParseError); ok {
+				err = pe.Err
+			}
+			im.lineError(line, "invalid csv: %v", err)
+			continue
+		}
+		if len(record) != 5 && len(record) != 6 {
+			im.lineError(line, "%d fields, must be key,family,qualifier,timestamp,value", len(record))
+			continue
+		}
+		key, qualifier, value := record[0], record[2], record[4]
+		var typ string
+		if len(record) == 6 {
+			if typ, err = decodeBinaryFields(record[5], &key, &qualifier, &value); err != nil {
+				im.lineError(line, "%v", err)
+				continue
+			}
+		}
+		im.addCell(line, key, record[1], qualifier, record[3], importValue{text: value, typ: typ})
+	}
+}
+
+func (im *importer) readTSV(data []byte) {
+	for i, l := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		line := i + 1
+		if l == "" {
+			continue
+		}
+		fields := strings.Split(l, "\t")
+		if len(fields) != 4 && len(fields) != 5 {
+			im.lineError(line, "%d fields, must be key, family:qualifier, timestamp and value", len(fields))
+			continue
+		}
+		for j := range fields {
+			f, err := tsvUnescape(fields[j])
+			if err != nil {
+				im.lineError(line, "%v", err)
+				fields = nil
+				break
+			}
+			fields[j] = f
+		}
+		if fields == nil {
+			continue
+		}
+		sep := strings.Index(fields[1], ":")
+		if sep < 0 {
+			im.lineError(line, "invalid column %q, must be family:qualifier", fields[1])
+			continue
+		}
+		key, qualifier, value := fields[0], fields[1][sep+1:], fields[3]
+		var typ string
+		if len(fields) == 5 {
+			var err error
+			if typ, err = decodeBinaryFields(fields[4], &key, &qualifier, &value); err != nil {
+				im.lineError(line, "%v", err)
+				continue
+			}
+		}
+		im.addCell(line, key, fields[1][:sep], qualifier, fields[2], importValue{text: value, typ: typ})
+	}
+}
+
+// decodeBinaryFields decodes the fields listed by the marker column of binarySafeFields, e.g. "key+value",
+// and returns the type of the value of typedMarker
+func decodeBinaryFields(marker string, key, qualifier, value *string) (string, error) {
+	if marker == "" {
+		return "", nil
+	}
+	var typ string
+	for _, name := range strings.Split(marker, "+") {
+		var f *string
+		switch name {
+		case decodeTypeInt, decodeTypeFloat:
+			typ = name
+			continue
+		case "key":
+			f = key
+		case "qualifier":
+			f = qualifier
+		case "value":
+			f = value
+		default:
+			return "", fmt.Errorf("invalid marker %q, must be key, qualifier, value, int or float joined by +", marker)
+		}
+		b, err := base64.StdEncoding.DecodeString(*f)
+		if err != nil {
+			return "", fmt.Errorf("invalid base64 %s %q", name, *f)
+		}
+		*f = string(b)
+	}
+	return typ, nil
+}
+
+// tsvUnescape reverts tsvEscaper
+func tsvUnescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 == len(s) {
+			return "", errors.New(`trailing \`)
+		}
+		i++
+		switch s[i] {
+		case '\\':
+			b.WriteByte('\\')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			return "", fmt.Errorf(`invalid escape \%c`, s[i])
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,443 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string) string {
+		file := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatalf("want no error, got %v", err)
+		}
+		return file
+	}
+	fingerprint := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ndjson := `{"key":"a","cells":[{"family":"d","qualifier":"name","value":"a1","timestamp":"2018-01-01T00:00:00Z"},{"family":"d","qualifier":"count","value":2,"timestamp":"2018-01-01T00:00:00Z"}]}` + "\n" +
+		`{"key":"b","cells":[{"family":"d","qualifier":"score","value":"AAE=","timestamp":"0001-01-01T00:00:00Z"}]}` + "\n"
+	ndjsonFile := write("events", ndjson)
+	writeManifest(ndjsonFile+manifestSuffix, &exportManifest{
+		Table:     "events",
+		Format:    "ndjson",
+		KeyFormat: "utf8",
+		Columns: []manifestColumn{
+			{Column: "d:count", Decode: "auto"},
+			{Column: "d:name", Decode: "auto"},
+			{Column: "d:score", Decode: "base64"},
+		},
+		Rows:        2,
+		Fingerprint: fingerprint(ndjson),
+	})
+	jsonFile := write("docs", `{"key":"a","cells":[{"family":"d","qualifier":"doc","value":{"x":[1,2.5]},"timestamp":"2018-01-01T00:00:00Z"},{"family":"d","qualifier":"name","value":"a1","timestamp":"2018-01-01T00:00:00Z"}]}`+"\n")
+	modified := write("modified", ndjson+`{"key":"c","cells":[{"family":"x","qualifier":"q","value":"c1","timestamp":"2018-01-01T00:00:00Z"}]}`+"\n")
+	csvFile := write("events.csv", "a,d,name,2018-01-01T00:00:00Z,a1\n"+
+		"a,d,count,2018-01-01T00:00:00Z,x\n"+
+		"b,m,name,2018-01-01T00:00:00Z,b1\n"+
+		"c,d,name\n"+
+		"d,d,name,yesterday,d1\n")
+	newFamilyFile := write("families.csv", "a,d,name,2018-01-01T00:00:00Z,a1\n"+
+		"a,m,name,2018-01-01T00:00:00Z,a2\n"+
+		"b,m,name,2018-01-01T00:00:00Z,b2\n")
+	tsvFile := write("events.tsv", "a\\tb\td:name\t2018-01-01T00:00:00Z\tline1\\nline2\n")
+	// the binary fields in base64 listed by the marker column
+	binaryFile := write("binary.csv", "YQBi,d,name,2018-01-01T00:00:00Z,/wA=,key+value\n"+
+		"c,d,name,2018-01-01T00:00:00Z,c1,\n"+
+		"d,d,name,2018-01-01T00:00:00Z,d1,key\n"+
+		"e,d,name,2018-01-01T00:00:00Z,e1,row\n")
+
+	info := &domain.TableInfo{Name: "events", Families: []*domain.Family{{Name: "d"}}}
+	cases := []struct {
+		input     string
+		dryRun    bool
+		expectErr string
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			"import events " + ndjsonFile,
+			false,
+			"Imported 2 rows and 3 cells to events\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+				mock.EXPECT().WriteRows(gomock.Any(), "events", []*domain.Row{
+					{Key: "a", Columns: []*domain.Column{
+						{Family: "d", Qualifier: "d:name", Value: []byte("a1"), Version: tm},
+						{Family: "d", Qualifier: "d:count", Value: []byte{0, 0, 0, 0, 0, 0, 0, 2}, Version: tm},
+					}},
+					{Key: "b", Columns: []*domain.Column{
+						{Family: "d", Qualifier: "d:score", Value: []byte{0, 1}},
+					}},
+				}).Return(nil)
+			},
+		},
+		{
+			"import events " + modified + " manifest=" + ndjsonFile + manifestSuffix,
+			false,
+			"fingerprint " + fingerprint(ndjson+`{"key":"c","cells":[{"family":"x","qualifier":"q","value":"c1","timestamp":"2018-01-01T00:00:00Z"}]}`+"\n") +
+				" unlike " + fingerprint(ndjson) + " of the manifest, the file is modified\n" +
+				"line 3: unknown family \"x\"\n" +
+				"Aborted by 2 errors, no rows are written\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+			},
+		},
+		{
+			"import events " + csvFile + " decode_columns=count:int validate-only=true",
+			false,
+			"line 2: d:count: invalid int \"x\"\n" +
+				"line 3: unknown family \"m\"\n" +
+				"line 4: 3 fields, must be key,family,qualifier,timestamp,value\n" +
+				"line 5: d:name: invalid timestamp \"yesterday\"\n" +
+				"Validated 1 rows and 1 cells of " + csvFile + ", 4 errors\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+			},
+		},
+		{
+			// validate-only doesn't write even in the dry run
+			"import events " + csvFile + " validate-only=true",
+			true,
+			"line 3: unknown family \"m\"\n" +
+				"line 4: 3 fields, must be key,family,qualifier,timestamp,value\n" +
+				"line 5: d:name: invalid timestamp \"yesterday\"\n" +
+				"Validated 1 rows and 2 cells of " + csvFile + ", 3 errors\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+			},
+		},
+		{
+			"import events " + csvFile,
+			true,
+			"Dry run: import events " + csvFile + " would import rows from a file written by export, run \"set dryrun off\" to execute\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"import events " + tsvFile,
+			false,
+			"Imported 1 rows and 1 cells to events\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+				mock.EXPECT().WriteRows(gomock.Any(), "events", []*domain.Row{
+					{Key: "a\tb", Columns: []*domain.Column{
+						{Family: "d", Qualifier: "d:name", Value: []byte("line1\nline2"), Version: tm},
+					}},
+				}).Return(nil)
+			},
+		},
+		{
+			"import events " + binaryFile,
+			false,
+			"line 3: invalid base64 key \"d\"\n" +
+				"line 4: invalid marker \"row\", must be key, qualifier, value, int or float joined by +\n" +
+				"Aborted by 2 errors, no rows are written\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+			},
+		},
+		{
+			"import events " + binaryFile + " validate-only=true",
+			false,
+			"line 3: invalid base64 key \"d\"\n" +
+				"line 4: invalid marker \"row\", must be key, qualifier, value, int or float joined by +\n" +
+				"Validated 2 rows and 2 cells of " + binaryFile + ", 2 errors\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+			},
+		},
+		{
+			"import events " + jsonFile + " decode_columns=doc:json",
+			false,
+			"Imported 1 rows and 2 cells to events\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+				mock.EXPECT().WriteRows(gomock.Any(), "events", []*domain.Row{
+					{Key: "a", Columns: []*domain.Column{
+						{Family: "d", Qualifier: "d:doc", Value: []byte(`{"x":[1,2.5]}`), Version: tm},
+						{Family: "d", Qualifier: "d:name", Value: []byte("a1"), Version: tm},
+					}},
+				}).Return(nil)
+			},
+		},
+		{
+			"import events " + jsonFile,
+			false,
+			"line 1: d:doc: invalid value map[x:[1 2.5]]\n" +
+				"Aborted by 1 errors, no rows are written\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+			},
+		},
+		{
+			// the missing families are created before writing
+			"import events " + newFamilyFile + " create-family=true family-policy=maxversions=1",
+			false,
+			"Created family m of events\nImported 2 rows and 3 cells to events\n",
+			func(mock *repository.MockBigtable) {
+				gomock.InOrder(
+					mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil),
+					mock.EXPECT().CreateFamily(gomock.Any(), "events", &domain.Family{Name: "m", MaxVersions: 1}).Return(nil),
+					mock.EXPECT().WriteRows(gomock.Any(), "events", []*domain.Row{
+						{Key: "a", Columns: []*domain.Column{
+							{Family: "d", Qualifier: "d:name", Value: []byte("a1"), Version: tm},
+							{Family: "m", Qualifier: "m:name", Value: []byte("a2"), Version: tm},
+						}},
+						{Key: "b", Columns: []*domain.Column{
+							{Family: "m", Qualifier: "m:name", Value: []byte("b2"), Version: tm},
+						}},
+					}).Return(nil),
+				)
+			},
+		},
+		{
+			"import events " + newFamilyFile + " create-family=true validate-only=true",
+			false,
+			"Families m would be created\nValidated 2 rows and 3 cells of " + newFamilyFile + ", 0 errors\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+			},
+		},
+		{
+			// no family is created when the file has an error
+			"import events " + csvFile + " create-family=true",
+			false,
+			"line 4: 3 fields, must be key,family,qualifier,timestamp,value\n" +
+				"line 5: d:name: invalid timestamp \"yesterday\"\n" +
+				"Aborted by 2 errors, no rows are written\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+			},
+		},
+		{
+			"import events " + newFamilyFile + " create-family=true family-policy=maxversions=x",
+			false,
+			"Invalid family-policy: invalid maxversions \"x\" of the created families\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"import events " + tsvFile + " format=yaml",
+			false,
+			"Invalid format: yaml, must be one of ndjson, csv, tsv\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"import events " + tsvFile + " validate-only=maybe",
+			false,
+			"Invalid validate-only: maybe\n",
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+			dryRun:          c.dryRun,
+		}
+		executor.Do(c.input)
+		assert.Equal(t, "", out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestDoImportDecoders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "counts.csv")
+	if err := ioutil.WriteFile(file, []byte("a,d,count,2018-01-01T00:00:00Z,ten\na,d,doc,2018-01-01T00:00:00Z,{\n"), 0600); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	info := &domain.TableInfo{Name: "events", Families: []*domain.Family{{Name: "d"}}}
+
+	cases := []struct {
+		input     string
+		expectErr string
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			"import events " + file,
+			"line 1: d:count: invalid int \"ten\"\n" +
+				"line 2: d:doc: invalid JSON \"{\"\n" +
+				"Aborted by 2 errors, no rows are written\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+			},
+		},
+		{
+			// the text of the decode option isn't written into the columns of the decoders
+			"import events " + file + " decode=string",
+			"line 1: d:count: 3 bytes value isn't int of the decoders, written by ignore-decoders=true\n" +
+				"line 2: d:doc: value \"{\" isn't json of the decoders, written by ignore-decoders=true\n" +
+				"Aborted by 2 errors, no rows are written\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+			},
+		},
+		{
+			"import events " + file + " decode=string ignore-decoders=true",
+			"Imported 1 rows and 2 cells to events\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+				mock.EXPECT().WriteRows(gomock.Any(), "events", []*domain.Row{
+					{Key: "a", Columns: []*domain.Column{
+						{Family: "d", Qualifier: "d:count", Value: []byte("ten"), Version: tm},
+						{Family: "d", Qualifier: "d:doc", Value: []byte("{"), Version: tm},
+					}},
+				}).Return(nil)
+			},
+		},
+		{
+			"import events " + file + " ignore-decoders=maybe",
+			"Invalid ignore-decoders: maybe\n",
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+			decoders:        map[string]string{"d:count": "int", "d:doc": "json"},
+		}
+		executor.Do(c.input)
+		assert.Equal(t, "", out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestTSVUnescape(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+		err    string
+	}{
+		{`a\\b\tc\nd\re`, "a\\b\tc\nd\re", ""},
+		{"plain", "plain", ""},
+		{`a\x`, "", `invalid escape \x`},
+		{`a\`, "", `trailing \`},
+	}
+	for i, c := range cases {
+		actual, err := tsvUnescape(c.input)
+		if c.err != "" {
+			if assert.Error(t, err, "#%d", i) {
+				assert.Equal(t, c.err, err.Error(), "#%d", i)
+			}
+			continue
+		}
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, c.expect, actual, "#%d", i)
+		assert.Equal(t, c.input, tsvEscaper.Replace(actual), "#%d", i)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	float := make([]byte, 8)
+	binary.BigEndian.PutUint64(float, math.Float64bits(2.0))
+	rows := []*domain.Row{
+		{
+			Key: "a",
+			Columns: []*domain.Column{
+				{Family: "d", Qualifier: "d:count", Value: []byte{0, 0, 0, 0, 0, 0, 0, 2}, Version: tm},
+				{Family: "d", Qualifier: "d:name", Value: []byte("a1"), Version: tm},
+				{Family: "d", Qualifier: "d:score", Value: float, Version: tm},
+			},
+		},
+		{
+			Key: "b",
+			Columns: []*domain.Column{
+				{Family: "d", Qualifier: "d:name", Value: []byte("abcdefgh"), Version: tm},
+			},
+		},
+		// the invalid UTF-8 in base64
+		{
+			Key: "c\xff",
+			Columns: []*domain.Column{
+				{Family: "d", Qualifier: "d:\xfe", Value: []byte("\xe3\x81"), Version: tm},
+			},
+		},
+	}
+	info := &domain.TableInfo{Name: "events", Families: []*domain.Family{{Name: "d"}}}
+
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i, format := range importFormats {
+		file := filepath.Join(dir, "events."+format)
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		mockBtRepo.EXPECT().ScanRows(gomock.Any(), "events", gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+				for _, r := range rows {
+					f(r)
+				}
+				return nil
+			})
+		mockBtRepo.EXPECT().TableInfo(gomock.Any(), "events").Return(info, nil)
+		var written []*domain.Row
+		mockBtRepo.EXPECT().WriteRows(gomock.Any(), "events", gomock.Any()).DoAndReturn(
+			func(ctx context.Context, table string, rs []*domain.Row) error {
+				written = rs
+				return nil
+			})
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+		}
+		executor.Do("export events " + file + " format=" + format)
+		executor.Do("import events " + file)
+		assert.Equal(t, "Exported 3 rows to "+file+"\nImported 3 rows and 5 cells to events\n", errOut.String(), "#%d", i)
+		assert.Equal(t, rows, written, "#%d", i)
+		ctrl.Finish()
+	}
+}
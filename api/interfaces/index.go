@@ -0,0 +1,170 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/takashabe/btcli/api/domain"
+)
+
+// defaultBloomFalsePositiveRate is a false positive rate of the exported bloom filter
+const defaultBloomFalsePositiveRate = 0.01
+
+func doIndex(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "index build <table> | index search <pattern> [table=<table>] [limit=<n>] | index bloom <table> <file> [fp-rate=<p>]")
+		return
+	}
+	switch args[1] {
+	case "build":
+		e.buildIndex(ctx, args[2])
+	case "search":
+		e.searchIndex(args[2], args[3:]...)
+	case "bloom":
+		if len(args) < 4 {
+			e.failf(e.msg("Invalid args: %v\n"), "index bloom <table> <file> [fp-rate=<p>]")
+			return
+		}
+		e.exportBloomFilter(ctx, args[2], args[3], args[4:]...)
+	default:
+		e.failf("Unknown index command: %s\n", args[1])
+	}
+}
+
+func (e *Executor) buildIndex(ctx context.Context, table string) {
+	fmt.Fprintf(e.errStream, "Reading the keys of %s...\n", table)
+	cnt, err := e.indexInteractor.Build(ctx, table)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	fmt.Fprintf(e.errStream, "Indexed %s keys of %s\n", e.formatNumber(int64(cnt)), table)
+}
+
+func (e *Executor) searchIndex(pattern string, args ...string) {
+	var tables []string
+	limit := 0
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "table":
+			tables = []string{v}
+		case "limit":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				e.failf("Invalid limit: %v\n", v)
+				return
+			}
+			limit = n
+		}
+	}
+
+	found, err := e.indexInteractor.Search(pattern, tables, limit)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+
+	names := make([]string, 0, len(found))
+	for table := range found {
+		names = append(names, table)
+	}
+	sort.Strings(names)
+	for _, table := range names {
+		for _, key := range found[table] {
+			// the table is obvious when given
+			if len(tables) > 0 {
+				fmt.Fprintln(e.outStream, printedKey(key))
+			} else {
+				fmt.Fprintf(e.outStream, "%s\t%s\n", table, printedKey(key))
+			}
+		}
+	}
+}
+
+// exportBloomFilter writes the bloom filter of the keys of the table to the file
+func (e *Executor) exportBloomFilter(ctx context.Context, table, file string, args ...string) {
+	p := defaultBloomFalsePositiveRate
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "fp-rate":
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f <= 0 || f >= 1 {
+				e.failf("Invalid fp-rate: %v\n", v)
+				return
+			}
+			p = f
+		}
+	}
+
+	fmt.Fprintf(e.errStream, "Reading the keys of %s...\n", table)
+	filter, cnt, err := e.indexInteractor.BloomFilter(ctx, table, p)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	data, err := filter.MarshalBinary()
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		e.printError(err)
+		return
+	}
+	fmt.Fprintf(e.errStream, "Exported %s keys of %s to %s in %s bytes\n", e.formatNumber(int64(cnt)), table, file, e.formatNumber(int64(len(data))))
+}
+
+func doMembership(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 4 || args[1] != "check" {
+		e.failf(e.msg("Invalid args: %v\n"), "membership check <keys-file> <filter-file>")
+		return
+	}
+	keys, err := loadKeysFile(args[2])
+	if err != nil {
+		e.failf("Invalid keys-file: %v\n", err)
+		return
+	}
+	data, err := ioutil.ReadFile(args[3])
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	var filter domain.BloomFilter
+	if err := filter.UnmarshalBinary(data); err != nil {
+		e.failf("Invalid filter-file: %v\n", err)
+		return
+	}
+
+	present := 0
+	for _, k := range keys {
+		if filter.MayContain(k) {
+			present++
+			fmt.Fprintf(e.outStream, "%s\tpresent\n", k)
+		} else {
+			fmt.Fprintf(e.outStream, "%s\tabsent\n", k)
+		}
+	}
+	fmt.Fprintf(e.errStream, "%s of %s keys may be present\n", e.formatNumber(int64(present)), e.formatNumber(int64(len(keys))))
+}
@@ -0,0 +1,90 @@
+package interfaces
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain/repository"
+	"github.com/takashabe/btcli/api/infrastructure/index"
+)
+
+func TestDoIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "index")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	mockBtRepo.EXPECT().Keys(gomock.Any(), "users", bigtable.InfiniteRange("")).Return([]string{"u#2", "u#1", "admin"}, nil)
+	mockBtRepo.EXPECT().Keys(gomock.Any(), "logs", bigtable.InfiniteRange("")).Return([]string{"u#1#log"}, nil)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:       &out,
+		errStream:       &errOut,
+		indexInteractor: application.NewIndexInteractor(mockBtRepo, index.NewFileKeyIndex(dir)),
+	}
+
+	executor.Do("index build users")
+	executor.Do("index build logs")
+	assert.Equal(t, "", out.String())
+	assert.Equal(t, "Reading the keys of users...\nIndexed 3 keys of users\nReading the keys of logs...\nIndexed 1 keys of logs\n", errOut.String())
+
+	cases := []struct {
+		input  string
+		expect string
+	}{
+		{"index search ^u#1", "logs\tu#1#log\nusers\tu#1\n"},
+		{"index search ^u# table=users", "u#1\nu#2\n"},
+		{"index search min", "users\tadmin\n"},
+		{"index search u limit=1", "logs\tu#1#log\n"},
+	}
+	for _, c := range cases {
+		out.Reset()
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), c.input)
+	}
+}
+
+func TestDoIndexBloom(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bloom")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	mockBtRepo.EXPECT().Keys(gomock.Any(), "users", bigtable.InfiniteRange("")).Return([]string{"u#1", "u#2", "u#3"}, nil)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:       &out,
+		errStream:       &errOut,
+		indexInteractor: application.NewIndexInteractor(mockBtRepo, index.NewFileKeyIndex(dir)),
+	}
+
+	filter := filepath.Join(dir, "users.bin")
+	executor.Do("index bloom users " + filter + " fp-rate=0.001")
+	assert.Equal(t, "", out.String())
+	assert.Equal(t, "Reading the keys of users...\nExported 3 keys of users to "+filter+" in 25 bytes\n", errOut.String())
+
+	keys := filepath.Join(dir, "keys.txt")
+	assert.NoError(t, ioutil.WriteFile(keys, []byte("u#1\nu#4\nu#3\nadmin\n"), 0644))
+	out.Reset()
+	errOut.Reset()
+	executor.Do("membership check " + keys + " " + filter)
+	assert.Equal(t, "u#1\tpresent\nu#4\tabsent\nu#3\tpresent\nadmin\tabsent\n", out.String())
+	assert.Equal(t, "2 of 4 keys may be present\n", errOut.String())
+
+	errOut.Reset()
+	executor.Do("membership check " + keys + " " + keys)
+	assert.Equal(t, "Invalid filter-file: not a bloom filter\n", errOut.String())
+}
@@ -0,0 +1,58 @@
+package interfaces
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	prompt "github.com/c-bata/go-prompt"
+)
+
+// longRunningCommands are not re-executed by the key binding,
+// Ctrl+C doesn't stop them while the prompt is in the raw mode
+var longRunningCommands = map[string]bool{
+	"watch-row": true,
+	"tail":      true,
+}
+
+// keyBindings returns the key bindings of the prompt
+func (e *Executor) keyBindings() []prompt.KeyBind {
+	return []prompt.KeyBind{
+		{Key: prompt.F5, Fn: e.rerun},
+	}
+}
+
+// rerun re-executes the previous command verbatim
+func (e *Executor) rerun(*prompt.Buffer) {
+	// the terminal is in the raw mode during the key binding
+	out, errOut := e.outStream, e.errStream
+	e.outStream, e.errStream = &crlfWriter{w: out}, &crlfWriter{w: errOut}
+	defer func() {
+		e.outStream, e.errStream = out, errOut
+	}()
+
+	fmt.Fprintln(e.errStream)
+	if e.lastArgs == nil {
+		fmt.Fprintln(e.errStream, "No previous command")
+		return
+	}
+	if longRunningCommands[e.lastArgs[0]] {
+		fmt.Fprintf(e.errStream, "%s can't be re-executed by the key, run it from the prompt\n", e.lastArgs[0])
+		return
+	}
+	cmd := joinCommand(e.lastArgs)
+	fmt.Fprintln(e.errStream, cmd)
+	e.Do(cmd)
+}
+
+// crlfWriter writes the line breaks as CRLF for the terminal in the raw mode
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (c *crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.Replace(p, []byte("\n"), []byte("\r\n"), -1)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,47 @@
+package interfaces
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestRerun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	mockBtRepo.EXPECT().Tables(gomock.Any()).Return([]string{"a", "b"}, nil).Times(4)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:       &out,
+		errStream:       &errOut,
+		tableInteractor: application.NewTableInteractor(mockBtRepo),
+	}
+
+	executor.rerun(nil)
+	assert.Equal(t, "\r\nNo previous command\r\n", errOut.String())
+
+	executor.Do("ls")
+	out.Reset()
+	errOut.Reset()
+	executor.rerun(nil)
+	assert.Equal(t, "a\r\nb\r\n", out.String())
+	assert.Equal(t, "\r\nls\r\n", errOut.String())
+
+	// restore the streams after the key binding
+	out.Reset()
+	executor.Do("ls")
+	assert.Equal(t, "a\nb\n", out.String())
+
+	// the args are quoted to be read back as they are
+	errOut.Reset()
+	executor.lastArgs = []string{"ls", "a b"}
+	executor.rerun(nil)
+	assert.True(t, strings.HasPrefix(errOut.String(), "\r\nls 'a b'\r\n"), errOut.String())
+}
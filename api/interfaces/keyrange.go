@@ -0,0 +1,203 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// keyRange is a range of the row keys in the byte-wise order, the empty end is unbounded
+type keyRange struct {
+	start string
+	end   string
+}
+
+// maxSplitPrecision is the number of the bytes added to the keys to divide a narrow range
+const maxSplitPrecision = 8
+
+func doRange(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 2 {
+		e.failf(e.msg("Invalid args: %v\n"), "range split <start> <end> parts=<n> | range split <range> parts=<n> | range intersect <range> <range> ...")
+		return
+	}
+	switch args[1] {
+	case "split":
+		doRangeSplit(e, args[2:])
+	case "intersect":
+		if len(args) < 4 {
+			e.failf(e.msg("Invalid args: %v\n"), "range intersect <range> <range> ...")
+			return
+		}
+		ranges := make([]keyRange, 0, len(args)-2)
+		for _, arg := range args[2:] {
+			r, err := parseKeyRange(arg)
+			if err != nil {
+				e.failf(e.msg("Invalid range: %v\n"), err)
+				return
+			}
+			ranges = append(ranges, r)
+		}
+		r := ranges[0]
+		for _, o := range ranges[1:] {
+			r = r.intersect(o)
+		}
+		if r.empty() {
+			fmt.Fprintln(e.errStream, "No intersection of the ranges")
+			return
+		}
+		fmt.Fprintln(e.outStream, r)
+	default:
+		e.failf("Unknown subcommand: %s, must be one of split, intersect\n", args[1])
+	}
+}
+
+func doRangeSplit(e *Executor, args []string) {
+	// --parts <n> is same as parts=<n>
+	if n := len(args); n >= 2 && args[n-2] == "--parts" {
+		args = append(args[:n-2:n-2], "parts="+args[n-1])
+	}
+	if len(args) != 2 && len(args) != 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "range split <start> <end> parts=<n> | range split <range> parts=<n>")
+		return
+	}
+	var r keyRange
+	var err error
+	if len(args) == 3 {
+		r, err = parseKeyPair(args[0], args[1])
+	} else {
+		r, err = parseKeyRange(args[0])
+	}
+	if err != nil {
+		e.failf(e.msg("Invalid range: %v\n"), err)
+		return
+	}
+	arg := strings.TrimPrefix(args[len(args)-1], "--")
+	i := strings.Index(arg, "=")
+	if i < 0 || arg[:i] != "parts" {
+		e.failf(e.msg("Unknown arg: %v\n"), args[len(args)-1])
+		return
+	}
+	parts, err := strconv.Atoi(arg[i+1:])
+	if err != nil || parts < 1 {
+		e.failf("Invalid parts: %v\n", arg[i+1:])
+		return
+	}
+	label := joinCommand(args[:len(args)-1])
+	if r.empty() {
+		e.failf(e.msg("Invalid range: %s is empty\n"), label)
+		return
+	}
+
+	splits, ok := r.split(parts)
+	if !ok {
+		e.failf("Invalid parts: %s has fewer than %d keys\n", label, parts)
+		return
+	}
+	for _, s := range splits {
+		fmt.Fprintln(e.outStream, s)
+	}
+}
+
+// parseKeyPair parses the start and the end keys escaped as printed by escapeKey, the empty end is unbounded
+func parseKeyPair(start, end string) (keyRange, error) {
+	s, err := unescapeKey(start)
+	if err != nil {
+		return keyRange{}, err
+	}
+	e, err := unescapeKey(end)
+	if err != nil {
+		return keyRange{}, err
+	}
+	return keyRange{start: s, end: e}, nil
+}
+
+// parseKeyRange parses "<start>..<end>" with the optional sides, or "<prefix>*" ending before the prefix successor,
+// the keys are escaped as printed by escapeKey and the dots of the keys are written as \x2e, e.g. 'a\x2e\x2e'..b
+func parseKeyRange(s string) (keyRange, error) {
+	if i := strings.Index(s, ".."); i >= 0 {
+		return parseKeyPair(s[:i], s[i+2:])
+	}
+	if strings.HasSuffix(s, "*") {
+		prefix, err := unescapeKey(s[:len(s)-1])
+		if err != nil {
+			return keyRange{}, err
+		}
+		return keyRange{start: prefix, end: prefixSuccessor(prefix)}, nil
+	}
+	return keyRange{}, fmt.Errorf("%q must be <start>..<end> or <prefix>*", s)
+}
+
+// String returns the range in the options of read quoted to be given back to the commands
+func (r keyRange) String() string {
+	return fmt.Sprintf("start=%s end=%s", printedKey(r.start), printedKey(r.end))
+}
+
+func (r keyRange) empty() bool {
+	return r.end != "" && r.start >= r.end
+}
+
+func (r keyRange) intersect(o keyRange) keyRange {
+	ret := r
+	if o.start > ret.start {
+		ret.start = o.start
+	}
+	if ret.end == "" || (o.end != "" && o.end < ret.end) {
+		ret.end = o.end
+	}
+	return ret
+}
+
+// split divides the range into the parts of the similar widths by interpolating the keys as the big-endian numbers,
+// ok is false if the range can't have the distinct split points
+func (r keyRange) split(parts int) ([]keyRange, bool) {
+	width := len(r.start)
+	if len(r.end) > width {
+		width = len(r.end)
+	}
+	if width == 0 {
+		width = 1
+	}
+
+	n := big.NewInt(int64(parts))
+	for precision := 0; precision <= maxSplitPrecision; precision++ {
+		l := width + precision
+		start := keyNumber(r.start, l)
+		end := keyNumber(r.end, l)
+		if r.end == "" {
+			// the key after all keys of the width
+			end = new(big.Int).Lsh(big.NewInt(1), uint(8*l))
+		}
+		size := new(big.Int).Sub(end, start)
+		if size.Cmp(n) < 0 {
+			continue
+		}
+
+		ret := make([]keyRange, 0, parts)
+		prev := r.start
+		for i := 1; i < parts; i++ {
+			p := new(big.Int).Mul(size, big.NewInt(int64(i)))
+			p.Div(p, n).Add(p, start)
+			k := numberKey(p, l)
+			ret = append(ret, keyRange{start: prev, end: k})
+			prev = k
+		}
+		return append(ret, keyRange{start: prev, end: r.end}), true
+	}
+	return nil, false
+}
+
+// keyNumber returns the key padded with the zero bytes to the length as the big-endian number
+func keyNumber(k string, l int) *big.Int {
+	b := make([]byte, l)
+	copy(b, k)
+	return new(big.Int).SetBytes(b)
+}
+
+// numberKey returns the key of the number of the length without the trailing zero bytes,
+// which is the smallest key padded to the same number
+func numberKey(n *big.Int, l int) string {
+	b := n.Bytes()
+	return strings.TrimRight(strings.Repeat("\x00", l-len(b))+string(b), "\x00")
+}
@@ -0,0 +1,51 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoRange(t *testing.T) {
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream: &out,
+		errStream: &errOut,
+	}
+
+	cases := []struct {
+		input     string
+		expectOut string
+		expectErr string
+	}{
+		{"range split a..e parts=4", "start=a end=b\nstart=b end=c\nstart=c end=d\nstart=d end=e\n", ""},
+		// a byte is added to divide the narrow range
+		{"range split a..b parts=2", "start=a end='a\\x80'\nstart='a\\x80' end=b\n", ""},
+		{"range split .. parts=2", "start= end='\\x80'\nstart='\\x80' end=\n", ""},
+		{"range split ab\xff* parts=2", "start='ab\\xff' end='ab\\xff\\x80'\nstart='ab\\xff\\x80' end=ac\n", ""},
+		// the start and the end keys, the keys having the spaces are quoted
+		{`range split "a b" "a c" --parts 2`, "start='a b' end='a b\\x80'\nstart='a b\\x80' end='a c'\n", ""},
+		{"range split a..b a.c parts=1", "start=a..b end=a.c\n", ""},
+		{`range split 'a\x2e\x2e'..b parts=1`, "start=a.. end=b\n", ""},
+		{"range split a '' parts=1", "start=a end=\n", ""},
+		{"range split b a parts=2", "", "Invalid range: b a is empty\n"},
+		{"range split a.. parts=1", "start=a end=\n", ""},
+		{"range split b..a parts=2", "", "Invalid range: b..a is empty\n"},
+		{"range split a..e parts=0", "", "Invalid parts: 0\n"},
+		{"range split a..e count=2", "", "Unknown arg: count=2\n"},
+		{"range split a parts=2", "", "Invalid range: \"a\" must be <start>..<end> or <prefix>*\n"},
+		{"range intersect a..m user* ..v", "", "No intersection of the ranges\n"},
+		{"range intersect a.. user* ..v", "start=user end=uses\n", ""},
+		{"range intersect ..m c.. b..x", "start=c end=m\n", ""},
+		{"range intersect a..m", "", "Invalid args: range intersect <range> <range> ...\n"},
+		{"range merge a..m b..c", "", "Unknown subcommand: merge, must be one of split, intersect\n"},
+	}
+	for i, c := range cases {
+		out.Reset()
+		errOut.Reset()
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+	}
+}
@@ -0,0 +1,153 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/takashabe/btcli/api/domain"
+)
+
+// letCommands are the commands of the rows read into the variables
+var letCommands = []string{"read", "lookup"}
+
+func doLet(ctx context.Context, e *Executor, args ...string) {
+	if len(args) == 1 {
+		names := make([]string, 0, len(e.variables))
+		for name := range e.variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(e.outStream, "%s = %s\n", name, e.variables[name])
+		}
+		return
+	}
+	if len(args) < 5 || args[2] != "=" {
+		e.failf(e.msg("Invalid args: %v\n"), "let <name> = read|lookup <table> [args ...]")
+		return
+	}
+	name := args[1]
+	if !isVariableName(name) {
+		e.failf("Invalid name: %s, must be the letters, the digits and _ not starting with a digit\n", name)
+		return
+	}
+	if !containsString(letCommands, args[3]) {
+		e.failf(e.msg("Unknown command: %s, must be one of %s\n"), args[3], strings.Join(letCommands, ", "))
+		return
+	}
+
+	v, ok := e.captureValue(ctx, args[3:])
+	if !ok {
+		fmt.Fprintf(e.errStream, "No cell read by %s, %s is unchanged\n", joinCommand(args[3:]), name)
+		return
+	}
+	if e.variables == nil {
+		e.variables = make(map[string]string)
+	}
+	e.variables[name] = v
+	fmt.Fprintf(e.errStream, "%s = %s\n", name, v)
+}
+
+// captureValue runs the command without printing the rows and returns the first cell of the first row
+// decoded by the options of the command
+func (e *Executor) captureValue(ctx context.Context, args []string) (string, bool) {
+	var (
+		value string
+		found bool
+	)
+	e.capture = func(p *Printer, r *domain.Row) {
+		if found || len(r.Columns) == 0 {
+			return
+		}
+		c := p.sortColumns(r.Columns)[0]
+		value, found = fmt.Sprint(p.typedValue(c.Qualifier, c.Value)), true
+	}
+	defer func() { e.capture = nil }()
+
+	switch args[0] {
+	case "read":
+		doRead(ctx, e, args...)
+	case "lookup":
+		doLookup(ctx, e, args...)
+	}
+	return value, found
+}
+
+// expandVariables replaces the unquoted $name and ${name} in the args with the variables of let and $$ with $,
+// the values of the regex options are kept as they are because $ is the end of the line.
+// The variables are expanded at last, the values are the literals not being the redirections or the expressions
+func expandVariables(tokens []token, variables map[string]string) ([]string, error) {
+	ret := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		arg := t.text
+		if i := strings.Index(arg, "="); i >= 0 && isPatternOption(arg[:i]) {
+			ret = append(ret, arg)
+			continue
+		}
+		var b strings.Builder
+		for i := 0; i < len(arg); i++ {
+			if arg[i] != '$' || t.quotedAt(i) {
+				b.WriteByte(arg[i])
+				continue
+			}
+			if i+1 < len(arg) && arg[i+1] == '$' && !t.quotedAt(i+1) {
+				b.WriteByte('$')
+				i++
+				continue
+			}
+			name, n := variableRef(arg[i:])
+			if n == 0 {
+				b.WriteByte(arg[i])
+				continue
+			}
+			v, ok := variables[name]
+			if !ok {
+				return nil, fmt.Errorf("undefined $%s", name)
+			}
+			b.WriteString(v)
+			i += n - 1
+		}
+		ret = append(ret, b.String())
+	}
+	return ret, nil
+}
+
+// variableRef returns the name of $name or ${name} at the head of s and the length of the reference, 0 if not a reference
+func variableRef(s string) (string, int) {
+	if !strings.HasPrefix(s, "$") {
+		return "", 0
+	}
+	if strings.HasPrefix(s, "${") {
+		end := strings.Index(s, "}")
+		if end < 0 || !isVariableName(s[2:end]) {
+			return "", 0
+		}
+		return s[2:end], end + 1
+	}
+	n := 1
+	for n < len(s) && isVariableByte(s[n], n == 1) {
+		n++
+	}
+	if n == 1 {
+		return "", 0
+	}
+	return s[1:n], n
+}
+
+func isVariableName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isVariableByte(s[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isVariableByte(c byte, first bool) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || (!first && '0' <= c && c <= '9')
+}
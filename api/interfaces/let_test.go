@@ -0,0 +1,201 @@
+package interfaces
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoLet(t *testing.T) {
+	balance := &domain.Bigtable{
+		Table: "users",
+		Rows: []*domain.Row{
+			{Key: "1", Columns: []*domain.Column{{Family: "d", Qualifier: "d:balance", Value: []byte{0, 0, 0, 0, 0, 0, 0, 100}}}},
+		},
+	}
+	cases := []struct {
+		input     string
+		expect    string
+		expectErr string
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			"let x = lookup users 1 d:balance decode=int",
+			"",
+			"x = 100\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().TableInfo(gomock.Any(), "users").Return(&domain.TableInfo{Families: []*domain.Family{{Name: "d"}}}, nil)
+				mock.EXPECT().Get(gomock.Any(), "users", "1", gomock.Any()).Return(balance, nil)
+			},
+		},
+		{
+			"let x = lookup users 2",
+			"",
+			"No cell read by lookup users 2, x is unchanged\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Get(gomock.Any(), "users", "2", gomock.Any()).Return(&domain.Bigtable{Table: "users", Rows: []*domain.Row{{Key: "2"}}}, nil)
+			},
+		},
+		{
+			"let x = ls users",
+			"",
+			"Unknown command: ls, must be one of read, lookup\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"let 1x = lookup users 1",
+			"",
+			"Invalid name: 1x, must be the letters, the digits and _ not starting with a digit\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"let x lookup users 1",
+			"",
+			"Invalid args: let <name> = read|lookup <table> [args ...]\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"lookup users $y",
+			"",
+			"Invalid variable: undefined $y\n",
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:          &out,
+			errStream:          &errOut,
+			rowsInteractor:     application.NewRowsInteractor(mockBtRepo),
+			metadataInteractor: application.NewMetadataInteractor(mockBtRepo, nil),
+			numberRows:         true,
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		assert.Nil(t, executor.lastRows, "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestDoLetExpand(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	mockBtRepo.EXPECT().Get(gomock.Any(), "users", "1", gomock.Any()).Return(&domain.Bigtable{
+		Table: "users",
+		Rows: []*domain.Row{
+			{Key: "1", Columns: []*domain.Column{{Family: "d", Qualifier: "d:friend", Value: []byte("2")}}},
+		},
+	}, nil)
+	mockBtRepo.EXPECT().Get(gomock.Any(), "users", "2", gomock.Any()).Return(&domain.Bigtable{Table: "users", Rows: []*domain.Row{{Key: "2"}}}, nil)
+	mockBtRepo.EXPECT().TableInfo(gomock.Any(), "users").Return(&domain.TableInfo{Families: []*domain.Family{{Name: "d"}}}, nil)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:          &out,
+		errStream:          &errOut,
+		rowsInteractor:     application.NewRowsInteractor(mockBtRepo),
+		metadataInteractor: application.NewMetadataInteractor(mockBtRepo, nil),
+	}
+	executor.Do("let friend = lookup users 1 d:friend decode=string")
+	executor.Do("lookup users ${friend}")
+	executor.Do("let")
+	assert.Equal(t, "2\nfriend = 2\n", out.String())
+	assert.Equal(t, "friend = 2\n----------------------------------------\n", errOut.String())
+}
+
+func TestExpandVariables(t *testing.T) {
+	variables := map[string]string{"x": "1", "name_2": "a b"}
+	cases := []struct {
+		input     string
+		expect    []string
+		expectErr string
+	}{
+		{"lookup t $x", []string{"lookup", "t", "1"}, ""},
+		{"read t prefix=${x}0 count=$x", []string{"read", "t", "prefix=10", "count=1"}, ""},
+		{"lookup t k$name_2", []string{"lookup", "t", "ka b"}, ""},
+		{"read t value-regex=^$x family=$x", []string{"read", "t", "value-regex=^$x", "family=$x"}, ""},
+		{"lookup t $ a$1 ${x", []string{"lookup", "t", "$", "a$1", "${x"}, ""},
+		// the quoted, the escaped and $$ are kept
+		{`lookup t 'a$b' "$y" \$x a$$b $$$x`, []string{"lookup", "t", "a$b", "$y", "$x", "a$b", "$1"}, ""},
+		{"lookup t $y", nil, "undefined $y"},
+	}
+	for i, c := range cases {
+		tokens, err := tokenizeCommand(c.input)
+		if err != nil {
+			t.Fatalf("#%d: want no error, got %v", i, err)
+		}
+		actual, err := expandVariables(tokens, variables)
+		if c.expectErr != "" {
+			assert.EqualError(t, err, c.expectErr, "#%d", i)
+			continue
+		}
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, c.expect, actual, "#%d", i)
+	}
+}
+
+func TestDoLetLiteral(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	// the values of the variables aren't the redirections
+	mockBtRepo.EXPECT().Get(gomock.Any(), "users", ">pwned", gomock.Any()).Return(&domain.Bigtable{Table: "users", Rows: []*domain.Row{{Key: ">pwned"}}}, nil)
+	mockBtRepo.EXPECT().Get(gomock.Any(), "users", "a$b", gomock.Any()).Return(&domain.Bigtable{Table: "users", Rows: []*domain.Row{{Key: "a$b"}}}, nil).Times(2)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:      &out,
+		errStream:      &errOut,
+		rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		variables:      map[string]string{"v": ">pwned"},
+	}
+	executor.Do("lookup users $v")
+	executor.Do("lookup users 'a$b'")
+	executor.Do("lookup users a$$b")
+	assert.Equal(t, "'>pwned'\n'a$b'\n'a$b'\n", out.String())
+	assert.NotContains(t, errOut.String(), "Invalid")
+	_, err := os.Stat("pwned")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDoLetFromBackup(t *testing.T) {
+	cases := []struct {
+		dryRun    bool
+		locked    bool
+		expectErr string
+	}{
+		{true, false, "Dry run: let x = read table from-backup=b1 cluster=c1 would read the value of a cell into a variable"},
+		{false, true, "Session is locked"},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		// the backup isn't restored
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:        &out,
+			errStream:        &errOut,
+			dryRun:           c.dryRun,
+			locked:           c.locked,
+			backupInteractor: application.NewBackupInteractor(mockBtRepo),
+		}
+		executor.Do("let x = read table from-backup=b1 cluster=c1")
+		assert.True(t, strings.HasPrefix(errOut.String(), c.expectErr), "#%d: got %q", i, errOut.String())
+		assert.Empty(t, executor.variables, "#%d", i)
+		ctrl.Finish()
+	}
+}
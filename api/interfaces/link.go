@@ -0,0 +1,82 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/takashabe/btcli/api/config"
+)
+
+// linkScheme is the scheme of the links of the rows opened by "btcli <link>"
+const linkScheme = "btcli://"
+
+func doLink(ctx context.Context, e *Executor, args ...string) {
+	if len(args) != 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "link <table> <row>")
+		return
+	}
+	key, err := unescapeKey(args[2])
+	if err != nil {
+		e.failf("Invalid key: %v\n", err)
+		return
+	}
+	fmt.Fprintln(e.outStream, consoleLink(e.project, e.instance, args[1]))
+	fmt.Fprintln(e.outStream, rowLink(e.project, e.instance, args[1], key))
+}
+
+// consoleLink returns the page of the table in the Cloud Console, the console has no page of a row
+func consoleLink(project, instance, table string) string {
+	return fmt.Sprintf("https://console.cloud.google.com/bigtable/instances/%s/tables/%s/overview?project=%s",
+		url.PathEscape(instance), url.PathEscape(table), url.QueryEscape(project))
+}
+
+// rowLink returns the link of the row in "btcli://<project>/<instance>/<table>/<row>"
+func rowLink(project, instance, table, key string) string {
+	return linkScheme + strings.Join([]string{
+		url.PathEscape(project), url.PathEscape(instance), url.PathEscape(table), url.PathEscape(key),
+	}, "/")
+}
+
+// parseRowLink returns the project, the instance, the table and the row key of the link made by rowLink
+func parseRowLink(link string) (project, instance, table, key string, err error) {
+	parts := strings.Split(strings.TrimPrefix(link, linkScheme), "/")
+	if !strings.HasPrefix(link, linkScheme) || len(parts) != 4 {
+		return "", "", "", "", fmt.Errorf("%q must be %s<project>/<instance>/<table>/<row>", link, linkScheme)
+	}
+	for i, p := range parts {
+		if parts[i], err = url.PathUnescape(p); err != nil {
+			return "", "", "", "", fmt.Errorf("invalid link %q: %v", link, err)
+		}
+	}
+	return parts[0], parts[1], parts[2], parts[3], nil
+}
+
+// openRowLink changes the config to the instance of the link, and returns the table and the row key of it
+func openRowLink(conf *config.Config, link string) (string, string, error) {
+	if conf.Script != "" || conf.Execute != "" {
+		return "", "", fmt.Errorf("a link may not be mixed with -e or -f")
+	}
+	project, instance, table, key, err := parseRowLink(link)
+	if err != nil {
+		return "", "", err
+	}
+	conf.Project = project
+	conf.Instance = instance
+	return table, key, nil
+}
+
+// lookupLink looks up the row of the link and returns the exit code,
+// the key is given as it is without the variables, the expressions and the redirections of the commands
+func (e *Executor) lookupLink(table, key string) int {
+	code := ExitCodeOK
+	e.exit = func(c int) {
+		code = c
+	}
+	e.lookupWithOptions(table, []string{escapeKey(key)})
+	if code == ExitCodeOK && e.failed {
+		code = ExitCodeError
+	}
+	return code
+}
@@ -0,0 +1,156 @@
+package interfaces
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/config"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoLink(t *testing.T) {
+	cases := []struct {
+		input     string
+		expect    string
+		expectErr string
+	}{
+		{
+			"link users 1",
+			"https://console.cloud.google.com/bigtable/instances/i/tables/users/overview?project=p\n" +
+				"btcli://p/i/users/1\n",
+			"",
+		},
+		{
+			`link users 'user/1#a b\x00'`,
+			"https://console.cloud.google.com/bigtable/instances/i/tables/users/overview?project=p\n" +
+				"btcli://p/i/users/user%2F1%23a%20b%00\n",
+			"",
+		},
+		{
+			`link users 'a\q'`,
+			"",
+			"Invalid key: invalid escape in \"a\\\\q\", write a backslash as \\\\\n",
+		},
+		{
+			"link users",
+			"",
+			"Invalid args: link <table> <row>\n",
+		},
+	}
+	for i, c := range cases {
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream: &out,
+			errStream: &errOut,
+			project:   "p",
+			instance:  "i",
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+	}
+}
+
+func TestOpenRowLink(t *testing.T) {
+	cases := []struct {
+		link           string
+		conf           config.Config
+		expectProject  string
+		expectInstance string
+		expectTable    string
+		expectKey      string
+		expectErr      string
+	}{
+		{
+			rowLink("p", "i", "users", "user/1 \x00"),
+			config.Config{Project: "q", Instance: "j"},
+			"p",
+			"i",
+			"users",
+			"user/1 \x00",
+			"",
+		},
+		{
+			"btcli://p/i/users",
+			config.Config{},
+			"",
+			"",
+			"",
+			"",
+			`"btcli://p/i/users" must be btcli://<project>/<instance>/<table>/<row>`,
+		},
+		{
+			"btcli://p/i/users/%zz",
+			config.Config{},
+			"",
+			"",
+			"",
+			"",
+			`invalid link "btcli://p/i/users/%zz": invalid URL escape "%zz"`,
+		},
+		{
+			"btcli://p/i/users/1",
+			config.Config{Execute: "ls"},
+			"",
+			"",
+			"",
+			"",
+			"a link may not be mixed with -e or -f",
+		},
+	}
+	for i, c := range cases {
+		conf := c.conf
+		table, key, err := openRowLink(&conf, c.link)
+		if c.expectErr != "" {
+			assert.EqualError(t, err, c.expectErr, "#%d", i)
+			continue
+		}
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, c.expectProject, conf.Project, "#%d", i)
+		assert.Equal(t, c.expectInstance, conf.Instance, "#%d", i)
+		assert.Equal(t, c.expectTable, table, "#%d", i)
+		assert.Equal(t, c.expectKey, key, "#%d", i)
+	}
+}
+
+func TestLookupLink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	victim := filepath.Join(dir, "victim")
+	if err := ioutil.WriteFile(victim, []byte("keep"), 0600); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	// the key of the link isn't a redirection, a variable or an expression
+	key := ">" + victim + " $x count=(1)"
+	mockBtRepo.EXPECT().Get(gomock.Any(), "users", key, gomock.Any()).Return(&domain.Bigtable{Table: "users", Rows: []*domain.Row{{Key: key}}}, nil)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:      &out,
+		errStream:      &errOut,
+		rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+	}
+	conf := config.Config{}
+	table, k, err := openRowLink(&conf, rowLink("p", "i", "users", key))
+	assert.NoError(t, err)
+	assert.Equal(t, ExitCodeOK, executor.lookupLink(table, k))
+	assert.Equal(t, printedKey(key)+"\n", out.String())
+
+	data, err := ioutil.ReadFile(victim)
+	assert.NoError(t, err)
+	assert.Equal(t, "keep", string(data))
+}
@@ -0,0 +1,54 @@
+package interfaces
+
+import (
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// catalogs are the translations of the user-facing messages by the locales, the English messages are the keys.
+// The catalogs cover the command descriptions, the hints and the errors of the common kinds e.g. "Invalid args: %v\n",
+// the errors starting with the same label must be printed by msg, and the other messages are printed in English
+var catalogs = map[string]map[string]string{
+	"ja": messagesJA,
+}
+
+// translate returns the message in the locale
+func translate(locale, s string) string {
+	if t, ok := catalogs[locale][s]; ok {
+		return t
+	}
+	return s
+}
+
+// msg returns the message in the locale of the session
+func (e *Executor) msg(s string) string {
+	return translate(e.locale, s)
+}
+
+// detectLocale returns the locale of the catalogs by LC_ALL, LC_MESSAGES or LANG, en unless translated
+func detectLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := os.Getenv(env)
+		if v == "" {
+			continue
+		}
+		// the locale is given in the form of "language_TERRITORY.codeset"
+		fields := strings.FieldsFunc(v, func(r rune) bool { return r == '_' || r == '.' || r == '-' })
+		if len(fields) == 0 {
+			break
+		}
+		if lang := strings.ToLower(fields[0]); catalogs[lang] != nil {
+			return lang
+		}
+		break
+	}
+	return "en"
+}
+
+// lowerFirst returns the message starting with the lower case to be embedded in a sentence
+func lowerFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
@@ -0,0 +1,96 @@
+package interfaces
+
+// messagesJA are the messages in Japanese
+var messagesJA = map[string]string{
+	"Invalid args: %v\n":        "引数が不正です: %v\n",
+	"Unknown arg: %v\n":         "不明な引数です: %v\n",
+	"Unknown command: %s\n":     "不明なコマンドです: %s\n",
+	"Invalid options: %v\n":     "オプションが不正です: %v\n",
+	"Invalid range: %v\n":       "範囲が不正です: %v\n",
+	"Invalid expression: %v\n":  "式が不正です: %v\n",
+	"Invalid redirection: %v\n": "リダイレクトが不正です: %v\n",
+	"No previous command":       "前のコマンドがありません",
+	"No more pages":             "次のページはありません",
+	"Session is locked after being idle for %s, run \"unlock\" to continue\n": "%s 操作がなかったためセッションをロックしました。続けるには \"unlock\" を実行してください\n",
+	"Dry run: %s would %s, run \"set dryrun off\" to execute\n":               "ドライラン: %s は「%s」を実行します。実行するには \"set dryrun off\" を実行してください\n",
+	"Unknown arg: %v, must be one of %s\n":                                    "不明な引数です: %v、%s のいずれかを指定してください\n",
+	"Unknown command: %s, must be one of %s\n":                                "不明なコマンドです: %s、%s のいずれかを指定してください\n",
+	"Invalid range: %s is empty\n":                                            "範囲が不正です: %s が空です\n",
+	"Unknown setting: %s, must be one of %s, %s\n":                            "不明な設定です: %s、%s, %s のいずれかを指定してください\n",
+	"Invalid value: %s, must be on or off\n":                                  "値が不正です: %s、on または off を指定してください\n",
+	"Invalid value: %s, must be one of %s\n":                                  "値が不正です: %s、%s のいずれかを指定してください\n",
+	"Failed to run the pager %q: %v\n":                                        "ページャ %q を実行できませんでした: %v\n",
+	"Hint: %s\n":                                                              "ヒント: %s\n",
+	"%s is experimental, run btcli with -enable-experimental to use it\n":     "%s は実験的なコマンドです。使うには -enable-experimental を付けて btcli を起動してください\n",
+	"Deprecated: %s will be removed, use \"%s\" instead\n":                    "非推奨: %s は削除される予定です。代わりに \"%s\" を使ってください\n",
+	"Experimental, enabled by -enable-experimental":                           "実験的なコマンドです。-enable-experimental で有効になります",
+	"Deprecated, use \"%s\" instead\n":                                        "非推奨です。代わりに \"%s\" を使ってください\n",
+
+	// the hints of the errors
+	`The credential lacks an IAM permission on project "{project}".
+Grant "roles/bigtable.reader" to read, or "roles/bigtable.user" to write, and "roles/bigtable.admin" for the table management.
+Check the roles with: gcloud projects get-iam-policy {project}`: `認証情報にプロジェクト "{project}" の IAM 権限がありません。
+読み取りには "roles/bigtable.reader"、書き込みには "roles/bigtable.user"、テーブルの管理には "roles/bigtable.admin" を付与してください。
+ロールの確認: gcloud projects get-iam-policy {project}`,
+	`The instance or the table is not found.
+Check the instance "{instance}" with: gcloud bigtable instances list --project={project}
+Check the table names with: ls`: `インスタンスまたはテーブルが見つかりません。
+インスタンス "{instance}" の確認: gcloud bigtable instances list --project={project}
+テーブル名の確認: ls`,
+	`The quota or the resource is exhausted.
+Check the quota of project "{project}": https://console.cloud.google.com/iam-admin/quotas?project={project}`: `割り当てまたはリソースが不足しています。
+プロジェクト "{project}" の割り当ての確認: https://console.cloud.google.com/iam-admin/quotas?project={project}`,
+	`The credential is invalid or expired.
+Run "gcloud auth application-default login", or give a credential file with -creds`: `認証情報が不正か期限切れです。
+"gcloud auth application-default login" を実行するか、-creds で認証情報のファイルを指定してください`,
+	`Bigtable is unavailable. Check the network and the status: https://status.cloud.google.com`: `Bigtable を利用できません。ネットワークと稼働状況を確認してください: https://status.cloud.google.com`,
+	`The request timed out. Narrow the range with prefix=/start=/end= or count=`:                 `リクエストがタイムアウトしました。prefix=/start=/end= または count= で範囲を絞り込んでください`,
+
+	// the descriptions of the commands
+	"help command":     "コマンドのヘルプ",
+	"List tables":      "テーブルの一覧",
+	"Count table rows": "テーブルの行数を数える",
+	"Show statistics of the cells in a range":                                        "範囲内のセルの統計を表示する",
+	"Show the sampled boundary keys of the tablets":                                  "タブレットの境界キーのサンプルを表示する",
+	"Estimate the bytes of each column family by the sampled rows":                   "サンプルの行から列ファミリーごとのバイト数を見積もる",
+	"Suggest the split points of a new table by the sampled keys of a similar table": "似たテーブルのキーのサンプルから新しいテーブルの分割点を提案する",
+	"Split or intersect the ranges of the row keys in the byte-wise order":           "行キーの範囲をバイト順で分割または交差する",
+	"Read from a single row":                                                         "1 行を読み取る",
+	"Check whether the replicas of the clusters agree on a row":                      "クラスタのレプリカで行が一致するか確認する",
+	"Read from a multi rows":                                                         "複数の行を読み取る",
+	"Export rows to a file":                                                          "行をファイルにエクスポートする",
+	"Import rows from a file written by export":                                      "export で書き出したファイルから行をインポートする",
+	"Write the affected keys of a bulk change to a file for apply":                   "一括変更の対象キーを apply 用のファイルに書き出す",
+	"Execute the bulk change of the keys written by plan":                            "plan で書き出したキーの一括変更を実行する",
+	"Print the request of read or lookup without sending it":                         "read または lookup のリクエストを送信せずに表示する",
+	"Print the next page of the last read":                                           "直前の read の次のページを表示する",
+	"Check which of the keys exist":                                                  "キーが存在するか確認する",
+	"Read the rows having a value matching the pattern":                              "パターンに一致する値を持つ行を読み取る",
+	"Watch changes of a single row":                                                  "1 行の変更を監視する",
+	"Print new cells in the rows periodically":                                       "行の新しいセルを定期的に表示する",
+	"Read a row periodically and report the latency SLO compliance":                  "行を定期的に読み取りレイテンシの SLO の達成状況を報告する",
+	"Build and search the local index of the row keys":                               "行キーのローカルインデックスを構築して検索する",
+	"Check the keys in a file against the bloom filter of index bloom":               "ファイルのキーを index bloom のブルームフィルタで確認する",
+	"Show the changes of a row recorded in the change stream":                        "変更ストリームに記録された行の変更を表示する",
+	"Show the differences of the cells between two rows":                             "2 行のセルの差分を表示する",
+	"Show the settings of a table":                                                   "テーブルの設定を表示する",
+	"Show the automated backup policy of a table":                                    "テーブルの自動バックアップポリシーを表示する",
+	"Set the automated backup policy of a table":                                     "テーブルの自動バックアップポリシーを設定する",
+	"Enable or disable the deletion protection of a table":                           "テーブルの削除保護を有効または無効にする",
+	"Enable or disable the change stream of a table":                                 "テーブルの変更ストリームを有効または無効にする",
+	"Create a table with the column families and the initial split points":           "列ファミリーと初期分割点を指定してテーブルを作成する",
+	"Delete a table":           "テーブルを削除する",
+	"Create a copy of a table": "テーブルのコピーを作成する",
+	"Re-execute the previous command with the options overridden":                      "オプションを上書きして前のコマンドを再実行する",
+	"Print the links of a row to share it":                                             "行を共有するリンクを表示する",
+	"Print a row of the last result by the number of the row":                          "直前の結果の行を行番号で表示する",
+	"Read the value of a cell into a variable expanded as $name in the later commands": "セルの値を後続のコマンドで $name として展開される変数に読み込む",
+	"Show or change the output format of the rows":                                     "行の出力形式を表示または変更する",
+	"Show, save or use the presets of the read and lookup options":                     "read と lookup のオプションのプリセットを表示、保存、使用する",
+	"Show or change the decodes of the columns":                                        "列のデコードを表示または変更する",
+	"Show or change the types guessed by the values without the decodes":               "デコードのない値から推測する型を表示または変更する",
+	"Show or change the session settings":                                              "セッションの設定を表示または変更する",
+	"Retry requests failing fast after the backend was unavailable":                    "バックエンドの停止後に即座に失敗するリクエストを再試行する",
+	"Unlock write commands locked by the idle timeout":                                 "アイドルタイムアウトでロックされた書き込みコマンドのロックを解除する",
+	"Exit this prompt": "プロンプトを終了する",
+}
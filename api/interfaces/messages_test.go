@@ -0,0 +1,141 @@
+package interfaces
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	gotoken "go/token"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessagesJA(t *testing.T) {
+	for _, c := range commands {
+		_, ok := messagesJA[c.Description]
+		assert.True(t, ok, "no translation of %q", c.Description)
+	}
+	for _, h := range errorHints {
+		_, ok := messagesJA[h]
+		assert.True(t, ok, "no translation of %q", h)
+	}
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream: &out,
+		errStream: &errOut,
+		dryRun:    true,
+		locale:    "ja",
+	}
+	executor.Do("deletetable t1")
+	executor.Do("count t1 prefix")
+	executor.Do("noop")
+	executor.Do("set timestamp-format iso")
+	assert.Equal(t, "", out.String())
+	assert.Equal(t, "ドライラン: deletetable t1 は「テーブルを削除する」を実行します。実行するには \"set dryrun off\" を実行してください\n"+
+		"引数が不正です: prefix\n"+
+		"不明なコマンドです: noop\n"+
+		"値が不正です: iso、default, rfc3339, unix-micros, relative のいずれかを指定してください\n", errOut.String())
+}
+
+func TestDetectLocale(t *testing.T) {
+	envs := []string{"LC_ALL", "LC_MESSAGES", "LANG"}
+	saved := map[string]string{}
+	for _, env := range envs {
+		saved[env] = os.Getenv(env)
+		os.Unsetenv(env)
+	}
+	defer func() {
+		for env, v := range saved {
+			os.Setenv(env, v)
+		}
+	}()
+
+	cases := []struct {
+		env    string
+		value  string
+		expect string
+	}{
+		{"LANG", "ja_JP.UTF-8", "ja"},
+		{"LC_MESSAGES", "en_US.UTF-8", "en"},
+		{"LC_ALL", "C", "en"},
+	}
+	for i, c := range cases {
+		os.Setenv(c.env, c.value)
+		assert.Equal(t, c.expect, detectLocale(), "#%d", i)
+	}
+}
+
+// TestMessagesCatalogued checks the messages of the errors by the source, the messages given to msg are translated
+// and the errors starting with the label of a translated message e.g. "Invalid args: " are printed by msg
+func TestMessagesCatalogued(t *testing.T) {
+	var labels []string
+	for k := range messagesJA {
+		if i := strings.Index(k, ": %"); i > 0 {
+			labels = append(labels, k[:i+2])
+		}
+	}
+
+	fset := gotoken.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	literal := func(e ast.Expr) (string, bool) {
+		lit, ok := e.(*ast.BasicLit)
+		if !ok || lit.Kind != gotoken.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(lit.Value)
+		return s, err == nil
+	}
+	for _, f := range pkgs["interfaces"].Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			var name, stream string
+			switch fun := call.Fun.(type) {
+			case *ast.SelectorExpr:
+				name = fun.Sel.Name
+				if len(call.Args) > 1 {
+					if s, ok := call.Args[0].(*ast.SelectorExpr); ok {
+						stream = s.Sel.Name
+					}
+				}
+			case *ast.Ident:
+				name = fun.Name
+			}
+
+			var message ast.Expr
+			switch {
+			case name == "msg":
+				if s, ok := literal(call.Args[0]); ok {
+					_, translated := messagesJA[s]
+					assert.True(t, translated, "%s: no translation of %q", fset.Position(call.Pos()), s)
+				}
+				return true
+			case name == "failf" || name == "failln":
+				message = call.Args[0]
+			case (name == "Fprintf" || name == "Fprintln") && stream == "errStream":
+				message = call.Args[1]
+			default:
+				return true
+			}
+			s, ok := literal(message)
+			if !ok {
+				return true
+			}
+			for _, label := range labels {
+				assert.False(t, strings.HasPrefix(s, label), "%s: %q isn't printed by msg", fset.Position(call.Pos()), s)
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package interfaces
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// thousands separators of the number formats
+var numberSeparators = map[string]string{
+	"comma":  ",",
+	"period": ".",
+	"space":  " ",
+}
+
+// localeSeparators are the thousands separators of the languages not using the comma
+var localeSeparators = map[string]string{
+	"da": ".",
+	"de": ".",
+	"es": ".",
+	"id": ".",
+	"it": ".",
+	"nl": ".",
+	"pt": ".",
+	"tr": ".",
+	"cs": " ",
+	"fi": " ",
+	"fr": " ",
+	"nb": " ",
+	"pl": " ",
+	"ru": " ",
+	"sv": " ",
+	"uk": " ",
+}
+
+// formatNumber returns the number in the number format, raw or empty format prints the digits only
+func (e *Executor) formatNumber(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	sep := numberSeparator(e.numberFormat)
+	if sep == "" {
+		return s
+	}
+
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	var b strings.Builder
+	for i, d := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
+
+// numberSeparator returns the thousands separator of the format
+func numberSeparator(format string) string {
+	if format != "locale" {
+		return numberSeparators[format]
+	}
+	// the locale is given in the form of "language_TERRITORY.codeset"
+	locale := ""
+	for _, env := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if locale = os.Getenv(env); locale != "" {
+			break
+		}
+	}
+	fields := strings.FieldsFunc(locale, func(r rune) bool { return r == '_' || r == '.' || r == '-' })
+	if len(fields) == 0 || locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	if sep, ok := localeSeparators[strings.ToLower(fields[0])]; ok {
+		return sep
+	}
+	return ","
+}
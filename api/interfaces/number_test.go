@@ -0,0 +1,51 @@
+package interfaces
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatNumber(t *testing.T) {
+	cases := []struct {
+		format string
+		input  int64
+		expect string
+	}{
+		{"", 1234567, "1234567"},
+		{"raw", 1234567, "1234567"},
+		{"comma", 1234567, "1,234,567"},
+		{"comma", 123456, "123,456"},
+		{"comma", 999, "999"},
+		{"comma", -1234, "-1,234"},
+		{"period", 1234567, "1.234.567"},
+		{"space", 1234567, "1 234 567"},
+	}
+	for _, c := range cases {
+		e := &Executor{numberFormat: c.format}
+		assert.Equal(t, c.expect, e.formatNumber(c.input), c.format)
+	}
+}
+
+func TestNumberSeparatorLocale(t *testing.T) {
+	for _, env := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		defer os.Setenv(env, os.Getenv(env))
+		os.Unsetenv(env)
+	}
+
+	cases := []struct {
+		lang   string
+		expect string
+	}{
+		{"en_US.UTF-8", ","},
+		{"de_DE.UTF-8", "."},
+		{"fr_FR", " "},
+		{"C", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		os.Setenv("LANG", c.lang)
+		assert.Equal(t, c.expect, numberSeparator("locale"), c.lang)
+	}
+}
@@ -0,0 +1,137 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// pager holds the state of the paginated read to continue by the next command
+type pager struct {
+	table  string
+	parsed map[string]string
+	rs     bigtable.RowSet
+	// end of the range, empty means the end of the table
+	end  string
+	opts []bigtable.ReadOption
+	size int
+	page int
+	// offset is a number of the rows skipped before the first page
+	offset int
+}
+
+func doNext(ctx context.Context, e *Executor, args ...string) {
+	if e.pager == nil {
+		fmt.Fprintln(e.errStream, e.msg("No more pages"))
+		return
+	}
+	// continue with the app profile of the read
+	e.readPage(e.requestContext(e.pager.parsed), 1)
+}
+
+// pageOption returns the page size and the page number of the read,
+// 0 page size means the read is not paginated
+func (e *Executor) pageOption(parsed map[string]string) (int, int, error) {
+	size := e.pageSize
+	// the pages are only for the terminal by default, the redirected output and the documents have all rows
+	if parsed["page"] == "" && (!e.interactive || e.redirected || documentFormats[e.rowFormat(parsed)]) {
+		size = 0
+	}
+	if v := parsed["page-size"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("page-size must be a non-negative integer: %q", v)
+		}
+		size = n
+	}
+	page := 1
+	if v := parsed["page"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("page must be a positive integer: %q", v)
+		}
+		page = n
+	}
+
+	// count gives the explicit limit, and the restored table of the backup is deleted after reading
+	if parsed["count"] != "" || parsed["from-backup"] != "" {
+		if parsed["page"] != "" || parsed["page-size"] != "" {
+			return 0, 0, fmt.Errorf(`"page"/"page-size" may not be mixed with "count" or "from-backup"`)
+		}
+		return 0, 0, nil
+	}
+	if size == 0 && parsed["page"] != "" {
+		return 0, 0, fmt.Errorf(`"page" requires non-zero "page-size"`)
+	}
+	return size, page, nil
+}
+
+// readPage skips forward pages and prints the page, clears the pager after the last page
+func (e *Executor) readPage(ctx context.Context, skip int) {
+	p := e.pager
+	target := p.page + skip
+	for i := 0; i < skip; i++ {
+		// read an extra row to know whether the next page exists
+		opts := append(p.opts[:len(p.opts):len(p.opts)], bigtable.LimitRows(int64(p.offset+p.size+1)))
+		rows, err := e.rowsInteractor.GetRows(ctx, p.table, p.rs, opts...)
+		if err != nil {
+			e.pager = nil
+			e.printError(err)
+			return
+		}
+		rows = skipRows(rows, p.offset)
+		p.offset = 0
+		p.page++
+
+		more := len(rows) > p.size
+		if more {
+			rows = rows[:p.size]
+			start := rows[len(rows)-1].Key + "\x00"
+			if p.end == "" {
+				p.rs = bigtable.InfiniteRange(start)
+			} else {
+				p.rs = bigtable.NewRange(start, p.end)
+			}
+		}
+		if i < skip-1 {
+			if !more {
+				e.pager = nil
+				fmt.Fprintf(e.errStream, "No page %d, the last page is %d\n", target, p.page)
+				return
+			}
+			continue
+		}
+
+		w := e.newPrinter(p.parsed)
+		w.table = p.table
+		w.printRows(rows)
+		if !more {
+			e.pager = nil
+			return
+		}
+		fmt.Fprintf(e.errStream, "-- page %d, run \"next\" for more rows --\n", p.page)
+	}
+}
+
+// rangeEnd returns the exclusive end key of the range options unescaped as rowRange, the keys are checked by rowRange
+func rangeEnd(parsedArgs map[string]string) string {
+	if prefix, _ := unescapeKey(parsedArgs["prefix"]); prefix != "" {
+		return prefixSuccessor(prefix)
+	}
+	end, _ := unescapeKey(parsedArgs["end"])
+	return end
+}
+
+// prefixSuccessor returns the lexically smallest key greater than all keys with the prefix,
+// empty means there is no such key
+func prefixSuccessor(prefix string) string {
+	n := len(prefix)
+	for n--; n >= 0 && prefix[n] == '\xff'; n-- {
+	}
+	if n < 0 {
+		return ""
+	}
+	return prefix[:n] + string([]byte{prefix[n] + 1})
+}
@@ -0,0 +1,130 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestReadPage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	gomock.InOrder(
+		mockBtRepo.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("a"), latest, bigtable.LimitRows(3)).Return(
+			&domain.Bigtable{
+				Rows: []*domain.Row{{Key: "a1"}, {Key: "a2"}, {Key: "a3"}},
+			}, nil),
+		mockBtRepo.EXPECT().GetRows(gomock.Any(), "table", bigtable.NewRange("a2\x00", "b"), latest, bigtable.LimitRows(3)).Return(
+			&domain.Bigtable{
+				Rows: []*domain.Row{{Key: "a3"}},
+			}, nil),
+	)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:      &out,
+		errStream:      &errOut,
+		rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		interactive:    true,
+		pageSize:       2,
+	}
+
+	executor.Do("read table prefix=a")
+	assert.Equal(t, "a1\na2\n", out.String())
+	assert.Equal(t, "----------------------------------------\n----------------------------------------\n-- page 1, run \"next\" for more rows --\n", errOut.String())
+
+	out.Reset()
+	errOut.Reset()
+	executor.Do("next")
+	assert.Equal(t, "a3\n", out.String())
+	assert.Equal(t, "----------------------------------------\n", errOut.String())
+
+	errOut.Reset()
+	executor.Do("next")
+	assert.Equal(t, "No more pages\n", errOut.String())
+}
+
+func TestReadPageEscaped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	gomock.InOrder(
+		mockBtRepo.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("a\x00"), latest, bigtable.LimitRows(2)).Return(
+			&domain.Bigtable{
+				Rows: []*domain.Row{{Key: "a\x001"}, {Key: "a\x002"}},
+			}, nil),
+		// the next page ends at the successor of the unescaped prefix
+		mockBtRepo.EXPECT().GetRows(gomock.Any(), "table", bigtable.NewRange("a\x001\x00", "a\x01"), latest, bigtable.LimitRows(2)).Return(
+			&domain.Bigtable{
+				Rows: []*domain.Row{{Key: "a\x002"}},
+			}, nil),
+	)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:      &out,
+		errStream:      &errOut,
+		rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		interactive:    true,
+		pageSize:       1,
+	}
+	executor.Do(`read table prefix='a\x00'`)
+	executor.Do("next")
+	assert.Equal(t, "'a\\x001'\n'a\\x002'\n", out.String())
+}
+
+func TestPageOption(t *testing.T) {
+	cases := []struct {
+		interactive bool
+		redirected  bool
+		parsed      map[string]string
+		expectSize  int
+		expectPage  int
+	}{
+		{true, false, map[string]string{}, 100, 1},
+		// the output of the scripts, -e and the files has all rows
+		{false, false, map[string]string{}, 0, 1},
+		{true, true, map[string]string{}, 0, 1},
+		{true, false, map[string]string{"format": "json"}, 0, 1},
+		{true, false, map[string]string{"format": "yaml"}, 0, 1},
+		{true, false, map[string]string{"format": "csv"}, 100, 1},
+		// the pages given by the options
+		{false, false, map[string]string{"page-size": "10"}, 10, 1},
+		{false, false, map[string]string{"page": "2"}, 100, 2},
+		{true, false, map[string]string{"format": "json", "page-size": "10"}, 10, 1},
+	}
+	for i, c := range cases {
+		e := &Executor{interactive: c.interactive, redirected: c.redirected, pageSize: 100}
+		size, page, err := e.pageOption(c.parsed)
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, c.expectSize, size, "#%d", i)
+		assert.Equal(t, c.expectPage, page, "#%d", i)
+	}
+}
+
+func TestPrefixSuccessor(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+	}{
+		{"a", "b"},
+		{"ab", "ac"},
+		{"a\xff", "b"},
+		{"\xff", ""},
+		{"", ""},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expect, prefixSuccessor(c.input))
+	}
+}
@@ -0,0 +1,94 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// defaultPager is the pager unless $PAGER is set, LESS=FRX prints the output fitting the screen without paging like git
+const defaultPager = "less"
+
+// interactivePager returns the pager of $PAGER for the terminal, empty $PAGER disables the pager like psql
+func interactivePager() string {
+	if !isTerminal(os.Stdout) {
+		return ""
+	}
+	p, ok := os.LookupEnv("PAGER")
+	if !ok {
+		return defaultPager
+	}
+	return strings.TrimSpace(p)
+}
+
+// runPaged runs the command with the output streamed through the pager as it is written,
+// the separators of the rows are paged with the rows and the errors are kept out of the pager on the error stream
+func (e *Executor) runPaged(ctx context.Context, c Command, args []string) {
+	pager := e.pagerCommand
+	if pager == "" {
+		pager = defaultPager
+	}
+	// the command stops reading after the pager quits
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	out := e.outStream
+	p, err := startPager(pager, out, cancel)
+	if err != nil {
+		fmt.Fprintf(e.errStream, e.msg("Failed to run the pager %q: %v\n"), pager, err)
+		c.Runner(ctx, e, args...)
+		return
+	}
+	e.outStream, e.paged = p, true
+	c.Runner(ctx, e, args...)
+	e.outStream, e.paged = out, false
+	p.Close()
+}
+
+// pagerWriter writes to the input of the pager, the writes fail after the pager quits
+type pagerWriter struct {
+	in     io.WriteCloser
+	cmd    *exec.Cmd
+	cancel func()
+}
+
+func (p *pagerWriter) Write(b []byte) (int, error) {
+	n, err := p.in.Write(b)
+	if err != nil {
+		p.cancel()
+	}
+	return n, err
+}
+
+// Close ends the input and waits for the pager to quit
+func (p *pagerWriter) Close() error {
+	p.in.Close()
+	// quitting the pager before the end isn't an error
+	p.cmd.Wait()
+	return nil
+}
+
+// startPager starts the pager writing to out, the error is returned only when the pager isn't started
+func startPager(pager string, out io.Writer, cancel func()) (io.WriteCloser, error) {
+	fields := strings.Fields(pager)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no command")
+	}
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &pagerWriter{in: in, cmd: cmd, cancel: cancel}, nil
+}
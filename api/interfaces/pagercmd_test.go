@@ -0,0 +1,64 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoPaged(t *testing.T) {
+	cases := []struct {
+		input        string
+		pagerCommand string
+		expectOut    string
+		expectErr    string
+	}{
+		// the output of the paged commands is piped, the errors aren't
+		{"help lookup", "cat", "lookup <table> <row>", ""},
+		{"help nosuchcmd", "cat", "", "Unknown command: nosuchcmd\n"},
+		{"set pager", "cat", "", "Invalid args: set [dryrun|color|pager|utc|verbose on|off] [timestamp-format <format>]\n"},
+		{"help lookup", "btcli-no-such-pager", "lookup <table> <row>", "Failed to run the pager \"btcli-no-such-pager\": exec: \"btcli-no-such-pager\": executable file not found in $PATH\n"},
+		{"help lookup", "  ", "lookup <table> <row>", "Failed to run the pager \"  \": no command\n"},
+	}
+	for i, c := range cases {
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:    &out,
+			errStream:    &errOut,
+			pagerOn:      true,
+			pagerCommand: c.pagerCommand,
+		}
+		executor.Do(c.input)
+		assert.Contains(t, out.String(), c.expectOut, "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+	}
+}
+
+func TestDoPagedSeparators(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	mockBtRepo.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowList{"a", "b"}, bigtable.RowFilter(bigtable.LatestNFilter(1))).Return(
+		&domain.Bigtable{Table: "table", Rows: []*domain.Row{{Key: "a"}, {Key: "b"}}}, nil)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:      &out,
+		errStream:      &errOut,
+		rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		numberRows:     true,
+		pagerOn:        true,
+		pagerCommand:   "cat",
+	}
+	// the numbers of the rows for show are paged with the rows
+	executor.Do("lookup table a b")
+	assert.Equal(t, "-- 1 -----------------------------------\na\n-- 2 -----------------------------------\nb\n", out.String())
+	assert.Equal(t, "", errOut.String())
+	assert.False(t, executor.paged)
+}
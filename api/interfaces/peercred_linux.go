@@ -0,0 +1,29 @@
+//go:build linux
+// +build linux
+
+package interfaces
+
+import (
+	"net"
+	"syscall"
+)
+
+// peerUID returns the user of the process connected to the unix socket, ok is false if unknown
+func peerUID(conn net.Conn) (uid int, ok bool) {
+	uc, isUnix := conn.(*net.UnixConn)
+	if !isUnix {
+		return 0, false
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return 0, false
+	}
+	var cred *syscall.Ucred
+	raw.Control(func(fd uintptr) {
+		cred, err = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil || cred == nil {
+		return 0, false
+	}
+	return int(cred.Uid), true
+}
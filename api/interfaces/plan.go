@@ -0,0 +1,236 @@
+package interfaces
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/takashabe/btcli/api/domain"
+)
+
+// planOperations are the bulk changes planned by plan and executed by apply
+var planOperations = []string{"deleterows", "update"}
+
+// key formats of the plan, the binary keys are written in base64
+const keyFormatBase64 = "base64"
+
+// planManifest is the affected keys of a bulk change, apply executes exactly the keys after the review
+type planManifest struct {
+	Operation string `json:"operation"`
+	Table     string `json:"table"`
+	// Cells are the cells set to the rows by update
+	Cells []planCell `json:"cells,omitempty"`
+	// KeyFormat is base64 when any key isn't valid UTF-8 and the keys are encoded, otherwise utf8
+	KeyFormat string    `json:"key_format"`
+	Keys      []string  `json:"keys"`
+	PlannedAt time.Time `json:"planned_at"`
+}
+
+// planCell is a cell set by update, the value is encoded by the decode like import
+type planCell struct {
+	Column string `json:"column"`
+	Value  string `json:"value"`
+	Decode string `json:"decode,omitempty"`
+}
+
+func doPlan(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 4 {
+		e.failf(e.msg("Invalid args: %v\n"), "plan deleterows|update <table> <file> [args ...]")
+		return
+	}
+	op, table, file := args[1], args[2], args[3]
+	if !containsString(planOperations, op) {
+		e.failf("Unknown operation: %s, must be one of %s\n", op, strings.Join(planOperations, ", "))
+		return
+	}
+
+	parsed := make(map[string]string)
+	var sets []string
+	for _, arg := range args[4:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch {
+		case k == "set" && op == "update":
+			sets = append(sets, v)
+		case k == "decode" && op == "update":
+			parsed[k] = v
+		case k == "start", k == "end", k == "prefix", k == "count":
+			parsed[k] = v
+		case k == "family", k == "columns", k == "qualifier-regex", k == "value-regex":
+			parsed[k] = v
+		case k == "app-profile":
+			parsed[k] = v
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		}
+	}
+
+	m := &planManifest{
+		Operation: op,
+		Table:     table,
+		KeyFormat: keyFormatUTF8,
+		Keys:      []string{},
+	}
+	for _, s := range sets {
+		c, err := parsePlanCell(s, parsed["decode"])
+		if err != nil {
+			e.failf("Invalid set: %v\n", err)
+			return
+		}
+		m.Cells = append(m.Cells, c)
+	}
+	if op == "update" && len(m.Cells) == 0 {
+		e.failf(e.msg("Invalid args: %v\n"), "update requires set=<family:qualifier>=<value>")
+		return
+	}
+
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		e.failln(`"start"/"end" may not be mixed with "prefix"`)
+		return
+	}
+	rr, err := rowRange(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid range: %v\n"), err)
+		return
+	}
+	defaultVersions(parsed)
+	ro, err := readOption(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+
+	err = e.rowsInteractor.ScanRows(e.requestContext(parsed), table, rr, func(r *domain.Row) bool {
+		if !utf8.ValidString(r.Key) {
+			m.KeyFormat = keyFormatBase64
+		}
+		m.Keys = append(m.Keys, r.Key)
+		return true
+	}, ro...)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	if m.KeyFormat == keyFormatBase64 {
+		for i, k := range m.Keys {
+			m.Keys[i] = base64.StdEncoding.EncodeToString([]byte(k))
+		}
+	}
+	m.PlannedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	if err := ioutil.WriteFile(file, append(data, '\n'), 0600); err != nil {
+		e.printError(err)
+		return
+	}
+	fmt.Fprintf(e.errStream, "Planned %s of %s rows of %s to %s, review it and run \"apply %s\" to execute\n",
+		op, e.formatNumber(int64(len(m.Keys))), table, file, file)
+}
+
+// parsePlanCell parses the cell of "family:qualifier=value", the value is checked by the decode
+func parsePlanCell(s, decode string) (planCell, error) {
+	i := strings.Index(s, "=")
+	if i < 0 || !strings.Contains(s[:i], ":") {
+		return planCell{}, fmt.Errorf("%q must be <family:qualifier>=<value>", s)
+	}
+	c := planCell{Column: s[:i], Value: s[i+1:], Decode: decode}
+	if _, err := encodeValue(decode, importValue{text: c.Value}); err != nil {
+		return planCell{}, fmt.Errorf("%s: %v", c.Column, err)
+	}
+	return c, nil
+}
+
+func doApply(ctx context.Context, e *Executor, args ...string) {
+	if len(args) != 2 {
+		e.failf(e.msg("Invalid args: %v\n"), "apply <file>")
+		return
+	}
+	file := args[1]
+	m, keys, err := readPlan(file)
+	if err != nil {
+		e.failf("Invalid plan: %v\n", err)
+		return
+	}
+
+	switch m.Operation {
+	case "deleterows":
+		if err := e.rowsInteractor.DeleteRows(e.requestContext(nil), m.Table, keys); err != nil {
+			e.printError(err)
+			return
+		}
+		fmt.Fprintf(e.errStream, "Deleted %s rows of %s\n", e.formatNumber(int64(len(keys))), m.Table)
+	case "update":
+		columns := make([]*domain.Column, 0, len(m.Cells))
+		for _, c := range m.Cells {
+			// already checked by readPlan
+			v, _ := encodeValue(c.Decode, importValue{text: c.Value})
+			columns = append(columns, &domain.Column{Family: c.Column[:strings.Index(c.Column, ":")], Qualifier: c.Column, Value: v})
+		}
+		rows := make([]*domain.Row, 0, len(keys))
+		for _, k := range keys {
+			rows = append(rows, &domain.Row{Key: k, Columns: columns})
+		}
+		if err := e.rowsInteractor.WriteRows(e.requestContext(nil), m.Table, rows); err != nil {
+			e.printError(err)
+			return
+		}
+		fmt.Fprintf(e.errStream, "Updated %s rows of %s\n", e.formatNumber(int64(len(keys))), m.Table)
+	}
+}
+
+// readPlan reads the plan and returns the decoded keys
+func readPlan(file string) (*planManifest, []string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	var m planManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", file, err)
+	}
+	if !containsString(planOperations, m.Operation) {
+		return nil, nil, fmt.Errorf("unknown operation %q, must be one of %s", m.Operation, strings.Join(planOperations, ", "))
+	}
+	if m.Table == "" {
+		return nil, nil, fmt.Errorf("no table in %s", file)
+	}
+	for _, c := range m.Cells {
+		if _, err := parsePlanCell(c.Column+"="+c.Value, c.Decode); err != nil {
+			return nil, nil, err
+		}
+	}
+	if m.Operation == "update" && len(m.Cells) == 0 {
+		return nil, nil, fmt.Errorf("no cells of update in %s", file)
+	}
+
+	keys := make([]string, len(m.Keys))
+	for i, k := range m.Keys {
+		switch m.KeyFormat {
+		case keyFormatUTF8:
+			keys[i] = k
+		case keyFormatBase64:
+			b, err := base64.StdEncoding.DecodeString(k)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid base64 key %q", k)
+			}
+			keys[i] = string(b)
+		default:
+			return nil, nil, fmt.Errorf("unknown key format %q, must be utf8 or base64", m.KeyFormat)
+		}
+	}
+	return &m, keys, nil
+}
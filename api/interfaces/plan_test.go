@@ -0,0 +1,244 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoPlan(t *testing.T) {
+	scan := func(keys ...string) func(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+		return func(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+			for _, k := range keys {
+				f(&domain.Row{Key: k})
+			}
+			return nil
+		}
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "plan.json")
+
+	cases := []struct {
+		input      string
+		expectErr  string
+		expectPlan *planManifest
+		prepare    func(*repository.MockBigtable)
+	}{
+		{
+			"plan deleterows table " + file + " prefix=a",
+			"Planned deleterows of 2 rows of table to " + file + ", review it and run \"apply " + file + "\" to execute\n",
+			&planManifest{Operation: "deleterows", Table: "table", KeyFormat: "utf8", Keys: []string{"a1", "a2"}},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.PrefixRange("a"), gomock.Any(), latest).DoAndReturn(scan("a1", "a2"))
+			},
+		},
+		{
+			"plan deleterows table " + file,
+			"Planned deleterows of 1 rows of table to " + file + ", review it and run \"apply " + file + "\" to execute\n",
+			&planManifest{Operation: "deleterows", Table: "table", KeyFormat: "base64", Keys: []string{"YP8="}},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), latest).DoAndReturn(scan("`\xff"))
+			},
+		},
+		{
+			"plan update table " + file + " set=d:count=3 set=d:total=4 decode=int count=1",
+			"Planned update of 1 rows of table to " + file + ", review it and run \"apply " + file + "\" to execute\n",
+			&planManifest{
+				Operation: "update",
+				Table:     "table",
+				Cells: []planCell{
+					{Column: "d:count", Value: "3", Decode: "int"},
+					{Column: "d:total", Value: "4", Decode: "int"},
+				},
+				KeyFormat: "utf8",
+				Keys:      []string{"a1"},
+			},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), bigtable.LimitRows(1), latest).DoAndReturn(scan("a1"))
+			},
+		},
+		{
+			"plan update table " + file + " set=d:count=x decode=int",
+			"Invalid set: d:count: invalid int \"x\"\n",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"plan update table " + file + " set=count=3",
+			"Invalid set: \"count=3\" must be <family:qualifier>=<value>\n",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"plan update table " + file,
+			"Invalid args: update requires set=<family:qualifier>=<value>\n",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"plan deleterows table " + file + " set=d:count=3",
+			"Unknown arg: set=d:count=3\n",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"plan deleteall table " + file,
+			"Unknown operation: deleteall, must be one of deleterows, update\n",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"plan deleterows table",
+			"Invalid args: plan deleterows|update <table> <file> [args ...]\n",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for i, c := range cases {
+		os.Remove(file)
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:      &out,
+			errStream:      &errOut,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		}
+		executor.Do(c.input)
+		assert.Equal(t, "", out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+		if c.expectPlan == nil {
+			_, err := os.Stat(file)
+			assert.True(t, os.IsNotExist(err), "#%d", i)
+			continue
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("#%d: want no error, got %v", i, err)
+		}
+		// the plan has the keys of the rows
+		if runtime.GOOS != "windows" {
+			fi, err := os.Stat(file)
+			if err != nil {
+				t.Fatalf("#%d: want no error, got %v", i, err)
+			}
+			assert.Equal(t, os.FileMode(0600), fi.Mode().Perm(), "#%d", i)
+		}
+		var m planManifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			t.Fatalf("#%d: want no error, got %v", i, err)
+		}
+		assert.False(t, m.PlannedAt.IsZero(), "#%d", i)
+		m.PlannedAt = c.expectPlan.PlannedAt
+		assert.Equal(t, c.expectPlan, &m, "#%d", i)
+	}
+}
+
+func TestDoApply(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "plan.json")
+
+	cases := []struct {
+		plan      string
+		dryRun    bool
+		expectErr string
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			`{"operation":"deleterows","table":"table","key_format":"utf8","keys":["a1","a2"]}`,
+			false,
+			"Deleted 2 rows of table\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().DeleteRows(gomock.Any(), "table", []string{"a1", "a2"}).Return(nil)
+			},
+		},
+		{
+			`{"operation":"deleterows","table":"table","key_format":"base64","keys":["YP8="]}`,
+			false,
+			"Deleted 1 rows of table\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().DeleteRows(gomock.Any(), "table", []string{"`\xff"}).Return(nil)
+			},
+		},
+		{
+			`{"operation":"update","table":"table","cells":[{"column":"d:count","value":"3","decode":"int"}],"key_format":"utf8","keys":["a1"]}`,
+			false,
+			"Updated 1 rows of table\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().WriteRows(gomock.Any(), "table", []*domain.Row{
+					{Key: "a1", Columns: []*domain.Column{{Family: "d", Qualifier: "d:count", Value: []byte{0, 0, 0, 0, 0, 0, 0, 3}}}},
+				}).Return(nil)
+			},
+		},
+		{
+			`{"operation":"deleterows","table":"table","key_format":"utf8","keys":["a1"]}`,
+			true,
+			"Dry run: apply " + file + " would execute the bulk change of the keys written by plan, run \"set dryrun off\" to execute\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			`{"operation":"update","table":"table","key_format":"utf8","keys":["a1"]}`,
+			false,
+			"Invalid plan: no cells of update in " + file + "\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			`{"operation":"deleterows","table":"table","key_format":"base64","keys":["%"]}`,
+			false,
+			"Invalid plan: invalid base64 key \"%\"\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			`{"operation":"deletetable","table":"table","key_format":"utf8","keys":[]}`,
+			false,
+			"Invalid plan: unknown operation \"deletetable\", must be one of deleterows, update\n",
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for i, c := range cases {
+		if err := ioutil.WriteFile(file, []byte(c.plan), 0644); err != nil {
+			t.Fatalf("#%d: want no error, got %v", i, err)
+		}
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:      &out,
+			errStream:      &errOut,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+			dryRun:         c.dryRun,
+		}
+		executor.Do("apply " + file)
+		assert.Equal(t, "", out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
@@ -0,0 +1,203 @@
+package interfaces
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/takashabe/btcli/api/config"
+)
+
+// presetOptions are the options of read and lookup saved by a preset, in the printed order
+var presetOptions = []string{"version", "versions", "family", "decode", "decode_columns", "format", "count"}
+
+// lookupPresetOptions are the options of a preset applied to lookup, which reads no count
+var lookupPresetOptions = []string{"version", "versions", "family", "decode", "decode_columns", "format"}
+
+func doPreset(ctx context.Context, e *Executor, args ...string) {
+	if len(args) == 1 {
+		names := make([]string, 0, len(e.presets))
+		for name := range e.presets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			mark := " "
+			if name == e.activePreset {
+				mark = "*"
+			}
+			fmt.Fprintf(e.outStream, "%s %s\t%s\n", mark, name, strings.Join(presetArgs(e.presets[name], presetOptions), " "))
+		}
+		return
+	}
+
+	switch args[1] {
+	case "save":
+		if len(args) < 4 {
+			e.failf(e.msg("Invalid args: %v\n"), "preset save <name> <key>=<value> ...")
+			return
+		}
+		e.savePreset(args[2], args[3:])
+	case "use":
+		if len(args) != 3 {
+			e.failf(e.msg("Invalid args: %v\n"), "preset use <name>|off")
+			return
+		}
+		name := args[2]
+		if name == "off" {
+			e.activePreset = ""
+			fmt.Fprintln(e.errStream, "Read without a preset")
+			return
+		}
+		if _, ok := e.presets[name]; !ok {
+			e.failf("Unknown preset: %s\n", name)
+			return
+		}
+		e.activePreset = name
+		fmt.Fprintf(e.errStream, "Read with the preset %s unless preset is given\n", name)
+	case "delete":
+		if len(args) != 3 {
+			e.failf(e.msg("Invalid args: %v\n"), "preset delete <name>")
+			return
+		}
+		name := args[2]
+		if _, ok := e.presets[name]; !ok {
+			e.failf("Unknown preset: %s\n", name)
+			return
+		}
+		delete(e.presets, name)
+		if e.activePreset == name {
+			e.activePreset = ""
+		}
+		if err := e.writePresets(); err != nil {
+			e.failf("Failed to save the presets: %v\n", err)
+			return
+		}
+		fmt.Fprintf(e.errStream, "Deleted the preset %s\n", name)
+	default:
+		e.failf("Unknown subcommand: %s, must be one of save, use, delete\n", args[1])
+	}
+}
+
+func (e *Executor) savePreset(name string, args []string) {
+	if name == "off" || strings.Contains(name, "=") {
+		e.failf("Invalid name: %s\n", name)
+		return
+	}
+	preset := map[string]string{}
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		if !containsString(presetOptions, k) {
+			e.failf(e.msg("Unknown arg: %v, must be one of %s\n"), arg, strings.Join(presetOptions, ", "))
+			return
+		}
+		if k == "format" && !containsString(config.Formats, v) {
+			e.failf("Invalid format: %s, must be one of %s\n", v, strings.Join(config.Formats, ", "))
+			return
+		}
+		preset[k] = v
+	}
+	if e.presets == nil {
+		e.presets = map[string]map[string]string{}
+	}
+	e.presets[name] = preset
+	if err := e.writePresets(); err != nil {
+		e.failf("Failed to save the presets: %v\n", err)
+		return
+	}
+	fmt.Fprintf(e.errStream, "Saved the preset %s\n", name)
+}
+
+// withPreset returns the arguments with the options of the preset given by the preset option or the preset in use,
+// the options in the arguments win over the preset
+func (e *Executor) withPreset(args []string, options []string) ([]string, error) {
+	name := e.activePreset
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "preset=") {
+			name = strings.TrimPrefix(arg, "preset=")
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	if name == "" {
+		return rest, nil
+	}
+	preset, ok := e.presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q", name)
+	}
+
+	given := map[string]bool{}
+	for _, arg := range rest {
+		given[optionName(arg)] = true
+	}
+	applied := map[string]string{}
+	for k, v := range preset {
+		if !given[optionName(k+"=")] {
+			applied[k] = v
+		}
+	}
+	return append(presetArgs(applied, options), rest...), nil
+}
+
+// presetArgs returns the options of the preset as the arguments in the order of the options
+func presetArgs(preset map[string]string, options []string) []string {
+	args := make([]string, 0, len(preset))
+	for _, k := range options {
+		if v, ok := preset[k]; ok {
+			args = append(args, k+"="+v)
+		}
+	}
+	return args
+}
+
+// loadPresets reads the presets saved by the previous sessions, no presets if the file doesn't exist
+func loadPresets(file string) (map[string]map[string]string, error) {
+	presets := map[string]map[string]string{}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return presets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return presets, nil
+}
+
+// writePresets saves the presets to presetFile, kept only in the session without the file
+func (e *Executor) writePresets() error {
+	if e.presetFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(e.presets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(e.presetFile), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(e.presetFile, append(data, '\n'), 0600)
+}
+
+func containsString(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoPreset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "presets.json")
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:  &out,
+		errStream:  &errOut,
+		presetFile: file,
+	}
+
+	cases := []struct {
+		input     string
+		expectOut string
+		expectErr string
+	}{
+		{"preset save wide family=d versions=all format=json", "", "Saved the preset wide\n"},
+		{"preset save one count=1", "", "Saved the preset one\n"},
+		{"preset save bad start=a", "", "Unknown arg: start=a, must be one of version, versions, family, decode, decode_columns, format, count\n"},
+		{"preset save bad format=xml", "", "Invalid format: xml, must be one of text, json, ndjson, csv, tsv, yaml, table, wide, plain, template\n"},
+		{"preset save bad", "", "Invalid args: preset save <name> <key>=<value> ...\n"},
+		{"preset use wide", "", "Read with the preset wide unless preset is given\n"},
+		{"preset use none", "", "Unknown preset: none\n"},
+		{"preset", "  one\tcount=1\n* wide\tversions=all family=d format=json\n", ""},
+		{"preset delete wide", "", "Deleted the preset wide\n"},
+		{"preset", "  one\tcount=1\n", ""},
+		{"preset rename one", "", "Unknown subcommand: rename, must be one of save, use, delete\n"},
+	}
+	for i, c := range cases {
+		out.Reset()
+		errOut.Reset()
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+	}
+	assert.Equal(t, "", executor.activePreset)
+
+	presets, err := loadPresets(file)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{"one": {"count": "1"}}, presets)
+
+	presets, err = loadPresets(filepath.Join(dir, "none.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{}, presets)
+}
+
+func TestReadWithPreset(t *testing.T) {
+	presets := map[string]map[string]string{
+		"p": {"family": "d", "count": "1", "format": "ndjson"},
+		"v": {"versions": "2"},
+	}
+	family := func(f string) bigtable.ReadOption {
+		return bigtable.RowFilter(bigtable.ChainFilters(bigtable.LatestNFilter(1), bigtable.FamilyFilter("^(?:"+f+")$")))
+	}
+	row := &domain.Bigtable{Rows: []*domain.Row{{Key: "a"}}}
+	scan := func(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+		for _, r := range row.Rows {
+			f(r)
+		}
+		return nil
+	}
+
+	cases := []struct {
+		input     string
+		active    string
+		expectOut string
+		expectErr string
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			"read table preset=p",
+			"",
+			`{"key":"a","cells":[]}` + "\n",
+			"",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.RowRange{}, gomock.Any(), bigtable.LimitRows(1), family("d")).
+					DoAndReturn(scan)
+			},
+		},
+		{
+			// the given options win over the preset
+			"read table family=e format=text",
+			"p",
+			"a\n",
+			"----------------------------------------\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowRange{}, bigtable.LimitRows(1), family("e")).Return(row, nil)
+			},
+		},
+		{
+			// lookup reads no count of the preset
+			"lookup table a",
+			"p",
+			`{"key":"a","cells":[]}` + "\n",
+			"",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Get(gomock.Any(), "table", "a", family("d")).Return(row, nil)
+			},
+		},
+		{
+			// "version" overrides "versions" of the preset
+			"lookup table a version=3",
+			"v",
+			"a\n",
+			"----------------------------------------\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Get(gomock.Any(), "table", "a", bigtable.RowFilter(bigtable.LatestNFilter(3))).Return(row, nil)
+			},
+		},
+		{
+			"read table version=1 versions=2",
+			"",
+			"",
+			"Invalid options: \"version\" may not be mixed with \"versions\"\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"read table preset=none",
+			"p",
+			"",
+			"Invalid preset: unknown preset \"none\"\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"read table format=xml",
+			"",
+			"",
+			"Invalid options: format must be one of text, json, ndjson, csv, tsv, yaml, table, wide, plain, template: \"xml\"\n",
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:      &out,
+			errStream:      &errOut,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+			presets:        presets,
+			activePreset:   c.active,
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
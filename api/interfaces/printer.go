@@ -1,91 +1,476 @@
 package interfaces
 
 import (
+	"bytes"
+	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/takashabe/btcli/api/domain"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
 const (
 	decodeTypeString = "string"
 	decodeTypeInt    = "int"
 	decodeTypeFloat  = "float"
+	// decodeTypeHex prints the value as a hex dump with the offsets
+	decodeTypeHex = "hex"
+	// decodeTypeBase64 prints the value in the standard base64 encoding to copy it losslessly
+	decodeTypeBase64 = "base64"
+	// decodeTypeJSON prints the JSON value re-indented
+	decodeTypeJSON = "json"
 )
 
+const timestampLayout = "2006/01/02-15:04:05.000000"
+
+// units of the timestamp suffix in the qualifiers
+var qualifierTimeUnits = map[string]time.Duration{
+	"s":  time.Second,
+	"ms": time.Millisecond,
+	"us": time.Microsecond,
+	"ns": time.Nanosecond,
+}
+
 // Printer print the bigtable items to stream
 type Printer struct {
 	outStream io.Writer
 	errStream io.Writer
+	// separatorsOut writes the separators of the rows to outStream read by the pager instead of errStream
+	separatorsOut bool
 
 	decodeType       string
 	decodeColumnType map[string]string
+
+	// qualifierTime is a unit of the timestamp suffix in the qualifiers
+	qualifierTime string
+	// pivot prints the time-bucketed qualifiers as a series
+	pivot bool
+
+	// formatter writes the rows in the output format instead of the text, nil prints the text
+	formatter rowFormatter
+	// template writes each row of the template format, templateErr stops it after the first error
+	template    *template.Template
+	templateErr error
+	// color paints the text output, valueMatch and qualifierMatch highlight the matched portions
+	color          bool
+	valueMatch     *regexp.Regexp
+	qualifierMatch *regexp.Regexp
+	// binaryEncoding is the encoding of the binary fields of csv and tsv, base64 adds the marker column
+	binaryEncoding string
+	// cellTypes records the types of the values guessed as the numbers, so that import writes the same bytes
+	cellTypes bool
+	// transforms are applied to the values of the matched qualifiers before decoding
+	transforms []*columnTransform
+
+	// table is the table of the rows, empty when the rows aren't read from a table
+	table string
+
+	// protoFiles are the descriptors of the proto decode, nil if not given
+	protoFiles *protoregistry.Files
+	// columnDecoders are the decodes of config.Decoders and the decode command by "family:qualifier"
+	columnDecoders map[string]string
+
+	// autoDecode is the types guessed by the values of 8 bytes in off, int, float or all, empty guesses all types
+	autoDecode string
+
+	// timestamps formats the timestamps of the cells
+	timestamps timestampFormatter
+	// verbose prints the byte size, the labels and the raw value of each cell
+	verbose bool
+	// maxValueBytes is a number of bytes of a value printed at most by the text output, 0 prints all bytes
+	maxValueBytes int
+
+	// record keeps the printed row for show and returns the number of it, nil doesn't number the rows
+	record func(*domain.Row) int
+
+	// avroSchemas are the schemas of the avro decode loaded by the files
+	avroSchemas map[string]*avroSchema
 }
 
 func (w *Printer) printRows(rs []*domain.Row) {
+	if w.formatter != nil {
+		for _, r := range rs {
+			w.recordRow(r)
+		}
+		w.formatter.writeRows(w, rs)
+		return
+	}
 	for _, r := range rs {
 		w.printRow(r)
 	}
 }
 
 func (w *Printer) printRow(r *domain.Row) {
-	fmt.Fprintln(w.outStream, strings.Repeat("-", 40))
-	fmt.Fprintln(w.outStream, r.Key)
+	n := w.recordRow(r)
+	if w.formatter != nil {
+		w.formatter.writeRow(w, r)
+		return
+	}
+	// the separator isn't a part of the data but is paged with the rows
+	sep := w.errStream
+	if w.separatorsOut {
+		sep = w.outStream
+	}
+	fmt.Fprintln(sep, rowSeparator(n))
+	fmt.Fprintln(w.outStream, w.paint(colorKey, printedKey(r.Key)))
 
-	for _, c := range r.Columns {
-		fmt.Fprintf(w.outStream, "  %-40s @ %s\n", c.Qualifier, c.Version.Format("2006/01/02-15:04:05.000000"))
+	if w.pivot {
+		w.printPivotColumns(r.Columns)
+		return
+	}
+
+	for _, c := range w.sortColumns(r.Columns) {
+		fmt.Fprintf(w.outStream, "  %s @ %s%s\n",
+			w.paintColumn(c.Qualifier, w.qualifierLabel(c.Qualifier), 40),
+			w.paint(colorTimestamp, w.timestamps.format(c.Version)), labelsSuffix(c))
 		w.printValue(c.Qualifier, c.Value)
+		w.printCellMetadata(c)
+	}
+}
+
+// recordRow keeps the row for show and returns the number of it, 0 if the rows aren't numbered
+func (w *Printer) recordRow(r *domain.Row) int {
+	if w.record == nil {
+		return 0
+	}
+	return w.record(r)
+}
+
+// rowSeparator returns the separator of the rows labeled with the number of the row, 0 isn't labeled
+func rowSeparator(n int) string {
+	if n == 0 {
+		return strings.Repeat("-", 40)
+	}
+	label := fmt.Sprintf("-- %d ", n)
+	return label + strings.Repeat("-", 40-len(label))
+}
+
+// printPivotColumns prints the latest cell of each time-bucketed qualifier
+// as a chronological series grouped by the qualifier without the timestamp
+func (w *Printer) printPivotColumns(cs []*domain.Column) {
+	var (
+		series  []string
+		buckets = map[string][]*domain.Column{}
+		seen    = map[string]bool{}
+		others  []*domain.Column
+	)
+	for _, c := range w.sortColumns(cs) {
+		if _, ok := w.qualifierTimestamp(c.Qualifier); !ok {
+			others = append(others, c)
+			continue
+		}
+		// cells are ordered by the newest version in the same qualifier
+		if seen[c.Qualifier] {
+			continue
+		}
+		seen[c.Qualifier] = true
+
+		name := strings.TrimRightFunc(c.Qualifier, func(r rune) bool { return '0' <= r && r <= '9' })
+		if _, ok := buckets[name]; !ok {
+			series = append(series, name)
+		}
+		buckets[name] = append(buckets[name], c)
+	}
+
+	for _, name := range series {
+		fmt.Fprintf(w.outStream, "  %s\n", w.paint(colorQualifier, name))
+		for _, c := range buckets[name] {
+			t, _ := w.qualifierTimestamp(c.Qualifier)
+			fmt.Fprintf(w.outStream, "    %s  %s\n", w.paint(colorTimestamp, w.timestamps.format(t)), w.paintValue(c.Qualifier, w.truncateValue(w.formatValue(c.Qualifier, c.Value))))
+		}
+	}
+	for _, c := range others {
+		fmt.Fprintf(w.outStream, "  %s @ %s%s\n",
+			w.paintColumn(c.Qualifier, c.Qualifier, 40),
+			w.paint(colorTimestamp, w.timestamps.format(c.Version)), labelsSuffix(c))
+		w.printValue(c.Qualifier, c.Value)
+		w.printCellMetadata(c)
+	}
+}
+
+// printCellMetadata prints the byte size, the labels and the raw value of the cell in verbose to debug the encodings,
+// the size and the raw value are of the stored value before the transforms
+func (w *Printer) printCellMetadata(c *domain.Column) {
+	if !w.verbose {
+		return
+	}
+	labels := "none"
+	if len(c.Labels) > 0 {
+		labels = strings.Join(c.Labels, ",")
+	}
+	fmt.Fprintf(w.outStream, "    size: %d bytes  labels: %s  raw: %s\n", len(c.Value), labels, hex.EncodeToString(c.Value))
+}
+
+// labelsSuffix returns the labels of the cell printed after the timestamp
+func labelsSuffix(c *domain.Column) string {
+	if len(c.Labels) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(c.Labels, ",") + "]"
+}
+
+// sortColumns returns columns sorted chronologically by the qualifier timestamp within each family
+func (w *Printer) sortColumns(cs []*domain.Column) []*domain.Column {
+	if w.qualifierTime == "" {
+		return cs
+	}
+	// keep the order of the families
+	families := map[string]int{}
+	for _, c := range cs {
+		if _, ok := families[c.Family]; !ok {
+			families[c.Family] = len(families)
+		}
+	}
+
+	sorted := make([]*domain.Column, len(cs))
+	copy(sorted, cs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		fi, fj := families[sorted[i].Family], families[sorted[j].Family]
+		if fi != fj {
+			return fi < fj
+		}
+		ti, iok := w.qualifierTimestamp(sorted[i].Qualifier)
+		tj, jok := w.qualifierTimestamp(sorted[j].Qualifier)
+		if iok != jok {
+			return iok
+		}
+		return ti.Before(tj)
+	})
+	return sorted
+}
+
+// qualifierLabel returns the qualifier with the human readable timestamp of the suffix
+func (w *Printer) qualifierLabel(q string) string {
+	t, ok := w.qualifierTimestamp(q)
+	if !ok {
+		return q
+	}
+	return fmt.Sprintf("%s (%s)", q, w.timestamps.format(t))
+}
+
+// qualifierTimestamp parses the trailing digits of the qualifier as the unix time
+func (w *Printer) qualifierTimestamp(q string) (time.Time, bool) {
+	unit, ok := qualifierTimeUnits[w.qualifierTime]
+	if !ok {
+		return time.Time{}, false
+	}
+	i := len(q)
+	for i > 0 && '0' <= q[i-1] && q[i-1] <= '9' {
+		i--
+	}
+	n, err := strconv.ParseInt(q[i:], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, 0).Add(time.Duration(n) * unit), true
+}
+
+// printRowDiff prints only the cells that changed from the prev row
+func (w *Printer) printRowDiff(prev, r *domain.Row) {
+	prevCells := make(map[string]*domain.Column, len(prev.Columns))
+	for _, c := range prev.Columns {
+		prevCells[c.Qualifier] = c
+	}
+
+	for _, c := range r.Columns {
+		p, ok := prevCells[c.Qualifier]
+		delete(prevCells, c.Qualifier)
+		switch {
+		case !ok:
+			fmt.Fprintf(w.outStream, "+ %-40s @ %s\n", c.Qualifier, w.timestamps.format(c.Version))
+			w.printValue(c.Qualifier, c.Value)
+		case !bytes.Equal(p.Value, c.Value) || !p.Version.Equal(c.Version):
+			fmt.Fprintf(w.outStream, "~ %-40s @ %s\n", c.Qualifier, w.timestamps.format(c.Version))
+			w.printValueDiff(c.Qualifier, p.Value, c.Value)
+		}
+	}
+	for _, c := range prev.Columns {
+		if _, ok := prevCells[c.Qualifier]; ok {
+			fmt.Fprintf(w.outStream, "- %-40s @ %s\n", c.Qualifier, w.timestamps.format(c.Version))
+		}
 	}
 }
 
 func (w *Printer) printValue(q string, v []byte) {
+	// indent each line of the hex dump
+	fmt.Fprintf(w.outStream, "    %s\n", strings.Replace(w.paintValue(q, w.truncateValue(w.formatValue(q, v))), "\n", "\n    ", -1))
+}
+
+// truncateValue cuts the printed value longer than maxValueBytes at the rune boundary and reports the rest,
+// the multi-megabyte blobs are printed up to the limit unless full-values is given
+func (w *Printer) truncateValue(s string) string {
+	if w.maxValueBytes <= 0 || len(s) <= w.maxValueBytes {
+		return s
+	}
+	n := w.maxValueBytes
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s ... (+%d bytes)", s[:n], len(s)-n)
+}
+
+// formatValue returns the value decoded by the option of the qualifier
+func (w *Printer) formatValue(q string, v []byte) string {
+	v, err := w.transformValue(q, v)
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	return w.decode(w.decodeTypeOf(q), v)
+}
+
+// decodeTypeOf returns the decode type of the qualifier, empty means guessing by the value
+func (w *Printer) decodeTypeOf(q string) string {
 	// extract columnName in a qualifier
 	// qualifier format: "columnFamily:columnName"
-	q = q[strings.Index(q, ":")+1:]
+	name := q[strings.Index(q, ":")+1:]
 
 	// retrieve decode each columns
 	// decodeColumns format "column1:type1,column2:type2,..."
 	for column, decode := range w.decodeColumnType {
-		if q == column {
-			w.doPrint(decode, v)
-			return
+		if name == column {
+			return decode
 		}
 	}
 
-	// invoke print with a general decodeType
-	w.doPrint(w.decodeType, v)
+	// a general decodeType
+	if w.decodeType != "" {
+		return w.decodeType
+	}
+	// the configured decoders of the columns
+	return w.columnDecoders[q]
 }
 
-func (w *Printer) doPrint(decode string, v []byte) {
+func (w *Printer) decode(decode string, v []byte) string {
+	// the numbers are of 8 bytes, the other sizes are written by the other types
+	if (decode == decodeTypeInt || decode == decodeTypeFloat) && len(v) != 8 {
+		return fmt.Sprintf("<%s: %d bytes>", decode, len(v))
+	}
 	switch decode {
 	case decodeTypeString:
-		fmt.Fprintf(w.outStream, "    %q\n", v)
+		return fmt.Sprintf("%q", v)
 	case decodeTypeInt:
-		fmt.Fprintf(w.outStream, "    %d\n", w.byte2Int(v))
+		return fmt.Sprintf("%d", w.byte2Int(v))
 	case decodeTypeFloat:
-		fmt.Fprintf(w.outStream, "    %f\n", w.byte2Float(v))
+		return fmt.Sprintf("%f", w.byte2Float(v))
+	case decodeTypeHex:
+		return hexDump(v)
+	case decodeTypeBase64:
+		return base64.StdEncoding.EncodeToString(v)
+	case decodeTypeJSON:
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, bytes.TrimSpace(v), "", "  "); err != nil {
+			return fmt.Sprintf("<json: %v>", err)
+		}
+		return buf.String()
 	default:
-		w.doGuessPrint(v)
+		if data, ok, err := w.structuredValue(decode, v); ok {
+			if err != nil {
+				return fmt.Sprintf("<%v>", err)
+			}
+			return string(data)
+		}
+		return w.guessDecode(v)
 	}
 }
 
-func (w *Printer) doGuessPrint(v []byte) {
-	if len(v) != 8 {
-		fmt.Fprintf(w.outStream, "    %q\n", v)
-		return
+// isStructuredDecode reports whether the decode renders the values as the JSON of the messages
+func isStructuredDecode(decode string) bool {
+	return decode == decodeTypeJSON || strings.HasPrefix(decode, decodeTypeProtoPrefix) || strings.HasPrefix(decode, decodeTypeAvroPrefix)
+}
+
+// structuredValue returns the JSON of the message decoded by json, proto:<message> or avro:<schema>,
+// ok is false for the other decodes
+func (w *Printer) structuredValue(decode string, v []byte) (data []byte, ok bool, err error) {
+	switch {
+	case decode == decodeTypeJSON:
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, v); err != nil {
+			return nil, true, fmt.Errorf("json: %v", err)
+		}
+		return buf.Bytes(), true, nil
+	case strings.HasPrefix(decode, decodeTypeProtoPrefix):
+		data, err = decodeProto(w.protoFiles, strings.TrimPrefix(decode, decodeTypeProtoPrefix), v)
+		if err != nil {
+			return nil, true, fmt.Errorf("proto: %v", err)
+		}
+		return data, true, nil
+	case strings.HasPrefix(decode, decodeTypeAvroPrefix):
+		data, err = w.avroValue(strings.TrimPrefix(decode, decodeTypeAvroPrefix), v)
+		if err != nil {
+			return nil, true, fmt.Errorf("avro: %v", err)
+		}
+		return data, true, nil
 	}
+	return nil, false, nil
+}
 
+func (w *Printer) guessDecode(v []byte) string {
+	switch w.guessNumber(v) {
+	case decodeTypeFloat:
+		return fmt.Sprintf("%f", w.byte2Float(v))
+	case decodeTypeInt:
+		return fmt.Sprintf("%d", w.byte2Int(v))
+	}
+	if !isPrintable(v) {
+		return hexDump(v)
+	}
+	return fmt.Sprintf("%q", v)
+}
+
+// guessNumber returns the number type guessed by the value of 8 bytes allowed by autoDecode, empty if not a number
+func (w *Printer) guessNumber(v []byte) string {
+	if len(v) != 8 {
+		return ""
+	}
 	// guess: float decides by high 2-bit flag
 	// https://en.wikipedia.org/wiki/Double-precision_floating-point_format
-	switch v[0] << 1 >> 7 & 1 {
-	case 1:
-		fmt.Fprintf(w.outStream, "    %f\n", w.byte2Float(v))
-	case 0:
-		fmt.Fprintf(w.outStream, "    %d\n", w.byte2Int(v))
+	guess := decodeTypeInt
+	if v[0]<<1>>7&1 == 1 {
+		guess = decodeTypeFloat
 	}
+	switch w.autoDecode {
+	case "", "all":
+		return guess
+	case "int":
+		// the floats are read as the ints
+		return decodeTypeInt
+	case "float":
+		if guess == decodeTypeFloat {
+			return guess
+		}
+	}
+	return ""
+}
+
+// isPrintable reports whether the value is a text without the control characters other than the spaces
+func isPrintable(v []byte) bool {
+	if !utf8.Valid(v) {
+		return false
+	}
+	for _, r := range string(v) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// hexDump returns the canonical hex dump of the value, the lines have the offsets and the printable characters
+func hexDump(v []byte) string {
+	return strings.TrimSuffix(hex.Dump(v), "\n")
 }
 
 func (*Printer) byte2Int(b []byte) int64 {
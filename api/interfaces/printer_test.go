@@ -2,8 +2,10 @@ package interfaces
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/takashabe/btcli/api/domain"
@@ -27,6 +29,20 @@ func TestPrintRows(t *testing.T) {
 			},
 			"----------------------------------------\na\n  d:row                                    @ 0001/01/01-00:00:00.000000\n    \"a1\"\n",
 		},
+		{
+			&domain.Row{
+				Key: "a",
+				Columns: []*domain.Column{
+					&domain.Column{
+						Family:    "d",
+						Qualifier: "d:row",
+						Value:     []byte("a1"),
+						Labels:    []string{"l1", "l2"},
+					},
+				},
+			},
+			"----------------------------------------\na\n  d:row                                    @ 0001/01/01-00:00:00.000000 [l1,l2]\n    \"a1\"\n",
+		},
 	}
 	for _, c := range cases {
 		var buf bytes.Buffer
@@ -40,6 +56,125 @@ func TestPrintRows(t *testing.T) {
 	}
 }
 
+func TestPrintRowWithQualifierTime(t *testing.T) {
+	var buf bytes.Buffer
+	printer := &Printer{
+		outStream:     &buf,
+		errStream:     &buf,
+		qualifierTime: "s",
+	}
+	printer.printRow(&domain.Row{
+		Key: "a",
+		Columns: []*domain.Column{
+			&domain.Column{
+				Family:    "d",
+				Qualifier: "d:cpu#200",
+				Value:     []byte("2"),
+			},
+			&domain.Column{
+				Family:    "d",
+				Qualifier: "d:cpu#100",
+				Value:     []byte("1"),
+			},
+		},
+	})
+
+	first := time.Unix(100, 0).Format(timestampLayout)
+	second := time.Unix(200, 0).Format(timestampLayout)
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, fmt.Sprintf("d:cpu#100 (%s)", first), strings.TrimSpace(strings.Split(lines[2], "@")[0]))
+	assert.Equal(t, fmt.Sprintf("d:cpu#200 (%s)", second), strings.TrimSpace(strings.Split(lines[4], "@")[0]))
+}
+
+func TestPrintRowWithPivot(t *testing.T) {
+	var buf bytes.Buffer
+	printer := &Printer{
+		outStream:     &buf,
+		errStream:     &buf,
+		decodeType:    "string",
+		qualifierTime: "s",
+		pivot:         true,
+	}
+	printer.printRow(&domain.Row{
+		Key: "a",
+		Columns: []*domain.Column{
+			&domain.Column{Family: "d", Qualifier: "d:cpu#200", Value: []byte("2")},
+			&domain.Column{Family: "d", Qualifier: "d:cpu#100", Value: []byte("1")},
+			&domain.Column{Family: "d", Qualifier: "d:mem#100", Value: []byte("3")},
+		},
+	})
+
+	expect := fmt.Sprintf("----------------------------------------\na\n  d:cpu#\n    %s  \"1\"\n    %s  \"2\"\n  d:mem#\n    %s  \"3\"\n",
+		time.Unix(100, 0).Format(timestampLayout),
+		time.Unix(200, 0).Format(timestampLayout),
+		time.Unix(100, 0).Format(timestampLayout),
+	)
+	assert.Equal(t, expect, buf.String())
+}
+
+func TestPrintRowVerbose(t *testing.T) {
+	tm := time.Unix(0, 0).UTC()
+	cases := []struct {
+		format string
+		expect string
+	}{
+		{
+			"",
+			"----------------------------------------\na\n" +
+				"  d:count                                  @ 1970/01/01-00:00:00.000000 [x]\n    3\n    size: 8 bytes  labels: x  raw: 0000000000000003\n" +
+				"  d:name                                   @ 1970/01/01-00:00:00.000000\n    \"\\xe3\\x81\"\n    size: 2 bytes  labels: none  raw: e381\n",
+		},
+		{
+			"json",
+			`{"key":"a","cells":[` +
+				`{"family":"d","qualifier":"count","value":3,"timestamp":"1970-01-01T00:00:00Z","labels":["x"],"size":8,"raw":"AAAAAAAAAAM="},` +
+				`{"family":"d","qualifier":"name","value":"44E=","timestamp":"1970-01-01T00:00:00Z","value_encoding":"base64","size":2,"raw":"44E="}]}` + "\n",
+		},
+	}
+	for i, c := range cases {
+		var buf bytes.Buffer
+		printer := &Printer{
+			outStream:        &buf,
+			errStream:        &buf,
+			decodeColumnType: map[string]string{"name": "string"},
+			formatter:        rowFormatters[c.format],
+			verbose:          true,
+		}
+		printer.printRow(&domain.Row{
+			Key: "a",
+			Columns: []*domain.Column{
+				{Family: "d", Qualifier: "d:count", Value: []byte{0, 0, 0, 0, 0, 0, 0, 3}, Version: tm, Labels: []string{"x"}},
+				{Family: "d", Qualifier: "d:name", Value: []byte{0xe3, 0x81}, Version: tm},
+			},
+		})
+		assert.Equal(t, c.expect, buf.String(), "#%d", i)
+	}
+}
+
+func TestTypedValueSize(t *testing.T) {
+	w := &Printer{columnDecoders: map[string]string{"d:i": "int", "d:f": "float"}}
+	assert.Equal(t, "<int: 3 bytes>", w.typedValue("d:i", []byte{0x00, 0x00, 0x01}))
+	assert.Equal(t, "<float: 0 bytes>", w.typedValue("d:f", nil))
+	assert.Equal(t, int64(1), w.typedValue("d:i", []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}))
+}
+
+func TestTruncateValue(t *testing.T) {
+	cases := []struct {
+		max    int
+		input  string
+		expect string
+	}{
+		{8, `"abcdef"`, `"abcdef"`},
+		{4, `"abcdef"`, `"abc ... (+4 bytes)`},
+		{3, "\"あい\"", "\" ... (+7 bytes)"},
+		{0, `"abcdef"`, `"abcdef"`},
+	}
+	for i, c := range cases {
+		printer := &Printer{maxValueBytes: c.max}
+		assert.Equal(t, c.expect, printer.truncateValue(c.input), "#%d", i)
+	}
+}
+
 func TestPrintValue(t *testing.T) {
 	cases := []struct {
 		printer   *Printer
@@ -100,6 +235,122 @@ func TestPrintValue(t *testing.T) {
 			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}, // 1
 			"1",
 		},
+		{
+			// autodecode off keeps the 8 bytes strings
+			&Printer{autoDecode: "off"},
+			"d:row",
+			[]byte("abcdefgh"),
+			`"abcdefgh"`,
+		},
+		{
+			// autodecode int reads the floats as the ints
+			&Printer{autoDecode: "int"},
+			"d:row",
+			[]byte{0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			"4611686018427387904",
+		},
+		{
+			// autodecode float guesses only the floats
+			&Printer{autoDecode: "float"},
+			"d:row",
+			[]byte{0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // 2.0
+			"2.000000",
+		},
+		{
+			&Printer{autoDecode: "float"},
+			"d:row",
+			[]byte("12345678"),
+			`"12345678"`,
+		},
+		{
+			// an explicit decode wins over autodecode off
+			&Printer{autoDecode: "off", decodeType: "int"},
+			"d:row",
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+			"1",
+		},
+		{
+			// the configured decoder of the column instead of the guess
+			&Printer{columnDecoders: map[string]string{"d:row": "int"}},
+			"d:row",
+			[]byte{0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			"4611686018427387904",
+		},
+		{
+			// the numbers of the other sizes than 8 bytes aren't decoded
+			&Printer{columnDecoders: map[string]string{"d:row": "int"}},
+			"d:row",
+			[]byte{0x00, 0x00, 0x01},
+			"<int: 3 bytes>",
+		},
+		{
+			&Printer{decodeType: "float"},
+			"d:row",
+			[]byte("abc"),
+			"<float: 3 bytes>",
+		},
+		{
+			// the decode options win over the configured decoders
+			&Printer{
+				decodeType:       "string",
+				decodeColumnType: map[string]string{"ro": "float"},
+				columnDecoders:   map[string]string{"d:row": "int", "d:ro": "int"},
+			},
+			"d:ro",
+			[]byte{0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // 2.0
+			"2.000000",
+		},
+		{
+			&Printer{
+				decodeType:     "string",
+				columnDecoders: map[string]string{"d:row": "int"},
+			},
+			"d:row",
+			[]byte("a"),
+			`"a"`,
+		},
+		{
+			// decode hex
+			&Printer{decodeType: "hex"},
+			"d:row",
+			[]byte("0123456789abcdefXYZ"),
+			"00000000  30 31 32 33 34 35 36 37  38 39 61 62 63 64 65 66  |0123456789abcdef|\n" +
+				"    00000010  58 59 5a                                          |XYZ|",
+		},
+		{
+			// decode base64
+			&Printer{decodeType: "base64"},
+			"d:row",
+			[]byte{0xff, 0x00, 0x01, 'a'},
+			"/wABYQ==",
+		},
+		{
+			// decode json re-indents the value
+			&Printer{decodeType: "json"},
+			"d:row",
+			[]byte(`{"user":{"id":1,"tags":["a","b"]},"empty":{}}` + "\n"),
+			"{\n      \"user\": {\n        \"id\": 1,\n        \"tags\": [\n          \"a\",\n          \"b\"\n        ]\n      },\n      \"empty\": {}\n    }",
+		},
+		{
+			&Printer{decodeType: "json"},
+			"d:row",
+			[]byte(`{"user":`),
+			"<json: unexpected end of JSON input>",
+		},
+		{
+			// decode guess falls back to hex for the binary values
+			&Printer{},
+			"d:row",
+			[]byte{0xff, 0x00, 0x01},
+			"00000000  ff 00 01                                          |...|",
+		},
+		{
+			// decode guess keeps the text with the spaces
+			&Printer{},
+			"d:row",
+			[]byte("a\tb\n"),
+			`"a\tb\n"`,
+		},
 	}
 	for _, c := range cases {
 		var buf bytes.Buffer
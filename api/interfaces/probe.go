@@ -0,0 +1,186 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultProbeInterval   = time.Second
+	defaultProbeSLO        = 100 * time.Millisecond
+	defaultProbeReport     = time.Minute
+	defaultProbeViolations = 3
+
+	// probeObjective is a ratio of the probes required to succeed within the SLO latency
+	probeObjective = 0.99
+)
+
+func doProbe(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "probe <table> <row> [interval=<duration>] [slo-p99=<duration>] [report=<duration>] [violations=<n>] [count=<n>] [app-profile=<id>]")
+		return
+	}
+	table := args[1]
+	key := args[2]
+
+	parsed := make(map[string]string)
+	for _, arg := range args[3:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "interval", "slo-p99", "report", "violations", "count", "app-profile":
+			parsed[k] = v
+		}
+	}
+
+	durations := map[string]time.Duration{
+		"interval": defaultProbeInterval,
+		"slo-p99":  defaultProbeSLO,
+		"report":   defaultProbeReport,
+	}
+	for k := range durations {
+		if v := parsed[k]; v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				e.failf("Invalid %s: %v\n", k, v)
+				return
+			}
+			durations[k] = d
+		}
+	}
+	// violations is a number of the consecutive violated reports to stop probing, 0 means never
+	ints := map[string]int{
+		"violations": defaultProbeViolations,
+		"count":      0,
+	}
+	for k := range ints {
+		if v := parsed[k]; v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				e.failf("Invalid %s: %v\n", k, v)
+				return
+			}
+			ints[k] = n
+		}
+	}
+	interval, slo, report := durations["interval"], durations["slo-p99"], durations["report"]
+	violations, count := ints["violations"], ints["count"]
+
+	// stop probing by Ctrl+C instead of exiting the prompt
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+
+	ctx = e.requestContext(parsed)
+	ro, _ := readOption(map[string]string{"version": "1"})
+	w := &probeWindow{start: time.Now()}
+	violated := 0
+	for i := 0; count == 0 || i < count; i++ {
+		if i > 0 {
+			select {
+			case <-sig:
+				if len(w.latencies) > 0 || w.errors > 0 {
+					e.printProbeReport(w, slo)
+				}
+				return
+			case <-time.After(interval):
+			}
+		}
+
+		start := time.Now()
+		_, err := e.rowsInteractor.GetRow(ctx, table, key, ro...)
+		if err != nil {
+			e.printError(err)
+			w.errors++
+		} else {
+			w.latencies = append(w.latencies, time.Since(start))
+		}
+
+		if time.Since(w.start) < report {
+			continue
+		}
+		if e.printProbeReport(w, slo) {
+			violated = 0
+		} else {
+			violated++
+		}
+		if violations > 0 && violated >= violations {
+			fmt.Fprintf(e.errStream, "SLO violated in %d consecutive reports\n", violated)
+			e.exitProcess(ExitCodeSLOViolation)
+			return
+		}
+		w = &probeWindow{start: time.Now()}
+	}
+	if len(w.latencies) > 0 || w.errors > 0 {
+		e.printProbeReport(w, slo)
+	}
+}
+
+// probeWindow is the probes of a report
+type probeWindow struct {
+	start     time.Time
+	latencies []time.Duration
+	errors    int
+}
+
+// printProbeReport prints the latencies of the window and reports whether the window meets the SLO,
+// the failed probes are counted as exceeding the SLO
+func (e *Executor) printProbeReport(w *probeWindow, slo time.Duration) bool {
+	sort.Slice(w.latencies, func(i, j int) bool { return w.latencies[i] < w.latencies[j] })
+	total := len(w.latencies) + w.errors
+	within := sort.Search(len(w.latencies), func(i int) bool { return w.latencies[i] > slo })
+	ok := float64(within) >= probeObjective*float64(total)
+
+	status := "ok"
+	if !ok {
+		status = "VIOLATED"
+	}
+	fmt.Fprintf(e.outStream, "%s  probes: %s  errors: %s  p50: %s  p99: %s  max: %s  within %v: %.1f%%  %s\n",
+		time.Now().Format(timestampLayout),
+		e.formatNumber(int64(total)),
+		e.formatNumber(int64(w.errors)),
+		percentile(w.latencies, 0.50),
+		percentile(w.latencies, 0.99),
+		percentile(w.latencies, 1),
+		slo,
+		100*float64(within)/float64(total),
+		status,
+	)
+	return ok
+}
+
+// percentile returns the latency of the sorted latencies at the rank, "-" if empty
+func percentile(latencies []time.Duration, rank float64) string {
+	if len(latencies) == 0 {
+		return "-"
+	}
+	// nearest-rank method
+	i := int(math.Ceil(rank*float64(len(latencies)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return latencies[i].Round(time.Microsecond).String()
+}
+
+// exitProcess exits the process with the code
+func (e *Executor) exitProcess(code int) {
+	if e.exit != nil {
+		e.exit(code)
+		return
+	}
+	os.Exit(code)
+}
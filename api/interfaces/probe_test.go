@@ -0,0 +1,102 @@
+package interfaces
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoProbeExecutor(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)
+	row := &domain.Bigtable{
+		Table: "table",
+		Rows: []*domain.Row{
+			&domain.Row{
+				Key: "canary",
+				Columns: []*domain.Column{
+					&domain.Column{
+						Family:    "d",
+						Qualifier: "d:row",
+						Value:     []byte("a"),
+						Version:   tm,
+					},
+				},
+			},
+		},
+	}
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+
+	cases := []struct {
+		input      string
+		prepare    func(*repository.MockBigtable)
+		expectOut  *regexp.Regexp
+		expectErr  string
+		expectExit int
+	}{
+		{
+			"probe table canary interval=0s count=3 report=1h slo-p99=1s",
+			func(m *repository.MockBigtable) {
+				m.EXPECT().Get(gomock.Any(), "table", "canary", latest).Return(row, nil).Times(3)
+			},
+			regexp.MustCompile(`^\S+  probes: 3  errors: 0  p50: \S+  p99: \S+  max: \S+  within 1s: 100\.0%  ok\n$`),
+			"",
+			0,
+		},
+		{
+			"probe table canary interval=0s report=0s violations=2",
+			func(m *repository.MockBigtable) {
+				m.EXPECT().Get(gomock.Any(), "table", "canary", latest).Return(nil, errors.New("unavailable")).Times(2)
+			},
+			regexp.MustCompile(`^(\S+  probes: 1  errors: 1  p50: -  p99: -  max: -  within 100ms: 0\.0%  VIOLATED\n){2}$`),
+			"unavailable\nunavailable\nSLO violated in 2 consecutive reports\n",
+			ExitCodeSLOViolation,
+		},
+		{
+			"probe table canary slo-p99=fast",
+			func(m *repository.MockBigtable) {},
+			regexp.MustCompile(`^$`),
+			"Invalid slo-p99: fast\n",
+			0,
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		exitCode := 0
+		executor := Executor{
+			outStream:      &out,
+			errStream:      &errOut,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+			exit:           func(code int) { exitCode = code },
+		}
+		executor.Do(c.input)
+
+		assert.Regexp(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		assert.Equal(t, c.expectExit, exitCode, "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := []time.Duration{}
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, "50ms", percentile(latencies, 0.50))
+	assert.Equal(t, "99ms", percentile(latencies, 0.99))
+	assert.Equal(t, "100ms", percentile(latencies, 1))
+	assert.Equal(t, "-", percentile(nil, 0.99))
+}
@@ -0,0 +1,67 @@
+package interfaces
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// decodeTypeProtoPrefix decodes the value as the protobuf message of the name following the prefix
+const decodeTypeProtoPrefix = "proto:"
+
+// loadProtoFiles reads the FileDescriptorSet of the file, e.g. "protoc --include_imports --descriptor_set_out",
+// an empty file returns nil
+func loadProtoFiles(file string) (*protoregistry.Files, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("%s isn't a FileDescriptorSet: %v", file, err)
+	}
+	return protodesc.NewFiles(set)
+}
+
+// decodeProto returns the value decoded as the message in the compact JSON
+func decodeProto(files *protoregistry.Files, message string, v []byte) ([]byte, error) {
+	if files == nil {
+		return nil, errors.New("no proto descriptors, set -proto-descriptors")
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(strings.TrimPrefix(message, ".")))
+	if err != nil {
+		return nil, fmt.Errorf("unknown message %s", message)
+	}
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s isn't a message", message)
+	}
+	msg := dynamicpb.NewMessage(md)
+	if err := proto.Unmarshal(v, msg); err != nil {
+		return nil, err
+	}
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	// protojson randomizes the spaces
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,169 @@
+package interfaces
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/config"
+	"github.com/takashabe/btcli/api/domain"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// writeEventDescriptors writes the descriptors of "message example.Event { string user = 1; int64 count = 2; }"
+func writeEventDescriptors(t *testing.T) string {
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			{
+				Name:    proto.String("event.proto"),
+				Package: proto.String("example"),
+				Syntax:  proto.String("proto3"),
+				MessageType: []*descriptorpb.DescriptorProto{
+					{
+						Name: proto.String("Event"),
+						Field: []*descriptorpb.FieldDescriptorProto{
+							{
+								Name:     proto.String("user"),
+								JsonName: proto.String("user"),
+								Number:   proto.Int32(1),
+								Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+								Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+							},
+							{
+								Name:     proto.String("count"),
+								JsonName: proto.String("count"),
+								Number:   proto.Int32(2),
+								Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+								Type:     descriptorpb.FieldDescriptorProto_TYPE_INT64.Enum(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	file := filepath.Join(dir, "event.pb")
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	return file
+}
+
+// eventValue returns the serialized example.Event
+func eventValue(user string, count int64) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, user)
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(count))
+	return b
+}
+
+func loadEventFiles(t *testing.T) *protoregistry.Files {
+	file := writeEventDescriptors(t)
+	defer os.RemoveAll(filepath.Dir(file))
+	files, err := loadProtoFiles(file)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	return files
+}
+
+func TestDecodeProto(t *testing.T) {
+	files := loadEventFiles(t)
+	cases := []struct {
+		files  *protoregistry.Files
+		decode string
+		input  []byte
+		expect string
+	}{
+		{
+			files,
+			"proto:example.Event",
+			eventValue("madoka", 2),
+			`{"user":"madoka","count":"2"}`,
+		},
+		{
+			files,
+			"proto:example.Event",
+			nil,
+			`{}`,
+		},
+		{
+			files,
+			"proto:example.Unknown",
+			eventValue("madoka", 2),
+			"<proto: unknown message example.Unknown>",
+		},
+		{
+			nil,
+			"proto:example.Event",
+			eventValue("madoka", 2),
+			"<proto: no proto descriptors, set -proto-descriptors>",
+		},
+	}
+	for i, c := range cases {
+		w := &Printer{decodeType: c.decode, protoFiles: c.files}
+		assert.Equal(t, c.expect, w.formatValue("d:event", c.input), "#%d", i)
+	}
+
+	// the spaces of the protobuf errors are randomized
+	w := &Printer{decodeType: "proto:example.Event", protoFiles: files}
+	assert.Contains(t, w.formatValue("d:event", []byte{0x0a, 0x10}), "cannot parse invalid wire-format data")
+}
+
+func TestProtoJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	w := &Printer{
+		outStream:  &buf,
+		formatter:  rowFormatters["json"],
+		decodeType: "proto:example.Event",
+		protoFiles: loadEventFiles(t),
+	}
+	w.printRow(&domain.Row{
+		Key: "1",
+		Columns: []*domain.Column{
+			{Family: "d", Qualifier: "d:event", Value: eventValue("madoka", 2), Version: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	})
+	assert.Contains(t, buf.String(), `"value":{"user":"madoka","count":"2"}`)
+}
+
+func TestProtoTransform(t *testing.T) {
+	transforms, err := compileTransforms([]*config.Transform{
+		{Name: "user", Columns: "^d:event$", Pipeline: "proto:example.Event | jsonpath:$.user"},
+	}, loadEventFiles(t))
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	w := &Printer{decodeType: "string", transforms: transforms}
+	assert.Equal(t, `"madoka"`, w.formatValue("d:event", eventValue("madoka", 2)))
+}
+
+func TestLoadProtoFilesError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "broken.pb")
+	ioutil.WriteFile(file, []byte{0x0a, 0x10}, 0600)
+
+	_, err = loadProtoFiles(file)
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/takashabe/btcli/api/domain"
+)
+
+func doQuorumRead(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "quorum-read <table> <row> [profiles=<id>,...] [versions=all|<n>] [family=<regex>] [columns=<family:qualifier>,...]")
+		return
+	}
+	table := args[1]
+	key := args[2]
+
+	parsed := make(map[string]string)
+	for _, arg := range args[3:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "decode", "decode_columns":
+			parsed[k] = v
+		case "profiles":
+			parsed[k] = v
+		case "version", "versions", "family", "columns":
+			parsed[k] = v
+		}
+	}
+	if err := validatePrinterOption(parsed); err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+	defaultVersions(parsed)
+	ro, err := readOption(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+
+	var profiles []string
+	if v := parsed["profiles"]; v != "" {
+		profiles = strings.Split(v, ",")
+	} else {
+		single, err := e.rowsInteractor.SingleClusterProfiles(ctx)
+		if err != nil {
+			e.printError(err)
+			return
+		}
+		// read each cluster once
+		seen := map[string]bool{}
+		for _, p := range single {
+			if !seen[p.Cluster] {
+				seen[p.Cluster] = true
+				profiles = append(profiles, p.ID)
+			}
+		}
+	}
+	if len(profiles) < 2 {
+		e.failln(`quorum-read requires the app profiles of two or more clusters, create single-cluster app profiles or give them by "profiles"`)
+		return
+	}
+
+	rows, err := e.rowsInteractor.GetRowReplicas(ctx, table, key, profiles, ro...)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	p := e.newPrinter(parsed)
+	p.printReplicaDiff(profiles, rows)
+}
+
+// printReplicaDiff prints the columns differing among the replicas and the summary
+func (w *Printer) printReplicaDiff(profiles []string, rows []*domain.Row) {
+	replicas := make([]map[string][]*domain.Column, len(rows))
+	qualifiers := []string{}
+	seen := map[string]bool{}
+	for i, r := range rows {
+		replicas[i] = groupByQualifier(r)
+		for q := range replicas[i] {
+			if !seen[q] {
+				seen[q] = true
+				qualifiers = append(qualifiers, q)
+			}
+		}
+	}
+	sort.Strings(qualifiers)
+
+	width := 0
+	for _, p := range profiles {
+		if len(p) > width {
+			width = len(p)
+		}
+	}
+
+	diffs := 0
+	for _, q := range qualifiers {
+		if replicasAgree(replicas, q) {
+			continue
+		}
+		diffs++
+		fmt.Fprintf(w.outStream, "! %s\n", q)
+		for i, p := range profiles {
+			cells := replicas[i][q]
+			if len(cells) == 0 {
+				fmt.Fprintf(w.outStream, "    %-*s  <missing>\n", width, p)
+				continue
+			}
+			for _, c := range cells {
+				fmt.Fprintf(w.outStream, "    %-*s  @ %s  %s\n", width, p, w.timestamps.format(c.Version), w.formatValue(q, c.Value))
+			}
+		}
+	}
+
+	if diffs == 0 {
+		fmt.Fprintf(w.outStream, "All %d replicas agree on %d columns\n", len(profiles), len(qualifiers))
+		return
+	}
+	fmt.Fprintf(w.outStream, "%d of %d columns differ among %d replicas\n", diffs, len(qualifiers), len(profiles))
+}
+
+// replicasAgree reports whether all replicas have the same cells of the qualifier
+func replicasAgree(replicas []map[string][]*domain.Column, q string) bool {
+	first := replicas[0][q]
+	for _, r := range replicas[1:] {
+		cells := r[q]
+		if len(cells) != len(first) {
+			return false
+		}
+		for i, c := range cells {
+			if !c.Version.Equal(first[i].Version) || !bytes.Equal(c.Value, first[i].Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
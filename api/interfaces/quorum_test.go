@@ -0,0 +1,91 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoQuorumRead(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)
+	latest := bigtable.RowFilter(bigtable.LatestNFilter(1))
+	row := func(cs ...*domain.Column) *domain.Bigtable {
+		return &domain.Bigtable{Rows: []*domain.Row{{Key: "a", Columns: cs}}}
+	}
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"quorum-read table a decode=string",
+			"! d:name\n" +
+				"    east  @ 2018/01/01-00:00:01.000000  \"new\"\n" +
+				"    west  @ 2018/01/01-00:00:00.000000  \"old\"\n" +
+				"! d:new\n" +
+				"    east  @ 2018/01/01-00:00:01.000000  \"x\"\n" +
+				"    west  <missing>\n" +
+				"2 of 3 columns differ among 2 replicas\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().AppProfiles(gomock.Any()).Return([]*domain.AppProfile{
+					{ID: "default"},
+					{ID: "east", Cluster: "c1"},
+					{ID: "east-batch", Cluster: "c1"},
+					{ID: "west", Cluster: "c2"},
+				}, nil)
+				mock.EXPECT().Get(appProfileMatcher("east"), "table", "a", latest).Return(row(
+					&domain.Column{Family: "d", Qualifier: "d:id", Value: []byte("1"), Version: tm},
+					&domain.Column{Family: "d", Qualifier: "d:name", Value: []byte("new"), Version: tm.Add(time.Second)},
+					&domain.Column{Family: "d", Qualifier: "d:new", Value: []byte("x"), Version: tm.Add(time.Second)},
+				), nil)
+				mock.EXPECT().Get(appProfileMatcher("west"), "table", "a", latest).Return(row(
+					&domain.Column{Family: "d", Qualifier: "d:id", Value: []byte("1"), Version: tm},
+					&domain.Column{Family: "d", Qualifier: "d:name", Value: []byte("old"), Version: tm},
+				), nil)
+			},
+		},
+		{
+			"quorum-read table a profiles=p1,p2",
+			"All 2 replicas agree on 1 columns\n",
+			func(mock *repository.MockBigtable) {
+				r := row(&domain.Column{Family: "d", Qualifier: "d:id", Value: []byte("1"), Version: tm})
+				mock.EXPECT().Get(appProfileMatcher("p1"), "table", "a", latest).Return(r, nil)
+				mock.EXPECT().Get(appProfileMatcher("p2"), "table", "a", latest).Return(r, nil)
+			},
+		},
+		{
+			"quorum-read table a",
+			"quorum-read requires the app profiles of two or more clusters, create single-cluster app profiles or give them by \"profiles\"\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().AppProfiles(gomock.Any()).Return([]*domain.AppProfile{
+					{ID: "default"},
+					{ID: "east", Cluster: "c1"},
+				}, nil)
+			},
+		},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		defer ctrl.Finish()
+
+		c.prepare(mockBtRepo)
+
+		var buf bytes.Buffer
+		executor := Executor{
+			outStream:      &buf,
+			errStream:      &buf,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+		}
+
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, buf.String(), c.input)
+	}
+}
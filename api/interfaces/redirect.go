@@ -0,0 +1,80 @@
+package interfaces
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// redirection is the file of the output given by "> <file>" or ">> <file>"
+type redirection struct {
+	file   string
+	append bool
+}
+
+// parseRedirection returns the args without the redirection, nil if the output isn't redirected.
+// Only the unquoted ">" is the redirection, the quoted or escaped one is a part of the arg, e.g. '>a' or \>a,
+// and the variables of the file are expanded
+func parseRedirection(tokens []token, variables map[string]string) ([]token, *redirection, error) {
+	ret := make([]token, 0, len(tokens))
+	var r *redirection
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if !strings.HasPrefix(t.text, ">") || t.quotedAt(0) {
+			ret = append(ret, t)
+			continue
+		}
+		if r != nil {
+			return nil, nil, fmt.Errorf("the output is redirected twice")
+		}
+		r = &redirection{}
+		n := 1
+		if strings.HasPrefix(t.text, ">>") && !t.quotedAt(1) {
+			r.append = true
+			n = 2
+		}
+		file := token{text: t.text[n:], literal: t.literal[n:]}
+		// the file is given with or without the space
+		if file.text == "" && i+1 < len(tokens) {
+			i++
+			file = tokens[i]
+		}
+		if file.text == "" {
+			return nil, nil, fmt.Errorf("missing the file")
+		}
+		expanded, err := expandVariables([]token{file}, variables)
+		if err != nil {
+			return nil, nil, err
+		}
+		r.file = expanded[0]
+	}
+	if len(ret) == 0 {
+		return nil, nil, fmt.Errorf("missing the command")
+	}
+	return ret, r, nil
+}
+
+// open opens the file to write the output, the file is truncated unless appended and created readable only by the user
+func (r *redirection) open() (*os.File, error) {
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if r.append {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	return os.OpenFile(r.file, flag, 0600)
+}
+
+// redirect writes the output to the file without the colors and the pager, and returns the func restoring the output
+func (e *Executor) redirect(r *redirection) (func(), error) {
+	f, err := r.open()
+	if err != nil {
+		return nil, err
+	}
+	out := e.outStream
+	e.outStream, e.redirected = f, true
+	return func() {
+		e.outStream, e.redirected = out, false
+		if err := f.Close(); err != nil {
+			e.failf("Failed to write %s: %v\n", r.file, err)
+		}
+	}, nil
+}
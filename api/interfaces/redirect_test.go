@@ -0,0 +1,100 @@
+package interfaces
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoRedirect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "btcli")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "out.txt")
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream: &out,
+		errStream: &errOut,
+		color:     true,
+		dryRun:    true,
+	}
+
+	cases := []struct {
+		input      string
+		expectFile string
+		expectErr  string
+	}{
+		{"set > " + file, "dryrun: on\ncolor: on\npager: off\nutc: off\nverbose: off\ntimestamp-format: default\n", ""},
+		{"set dryrun off >" + file, "", "dryrun: off\n"},
+		{"set >> " + file, "dryrun: off\ncolor: on\npager: off\nutc: off\nverbose: off\ntimestamp-format: default\n", ""},
+		{"set >>" + file, "dryrun: off\ncolor: on\npager: off\nutc: off\nverbose: off\ntimestamp-format: default\n" +
+			"dryrun: off\ncolor: on\npager: off\nutc: off\nverbose: off\ntimestamp-format: default\n", ""},
+		{"set >", "", "Invalid redirection: missing the file\n"},
+		{"set > a > b", "", "Invalid redirection: the output is redirected twice\n"},
+		{"> " + file, "", "Invalid redirection: missing the command\n"},
+	}
+	for i, c := range cases {
+		out.Reset()
+		errOut.Reset()
+		executor.Do(c.input)
+		assert.Equal(t, "", out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		if c.expectErr != "" {
+			continue
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("#%d: want no error, got %v", i, err)
+		}
+		assert.Equal(t, c.expectFile, string(data), "#%d", i)
+		// the output may have the data of the rows
+		if runtime.GOOS != "windows" {
+			fi, err := os.Stat(file)
+			if err != nil {
+				t.Fatalf("#%d: want no error, got %v", i, err)
+			}
+			assert.Equal(t, os.FileMode(0600), fi.Mode().Perm(), "#%d", i)
+		}
+	}
+	// the output is restored
+	assert.Equal(t, &out, executor.outStream)
+	assert.True(t, executor.newPrinter(map[string]string{}).color)
+}
+
+func TestParseRedirection(t *testing.T) {
+	cases := []struct {
+		input        string
+		expectArgs   []string
+		expectFile   string
+		expectAppend bool
+	}{
+		{"lookup t a > out", []string{"lookup", "t", "a"}, "out", false},
+		{"lookup t a >>out", []string{"lookup", "t", "a"}, "out", true},
+		// the quoted or escaped ">" is a part of the key
+		{`lookup t '>abc' \>def ">>g"`, []string{"lookup", "t", ">abc", ">def", ">>g"}, "", false},
+		{`lookup t a >\>out`, []string{"lookup", "t", "a"}, ">out", false},
+		{`lookup t a > $out`, []string{"lookup", "t", "a"}, "a.txt", false},
+	}
+	for i, c := range cases {
+		tokens, err := tokenizeCommand(c.input)
+		if err != nil {
+			t.Fatalf("#%d: want no error, got %v", i, err)
+		}
+		tokens, r, err := parseRedirection(tokens, map[string]string{"out": "a.txt"})
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, c.expectArgs, tokenTexts(tokens), "#%d", i)
+		if c.expectFile == "" {
+			assert.Nil(t, r, "#%d", i)
+			continue
+		}
+		assert.Equal(t, &redirection{file: c.expectFile, append: c.expectAppend}, r, "#%d", i)
+	}
+}
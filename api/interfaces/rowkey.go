@@ -0,0 +1,48 @@
+package interfaces
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// escapeKey returns the key escaped in Go if it has the bytes other than the printable ASCII or the backslashes,
+// the non-printable bytes would corrupt the terminal
+func escapeKey(k string) string {
+	for i := 0; i < len(k); i++ {
+		if k[i] <= ' ' || k[i] > '~' || k[i] == '\\' {
+			q := strconv.Quote(k)
+			return q[1 : len(q)-1]
+		}
+	}
+	return k
+}
+
+// printedKey returns the key escaped by escapeKey and quoted by quoteArg, the printed keys are given back to the commands
+// as they are, e.g. 'user 1' or 'user\x00', the empty key of the end of the table is kept
+func printedKey(k string) string {
+	if k == "" {
+		return k
+	}
+	return quoteArg(escapeKey(k))
+}
+
+// escapeText returns the text escaped in Go without the quotes, the printable characters other than the backslashes
+// are kept as they are and the control characters don't reach the terminal
+func escapeText(s string) string {
+	q := strconv.Quote(s)
+	return strings.Replace(q[1:len(q)-1], `\"`, `"`, -1)
+}
+
+// unescapeKey returns the key of the escapes printed by escapeKey e.g. user\x00\xff, the keys without the backslashes are kept as they are
+func unescapeKey(k string) (string, error) {
+	if !strings.Contains(k, `\`) {
+		return k, nil
+	}
+	// the quotes are escaped in the printed keys, and kept as they are in the given keys
+	s, err := strconv.Unquote(`"` + strings.Replace(strings.Replace(k, `\"`, `"`, -1), `"`, `\"`, -1) + `"`)
+	if err != nil {
+		return "", fmt.Errorf("invalid escape in %q, write a backslash as \\\\", k)
+	}
+	return s, nil
+}
@@ -0,0 +1,82 @@
+package interfaces
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeKey(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+	}{
+		{"user#1", "user#1"},
+		{"user 1", "user 1"},
+		{"user\x00\xff", `user\x00\xff`},
+		{"a\nb", `a\nb`},
+		{`a\b`, `a\\b`},
+		{"u\"1\x01", `u\"1\x01`},
+		{"ユーザ", `ユーザ`},
+	}
+	for i, c := range cases {
+		actual := escapeKey(c.input)
+		assert.Equal(t, c.expect, actual, "#%d", i)
+
+		key, err := unescapeKey(actual)
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, c.input, key, "#%d", i)
+	}
+}
+
+func TestUnescapeKey(t *testing.T) {
+	cases := []struct {
+		input     string
+		expect    string
+		expectErr string
+	}{
+		{`u"1`, `u"1`, ""},
+		{`u"1\x00`, "u\"1\x00", ""},
+		{`a\q`, "", `invalid escape in "a\\q", write a backslash as \\`},
+	}
+	for i, c := range cases {
+		actual, err := unescapeKey(c.input)
+		if c.expectErr != "" {
+			assert.EqualError(t, err, c.expectErr, "#%d", i)
+			continue
+		}
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, c.expect, actual, "#%d", i)
+	}
+}
+
+func TestPrintedKey(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+	}{
+		{"user#1", "user#1"},
+		{"user 1", "'user 1'"},
+		{"user\x00\xff", `'user\x00\xff'`},
+		{"it's", `"it's"`},
+		{">a", "'>a'"},
+		{"a$b", "'a$b'"},
+		{"u\"1\x1b", `'u\"1\x1b'`},
+	}
+	for i, c := range cases {
+		actual := printedKey(c.input)
+		assert.Equal(t, c.expect, actual, "#%d", i)
+
+		// the printed key is given back to the commands
+		tokens, err := tokenizeCommand("lookup t " + actual)
+		assert.NoError(t, err, "#%d", i)
+		tokens, r, err := parseRedirection(tokens, nil)
+		assert.NoError(t, err, "#%d", i)
+		assert.Nil(t, r, "#%d", i)
+		args, err := expandVariables(tokens, nil)
+		assert.NoError(t, err, "#%d", i)
+		key, err := unescapeKey(args[2])
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, c.input, key, "#%d", i)
+	}
+}
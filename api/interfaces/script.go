@@ -0,0 +1,292 @@
+package interfaces
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkpointDirective pauses the script until the operator confirms the message
+const checkpointDirective = "# checkpoint:"
+
+// auditEntry is a step of the script in the audit log
+type auditEntry struct {
+	Time   string `json:"time"`
+	Script string `json:"script"`
+	Line   int    `json:"line"`
+	// Command, Elapsed and Outcome are of the executed commands, the outcome is ok or failed
+	Command string `json:"command,omitempty"`
+	Elapsed string `json:"elapsed,omitempty"`
+	Outcome string `json:"outcome,omitempty"`
+	// Checkpoint and Confirmed are of the checkpoints
+	Checkpoint string `json:"checkpoint,omitempty"`
+	Confirmed  *bool  `json:"confirmed,omitempty"`
+}
+
+// runScript executes the commands of the file line by line, the empty lines and the comments are skipped.
+// the checkpoints read the confirmation from in and the steps are logged to audit unless nil
+func (e *Executor) runScript(file string, in io.Reader, audit io.Writer) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var lines []scriptLine
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		lines = append(lines, scriptLine{n: n, text: strings.TrimSpace(s.Text())})
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	r := &scriptRunner{e: e, file: file, answers: bufio.NewReader(in), audit: audit}
+	if err := r.check(lines); err != nil {
+		return err
+	}
+	return r.run(lines)
+}
+
+// scriptLine is a line of the script trimmed the spaces
+type scriptLine struct {
+	n    int
+	text string
+}
+
+// scriptRunner executes the lines of a script, the blocks of repeat are executed recursively
+type scriptRunner struct {
+	e       *Executor
+	file    string
+	answers *bufio.Reader
+	audit   io.Writer
+}
+
+func (r *scriptRunner) log(entry auditEntry) {
+	if r.audit == nil {
+		return
+	}
+	entry.Time = time.Now().Format(time.RFC3339Nano)
+	entry.Script = r.file
+	json.NewEncoder(r.audit).Encode(entry)
+}
+
+func (r *scriptRunner) run(lines []scriptLine) error {
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+		switch {
+		case strings.HasPrefix(l.text, checkpointDirective):
+			msg := strings.TrimSpace(strings.TrimPrefix(l.text, checkpointDirective))
+			fmt.Fprintf(r.e.errStream, "Checkpoint: %s\nContinue? [y/N] ", msg)
+			answer, _ := r.answers.ReadString('\n')
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			confirmed := answer == "y" || answer == "yes"
+			r.log(auditEntry{Line: l.n, Checkpoint: msg, Confirmed: &confirmed})
+			if !confirmed {
+				return fmt.Errorf("Aborted at the checkpoint of %s:%d", r.file, l.n)
+			}
+		case l.text == "" || strings.HasPrefix(l.text, "#"):
+		case isDirective(l.text, "repeat"):
+			end, err := r.blockEnd(lines, i)
+			if err != nil {
+				return err
+			}
+			n, err := r.repeatCount(l)
+			if err != nil {
+				return err
+			}
+			for k := 0; k < n; k++ {
+				if err := r.run(lines[i+1 : end]); err != nil {
+					return err
+				}
+			}
+			i = end
+		case isDirective(l.text, "end"):
+			return fmt.Errorf("%s:%d: end without repeat", r.file, l.n)
+		case isDirective(l.text, "if"):
+			if err := r.runIf(l); err != nil {
+				return err
+			}
+		default:
+			if err := r.exec(l, l.text); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// check validates the blocks and the directives of the whole script before executing any line,
+// the counts of repeat having the variables are validated when executed
+func (r *scriptRunner) check(lines []scriptLine) error {
+	var repeats []scriptLine
+	for _, l := range lines {
+		switch {
+		case l.text == "" || strings.HasPrefix(l.text, "#"):
+		case isDirective(l.text, "repeat"):
+			repeats = append(repeats, l)
+			if !strings.Contains(l.text, "$") {
+				if _, err := r.repeatCount(l); err != nil {
+					return err
+				}
+			}
+		case isDirective(l.text, "end"):
+			if len(repeats) == 0 {
+				return fmt.Errorf("%s:%d: end without repeat", r.file, l.n)
+			}
+			repeats = repeats[:len(repeats)-1]
+		case isDirective(l.text, "if"):
+			if _, _, _, err := r.splitIf(l); err != nil {
+				return err
+			}
+		}
+	}
+	if len(repeats) > 0 {
+		return fmt.Errorf("%s:%d: repeat without end", r.file, repeats[0].n)
+	}
+	return nil
+}
+
+// exec executes the command of the line, the script stops at the failed command
+func (r *scriptRunner) exec(l scriptLine, cmd string) error {
+	fmt.Fprintf(r.e.errStream, "%s:%d> %s\n", r.file, l.n, cmd)
+	start := time.Now()
+	r.e.Do(cmd)
+	outcome := "ok"
+	if r.e.failed {
+		outcome = "failed"
+	}
+	r.log(auditEntry{Line: l.n, Command: cmd, Elapsed: time.Since(start).String(), Outcome: outcome})
+	if r.e.failed {
+		return fmt.Errorf("%s:%d: %s failed", r.file, l.n, cmd)
+	}
+	return nil
+}
+
+// isDirective reports whether the line starts with the word of the control flow
+func isDirective(line, word string) bool {
+	return line == word || strings.HasPrefix(line, word+" ")
+}
+
+// blockEnd returns the index of the end closing the repeat at i, the nested repeats have their ends
+func (r *scriptRunner) blockEnd(lines []scriptLine, i int) (int, error) {
+	depth := 0
+	for j := i + 1; j < len(lines); j++ {
+		switch {
+		case isDirective(lines[j].text, "repeat"):
+			depth++
+		case isDirective(lines[j].text, "end"):
+			if depth == 0 {
+				return j, nil
+			}
+			depth--
+		}
+	}
+	return 0, fmt.Errorf("%s:%d: repeat without end", r.file, lines[i].n)
+}
+
+// repeatCount parses "repeat <n>"
+func (r *scriptRunner) repeatCount(l scriptLine) (int, error) {
+	args, err := r.directiveArgs(l)
+	if err != nil {
+		return 0, err
+	}
+	if len(args) != 2 {
+		return 0, fmt.Errorf("%s:%d: Invalid args: repeat <n>", r.file, l.n)
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s:%d: Invalid count: %s", r.file, l.n, args[1])
+	}
+	return n, nil
+}
+
+// splitIf returns the tokens of "if exists <table> key=<row> then <command> [else <command>]" and the indexes of
+// then and else, else is the length of the tokens if not given. Only the unquoted then and else are the words of if,
+// e.g. the key of else is written as 'else'
+func (r *scriptRunner) splitIf(l scriptLine) ([]token, int, int, error) {
+	tokens, err := tokenizeCommand(l.text)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("%s:%d: Invalid args: %v", r.file, l.n, err)
+	}
+	then, els := -1, len(tokens)
+	for i, t := range tokens {
+		if t.quotedAt(0) {
+			continue
+		}
+		if then < 0 && t.text == "then" {
+			then = i
+		} else if then >= 0 && t.text == "else" {
+			els = i
+			break
+		}
+	}
+	if then < 0 || then+1 >= els || els == len(tokens)-1 {
+		return nil, 0, 0, fmt.Errorf("%s:%d: Invalid args: if exists <table> key=<row> then <command> [else <command>]", r.file, l.n)
+	}
+	return tokens, then, els, nil
+}
+
+// runIf executes the command of then if the row exists, otherwise the command of else if given
+func (r *scriptRunner) runIf(l scriptLine) error {
+	tokens, then, els, err := r.splitIf(l)
+	if err != nil {
+		return err
+	}
+
+	exists, err := r.exists(l, tokens[1:then])
+	if err != nil {
+		return err
+	}
+	// the command is executed as it's written to be expanded by Do
+	branch := tokens[then+1 : els]
+	if !exists {
+		if els == len(tokens) {
+			return nil
+		}
+		branch = tokens[els+1:]
+	}
+	raw := make([]string, len(branch))
+	for i, t := range branch {
+		raw[i] = t.raw
+	}
+	return r.exec(l, strings.Join(raw, " "))
+}
+
+// exists evaluates the condition "exists <table> key=<row>"
+func (r *scriptRunner) exists(l scriptLine, tokens []token) (bool, error) {
+	cond, err := expandVariables(tokens, r.e.variables)
+	if err != nil {
+		return false, fmt.Errorf("%s:%d: Invalid variable: %v", r.file, l.n, err)
+	}
+	if len(cond) != 3 || cond[0] != "exists" || !strings.HasPrefix(cond[2], "key=") {
+		return false, fmt.Errorf("%s:%d: Invalid condition: %s, must be exists <table> key=<row>", r.file, l.n, joinCommand(cond))
+	}
+	key, err := unescapeKey(strings.TrimPrefix(cond[2], "key="))
+	if err != nil {
+		return false, fmt.Errorf("%s:%d: Invalid key: %v", r.file, l.n, err)
+	}
+	existing, err := r.e.rowsInteractor.ExistingKeys(r.e.requestContext(nil), cond[1], []string{key}, 1)
+	if err != nil {
+		return false, fmt.Errorf("%s:%d: %v", r.file, l.n, err)
+	}
+	return existing[key], nil
+}
+
+// directiveArgs returns the args of the directive with the variables expanded
+func (r *scriptRunner) directiveArgs(l scriptLine) ([]string, error) {
+	tokens, err := tokenizeCommand(l.text)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%d: Invalid args: %v", r.file, l.n, err)
+	}
+	args, err := expandVariables(tokens, r.e.variables)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%d: Invalid variable: %v", r.file, l.n, err)
+	}
+	return args, nil
+}
@@ -0,0 +1,247 @@
+package interfaces
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestRunScript(t *testing.T) {
+	f, err := ioutil.TempFile("", "script")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString("# list the tables\nls\n\n# checkpoint: drop t1\ndeletetable t1\n# checkpoint: drop t2\ndeletetable t2\n")
+	f.Close()
+
+	confirmed, rejected := true, false
+	cases := []struct {
+		answers   string
+		expectErr string
+		expectLog []auditEntry
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			"y\nyes\n",
+			"",
+			[]auditEntry{
+				{Line: 2, Command: "ls", Outcome: "ok"},
+				{Line: 4, Checkpoint: "drop t1", Confirmed: &confirmed},
+				{Line: 5, Command: "deletetable t1", Outcome: "ok"},
+				{Line: 6, Checkpoint: "drop t2", Confirmed: &confirmed},
+				{Line: 7, Command: "deletetable t2", Outcome: "ok"},
+			},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1", "t2"}, nil)
+				for _, table := range []string{"t1", "t2"} {
+					mock.EXPECT().TableInfo(gomock.Any(), table).Return(&domain.TableInfo{Name: table}, nil)
+					mock.EXPECT().DeleteTable(gomock.Any(), table).Return(nil)
+				}
+			},
+		},
+		{
+			"y\nn\n",
+			"Aborted at the checkpoint of " + f.Name() + ":6",
+			[]auditEntry{
+				{Line: 2, Command: "ls", Outcome: "ok"},
+				{Line: 4, Checkpoint: "drop t1", Confirmed: &confirmed},
+				{Line: 5, Command: "deletetable t1", Outcome: "ok"},
+				{Line: 6, Checkpoint: "drop t2", Confirmed: &rejected},
+			},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1", "t2"}, nil)
+				mock.EXPECT().TableInfo(gomock.Any(), "t1").Return(&domain.TableInfo{Name: "t1"}, nil)
+				mock.EXPECT().DeleteTable(gomock.Any(), "t1").Return(nil)
+			},
+		},
+		{
+			// no answer aborts
+			"",
+			"Aborted at the checkpoint of " + f.Name() + ":4",
+			[]auditEntry{
+				{Line: 2, Command: "ls", Outcome: "ok"},
+				{Line: 4, Checkpoint: "drop t1", Confirmed: &rejected},
+			},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1", "t2"}, nil)
+			},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut, audit bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+		}
+		err := executor.runScript(f.Name(), strings.NewReader(c.answers), &audit)
+		if c.expectErr == "" {
+			assert.NoError(t, err, "#%d", i)
+		} else if assert.Error(t, err, "#%d", i) {
+			assert.Equal(t, c.expectErr, err.Error(), "#%d", i)
+		}
+		assert.Equal(t, "t1\nt2\n", out.String(), "#%d", i)
+
+		var entries []auditEntry
+		dec := json.NewDecoder(&audit)
+		for dec.More() {
+			var entry auditEntry
+			assert.NoError(t, dec.Decode(&entry), "#%d", i)
+			assert.Equal(t, f.Name(), entry.Script, "#%d", i)
+			assert.NotEmpty(t, entry.Time, "#%d", i)
+			if entry.Command != "" {
+				assert.NotEmpty(t, entry.Elapsed, "#%d", i)
+			}
+			entry.Time, entry.Script, entry.Elapsed = "", "", ""
+			entries = append(entries, entry)
+		}
+		assert.Equal(t, c.expectLog, entries, "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestRunScriptControlFlow(t *testing.T) {
+	f, err := ioutil.TempFile("", "script")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	file := f.Name()
+	f.Close()
+
+	cases := []struct {
+		script    string
+		expectErr string
+		expectLog []auditEntry
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			"repeat 2\n  ls\n  repeat 0\n    deletetable t1\n  end\nend\n",
+			"",
+			[]auditEntry{{Line: 2, Command: "ls", Outcome: "ok"}, {Line: 2, Command: "ls", Outcome: "ok"}},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1"}, nil).Times(2)
+			},
+		},
+		{
+			"if exists users key='1\\x00' then ls else deletetable t1\nif exists users key=2 then deletetable t1 else ls\nif exists users key=2 then deletetable t1\n",
+			"",
+			[]auditEntry{{Line: 1, Command: "ls", Outcome: "ok"}, {Line: 2, Command: "ls", Outcome: "ok"}},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Keys(gomock.Any(), "users", bigtable.RowList{"1\x00"}).Return([]string{"1\x00"}, nil)
+				mock.EXPECT().Keys(gomock.Any(), "users", bigtable.RowList{"2"}).Return(nil, nil).Times(2)
+				mock.EXPECT().Tables(gomock.Any()).Return([]string{"t1"}, nil).Times(2)
+			},
+		},
+		{
+			"if exists users key=1 then ls else\n",
+			file + ":1: Invalid args: if exists <table> key=<row> then <command> [else <command>]",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"if exists users 1 then ls\n",
+			file + ":1: Invalid condition: exists users 1, must be exists <table> key=<row>",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"repeat 2\nls\n",
+			file + ":1: repeat without end",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"repeat x\nend\n",
+			file + ":1: Invalid count: x",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		// the script is validated before executing any line
+		{
+			"ls\nend\n",
+			file + ":2: end without repeat",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"ls\nrepeat 2\nrepeat 1\nend\n",
+			file + ":2: repeat without end",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"ls\nif exists users key=1 then\n",
+			file + ":2: Invalid args: if exists <table> key=<row> then <command> [else <command>]",
+			nil,
+			func(mock *repository.MockBigtable) {},
+		},
+		// the script stops at the failed command
+		{
+			"foo\nls\n",
+			file + ":1: foo failed",
+			[]auditEntry{{Line: 1, Command: "foo", Outcome: "failed"}},
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"if exists users key=1 then deletetable\nls\n",
+			file + ":1: deletetable failed",
+			[]auditEntry{{Line: 1, Command: "deletetable", Outcome: "failed"}},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Keys(gomock.Any(), "users", bigtable.RowList{"1"}).Return([]string{"1"}, nil)
+			},
+		},
+		// the quoted then and else are the args of the commands
+		{
+			"if exists users key=1 then lookup users 'else' else ls\n",
+			"",
+			[]auditEntry{{Line: 1, Command: "lookup users 'else'", Outcome: "ok"}},
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Keys(gomock.Any(), "users", bigtable.RowList{"1"}).Return([]string{"1"}, nil)
+				mock.EXPECT().Get(gomock.Any(), "users", "else", gomock.Any()).Return(&domain.Bigtable{Table: "users", Rows: []*domain.Row{{Key: "else"}}}, nil)
+			},
+		},
+	}
+	for i, c := range cases {
+		assert.NoError(t, ioutil.WriteFile(file, []byte(c.script), 0644), "#%d", i)
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut, audit bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+			rowsInteractor:  application.NewRowsInteractor(mockBtRepo),
+		}
+		err := executor.runScript(file, strings.NewReader(""), &audit)
+		if c.expectErr == "" {
+			assert.NoError(t, err, "#%d", i)
+		} else if assert.Error(t, err, "#%d", i) {
+			assert.Equal(t, c.expectErr, err.Error(), "#%d", i)
+		}
+
+		var entries []auditEntry
+		dec := json.NewDecoder(&audit)
+		for dec.More() {
+			var entry auditEntry
+			assert.NoError(t, dec.Decode(&entry), "#%d", i)
+			entry.Time, entry.Script, entry.Elapsed = "", "", ""
+			entries = append(entries, entry)
+		}
+		assert.Equal(t, c.expectLog, entries, "#%d", i)
+		ctrl.Finish()
+	}
+}
@@ -0,0 +1,91 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/takashabe/btcli/api/config"
+)
+
+// settingNames are the session settings toggled by set, in the printed order
+var settingNames = []string{"dryrun", "color", "pager", "utc", "verbose"}
+
+// settingTimestampFormat is the setting of the timestamp format, printed after the toggled settings
+const settingTimestampFormat = "timestamp-format"
+
+// setting returns the session setting of the name, nil if unknown
+func (e *Executor) setting(name string) *bool {
+	switch name {
+	case "dryrun":
+		return &e.dryRun
+	case "color":
+		return &e.color
+	case "pager":
+		return &e.pagerOn
+	case "utc":
+		return &e.utc
+	case "verbose":
+		return &e.verbose
+	}
+	return nil
+}
+
+func doSet(ctx context.Context, e *Executor, args ...string) {
+	if len(args) == 1 {
+		for _, name := range settingNames {
+			fmt.Fprintf(e.outStream, "%s: %s\n", name, onOff(*e.setting(name)))
+		}
+		fmt.Fprintf(e.outStream, "%s: %s\n", settingTimestampFormat, e.timestampFormatName())
+		return
+	}
+	if len(args) != 3 {
+		e.failf(e.msg("Invalid args: %v\n"), fmt.Sprintf("set [%s on|off] [%s <format>]", strings.Join(settingNames, "|"), settingTimestampFormat))
+		return
+	}
+	if args[1] == settingTimestampFormat {
+		if !containsString(config.TimestampFormats, args[2]) {
+			e.failf(e.msg("Invalid value: %s, must be one of %s\n"), args[2], strings.Join(config.TimestampFormats, ", "))
+			return
+		}
+		e.timestampFormat = args[2]
+		fmt.Fprintf(e.errStream, "%s: %s\n", args[1], args[2])
+		return
+	}
+	s := e.setting(args[1])
+	if s == nil {
+		e.failf(e.msg("Unknown setting: %s, must be one of %s, %s\n"), args[1], strings.Join(settingNames, ", "), settingTimestampFormat)
+		return
+	}
+	switch args[2] {
+	case "on":
+		*s = true
+	case "off":
+		*s = false
+	default:
+		e.failf(e.msg("Invalid value: %s, must be on or off\n"), args[2])
+		return
+	}
+	fmt.Fprintf(e.errStream, "%s: %s\n", args[1], args[2])
+}
+
+// timestampFormatName returns the timestamp format, default if unset
+func (e *Executor) timestampFormatName() string {
+	if e.timestampFormat == "" {
+		return timestampFormatDefault
+	}
+	return e.timestampFormat
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// printDryRun prints the command not executed by the dry run
+func (e *Executor) printDryRun(c Command, args []string) {
+	fmt.Fprintf(e.errStream, e.msg("Dry run: %s would %s, run \"set dryrun off\" to execute\n"),
+		joinCommand(args), lowerFirst(e.msg(c.Description)))
+}
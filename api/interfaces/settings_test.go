@@ -0,0 +1,78 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoSetDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	mockBtRepo.EXPECT().Tables(gomock.Any()).Return([]string{"t1"}, nil)
+	mockBtRepo.EXPECT().DeleteTable(gomock.Any(), gomock.Any()).Times(0)
+
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:       &out,
+		errStream:       &errOut,
+		tableInteractor: application.NewTableInteractor(mockBtRepo),
+	}
+
+	cases := []struct {
+		input     string
+		expectOut string
+		expectErr string
+	}{
+		{"set", "dryrun: off\ncolor: off\npager: off\nutc: off\nverbose: off\ntimestamp-format: default\n", ""},
+		{"set dryrun on", "", "dryrun: on\n"},
+		{"set", "dryrun: on\ncolor: off\npager: off\nutc: off\nverbose: off\ntimestamp-format: default\n", ""},
+		{"deletetable t1", "", "Dry run: deletetable t1 would delete a table, run \"set dryrun off\" to execute\n"},
+		// the read commands are executed
+		{"ls", "t1\n", ""},
+		{"set dryrun yes", "", "Invalid value: yes, must be on or off\n"},
+		{"set quiet on", "", "Unknown setting: quiet, must be one of dryrun, color, pager, utc, verbose, timestamp-format\n"},
+		{"set dryrun", "", "Invalid args: set [dryrun|color|pager|utc|verbose on|off] [timestamp-format <format>]\n"},
+		{"set dryrun off", "", "dryrun: off\n"},
+	}
+	for i, c := range cases {
+		out.Reset()
+		errOut.Reset()
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+	}
+}
+
+func TestDoSetTimestamps(t *testing.T) {
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream: &out,
+		errStream: &errOut,
+	}
+
+	cases := []struct {
+		input     string
+		expectOut string
+		expectErr string
+	}{
+		{"set timestamp-format relative", "", "timestamp-format: relative\n"},
+		{"set utc on", "", "utc: on\n"},
+		{"set", "dryrun: off\ncolor: off\npager: off\nutc: on\nverbose: off\ntimestamp-format: relative\n", ""},
+		{"set timestamp-format iso", "", "Invalid value: iso, must be one of default, rfc3339, unix-micros, relative\n"},
+		{"set timestamp-format default", "", "timestamp-format: default\n"},
+	}
+	for i, c := range cases {
+		out.Reset()
+		errOut.Reset()
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+	}
+	assert.Equal(t, timestampFormatter{layout: "default", utc: true}, executor.newPrinter(map[string]string{}).timestamps)
+}
@@ -0,0 +1,56 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxShowRows is the number of the rows kept for show, the rows printed after it are numbered but not kept
+const maxShowRows = 10000
+
+func doShow(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 2 {
+		e.failf(e.msg("Invalid args: %v\n"), "show <n> [args ...]")
+		return
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 1 {
+		e.failf("Invalid row number: %v\n", args[1])
+		return
+	}
+	parsed := make(map[string]string)
+	for _, arg := range args[2:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		case "decode", "decode_columns", "qualifier-time", "pivot", "format", "template", "full-values":
+			parsed[k] = v
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		}
+	}
+	if err := validatePrinterOption(parsed); err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+	if len(e.lastRows) == 0 {
+		fmt.Fprintln(e.errStream, "No rows printed by the last command")
+		return
+	}
+	if n > len(e.lastRows) {
+		e.failf("Invalid row number: %d, the last result has %d rows\n", n, len(e.lastRows))
+		return
+	}
+
+	// the reprinted row is neither numbered nor replaces the last result
+	p := e.newPrinter(parsed)
+	p.record = nil
+	p.printRow(e.lastRows[n-1])
+}
@@ -0,0 +1,124 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoShow(t *testing.T) {
+	tm, _ := time.Parse("2006-01-02 15:04:05", "2018-01-01 00:00:00")
+	rows := []*domain.Row{
+		{Key: "a"},
+		{Key: "b", Columns: []*domain.Column{{Family: "d", Qualifier: "d:count", Value: []byte{0, 0, 0, 0, 0, 0, 0, 3}, Version: tm}}},
+	}
+
+	cases := []struct {
+		input     string
+		expect    string
+		expectErr string
+	}{
+		{
+			"show 2 decode=int",
+			"b\n  d:count                                  @ 2018/01/01-00:00:00.000000\n    3\n",
+			"----------------------------------------\n",
+		},
+		{
+			"show 2 format=json decode=int",
+			`{"key":"b","cells":[{"family":"d","qualifier":"count","value":3,"timestamp":"2018-01-01T00:00:00Z"}]}` + "\n",
+			"",
+		},
+		{
+			"show 3",
+			"",
+			"Invalid row number: 3, the last result has 2 rows\n",
+		},
+		{
+			"show 0",
+			"",
+			"Invalid row number: 0\n",
+		},
+		{
+			"show 1 format=xml",
+			"",
+			"Invalid options: format must be one of text, json, ndjson, csv, tsv, yaml, table, wide, plain, template: \"xml\"\n",
+		},
+		{
+			"show 1 versions=all",
+			"",
+			"Unknown arg: versions=all\n",
+		},
+		{
+			"show",
+			"",
+			"Invalid args: show <n> [args ...]\n",
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		mockBtRepo.EXPECT().GetRows(gomock.Any(), "table", bigtable.RowList{"a", "b"}, bigtable.RowFilter(bigtable.LatestNFilter(1))).Return(
+			&domain.Bigtable{Table: "table", Rows: rows}, nil)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:      &out,
+			errStream:      &errOut,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+			numberRows:     true,
+		}
+		executor.Do("lookup table a b")
+		assert.Equal(t, "a\nb\n  d:count                                  @ 2018/01/01-00:00:00.000000\n    3\n", out.String(), "#%d", i)
+		assert.Equal(t, "-- 1 -----------------------------------\n-- 2 -----------------------------------\n", errOut.String(), "#%d", i)
+
+		out.Reset()
+		errOut.Reset()
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
+
+func TestDoShowWithoutRows(t *testing.T) {
+	var out, errOut bytes.Buffer
+	executor := Executor{
+		outStream:  &out,
+		errStream:  &errOut,
+		numberRows: true,
+	}
+	executor.Do("show 1")
+	assert.Equal(t, "", out.String())
+	assert.Equal(t, "No rows printed by the last command\n", errOut.String())
+}
+
+func TestDoShowFullValues(t *testing.T) {
+	rows := []*domain.Row{
+		{Key: "a", Columns: []*domain.Column{{Family: "d", Qualifier: "d:blob", Value: []byte("0123456789")}}},
+	}
+	cases := []struct {
+		input  string
+		expect string
+	}{
+		{"show 1 decode=string", "a\n  d:blob                                   @ 0001/01/01-00:00:00.000000\n    \"012 ... (+8 bytes)\n"},
+		{"show 1 decode=string full-values=true", "a\n  d:blob                                   @ 0001/01/01-00:00:00.000000\n    \"0123456789\"\n"},
+	}
+	for i, c := range cases {
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:     &out,
+			errStream:     &errOut,
+			lastRows:      rows,
+			maxValueBytes: 4,
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, out.String(), "#%d", i)
+	}
+}
@@ -0,0 +1,51 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func doSuggestSplits(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "suggest-splits <src-table> target=<n>")
+		return
+	}
+	table := args[1]
+
+	target := 0
+	for _, arg := range args[2:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "target":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 2 {
+				e.failf("Invalid target: %v\n", v)
+				return
+			}
+			target = n
+		}
+	}
+	if target == 0 {
+		e.failln(`"target" is required`)
+		return
+	}
+
+	splits, err := e.tableInteractor.SuggestSplits(ctx, table, target)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	// a line of the splits is given to the splits option of createtable
+	fmt.Fprintln(e.outStream, strings.Join(splits, ","))
+	fmt.Fprintf(e.errStream, "%s split points for %s tablets\n", e.formatNumber(int64(len(splits))), e.formatNumber(int64(len(splits)+1)))
+}
@@ -0,0 +1,89 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoSuggestSplits(t *testing.T) {
+	samples := []*domain.KeySample{
+		&domain.KeySample{Key: "b", Offset: 100},
+		&domain.KeySample{Key: "d", Offset: 250},
+		&domain.KeySample{Key: "f", Offset: 500},
+		&domain.KeySample{Key: "h", Offset: 760},
+		&domain.KeySample{Key: "", Offset: 1000},
+	}
+	cases := []struct {
+		input     string
+		expectOut string
+		expectErr string
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			"suggest-splits table target=4",
+			"d,f,h\n",
+			"3 split points for 4 tablets\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().SampleKeys(gomock.Any(), "table").Return(samples, nil)
+			},
+		},
+		{
+			"suggest-splits table target=2",
+			"f\n",
+			"1 split points for 2 tablets\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().SampleKeys(gomock.Any(), "table").Return(samples, nil)
+			},
+		},
+		{
+			"suggest-splits table target=10",
+			"b,d,f,h\n",
+			"4 split points for 5 tablets\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().SampleKeys(gomock.Any(), "table").Return(samples, nil)
+			},
+		},
+		{
+			"suggest-splits table target=4",
+			"\n",
+			"0 split points for 1 tablets\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().SampleKeys(gomock.Any(), "table").Return([]*domain.KeySample{&domain.KeySample{Key: "", Offset: 10}}, nil)
+			},
+		},
+		{
+			"suggest-splits table target=1",
+			"",
+			"Invalid target: 1\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"suggest-splits table",
+			"",
+			"Invalid args: suggest-splits <src-table> target=<n>\n",
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:       &out,
+			errStream:       &errOut,
+			tableInteractor: application.NewTableInteractor(mockBtRepo),
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
@@ -0,0 +1,95 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/takashabe/btcli/api/domain"
+)
+
+func doStats(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 2 {
+		e.failf(e.msg("Invalid args: %v\n"), "stats <table> [start=<row>] [end=<row>] [prefix=<prefix>] [values=true] [args ...]")
+		return
+	}
+	table := args[1]
+
+	parsed := make(map[string]string)
+	for _, arg := range args[2:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "start", "end", "prefix":
+			parsed[k] = v
+		case "version", "versions", "family", "columns", "qualifier-regex", "from", "to":
+			parsed[k] = v
+		case "values", "app-profile":
+			parsed[k] = v
+		}
+	}
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		e.failln(`"start"/"end" may not be mixed with "prefix"`)
+		return
+	}
+	values := false
+	if v := parsed["values"]; v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			e.failf("Invalid values: %v\n", err)
+			return
+		}
+		values = b
+	}
+
+	var rs bigtable.RowSet = bigtable.InfiniteRange("")
+	if parsed["start"] != "" || parsed["end"] != "" || parsed["prefix"] != "" {
+		rr, err := rowRange(parsed)
+		if err != nil {
+			e.failf(e.msg("Invalid range: %v\n"), err)
+			return
+		}
+		rs = rr
+	}
+	filters, err := readFilters(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+	// only the sizes of the keys and the qualifiers are counted without reading the values
+	if !values {
+		filters = append(filters, bigtable.StripValueFilter())
+	}
+
+	stats, err := e.rowsInteractor.GetRangeStats(e.requestContext(parsed), table, rs, rowFilterOption(filters...)...)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	e.printRangeStats(stats, values)
+}
+
+func (e *Executor) printRangeStats(s *domain.RangeStats, values bool) {
+	fmt.Fprintf(e.outStream, "rows: %s\n", e.formatNumber(int64(s.Rows)))
+	fmt.Fprintf(e.outStream, "cells: %s\n", e.formatNumber(int64(s.Cells)))
+	fmt.Fprintf(e.outStream, "qualifiers: %s\n", e.formatNumber(int64(s.Qualifiers)))
+	if s.Cells > 0 {
+		fmt.Fprintf(e.outStream, "min timestamp: %s\n", e.timestamps().format(s.MinTimestamp))
+		fmt.Fprintf(e.outStream, "max timestamp: %s\n", e.timestamps().format(s.MaxTimestamp))
+	}
+	fmt.Fprintf(e.outStream, "key bytes: %s\n", e.formatNumber(s.KeyBytes))
+	fmt.Fprintf(e.outStream, "qualifier bytes: %s\n", e.formatNumber(s.QualifierBytes))
+	if values {
+		fmt.Fprintf(e.outStream, "value bytes: %s\n", e.formatNumber(s.ValueBytes))
+		fmt.Fprintf(e.outStream, "total bytes: %s\n", e.formatNumber(s.KeyBytes+s.QualifierBytes+s.ValueBytes))
+	}
+}
@@ -0,0 +1,101 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoStats(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)
+	stats := &domain.RangeStats{
+		Rows:           2,
+		Cells:          3,
+		Qualifiers:     2,
+		MinTimestamp:   tm,
+		MaxTimestamp:   tm.Add(time.Second),
+		KeyBytes:       4,
+		QualifierBytes: 1200,
+		ValueBytes:     3000,
+	}
+	cases := []struct {
+		input   string
+		expect  string
+		prepare func(*repository.MockBigtable)
+	}{
+		{
+			"stats table prefix=a",
+			"rows: 2\n" +
+				"cells: 3\n" +
+				"qualifiers: 2\n" +
+				"min timestamp: 2018/01/01-00:00:00.000000\n" +
+				"max timestamp: 2018/01/01-00:00:01.000000\n" +
+				"key bytes: 4\n" +
+				"qualifier bytes: 1,200\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Stats(gomock.Any(), "table", bigtable.PrefixRange("a"), bigtable.RowFilter(bigtable.StripValueFilter())).Return(stats, nil)
+			},
+		},
+		{
+			"stats table family=d values=true",
+			"rows: 2\n" +
+				"cells: 3\n" +
+				"qualifiers: 2\n" +
+				"min timestamp: 2018/01/01-00:00:00.000000\n" +
+				"max timestamp: 2018/01/01-00:00:01.000000\n" +
+				"key bytes: 4\n" +
+				"qualifier bytes: 1,200\n" +
+				"value bytes: 3,000\n" +
+				"total bytes: 4,204\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Stats(gomock.Any(), "table", bigtable.InfiniteRange(""), bigtable.RowFilter(bigtable.FamilyFilter("^(?:d)$"))).Return(stats, nil)
+			},
+		},
+		{
+			"stats table",
+			"rows: 0\n" +
+				"cells: 0\n" +
+				"qualifiers: 0\n" +
+				"key bytes: 0\n" +
+				"qualifier bytes: 0\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().Stats(gomock.Any(), "table", bigtable.InfiniteRange(""), gomock.Any()).Return(&domain.RangeStats{}, nil)
+			},
+		},
+		{
+			"stats table prefix=a start=b",
+			"\"start\"/\"end\" may not be mixed with \"prefix\"\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"stats table values=yes",
+			"Invalid values: strconv.ParseBool: parsing \"yes\": invalid syntax\n",
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for _, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		defer ctrl.Finish()
+
+		c.prepare(mockBtRepo)
+
+		var buf bytes.Buffer
+		executor := Executor{
+			outStream:      &buf,
+			errStream:      &buf,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+			numberFormat:   "comma",
+		}
+
+		executor.Do(c.input)
+		assert.Equal(t, c.expect, buf.String(), c.input)
+	}
+}
@@ -0,0 +1,74 @@
+package interfaces
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// formats of the timestamps, see config.TimestampFormats
+const (
+	timestampFormatDefault    = "default"
+	timestampFormatRFC3339    = "rfc3339"
+	timestampFormatUnixMicros = "unix-micros"
+	timestampFormatRelative   = "relative"
+)
+
+// timestampFormatter formats the timestamps of the cells, the zero value prints the default layout in the time of the cells
+type timestampFormatter struct {
+	// layout is one of the timestamp formats, empty is the default
+	layout string
+	// utc prints the timestamps in UTC, otherwise in the local time read from the server
+	utc bool
+	// now is the base of the relative format, nil uses time.Now
+	now func() time.Time
+}
+
+// timestamps returns the formatter of the timestamp settings
+func (e *Executor) timestamps() timestampFormatter {
+	return timestampFormatter{layout: e.timestampFormat, utc: e.utc}
+}
+
+func (f timestampFormatter) format(t time.Time) string {
+	if f.utc {
+		t = t.UTC()
+	}
+	switch f.layout {
+	case timestampFormatRFC3339:
+		return t.Format(time.RFC3339Nano)
+	case timestampFormatUnixMicros:
+		// the versions of bigtable are in microseconds
+		return strconv.FormatInt(t.UnixNano()/int64(time.Microsecond), 10)
+	case timestampFormatRelative:
+		now := time.Now
+		if f.now != nil {
+			now = f.now
+		}
+		return relativeTime(now().Sub(t))
+	default:
+		return t.Format(timestampLayout)
+	}
+}
+
+// relativeTime returns the elapsed time in the largest unit, e.g. "3h ago", the future times are "in 3h"
+func relativeTime(d time.Duration) string {
+	suffix := "%s ago"
+	if d < 0 {
+		d = -d
+		suffix = "in %s"
+	}
+	var s string
+	switch {
+	case d < time.Second:
+		return "now"
+	case d < time.Minute:
+		s = fmt.Sprintf("%ds", d/time.Second)
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", d/time.Minute)
+	case d < 24*time.Hour:
+		s = fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		s = fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return fmt.Sprintf(suffix, s)
+}
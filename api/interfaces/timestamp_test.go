@@ -0,0 +1,33 @@
+package interfaces
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestampFormatterFormat(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	tm := time.Date(2018, 1, 1, 9, 0, 0, 123456000, jst)
+	now := func() time.Time { return tm.Add(3*time.Hour + 20*time.Minute) }
+
+	cases := []struct {
+		formatter timestampFormatter
+		input     time.Time
+		expect    string
+	}{
+		{timestampFormatter{}, tm, "2018/01/01-09:00:00.123456"},
+		{timestampFormatter{utc: true}, tm, "2018/01/01-00:00:00.123456"},
+		{timestampFormatter{layout: "rfc3339"}, tm, "2018-01-01T09:00:00.123456+09:00"},
+		{timestampFormatter{layout: "rfc3339", utc: true}, tm, "2018-01-01T00:00:00.123456Z"},
+		{timestampFormatter{layout: "unix-micros"}, tm, "1514764800123456"},
+		{timestampFormatter{layout: "relative", now: now}, tm, "3h ago"},
+		{timestampFormatter{layout: "relative", now: now}, tm.Add(3*time.Hour + 19*time.Minute), "1m ago"},
+		{timestampFormatter{layout: "relative", now: now}, tm.Add(50 * time.Hour), "in 1d"},
+		{timestampFormatter{layout: "relative", now: now}, now(), "now"},
+	}
+	for i, c := range cases {
+		assert.Equal(t, c.expect, c.formatter.format(c.input), "#%d", i)
+	}
+}
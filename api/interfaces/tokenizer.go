@@ -0,0 +1,185 @@
+package interfaces
+
+import (
+	"fmt"
+	"strings"
+)
+
+// token is an arg of the command with the quoting of it
+type token struct {
+	text string
+	// literal tells the bytes of text quoted or escaped, they aren't redirections or variables
+	literal []bool
+	// expr tells the option value is an expression kept as it's written
+	expr bool
+	// raw is the arg as it's written in the command at pos
+	raw string
+	pos int
+}
+
+// quotedAt reports whether the byte i of the text is quoted or escaped
+func (t token) quotedAt(i int) bool {
+	return i < len(t.literal) && t.literal[i]
+}
+
+// tokenizeCommand splits the command into the args by the spaces,
+// the quotes at the start of the args or the values and the backslashes keep the spaces, the quotes and "=" in the args
+// like the shell, e.g. start="user 1" or start=user\ 1.
+// The expressions of the option values are kept with the quotes and the spaces to be evaluated by expandExprs
+func tokenizeCommand(s string) ([]token, error) {
+	var tokens []token
+	var raw, arg strings.Builder
+	var literal []bool
+	started := false
+	start := 0
+	depth := 0
+	var quote byte
+
+	write := func(c byte, quoted bool) {
+		arg.WriteByte(c)
+		literal = append(literal, quoted)
+	}
+	begin := func(i int) {
+		if !started {
+			start = i
+		}
+		started = true
+	}
+	flush := func() {
+		if !started {
+			return
+		}
+		if isExprArg(raw.String()) {
+			tokens = append(tokens, token{text: raw.String(), literal: make([]bool, raw.Len()), expr: true, raw: raw.String(), pos: start})
+		} else {
+			tokens = append(tokens, token{text: arg.String(), literal: literal, raw: raw.String(), pos: start})
+		}
+		raw.Reset()
+		arg.Reset()
+		literal = nil
+		started = false
+		depth = 0
+	}
+
+	// the special characters are ASCII, the bytes of the binary keys are kept as they are
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				write(c, true)
+			}
+		case quote == '"':
+			switch {
+			case c == '"':
+				quote = 0
+			case c == '\\' && i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\'):
+				raw.WriteByte(c)
+				i++
+				c = s[i]
+				write(c, true)
+			default:
+				write(c, true)
+			}
+		case c == ' ' || c == '\t':
+			// the spaces in the parentheses of an expression are a part of it
+			if depth > 0 && isExprArg(raw.String()) {
+				write(c, false)
+				break
+			}
+			flush()
+			continue
+		case (c == '\'' || c == '"') && (!started || strings.HasSuffix(raw.String(), "=")):
+			// the quotes in the middle of the keys are the characters of them, e.g. u"1
+			quote = c
+			begin(i)
+		case c == '\\' && i+1 < len(s):
+			begin(i)
+			raw.WriteByte(c)
+			i++
+			c = s[i]
+			write(c, true)
+		default:
+			if c == '(' {
+				depth++
+			} else if c == ')' {
+				depth--
+			}
+			write(c, false)
+			begin(i)
+		}
+		raw.WriteByte(c)
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote %c in %q", quote, s)
+	}
+	flush()
+	return tokens, nil
+}
+
+// literalToken returns the token of the prefix and the value quoted
+func literalToken(prefix, value string) token {
+	literal := make([]bool, len(prefix)+len(value))
+	for i := len(prefix); i < len(literal); i++ {
+		literal[i] = true
+	}
+	return token{text: prefix + value, literal: literal, raw: prefix + quoteArg(value)}
+}
+
+// rawArgs returns the args from the n-th as they are written in the command with the spaces between them,
+// the redirection at the end is excluded
+func rawArgs(s string, n int) string {
+	tokens, err := tokenizeCommand(s)
+	if err != nil {
+		return ""
+	}
+	for i, t := range tokens {
+		if strings.HasPrefix(t.text, ">") && !t.quotedAt(0) {
+			tokens = tokens[:i]
+			break
+		}
+	}
+	if len(tokens) <= n {
+		return ""
+	}
+	last := tokens[len(tokens)-1]
+	return s[tokens[n].pos : last.pos+len(last.raw)]
+}
+
+// tokenTexts returns the args of the tokens
+func tokenTexts(tokens []token) []string {
+	args := make([]string, len(tokens))
+	for i, t := range tokens {
+		args[i] = t.text
+	}
+	return args
+}
+
+// isExprArg reports whether the arg is an option of the expression value
+func isExprArg(arg string) bool {
+	i := strings.Index(arg, "=")
+	return i >= 0 && isExprValue(arg[:i], arg[i+1:])
+}
+
+// joinCommand joins the args into the command read back by tokenizeCommand
+func joinCommand(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = quoteArg(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// quoteArg quotes the arg having the spaces, the quotes, the backslashes, $ of the variables or ">" of the redirection
+// at the start in the single quotes, the expressions are kept as they are
+func quoteArg(a string) string {
+	if a != "" && (isExprArg(a) || !strings.ContainsAny(a, " \t'\"\\$") && !strings.HasPrefix(a, ">")) {
+		return a
+	}
+	if !strings.Contains(a, "'") {
+		return "'" + a + "'"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(a) + `"`
+}
@@ -0,0 +1,73 @@
+package interfaces
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizeCommand(t *testing.T) {
+	cases := []struct {
+		input     string
+		expect    []string
+		expectErr string
+	}{
+		{"read users  prefix=a", []string{"read", "users", "prefix=a"}, ""},
+		{`lookup users "user 1"`, []string{"lookup", "users", "user 1"}, ""},
+		{`read users start="user 1" end='user 2'`, []string{"read", "users", "start=user 1", "end=user 2"}, ""},
+		{`read users start=user\ 1 value-regex="a=b"`, []string{"read", "users", "start=user 1", "value-regex=a=b"}, ""},
+		{`lookup users "say \"hi\"" 'a\b' ""`, []string{"lookup", "users", `say "hi"`, `a\b`, ""}, ""},
+		// the quotes in the middle of the keys are kept
+		{`lookup users u"1 it's`, []string{"lookup", "users", `u"1`, "it's"}, ""},
+		{"lookup users ab\xff", []string{"lookup", "users", "ab\xff"}, ""},
+		// the expressions are kept with the quotes and the spaces
+		{`read users from=concat("2018", env("ID")) count=(1 + 2)`, []string{"read", "users", `from=concat("2018", env("ID"))`, "count=(1 + 2)"}, ""},
+		{`read users prefix=concat("a b")`, []string{"read", "users", `prefix=concat("a b")`}, ""},
+		{`read users prefix=(a b)`, []string{"read", "users", "prefix=(a", "b)"}, ""},
+		{`read users value-regex=(a b)`, []string{"read", "users", "value-regex=(a", "b)"}, ""},
+		{`lookup users "user 1`, nil, `unterminated quote " in "lookup users \"user 1"`},
+	}
+	for i, c := range cases {
+		actual, err := tokenizeCommand(c.input)
+		if c.expectErr != "" {
+			assert.EqualError(t, err, c.expectErr, "#%d", i)
+			continue
+		}
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, c.expect, tokenTexts(actual), "#%d", i)
+	}
+}
+
+func TestTokenizeCommandQuoting(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect [][]bool
+	}{
+		{`lookup '>a' \>b >c`, [][]bool{{false, false, false, false, false, false}, {true, true}, {true, false}, {false, false}}},
+		{`let x="$y" z=\$w`, [][]bool{{false, false, false}, {false, false, true, true}, {false, false, true, false}}},
+		{`read t count=($n + 1)`, [][]bool{{false, false, false, false}, {false}, make([]bool, len("count=($n + 1)"))}},
+	}
+	for i, c := range cases {
+		tokens, err := tokenizeCommand(c.input)
+		assert.NoError(t, err, "#%d", i)
+		actual := make([][]bool, len(tokens))
+		for j, tk := range tokens {
+			actual[j] = tk.literal
+		}
+		assert.Equal(t, c.expect, actual, "#%d", i)
+	}
+}
+
+func TestJoinCommand(t *testing.T) {
+	cases := [][]string{
+		{"read", "users", "start=user 1"},
+		{"lookup", "users", `say "hi"`, "it's", `a\b`, ""},
+		{"read", "users", "count=(1 + 2)"},
+		{"lookup", "users", ">a", ">>b", "c>"},
+	}
+	for i, c := range cases {
+		actual, err := tokenizeCommand(joinCommand(c))
+		assert.NoError(t, err, "#%d", i)
+		assert.Equal(t, c, tokenTexts(actual), "#%d", i)
+	}
+}
@@ -0,0 +1,204 @@
+package interfaces
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/takashabe/btcli/api/config"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// transformStage transforms a value, a stage of the pipeline
+type transformStage func(v []byte) ([]byte, error)
+
+// columnTransform is the compiled pipeline applied to the values of the matched columns
+type columnTransform struct {
+	name    string
+	columns *regexp.Regexp
+	stages  []transformStage
+}
+
+// compileTransforms compiles the transforms of the config in the order of the definitions
+func compileTransforms(ts []*config.Transform, protoFiles *protoregistry.Files) ([]*columnTransform, error) {
+	compiled := make([]*columnTransform, 0, len(ts))
+	for _, t := range ts {
+		columns, err := regexp.Compile(t.Columns)
+		if err != nil {
+			return nil, fmt.Errorf("transform %q: invalid columns: %v", t.Name, err)
+		}
+		stages, err := parsePipeline(t.Pipeline, protoFiles)
+		if err != nil {
+			return nil, fmt.Errorf("transform %q: %v", t.Name, err)
+		}
+		compiled = append(compiled, &columnTransform{name: t.Name, columns: columns, stages: stages})
+	}
+	return compiled, nil
+}
+
+// parsePipeline parses the stages separated by "|", e.g. "gunzip | jsonpath:$.user.id"
+func parsePipeline(pipeline string, protoFiles *protoregistry.Files) ([]transformStage, error) {
+	var stages []transformStage
+	for _, s := range strings.Split(pipeline, "|") {
+		s = strings.TrimSpace(s)
+		name, arg := s, ""
+		if i := strings.Index(s, ":"); i >= 0 {
+			name, arg = s[:i], s[i+1:]
+		}
+		switch name {
+		case "gunzip":
+			stages = append(stages, gunzipStage)
+		case "base64":
+			stages = append(stages, base64Stage)
+		case "jsonpath":
+			path, err := parseJSONPath(arg)
+			if err != nil {
+				return nil, err
+			}
+			stages = append(stages, jsonPathStage(path))
+		case "proto":
+			if protoFiles == nil {
+				return nil, errors.New("proto stage needs the descriptors, set -proto-descriptors")
+			}
+			stages = append(stages, protoStage(protoFiles, arg))
+		case "avro":
+			schema, err := loadAvroSchema(arg)
+			if err != nil {
+				return nil, err
+			}
+			stages = append(stages, avroStage(schema))
+		default:
+			return nil, fmt.Errorf("unknown stage %q, must be one of gunzip, base64, jsonpath:<path>, proto:<message>, avro:<schema>", s)
+		}
+	}
+	return stages, nil
+}
+
+// apply transforms the value by the stages
+func (t *columnTransform) apply(v []byte) ([]byte, error) {
+	for _, stage := range t.stages {
+		var err error
+		if v, err = stage(v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func gunzipStage(v []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(v))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+func base64Stage(v []byte) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(string(v))
+}
+
+// protoStage decodes the message into the JSON, so that jsonpath can follow
+func protoStage(files *protoregistry.Files, message string) transformStage {
+	return func(v []byte) ([]byte, error) {
+		return decodeProto(files, message, v)
+	}
+}
+
+// avroStage decodes the record into the JSON
+func avroStage(schema *avroSchema) transformStage {
+	return func(v []byte) ([]byte, error) {
+		return decodeAvro(schema, v)
+	}
+}
+
+// parseJSONPath parses the path of the fields and the indexes, e.g. "$.users[0].id"
+func parseJSONPath(path string) ([]interface{}, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid jsonpath %q, must start with $", path)
+	}
+	var steps []interface{}
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[") + 1
+			if end == 0 {
+				end = len(rest)
+			}
+			if end == 1 {
+				return nil, fmt.Errorf("invalid jsonpath %q", path)
+			}
+			steps = append(steps, rest[1:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid jsonpath %q", path)
+			}
+			i, err := strconv.Atoi(rest[1:end])
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid jsonpath %q", path)
+			}
+			steps = append(steps, i)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid jsonpath %q", path)
+		}
+	}
+	return steps, nil
+}
+
+// jsonPathStage extracts the value at the path, a string is extracted without the quotes
+func jsonPathStage(path []interface{}) transformStage {
+	return func(v []byte) ([]byte, error) {
+		var doc interface{}
+		if err := unmarshalJSONNumber(v, &doc); err != nil {
+			return nil, err
+		}
+		for _, step := range path {
+			switch s := step.(type) {
+			case string:
+				m, ok := doc.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("no field %q", s)
+				}
+				if doc, ok = m[s]; !ok {
+					return nil, fmt.Errorf("no field %q", s)
+				}
+			case int:
+				a, ok := doc.([]interface{})
+				if !ok || s >= len(a) {
+					return nil, fmt.Errorf("no index %d", s)
+				}
+				doc = a[s]
+			}
+		}
+		if s, ok := doc.(string); ok {
+			return []byte(s), nil
+		}
+		return json.Marshal(doc)
+	}
+}
+
+// transformValue returns the value transformed by the first transform matching the qualifier,
+// the value is returned as is when no transform matches
+func (w *Printer) transformValue(q string, v []byte) ([]byte, error) {
+	for _, t := range w.transforms {
+		if t.columns.MatchString(q) {
+			tv, err := t.apply(v)
+			if err != nil {
+				return nil, fmt.Errorf("transform %s: %v", t.name, err)
+			}
+			return tv, nil
+		}
+	}
+	return v, nil
+}
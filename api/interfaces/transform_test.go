@@ -0,0 +1,121 @@
+package interfaces
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/config"
+)
+
+func gzipValue(s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(s))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestTransformValue(t *testing.T) {
+	cases := []struct {
+		transform *config.Transform
+		qualifier string
+		input     []byte
+		decode    string
+		expect    string
+	}{
+		{
+			&config.Transform{Name: "user", Columns: "^d:event$", Pipeline: "gunzip | jsonpath:$.user.id"},
+			"d:event",
+			gzipValue(`{"user":{"id":"u1"}}`),
+			"string",
+			`"u1"`,
+		},
+		{
+			&config.Transform{Name: "tag", Columns: "^d:", Pipeline: "base64 | jsonpath:$.tags[1]"},
+			"d:event",
+			[]byte("eyJ0YWdzIjpbImEiLCJiIl19"),
+			"string",
+			`"b"`,
+		},
+		{
+			&config.Transform{Name: "user", Columns: "^d:event$", Pipeline: "jsonpath:$.user"},
+			"d:event",
+			[]byte(`{"user":{"id":1}}`),
+			"string",
+			`"{\"id\":1}"`,
+		},
+		{
+			// the large integers aren't rounded
+			&config.Transform{Name: "user", Columns: "^d:event$", Pipeline: "jsonpath:$.user.id"},
+			"d:event",
+			[]byte(`{"user":{"id":12345678901234567891}}`),
+			"string",
+			`"12345678901234567891"`,
+		},
+		{
+			&config.Transform{Name: "user", Columns: "^d:event$", Pipeline: "jsonpath:$.user"},
+			"d:other",
+			[]byte("raw"),
+			"string",
+			`"raw"`,
+		},
+		{
+			&config.Transform{Name: "user", Columns: "^d:event$", Pipeline: "gunzip"},
+			"d:event",
+			[]byte("raw"),
+			"string",
+			"<transform user: unexpected EOF>",
+		},
+		{
+			&config.Transform{Name: "user", Columns: "^d:event$", Pipeline: "jsonpath:$.name"},
+			"d:event",
+			[]byte(`{"user":1}`),
+			"string",
+			`<transform user: no field "name">`,
+		},
+	}
+	for i, c := range cases {
+		transforms, err := compileTransforms([]*config.Transform{c.transform}, nil)
+		if err != nil {
+			t.Fatalf("#%d: want no error, got %v", i, err)
+		}
+		w := &Printer{decodeType: c.decode, transforms: transforms}
+		assert.Equal(t, c.expect, w.formatValue(c.qualifier, c.input), "#%d", i)
+	}
+}
+
+func TestCompileTransformsError(t *testing.T) {
+	cases := []struct {
+		transform *config.Transform
+		expect    string
+	}{
+		{
+			&config.Transform{Name: "a", Columns: "(", Pipeline: "gunzip"},
+			"transform \"a\": invalid columns: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			&config.Transform{Name: "a", Columns: "d:", Pipeline: "gunzip | snappy"},
+			`transform "a": unknown stage "snappy", must be one of gunzip, base64, jsonpath:<path>, proto:<message>, avro:<schema>`,
+		},
+		{
+			&config.Transform{Name: "a", Columns: "d:", Pipeline: "gunzip | proto:Event"},
+			`transform "a": proto stage needs the descriptors, set -proto-descriptors`,
+		},
+		{
+			&config.Transform{Name: "a", Columns: "d:", Pipeline: "jsonpath:user"},
+			`transform "a": invalid jsonpath "user", must start with $`,
+		},
+		{
+			&config.Transform{Name: "a", Columns: "d:", Pipeline: "jsonpath:$.users[x]"},
+			`transform "a": invalid jsonpath "$.users[x]"`,
+		},
+	}
+	for i, c := range cases {
+		_, err := compileTransforms([]*config.Transform{c.transform}, nil)
+		if assert.Error(t, err, "#%d", i) {
+			assert.Equal(t, c.expect, err.Error(), "#%d", i)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/bigtable"
+)
+
+// defaultUsageSample is a ratio of the rows read by usage
+const defaultUsageSample = 0.01
+
+func doUsage(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 2 {
+		e.failf(e.msg("Invalid args: %v\n"), "usage <table> [sample=<p>] [app-profile=<id>]")
+		return
+	}
+	table := args[1]
+
+	parsed := make(map[string]string)
+	for _, arg := range args[2:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "sample", "app-profile":
+			parsed[k] = v
+		}
+	}
+	// 1 reads all rows
+	sample := defaultUsageSample
+	if v := parsed["sample"]; v != "" {
+		p, err := strconv.ParseFloat(v, 64)
+		if err != nil || p <= 0 || p > 1 {
+			e.failf("Invalid sample: %v\n", v)
+			return
+		}
+		sample = p
+	}
+
+	ctx = e.requestContext(parsed)
+	var opts []bigtable.ReadOption
+	if sample < 1 {
+		opts = rowFilterOption(bigtable.RowSampleFilter(sample))
+	}
+	usages, err := e.rowsInteractor.FamilyUsage(ctx, table, opts...)
+	if err != nil {
+		e.printError(err)
+		return
+	}
+	if len(usages) == 0 {
+		fmt.Fprintln(e.errStream, "No cells sampled")
+		return
+	}
+
+	var sampled int64
+	width := 0
+	for _, u := range usages {
+		sampled += u.Bytes
+		if len(u.Family) > width {
+			width = len(u.Family)
+		}
+	}
+	// the sampled bytes are scaled to the table size of the server if available,
+	// the size is the offset of the last sample
+	var tableBytes int64
+	if samples, err := e.rowsInteractor.SampleKeys(ctx, table); err == nil && len(samples) > 0 {
+		tableBytes = samples[len(samples)-1].Offset
+	}
+	for _, u := range usages {
+		share := float64(u.Bytes) / float64(sampled)
+		bytes := int64(float64(u.Bytes) / sample)
+		if tableBytes > 0 {
+			bytes = int64(share * float64(tableBytes))
+		}
+		fmt.Fprintf(e.outStream, "%-*s  cells: %s  bytes: %s  share: %.1f%%\n",
+			width, u.Family, e.formatNumber(int64(float64(u.Cells)/sample)), e.formatNumber(bytes), 100*share)
+	}
+	if tableBytes > 0 {
+		fmt.Fprintf(e.errStream, "Estimated by %g of the rows scaled to %s bytes of the table\n", sample, e.formatNumber(tableBytes))
+	} else {
+		fmt.Fprintf(e.errStream, "Estimated by %g of the rows\n", sample)
+	}
+}
@@ -0,0 +1,120 @@
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoUsage(t *testing.T) {
+	rows := []*domain.Row{
+		&domain.Row{
+			Key: "a",
+			Columns: []*domain.Column{
+				&domain.Column{Family: "d", Qualifier: "d:q", Value: []byte("1234567")},
+				&domain.Column{Family: "m", Qualifier: "m:q", Value: []byte{}},
+			},
+		},
+		&domain.Row{
+			Key: "b",
+			Columns: []*domain.Column{
+				&domain.Column{Family: "d", Qualifier: "d:q", Value: []byte("1234567")},
+			},
+		},
+	}
+	scan := func(ctx context.Context, table string, rs bigtable.RowSet, f func(*domain.Row) bool, opts ...bigtable.ReadOption) error {
+		for _, r := range rows {
+			f(r)
+		}
+		return nil
+	}
+	sample := func(p float64) bigtable.ReadOption {
+		return bigtable.RowFilter(bigtable.RowSampleFilter(p))
+	}
+
+	cases := []struct {
+		input     string
+		expectOut string
+		expectErr string
+		prepare   func(*repository.MockBigtable)
+	}{
+		{
+			"usage table",
+			"d  cells: 200  bytes: 900  share: 90.0%\n" +
+				"m  cells: 100  bytes: 100  share: 10.0%\n",
+			"Estimated by 0.01 of the rows scaled to 1,000 bytes of the table\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.InfiniteRange(""), gomock.Any(), sample(0.01)).DoAndReturn(scan)
+				mock.EXPECT().SampleKeys(gomock.Any(), "table").Return([]*domain.KeySample{
+					&domain.KeySample{Key: "b", Offset: 500},
+					&domain.KeySample{Key: "", Offset: 1000},
+				}, nil)
+			},
+		},
+		{
+			"usage table sample=0.5",
+			"d  cells: 4  bytes: 36  share: 90.0%\n" +
+				"m  cells: 2  bytes: 4  share: 10.0%\n",
+			"Estimated by 0.5 of the rows\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.InfiniteRange(""), gomock.Any(), sample(0.5)).DoAndReturn(scan)
+				mock.EXPECT().SampleKeys(gomock.Any(), "table").Return(nil, errors.New("unavailable"))
+			},
+		},
+		{
+			"usage table sample=1",
+			"d  cells: 2  bytes: 18  share: 90.0%\n" +
+				"m  cells: 1  bytes: 2  share: 10.0%\n",
+			"Estimated by 1 of the rows\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.InfiniteRange(""), gomock.Any()).DoAndReturn(scan)
+				mock.EXPECT().SampleKeys(gomock.Any(), "table").Return([]*domain.KeySample{}, nil)
+			},
+		},
+		{
+			"usage table",
+			"",
+			"No cells sampled\n",
+			func(mock *repository.MockBigtable) {
+				mock.EXPECT().ScanRows(gomock.Any(), "table", bigtable.InfiniteRange(""), gomock.Any(), sample(0.01)).Return(nil)
+			},
+		},
+		{
+			"usage table sample=0",
+			"",
+			"Invalid sample: 0\n",
+			func(mock *repository.MockBigtable) {},
+		},
+		{
+			"usage table count=1",
+			"",
+			"Unknown arg: count=1\n",
+			func(mock *repository.MockBigtable) {},
+		},
+	}
+	for i, c := range cases {
+		ctrl := gomock.NewController(t)
+		mockBtRepo := repository.NewMockBigtable(ctrl)
+		c.prepare(mockBtRepo)
+
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream:      &out,
+			errStream:      &errOut,
+			rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+			numberFormat:   "comma",
+		}
+		executor.Do(c.input)
+		assert.Equal(t, c.expectOut, out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+		ctrl.Finish()
+	}
+}
@@ -0,0 +1,202 @@
+package interfaces
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDiffLines is a limit of the lines compared by the line diff, the larger values are printed in full
+const maxDiffLines = 1000
+
+// printValueDiff prints the change of the value,
+// the JSON values are compared by the fields and the multi-line texts by the lines
+func (w *Printer) printValueDiff(q string, prev, v []byte) {
+	// the cell is written again with the same value
+	if bytes.Equal(prev, v) {
+		fmt.Fprintf(w.outStream, "    %s (unchanged)\n", w.formatValue(q, v))
+		return
+	}
+	if w.isText(q, prev) && w.isText(q, v) {
+		// the same JSON in the other spaces or the order of the fields is printed as the values
+		if a, b, ok := parseJSONValues(prev, v); ok {
+			if lines := diffJSON("", a, b); len(lines) > 0 {
+				for _, l := range lines {
+					fmt.Fprintf(w.outStream, "    %s\n", l)
+				}
+				return
+			}
+		}
+		if bytes.Contains(prev, []byte("\n")) || bytes.Contains(v, []byte("\n")) {
+			if lines, ok := diffLines(strings.Split(string(prev), "\n"), strings.Split(string(v), "\n")); ok {
+				for _, l := range lines {
+					fmt.Fprintf(w.outStream, "    %s\n", l)
+				}
+				return
+			}
+		}
+	}
+	fmt.Fprintf(w.outStream, "    %s -> %s\n", w.formatValue(q, prev), w.formatValue(q, v))
+}
+
+// isText reports whether the value is decoded as a text
+func (w *Printer) isText(q string, v []byte) bool {
+	switch w.decodeTypeOf(q) {
+	case decodeTypeString, decodeTypeJSON:
+		return utf8.Valid(v)
+	case decodeTypeInt, decodeTypeFloat, decodeTypeHex, decodeTypeBase64:
+		return false
+	default:
+		if isStructuredDecode(w.decodeTypeOf(q)) {
+			return false
+		}
+		// 8 bytes values may be guessed as the numbers
+		return w.guessNumber(v) == "" && isPrintable(v)
+	}
+}
+
+// parseJSONValues returns the values parsed as the JSON objects or arrays, the numbers are kept as json.Number
+func parseJSONValues(a, b []byte) (interface{}, interface{}, bool) {
+	var va, vb interface{}
+	for _, p := range []struct {
+		data []byte
+		v    *interface{}
+	}{{a, &va}, {b, &vb}} {
+		trimmed := bytes.TrimSpace(p.data)
+		if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+			return nil, nil, false
+		}
+		if err := unmarshalJSONNumber(trimmed, p.v); err != nil {
+			return nil, nil, false
+		}
+	}
+	return va, vb, true
+}
+
+// unmarshalJSONNumber parses the JSON with the numbers as json.Number, the large integers aren't rounded by float64
+func unmarshalJSONNumber(data []byte, v *interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(v); err != nil {
+		return err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return fmt.Errorf("invalid character after top-level value")
+	}
+	return nil
+}
+
+// diffJSON returns the changed fields as "- <path>: <value>" and "+ <path>: <value>" lines
+func diffJSON(path string, a, b interface{}) []string {
+	ma, aok := a.(map[string]interface{})
+	mb, bok := b.(map[string]interface{})
+	if aok && bok {
+		keys := []string{}
+		for k := range ma {
+			keys = append(keys, k)
+		}
+		for k := range mb {
+			if _, ok := ma[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		var lines []string
+		for _, k := range keys {
+			va, inA := ma[k]
+			vb, inB := mb[k]
+			p := path + "." + k
+			switch {
+			case !inA:
+				lines = append(lines, "+ "+p+": "+jsonString(vb))
+			case !inB:
+				lines = append(lines, "- "+p+": "+jsonString(va))
+			default:
+				lines = append(lines, diffJSON(p, va, vb)...)
+			}
+		}
+		return lines
+	}
+
+	sa, aok := a.([]interface{})
+	sb, bok := b.([]interface{})
+	if aok && bok {
+		var lines []string
+		for i := 0; i < len(sa) || i < len(sb); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(sa):
+				lines = append(lines, "+ "+p+": "+jsonString(sb[i]))
+			case i >= len(sb):
+				lines = append(lines, "- "+p+": "+jsonString(sa[i]))
+			default:
+				lines = append(lines, diffJSON(p, sa[i], sb[i])...)
+			}
+		}
+		return lines
+	}
+
+	if ja, jb := jsonString(a), jsonString(b); ja != jb {
+		if path == "" {
+			path = "."
+		}
+		return []string{"- " + path + ": " + ja, "+ " + path + ": " + jb}
+	}
+	return nil
+}
+
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// diffLines returns the lines in the unified diff format by the longest common subsequence,
+// false if the texts are too large to compare
+func diffLines(a, b []string) ([]string, bool) {
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return nil, false
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case j >= len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	return lines, true
+}
@@ -0,0 +1,81 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintValueDiff(t *testing.T) {
+	cases := []struct {
+		printer *Printer
+		prev    string
+		value   string
+		expect  string
+	}{
+		{
+			&Printer{decodeType: "string"},
+			"a1",
+			"a2",
+			"    \"a1\" -> \"a2\"\n",
+		},
+		{
+			&Printer{},
+			`{"name":"a","tags":["x","y"],"old":1}`,
+			`{"name":"b","tags":["x"],"new":{"n":2}}`,
+			"    - .name: \"a\"\n" +
+				"    + .name: \"b\"\n" +
+				"    + .new: {\"n\":2}\n" +
+				"    - .old: 1\n" +
+				"    - .tags[1]: \"y\"\n",
+		},
+		{
+			&Printer{decodeType: "string"},
+			"line1\nline2\nline3",
+			"line1\nline2'\nline3\nline4",
+			"      line1\n" +
+				"    - line2\n" +
+				"    + line2'\n" +
+				"      line3\n" +
+				"    + line4\n",
+		},
+		{
+			// JSON isn't compared when the value is decoded as a number
+			&Printer{decodeType: "int"},
+			"[1,2,34]",
+			"[1,2,35]",
+			"    6571081925311804509 -> 6571081925311804765\n",
+		},
+		{
+			// the large integers aren't rounded
+			&Printer{},
+			`{"id":12345678901234567890}`,
+			`{"id":12345678901234567891}`,
+			"    - .id: 12345678901234567890\n" +
+				"    + .id: 12345678901234567891\n",
+		},
+		{
+			// only the version is changed
+			&Printer{decodeType: "string"},
+			`{"id":1}`,
+			`{"id":1}`,
+			"    \"{\\\"id\\\":1}\" (unchanged)\n",
+		},
+		{
+			// the same JSON in the other order
+			&Printer{decodeType: "string"},
+			`{"a":1,"b":2}`,
+			`{"b":2,"a":1}`,
+			"    \"{\\\"a\\\":1,\\\"b\\\":2}\" -> \"{\\\"b\\\":2,\\\"a\\\":1}\"\n",
+		},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		c.printer.outStream = &buf
+		c.printer.errStream = &buf
+
+		c.printer.printValueDiff("d:row", []byte(c.prev), []byte(c.value))
+		assert.Equal(t, c.expect, buf.String())
+	}
+}
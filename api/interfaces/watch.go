@@ -0,0 +1,233 @@
+package interfaces
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/takashabe/btcli/api/domain"
+)
+
+const defaultWatchInterval = 2 * time.Second
+
+func doWatchRow(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 3 {
+		e.failf(e.msg("Invalid args: %v\n"), "watch-row <table> <row> [interval=<duration>] [count=<n>]")
+		return
+	}
+	table := args[1]
+	key := args[2]
+
+	parsed := make(map[string]string)
+	for _, arg := range args[3:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "decode", "decode_columns":
+			parsed[k] = v
+		case "interval", "count", "family", "columns":
+			parsed[k] = v
+		}
+	}
+
+	interval := defaultWatchInterval
+	if v := parsed["interval"]; v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			e.failf("Invalid interval: %v\n", err)
+			return
+		}
+		// interval=0 would poll the row without waiting
+		if d <= 0 {
+			e.failf("Invalid interval: must be a positive duration: %q\n", v)
+			return
+		}
+		interval = d
+	}
+	// count is a number of polls, 0 means until interrupted
+	count := 0
+	if v := parsed["count"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			e.failf("Invalid count: %v\n", err)
+			return
+		}
+		if n < 0 {
+			e.failf("Invalid count: must be a non-negative integer: %q\n", v)
+			return
+		}
+		count = n
+	}
+	delete(parsed, "count")
+	parsed["version"] = "1"
+	ro, err := readOption(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+
+	// stop watching by Ctrl+C instead of exiting the prompt
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+
+	p := e.newPrinter(parsed)
+	var prev *domain.Row
+	for i := 0; count == 0 || i < count; i++ {
+		if i > 0 {
+			select {
+			case <-sig:
+				return
+			case <-time.After(interval):
+			}
+		}
+
+		row, err := e.rowsInteractor.GetRow(ctx, table, key, ro...)
+		if err != nil {
+			e.printError(err)
+			return
+		}
+		if prev == nil {
+			p.printRow(row)
+		} else {
+			p.printRowDiff(prev, row)
+		}
+		prev = row
+	}
+}
+
+func doTail(ctx context.Context, e *Executor, args ...string) {
+	if len(args) < 2 {
+		e.failf(e.msg("Invalid args: %v\n"), "tail <table> [start=<row>] [end=<row>] [prefix=<prefix>] [interval=<duration>] [count=<n>] [since=<timestamp>]")
+		return
+	}
+	table := args[1]
+
+	parsed := make(map[string]string)
+	for _, arg := range args[2:] {
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			e.failf(e.msg("Invalid args: %v\n"), arg)
+			return
+		}
+		k, v := arg[:i], arg[i+1:]
+		switch k {
+		default:
+			e.failf(e.msg("Unknown arg: %v\n"), arg)
+			return
+		case "decode", "decode_columns":
+			parsed[k] = v
+		case "start", "end", "prefix", "family", "columns", "qualifier-regex", "value-regex":
+			parsed[k] = v
+		case "interval", "count", "since":
+			parsed[k] = v
+		}
+	}
+	if (parsed["start"] != "" || parsed["end"] != "") && parsed["prefix"] != "" {
+		e.failln(`"start"/"end" may not be mixed with "prefix"`)
+		return
+	}
+
+	interval := defaultWatchInterval
+	if v := parsed["interval"]; v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			e.failf("Invalid interval: %v\n", err)
+			return
+		}
+		// interval=0 would poll the rows without waiting
+		if d <= 0 {
+			e.failf("Invalid interval: must be a positive duration: %q\n", v)
+			return
+		}
+		interval = d
+	}
+	// count is a number of polls, 0 means until interrupted
+	count := 0
+	if v := parsed["count"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			e.failf("Invalid count: %v\n", err)
+			return
+		}
+		if n < 0 {
+			e.failf("Invalid count: must be a non-negative integer: %q\n", v)
+			return
+		}
+		count = n
+	}
+	since := time.Now()
+	if v := parsed["since"]; v != "" {
+		t, err := parseTimestamp(v)
+		if err != nil {
+			e.failf("Invalid since: %v\n", err)
+			return
+		}
+		since = t
+	}
+	// the cell timestamps have the millisecond granularity
+	since = since.Truncate(time.Millisecond)
+
+	rr, err := rowRange(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid range: %v\n"), err)
+		return
+	}
+	filters, err := readFilters(parsed)
+	if err != nil {
+		e.failf(e.msg("Invalid options: %v\n"), err)
+		return
+	}
+
+	// stop tailing by Ctrl+C instead of exiting the prompt
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	defer signal.Stop(sig)
+
+	p := e.newPrinter(parsed)
+	for i := 0; count == 0 || i < count; i++ {
+		if i > 0 {
+			select {
+			case <-sig:
+				return
+			case <-time.After(interval):
+			}
+		}
+
+		// read only the cells written after the last seen cell
+		ro := rowFilterOption(append(filters, bigtable.TimestampRangeFilter(since, time.Time{}))...)
+		rows, err := e.rowsInteractor.GetRows(ctx, table, rr, ro...)
+		if err != nil {
+			e.printError(err)
+			return
+		}
+		p.printRows(rows)
+		if last := latestVersion(rows); !last.Before(since) {
+			since = last.Truncate(time.Millisecond).Add(time.Millisecond)
+		}
+	}
+}
+
+// latestVersion returns the newest timestamp of the cells in the rows
+func latestVersion(rows []*domain.Row) time.Time {
+	var latest time.Time
+	for _, r := range rows {
+		for _, c := range r.Columns {
+			if c.Version.After(latest) {
+				latest = c.Version
+			}
+		}
+	}
+	return latest
+}
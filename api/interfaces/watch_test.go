@@ -0,0 +1,127 @@
+package interfaces
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigtable"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/takashabe/btcli/api/application"
+	"github.com/takashabe/btcli/api/domain"
+	"github.com/takashabe/btcli/api/domain/repository"
+)
+
+func TestDoWatchRowExecutor(t *testing.T) {
+	tm, _ := time.Parse("2006-01-02 15:04:05", "2018-01-01 00:00:00")
+	row := func(value string, tm time.Time) *domain.Bigtable {
+		return &domain.Bigtable{
+			Table: "table",
+			Rows: []*domain.Row{
+				&domain.Row{
+					Key: "a",
+					Columns: []*domain.Column{
+						&domain.Column{
+							Family:    "d",
+							Qualifier: "d:row",
+							Value:     []byte(value),
+							Version:   tm,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	gomock.InOrder(
+		mockBtRepo.EXPECT().Get(gomock.Any(), "table", "a", bigtable.RowFilter(bigtable.LatestNFilter(1))).Return(row("a1", tm), nil),
+		mockBtRepo.EXPECT().Get(gomock.Any(), "table", "a", bigtable.RowFilter(bigtable.LatestNFilter(1))).Return(row("a1", tm), nil),
+		mockBtRepo.EXPECT().Get(gomock.Any(), "table", "a", bigtable.RowFilter(bigtable.LatestNFilter(1))).Return(row("a2", tm.Add(time.Second)), nil),
+	)
+
+	var buf bytes.Buffer
+	executor := Executor{
+		outStream:      &buf,
+		errStream:      &buf,
+		rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+	}
+	executor.Do("watch-row table a interval=1ms count=3 decode=string")
+
+	expect := "----------------------------------------\na\n  d:row                                    @ 2018/01/01-00:00:00.000000\n    \"a1\"\n" +
+		"~ d:row                                    @ 2018/01/01-00:00:01.000000\n    \"a1\" -> \"a2\"\n"
+	assert.Equal(t, expect, buf.String())
+}
+
+func TestDoTailExecutor(t *testing.T) {
+	tm := time.Date(2018, 1, 1, 0, 0, 0, 0, time.Local)
+	rows := func(key, value string, tm time.Time) *domain.Bigtable {
+		return &domain.Bigtable{
+			Table: "table",
+			Rows: []*domain.Row{
+				&domain.Row{
+					Key: key,
+					Columns: []*domain.Column{
+						&domain.Column{
+							Family:    "d",
+							Qualifier: "d:row",
+							Value:     []byte(value),
+							Version:   tm,
+						},
+					},
+				},
+			},
+		}
+	}
+	after := func(t time.Time) bigtable.ReadOption {
+		return bigtable.RowFilter(bigtable.TimestampRangeFilter(t, time.Time{}))
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBtRepo := repository.NewMockBigtable(ctrl)
+	gomock.InOrder(
+		mockBtRepo.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("a"), after(tm)).Return(rows("a1", "v1", tm), nil),
+		mockBtRepo.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("a"), after(tm.Add(time.Millisecond))).Return(rows("a2", "v2", tm.Add(time.Second)), nil),
+		mockBtRepo.EXPECT().GetRows(gomock.Any(), "table", bigtable.PrefixRange("a"), after(tm.Add(time.Second+time.Millisecond))).Return(&domain.Bigtable{}, nil),
+	)
+
+	var buf bytes.Buffer
+	executor := Executor{
+		outStream:      &buf,
+		errStream:      &buf,
+		rowsInteractor: application.NewRowsInteractor(mockBtRepo),
+	}
+	executor.Do("tail table prefix=a interval=1ms count=3 since=2018-01-01 decode=string")
+
+	expect := "----------------------------------------\na1\n  d:row                                    @ 2018/01/01-00:00:00.000000\n    \"v1\"\n" +
+		"----------------------------------------\na2\n  d:row                                    @ 2018/01/01-00:00:01.000000\n    \"v2\"\n"
+	assert.Equal(t, expect, buf.String())
+}
+
+func TestDoWatchInvalid(t *testing.T) {
+	cases := []struct {
+		input     string
+		expectErr string
+	}{
+		{"tail table count=-1", "Invalid count: must be a non-negative integer: \"-1\"\n"},
+		{"tail table interval=-1s", "Invalid interval: must be a positive duration: \"-1s\"\n"},
+		{"tail table interval=0s", "Invalid interval: must be a positive duration: \"0s\"\n"},
+		{"watch-row table a count=-1", "Invalid count: must be a non-negative integer: \"-1\"\n"},
+		{"watch-row table a interval=-1s", "Invalid interval: must be a positive duration: \"-1s\"\n"},
+		{"watch-row table a interval=0 count=0", "Invalid interval: must be a positive duration: \"0\"\n"},
+	}
+	for i, c := range cases {
+		var out, errOut bytes.Buffer
+		executor := Executor{
+			outStream: &out,
+			errStream: &errOut,
+		}
+		executor.Do(c.input)
+		assert.Equal(t, "", out.String(), "#%d", i)
+		assert.Equal(t, c.expectErr, errOut.String(), "#%d", i)
+	}
+}